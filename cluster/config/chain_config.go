@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/QuarkChain/goquarkchain/account"
 	ethcom "github.com/ethereum/go-ethereum/common"
+	"math"
 	"math/big"
 )
 
@@ -25,6 +26,9 @@ type ChainConfig struct {
 	DifficultyAdjustmentFactor     uint32      `json:"DIFFICULTY_ADJUSTMENT_FACTOR"`
 	ExtraShardBlocksInRootBlock    uint32      `json:"EXTRA_SHARD_BLOCKS_IN_ROOT_BLOCK"`
 	PoswConfig                     *POSWConfig `json:"POSW_CONFIG"`
+	// EnableEthTxHeight is the minor block height from which the shards of the
+	// chain accept transactions signed in the Ethereum format, never by default
+	EnableEthTxHeight uint64 `json:"ENABLE_ETH_TX_HEIGHT"`
 }
 
 func NewChainConfig() *ChainConfig {
@@ -41,6 +45,7 @@ func NewChainConfig() *ChainConfig {
 		ExtraShardBlocksInRootBlock:    3,
 		PoswConfig:                     NewPOSWConfig(),
 		EpochInterval:                  uint64(210000 * 60),
+		EnableEthTxHeight:              math.MaxUint64,
 	}
 }
 
@@ -60,6 +65,7 @@ func (c *ChainConfig) UnmarshalJSON(input []byte) error {
 		ChainConfigAlias
 		CoinbaseAddress string `json:"COINBASE_ADDRESS"`
 	}
+	jsonConfig.EnableEthTxHeight = math.MaxUint64
 	if err := json.Unmarshal(input, &jsonConfig); err != nil {
 		return err
 	}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"math/big"
+	"os"
 	"sort"
 
 	"github.com/QuarkChain/goquarkchain/account"
 	"github.com/QuarkChain/goquarkchain/common"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
 	ethcom "github.com/ethereum/go-ethereum/common"
 )
 
@@ -19,28 +23,44 @@ var (
 )
 
 type ClusterConfig struct {
-	P2PPort                  uint16            `json:"P2P_PORT"`
-	JSONRPCPort              uint16            `json:"JSON_RPC_PORT"`
-	JSONRPCHOST              string            `json:"JSON_RPC_HOST"`
-	PrivateJSONRPCPort       uint16            `json:"PRIVATE_JSON_RPC_PORT"`
-	PrivateJSONRPCHOST       string            `json:"PRIVATE_JSON_RPC_HOST"`
-	EnableTransactionHistory bool              `json:"ENABLE_TRANSACTION_HISTORY"`
-	DbPathRoot               string            `json:"DB_PATH_ROOT"`
-	LogLevel                 string            `json:"LOG_LEVEL"`
-	StartSimulatedMining     bool              `json:"START_SIMULATED_MINING"`
-	Clean                    bool              `json:"CLEAN"`
-	GenesisDir               string            `json:"GENESIS_DIR"`
-	Quarkchain               *QuarkChainConfig `json:"QUARKCHAIN"`
-	Master                   *MasterConfig     `json:"MASTER"`
-	SlaveList                []*SlaveConfig    `json:"SLAVE_LIST"`
-	SimpleNetwork            *SimpleNetwork    `json:"SIMPLE_NETWORK,omitempty"`
-	P2P                      *P2PConfig        `json:"P2P,omitempty"`
-	Monitoring               *MonitoringConfig `json:"MONITORING"`
+	P2PPort                  uint16                     `json:"P2P_PORT"`
+	JSONRPCPort              uint16                     `json:"JSON_RPC_PORT"`
+	JSONRPCHOST              string                     `json:"JSON_RPC_HOST"`
+	PrivateJSONRPCPort       uint16                     `json:"PRIVATE_JSON_RPC_PORT"`
+	PrivateJSONRPCHOST       string                     `json:"PRIVATE_JSON_RPC_HOST"`
+	WSJSONRPCPort            uint16                     `json:"WEBSOCKET_JSON_RPC_PORT"`
+	EthFullShardKey          uint32                     `json:"ETH_FULL_SHARD_KEY"` // shard of the eth namespace
+	EnableTransactionHistory bool                       `json:"ENABLE_TRANSACTION_HISTORY"`
+	DbPathRoot               string                     `json:"DB_PATH_ROOT"`
+	LogLevel                 string                     `json:"LOG_LEVEL"`
+	StartSimulatedMining     bool                       `json:"START_SIMULATED_MINING"`
+	Clean                    bool                       `json:"CLEAN"`
+	GenesisDir               string                     `json:"GENESIS_DIR"`
+	Quarkchain               *QuarkChainConfig          `json:"QUARKCHAIN"`
+	Master                   *MasterConfig              `json:"MASTER"`
+	SlaveList                []*SlaveConfig             `json:"SLAVE_LIST"`
+	SimpleNetwork            *SimpleNetwork             `json:"SIMPLE_NETWORK,omitempty"`
+	P2P                      *P2PConfig                 `json:"P2P,omitempty"`
+	Monitoring               *MonitoringConfig          `json:"MONITORING"`
+	GasPriceOracle           *GasPriceOracleConfig      `json:"GAS_PRICE_ORACLE"`
+	RateLimit                *rpcconfig.RateLimitConfig `json:"RATE_LIMIT"`
+	PrivateJSONRPCAuth       *rpcconfig.AuthConfig      `json:"PRIVATE_JSON_RPC_AUTH"`
+	Readiness                *ReadinessConfig           `json:"READINESS"`
 	CheckDB                  bool
 	CheckDBRBlockFrom        int
 	CheckDBRBlockTo          int
 	CheckDBRBlockBatch       int
+	CheckDBReport            string
 	NoPruning                bool
+	// CheckDBRegenerateRange is how many minor blocks the db check runs again
+	// at most to regenerate the pruned state of the parent of a block
+	CheckDBRegenerateRange int
+	// FastSync makes the shards download the states of the blocks near the tip
+	// of the peers instead of executing all the blocks when syncing
+	FastSync bool `json:"FAST_SYNC"`
+	// ConfigFile is the file the config is loaded from, rewritten when shards
+	// move between slaves
+	ConfigFile string `json:"-"`
 }
 
 func NewClusterConfig() *ClusterConfig {
@@ -50,6 +70,7 @@ func NewClusterConfig() *ClusterConfig {
 		JSONRPCHOST:              "0.0.0.0",
 		PrivateJSONRPCPort:       DefaultPrivRpcPort,
 		PrivateJSONRPCHOST:       DefaultHost,
+		WSJSONRPCPort:            DefaultMasterWSPort,
 		EnableTransactionHistory: false,
 		DbPathRoot:               "./db",
 		LogLevel:                 "info",
@@ -61,6 +82,10 @@ func NewClusterConfig() *ClusterConfig {
 		SimpleNetwork:            NewSimpleNetwork(),
 		P2P:                      NewP2PConfig(),
 		Monitoring:               NewMonitoringConfig(),
+		GasPriceOracle:           NewGasPriceOracleConfig(),
+		RateLimit:                rpcconfig.NewRateLimitConfig(),
+		PrivateJSONRPCAuth:       rpcconfig.NewAuthConfig(),
+		Readiness:                NewReadinessConfig(),
 		CheckDB:                  false,
 		CheckDBRBlockFrom:        -1,
 		CheckDBRBlockTo:          0,
@@ -133,6 +158,71 @@ func (c *ClusterConfig) BackWardChainMaskList() error {
 	return nil
 }
 
+// UpdateFullShardLists rewrites FULL_SHARD_ID_LIST of the slaves in lists in
+// the cluster config file, the rest of the file is kept. The CHAIN_MASK_LIST
+// of the other slaves is replaced as well, as it is only read when no slave
+// has a FULL_SHARD_ID_LIST.
+func UpdateFullShardLists(file string, lists map[string][]uint32) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var (
+		cfg    map[string]json.RawMessage
+		slaves []map[string]json.RawMessage
+		loaded = NewClusterConfig()
+	)
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(cfg["SLAVE_LIST"], &slaves); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, loaded); err != nil {
+		return err
+	}
+	if err := loaded.BackWardChainMaskList(); err != nil {
+		return err
+	}
+	for i, slave := range slaves {
+		var id string
+		if err := json.Unmarshal(slave["ID"], &id); err != nil {
+			return err
+		}
+		list, ok := lists[id]
+		if !ok {
+			if _, legacy := slave["CHAIN_MASK_LIST"]; !legacy {
+				continue
+			}
+			list = loaded.SlaveList[i].FullShardList
+		}
+		ids := make([]hexutil.Uint, len(list))
+		for i, fullShardId := range list {
+			ids[i] = hexutil.Uint(fullShardId)
+		}
+		if slave["FULL_SHARD_ID_LIST"], err = json.Marshal(ids); err != nil {
+			return err
+		}
+		delete(slave, "CHAIN_MASK_LIST")
+	}
+	if cfg["SLAVE_LIST"], err = json.Marshal(slaves); err != nil {
+		return err
+	}
+	if content, err = json.MarshalIndent(cfg, "", "    "); err != nil {
+		return err
+	}
+	// the file is replaced at once so that it is never left half written
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
 type fullShardList []uint32
 
 func (a fullShardList) Len() int           { return len(a) }
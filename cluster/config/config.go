@@ -27,12 +27,15 @@ const (
 	// PoWQkchash is the consensus type running qkchash algorithm.
 	PoWQkchash = "POW_QKCHASH"
 
-	DefaultGrpcPort    uint16 = 38191
-	DefaultP2PPort     uint16 = 38291
-	DefaultPubRpcPort  uint16 = 38391
-	DefaultPrivRpcPort uint16 = 38491
-	DefaultWSPort      uint16 = 38590
-	DefaultHost               = "localhost"
+	DefaultGrpcPort     uint16 = 38191
+	DefaultP2PPort      uint16 = 38291
+	DefaultPubRpcPort   uint16 = 38391
+	DefaultPrivRpcPort  uint16 = 38491
+	DefaultWSPort       uint16 = 38590
+	DefaultMasterWSPort uint16 = 38690
+	DefaultMetricsPort  uint16 = 38790
+	DefaultHealthPort   uint16 = 38890
+	DefaultHost                = "localhost"
 
 	HeartbeatInterval = time.Duration(4 * time.Second)
 )
@@ -203,7 +206,21 @@ type NetWorkId struct {
 
 type MasterConfig struct {
 	// default 1.0
-	MasterToSlaveConnectRetryDelay float32 `json:"MASTER_TO_SLAVE_CONNECT_RETRY_DELAY"`
+	MasterToSlaveConnectRetryDelay float32        `json:"MASTER_TO_SLAVE_CONNECT_RETRY_DELAY"`
+	TLS                            *GRPCTLSConfig `json:"TLS,omitempty"` // plaintext gRPC links if nil
+}
+
+// GRPCTLSConfig secures the gRPC links of the master or of a slave. The key and
+// certificate identify the process to the others, which check the certificate
+// is issued by the CA bundle for the identity of the process, a DNS name or
+// common name of the certificate. With MutualTLS the server of the process
+// also rejects the connections of clients without such a certificate.
+type GRPCTLSConfig struct {
+	CertFile  string `json:"CERT_FILE"`
+	KeyFile   string `json:"KEY_FILE"`
+	CAFile    string `json:"CA_FILE"`
+	Identity  string `json:"IDENTITY"`
+	MutualTLS bool   `json:"MUTUAL_TLS"`
 }
 
 func NewMasterConfig() *MasterConfig {
@@ -258,6 +275,47 @@ func NewMonitoringConfig() *MonitoringConfig {
 	}
 }
 
+// GasPriceOracleConfig configures the gas price suggestions of the shards.
+type GasPriceOracleConfig struct {
+	CheckBlocks uint64 `json:"CHECK_BLOCKS"` // number of recent blocks sampled
+	Percentile  uint64 `json:"PERCENTILE"`   // percentile of the sampled prices suggested
+	IgnorePrice uint64 `json:"IGNORE_PRICE"` // prices below it in the default chain token are not sampled
+}
+
+func NewGasPriceOracleConfig() *GasPriceOracleConfig {
+	return &GasPriceOracleConfig{
+		CheckBlocks: 5,
+		Percentile:  50,
+		IgnorePrice: 0,
+	}
+}
+
+// Validate checks the oracle samples some blocks and suggests a percentile of
+// their prices.
+func (c *GasPriceOracleConfig) Validate() error {
+	if c.CheckBlocks == 0 {
+		return fmt.Errorf("GAS_PRICE_ORACLE.CHECK_BLOCKS must be positive")
+	}
+	if c.Percentile > 100 {
+		return fmt.Errorf("GAS_PRICE_ORACLE.PERCENTILE must not exceed 100, got %d", c.Percentile)
+	}
+	return nil
+}
+
+// ReadinessConfig sets how many blocks the tips may lag the tips announced by
+// the peers before the readiness probes fail.
+type ReadinessConfig struct {
+	MaxRootTipLag  uint64 `json:"MAX_ROOT_TIP_LAG"`
+	MaxShardTipLag uint64 `json:"MAX_SHARD_TIP_LAG"`
+}
+
+func NewReadinessConfig() *ReadinessConfig {
+	return &ReadinessConfig{
+		MaxRootTipLag:  10,
+		MaxShardTipLag: 60,
+	}
+}
+
 type GenesisAddress struct {
 	Address string `json:"address"`
 	PrivKey string `json:"key"`
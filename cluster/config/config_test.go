@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -196,6 +197,18 @@ func TestShardGenesisAlloc(t *testing.T) {
 	assert.Contains(t, string(jsonConfig), `608060405260043610610112576000357c0100000000000000000000000000000000000000000000000000000000900463ffffffff`)
 }
 
+func TestGasPriceOracleConfigValidate(t *testing.T) {
+	cfg := NewGasPriceOracleConfig()
+	assert.NoError(t, cfg.Validate())
+	cfg.Percentile = 100
+	assert.NoError(t, cfg.Validate())
+	cfg.Percentile = 101
+	assert.Error(t, cfg.Validate())
+	cfg = NewGasPriceOracleConfig()
+	cfg.CheckBlocks = 0
+	assert.Error(t, cfg.Validate())
+}
+
 func loadConfig(file string, cfg *ClusterConfig) error {
 	var (
 		content []byte
@@ -206,3 +219,26 @@ func loadConfig(file string, cfg *ClusterConfig) error {
 	}
 	return json.Unmarshal(content, cfg)
 }
+
+func TestUpdateFullShardLists(t *testing.T) {
+	before := NewClusterConfig()
+	content, err := json.Marshal(before)
+	assert.NoError(t, err)
+	file, err := ioutil.TempFile("", "cluster_config")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	slaveID := before.SlaveList[0].ID
+	assert.NoError(t, UpdateFullShardLists(file.Name(), map[string][]uint32{slaveID: {1, 0x10001}}))
+
+	after := NewClusterConfig()
+	assert.NoError(t, loadConfig(file.Name(), after))
+	assert.Equal(t, []uint32{1, 0x10001}, after.SlaveList[0].FullShardList)
+	before.SlaveList[0].FullShardList = after.SlaveList[0].FullShardList
+	assert.Equal(t, before.SlaveList, after.SlaveList)
+	assert.Equal(t, before.Quarkchain.ChainSize, after.Quarkchain.ChainSize)
+	assert.Equal(t, before.DbPathRoot, after.DbPathRoot)
+}
@@ -8,12 +8,17 @@ import (
 )
 
 type SlaveConfig struct {
-	IP                       string   `json:"HOST"` // DEFAULT_HOST
-	Port                     uint16   `json:"PORT"` // 38392
-	ID                       string   `json:"ID"`
-	WSPort                   uint16   `json:"WEBSOCKET_JSON_RPC_PORT"`
-	FullShardList            []uint32 `json:"-"`
-	ChainMaskListForBackward []uint32 `json:"-"`
+	IP                       string         `json:"HOST"` // DEFAULT_HOST
+	Port                     uint16         `json:"PORT"` // 38392
+	ID                       string         `json:"ID"`
+	WSPort                   uint16         `json:"WEBSOCKET_JSON_RPC_PORT"`
+	FullShardList            []uint32       `json:"-"`
+	ChainMaskListForBackward []uint32       `json:"-"`
+	TLS                      *GRPCTLSConfig `json:"TLS,omitempty"` // plaintext gRPC links if nil
+	// Standby slaves import the blocks of their shards without mining them or
+	// serving the writes until the master promotes them, when an active slave
+	// of the same shards stops answering heartbeats.
+	Standby bool `json:"STANDBY,omitempty"`
 }
 
 type SlaveConfigAlias SlaveConfig
@@ -0,0 +1,266 @@
+package master
+
+import (
+	"context"
+	"errors"
+
+	"github.com/QuarkChain/goquarkchain/core"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/internal/encoder"
+	qrpc "github.com/QuarkChain/goquarkchain/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// rootChainEvChanSize is the size of channel listening to RootChainHeadEvent.
+	rootChainEvChanSize = 10
+	// rootSideEvChanSize is the size of channel listening to RootChainSideEvent.
+	rootSideEvChanSize = 10
+)
+
+// rootChain is the part of the root block chain the subscriptions are served from.
+type rootChain interface {
+	CurrentBlock() *types.RootBlock
+	GetBlock(hash common.Hash) types.IBlock
+	SubscribeChainHeadEvent(ch chan<- core.RootChainHeadEvent) event.Subscription
+	SubscribeChainSideEvent(ch chan<- core.RootChainSideEvent) event.Subscription
+}
+
+// rootChainUpdate is the change of the canonical root chain between two head events.
+type rootChainUpdate struct {
+	ancestor *types.RootBlock
+	reverted []*types.RootBlock // from the old head down to the common ancestor
+	applied  []*types.RootBlock // from the common ancestor up to the new head
+}
+
+// sideRootChain looks up the root blocks of the chain, and of the side events
+// received since the last head event: the blocks removed from the canonical
+// chain by a reorg are known from their side events even if they are no
+// longer in the chain.
+type sideRootChain struct {
+	rootChain
+	side map[common.Hash]*types.RootBlock
+}
+
+func parentRootBlock(chain *sideRootChain, block *types.RootBlock) (*types.RootBlock, error) {
+	if parent, ok := chain.side[block.ParentHash()]; ok {
+		return parent, nil
+	}
+	parent, ok := chain.GetBlock(block.ParentHash()).(*types.RootBlock)
+	if !ok || parent == nil {
+		return nil, errors.New("missing parent of root block " + block.Hash().Hex())
+	}
+	return parent, nil
+}
+
+// diffRootChain walks back from both heads to their common ancestor, returning
+// the root blocks which left and joined the canonical chain.
+func diffRootChain(chain *sideRootChain, oldHead, newHead *types.RootBlock) (*rootChainUpdate, error) {
+	var (
+		update             = new(rootChainUpdate)
+		oldBlock, newBlock = oldHead, newHead
+		err                error
+	)
+	for newBlock.NumberU64() > oldBlock.NumberU64() {
+		update.applied = append(update.applied, newBlock)
+		if newBlock, err = parentRootBlock(chain, newBlock); err != nil {
+			return nil, err
+		}
+	}
+	for oldBlock.NumberU64() > newBlock.NumberU64() {
+		update.reverted = append(update.reverted, oldBlock)
+		if oldBlock, err = parentRootBlock(chain, oldBlock); err != nil {
+			return nil, err
+		}
+	}
+	for oldBlock.Hash() != newBlock.Hash() {
+		update.reverted = append(update.reverted, oldBlock)
+		update.applied = append(update.applied, newBlock)
+		if oldBlock, err = parentRootBlock(chain, oldBlock); err != nil {
+			return nil, err
+		}
+		if newBlock, err = parentRootBlock(chain, newBlock); err != nil {
+			return nil, err
+		}
+	}
+	update.ancestor = oldBlock
+	for i, j := 0, len(update.applied)-1; i < j; i, j = i+1, j-1 {
+		update.applied[i], update.applied[j] = update.applied[j], update.applied[i]
+	}
+	return update, nil
+}
+
+// PublicFilterAPI offers the subscriptions to the root chain on the master,
+// the subscriptions to the shards are served by the slaves.
+type PublicFilterAPI struct {
+	chain rootChain
+}
+
+// NewPublicFilterAPI returns a new PublicFilterAPI instance.
+func NewPublicFilterAPI(chain rootChain) *PublicFilterAPI {
+	return &PublicFilterAPI{chain: chain}
+}
+
+// subscribe creates a subscription which calls handle with the change of the
+// canonical root chain on each root chain head event, the side events of the
+// root blocks removed by a reorg are kept until the next head event.
+func (api *PublicFilterAPI) subscribe(ctx context.Context, handle func(notifier *qrpc.Notifier, id qrpc.ID, update *rootChainUpdate)) (*qrpc.Subscription, error) {
+	notifier, supported := qrpc.NotifierFromContext(ctx)
+	if !supported {
+		return &qrpc.Subscription{}, qrpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		heads := make(chan core.RootChainHeadEvent, rootChainEvChanSize)
+		headsSub := api.chain.SubscribeChainHeadEvent(heads)
+		sides := make(chan core.RootChainSideEvent, rootSideEvChanSize)
+		sidesSub := api.chain.SubscribeChainSideEvent(sides)
+		defer func() {
+			headsSub.Unsubscribe()
+			sidesSub.Unsubscribe()
+		}()
+		chain := &sideRootChain{rootChain: api.chain, side: make(map[common.Hash]*types.RootBlock)}
+		head := api.chain.CurrentBlock()
+		addSide := func(block *types.RootBlock) {
+			// only the blocks up to the current head can be on its chain
+			if block.NumberU64() <= head.NumberU64() {
+				chain.side[block.Hash()] = block
+			}
+		}
+
+		for {
+			select {
+			case ev := <-heads:
+				// the side events sent before the head event are taken first
+				for drained := false; !drained; {
+					select {
+					case side := <-sides:
+						addSide(side.Block)
+					default:
+						drained = true
+					}
+				}
+				update, err := diffRootChain(chain, head, ev.Block)
+				head = ev.Block
+				chain.side = make(map[common.Hash]*types.RootBlock)
+				if err != nil {
+					log.Error("failed to diff root chain for subscription", "head", ev.Block.Hash(), "err", err)
+					continue
+				}
+				handle(notifier, rpcSub.ID, update)
+			case ev := <-sides:
+				addSide(ev.Block)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// NewRootHeads sends a notification with the header of each root block which
+// joins or leaves the canonical root chain: on a reorg, the removed root
+// blocks come first from the old head down, with removed set, then the new
+// ones in the order of height.
+func (api *PublicFilterAPI) NewRootHeads(ctx context.Context) (*qrpc.Subscription, error) {
+	return api.subscribe(ctx, func(notifier *qrpc.Notifier, id qrpc.ID, update *rootChainUpdate) {
+		notify := func(block *types.RootBlock, removed bool) {
+			hd, err := encoder.RootBlockHeaderEncoder(block.Header())
+			if err != nil {
+				log.Error("encode RootBlockHeader error", "err", err)
+				return
+			}
+			hd["removed"] = removed
+			notifier.Notify(id, hd)
+		}
+		for _, block := range update.reverted {
+			notify(block, true)
+		}
+		for _, block := range update.applied {
+			notify(block, false)
+		}
+	})
+}
+
+// RootReorgs sends a notification each time root blocks are removed from the
+// canonical root chain, with the removed and the new canonical root blocks
+// since their common ancestor.
+func (api *PublicFilterAPI) RootReorgs(ctx context.Context) (*qrpc.Subscription, error) {
+	return api.subscribe(ctx, func(notifier *qrpc.Notifier, id qrpc.ID, update *rootChainUpdate) {
+		if len(update.reverted) == 0 {
+			return
+		}
+		reverted, err := encodeRootBlockHeaders(update.reverted)
+		if err != nil {
+			log.Error("encode RootBlockHeader error", "err", err)
+			return
+		}
+		applied, err := encodeRootBlockHeaders(update.applied)
+		if err != nil {
+			log.Error("encode RootBlockHeader error", "err", err)
+			return
+		}
+		notifier.Notify(id, map[string]interface{}{
+			"commonAncestorHash":   update.ancestor.Hash(),
+			"commonAncestorHeight": hexutil.Uint64(update.ancestor.NumberU64()),
+			"revertedBlocks":       reverted,
+			"appliedBlocks":        applied,
+		})
+	})
+}
+
+// MinorBlockConfirmations sends a notification each time a minor block is
+// confirmed by a root block joining the canonical root chain, or loses its
+// confirmation as the root block is removed from the canonical root chain.
+// The notifications are limited to the given shard if fullShardId is set.
+func (api *PublicFilterAPI) MinorBlockConfirmations(ctx context.Context, fullShardId *hexutil.Uint) (*qrpc.Subscription, error) {
+	return api.subscribe(ctx, func(notifier *qrpc.Notifier, id qrpc.ID, update *rootChainUpdate) {
+		notify := func(block *types.RootBlock, reverted bool) {
+			for _, header := range block.MinorBlockHeaders() {
+				if fullShardId != nil && header.Branch.GetFullShardID() != uint32(*fullShardId) {
+					continue
+				}
+				notifier.Notify(id, minorBlockConfirmationEncoder(header, block, reverted))
+			}
+		}
+		for _, block := range update.reverted {
+			notify(block, true)
+		}
+		for _, block := range update.applied {
+			notify(block, false)
+		}
+	})
+}
+
+func encodeRootBlockHeaders(blocks []*types.RootBlock) ([]map[string]interface{}, error) {
+	headers := make([]map[string]interface{}, 0, len(blocks))
+	for _, block := range blocks {
+		hd, err := encoder.RootBlockHeaderEncoder(block.Header())
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, hd)
+	}
+	return headers, nil
+}
+
+func minorBlockConfirmationEncoder(header *types.MinorBlockHeader, rootBlock *types.RootBlock, reverted bool) map[string]interface{} {
+	fullShardId := header.Branch.GetFullShardID()
+	return map[string]interface{}{
+		"minorBlockId":     encoder.IDEncoder(header.Hash().Bytes(), fullShardId),
+		"minorBlockHash":   header.Hash(),
+		"minorBlockHeight": hexutil.Uint64(header.Number),
+		"fullShardId":      hexutil.Uint64(fullShardId),
+		"rootBlockHash":    rootBlock.Hash(),
+		"rootBlockHeight":  hexutil.Uint64(rootBlock.NumberU64()),
+		"reverted":         reverted,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"net"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/QuarkChain/goquarkchain/account"
@@ -60,19 +61,11 @@ func (s *QKCMasterBackend) AddTransaction(tx *types.Transaction) error {
 		return errors.New(fmt.Sprintf("Failed to set fromShardSize, fromShardSize: %d, err: %v", fromShardSize, err))
 	}
 	fullShardId := evmTx.FromFullShardId()
-	slaves := s.GetSlaveConnsById(fullShardId)
-	if len(slaves) == 0 {
+	slaveConn := s.GetOneSlaveConnById(fullShardId)
+	if slaveConn == nil {
 		return ErrNoBranchConn
 	}
-	var g errgroup.Group
-	for index := range slaves {
-		i := index
-		g.Go(func() error {
-			return slaves[i].AddTransaction(tx)
-		})
-	}
-	err = g.Wait() //TODO?? peer broadcast
-	if err != nil {
+	if err := slaveConn.AddTransaction(tx); err != nil {
 		return err
 	}
 	data, err := serialize.SerializeToBytes(&p2p.NewTransactionList{TransactionList: []*types.Transaction{tx}})
@@ -83,7 +76,7 @@ func (s *QKCMasterBackend) AddTransaction(tx *types.Transaction) error {
 	return nil
 }
 
-func (s *QKCMasterBackend) ExecuteTransaction(tx *types.Transaction, address *account.Address, height *uint64) ([]byte, error) {
+func (s *QKCMasterBackend) ExecuteTransaction(tx *types.Transaction, address *account.Address, height *uint64, overrides []*rpc.StateOverride) ([]byte, error) {
 	evmTx := tx.EvmTx
 	fromShardSize, err := s.clusterConfig.Quarkchain.GetShardSizeByChainId(tx.EvmTx.FromChainID())
 	if err != nil {
@@ -92,31 +85,11 @@ func (s *QKCMasterBackend) ExecuteTransaction(tx *types.Transaction, address *ac
 	if err := tx.EvmTx.SetFromShardSize(fromShardSize); err != nil {
 		return nil, errors.New(fmt.Sprintf("Failed to set fromShardSize, fromShardSize: %d, err: %v", fromShardSize, err))
 	}
-	slaves := s.GetSlaveConnsById(evmTx.FromFullShardId())
-	if len(slaves) == 0 {
+	slaveConn := s.GetOneSlaveConnById(evmTx.FromFullShardId())
+	if slaveConn == nil {
 		return nil, ErrNoBranchConn
 	}
-	var g errgroup.Group
-	rspList := make([][]byte, len(slaves))
-	for index := range slaves {
-		i := index
-		g.Go(func() error {
-			rsp, err := slaves[i].ExecuteTransaction(tx, address, height)
-			rspList[i] = rsp
-			return err
-		})
-	}
-	if err := g.Wait(); err != nil {
-		return nil, err
-	}
-
-	resultBytes := rspList[0] // before already this len>0
-	for _, res := range rspList {
-		if res != nil && !bytes.Equal(resultBytes, res) {
-			return nil, errors.New("exist more than one result")
-		}
-	}
-	return resultBytes, nil
+	return slaveConn.ExecuteTransaction(tx, address, height, overrides)
 
 }
 
@@ -161,6 +134,22 @@ func (s *QKCMasterBackend) GetTransactionReceipt(txHash common.Hash, branch acco
 	return slaveConn.GetTransactionReceipt(txHash, branch)
 }
 
+func (s *QKCMasterBackend) TraceTransaction(txHash common.Hash, branch account.Branch, config *rpc.TraceConfig) ([]byte, error) {
+	slaveConn := s.GetOneSlaveConnById(branch.Value)
+	if slaveConn == nil {
+		return nil, ErrNoBranchConn
+	}
+	return slaveConn.TraceTransaction(txHash, branch, config)
+}
+
+func (s *QKCMasterBackend) TraceMinorBlock(blockHash common.Hash, branch account.Branch, config *rpc.TraceConfig) ([]byte, error) {
+	slaveConn := s.GetOneSlaveConnById(branch.Value)
+	if slaveConn == nil {
+		return nil, ErrNoBranchConn
+	}
+	return slaveConn.TraceMinorBlock(blockHash, branch, config)
+}
+
 func (s *QKCMasterBackend) GetTransactionsByAddress(address *account.Address, start []byte, limit uint32, transferTokenID *uint64) ([]*rpc.TransactionDetail, []byte, error) {
 	fullShardID, err := s.clusterConfig.Quarkchain.GetFullShardIdByFullShardKey(address.FullShardKey)
 	if err != nil {
@@ -190,7 +179,7 @@ func (s *QKCMasterBackend) GetLogs(args *qrpc.FilterQuery) ([]*types.Log, error)
 	return slaveConn.GetLogs(args)
 }
 
-func (s *QKCMasterBackend) EstimateGas(tx *types.Transaction, fromAddress *account.Address) (uint32, error) {
+func (s *QKCMasterBackend) EstimateGas(tx *types.Transaction, fromAddress *account.Address, overrides []*rpc.StateOverride) (uint32, error) {
 	evmTx := tx.EvmTx
 	fromShardSize, err := s.clusterConfig.Quarkchain.GetShardSizeByChainId(tx.EvmTx.FromChainID())
 	if err != nil {
@@ -214,10 +203,10 @@ func (s *QKCMasterBackend) EstimateGas(tx *types.Transaction, fromAddress *accou
 		return 0, ErrNoBranchConn
 	}
 	if !evmTx.IsCrossShard() {
-		return slaveConn.EstimateGas(tx, fromAddress)
+		return slaveConn.EstimateGas(tx, fromAddress, overrides)
 	}
 	fAddr := account.Address{Recipient: fromAddress.Recipient, FullShardKey: evmTx.ToFullShardKey()}
-	res, err := slaveConn.EstimateGas(tx, &fAddr)
+	res, err := slaveConn.EstimateGas(tx, &fAddr, overrides)
 	if err != nil {
 		return 0, err
 	}
@@ -236,6 +225,90 @@ func (s *QKCMasterBackend) GetStorageAt(address *account.Address, key common.Has
 	return slaveConn.GetStorageAt(address, key, height)
 }
 
+func (s *QKCMasterBackend) GetProof(address *account.Address, storageKeys []common.Hash, height *uint64) (*rpc.AccountProof, error) {
+	fullShardID, err := s.clusterConfig.Quarkchain.GetFullShardIdByFullShardKey(address.FullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	slaveConn := s.GetOneSlaveConnById(fullShardID)
+	if slaveConn == nil {
+		return nil, ErrNoBranchConn
+	}
+	return slaveConn.GetProof(address, storageKeys, height)
+}
+
+func (s *QKCMasterBackend) GetPendingTransactionHashes(branch account.Branch) ([]common.Hash, error) {
+	slaveConn := s.GetOneSlaveConnById(branch.Value)
+	if slaveConn == nil {
+		return nil, ErrNoBranchConn
+	}
+	return slaveConn.GetPendingTransactionHashes(branch)
+}
+
+func (s *QKCMasterBackend) GetXShardDepositStatus(txHash common.Hash, branch account.Branch, sourceBlockHash, rootBlockHash common.Hash) (*rpc.XShardDepositStatus, error) {
+	slaveConn := s.GetOneSlaveConnById(branch.Value)
+	if slaveConn == nil {
+		return nil, ErrNoBranchConn
+	}
+	return slaveConn.GetXShardDepositStatus(txHash, branch, sourceBlockHash, rootBlockHash)
+}
+
+func (s *QKCMasterBackend) GetFeeHistory(branch account.Branch, tokenID, blockCount uint64, percentiles []uint32) (*rpc.FeeHistory, error) {
+	slaveConn := s.GetOneSlaveConnById(branch.Value)
+	if slaveConn == nil {
+		return nil, ErrNoBranchConn
+	}
+	return slaveConn.GetFeeHistory(branch, tokenID, blockCount, percentiles)
+}
+
+// GetTxPoolContent returns the transaction pool of the shard of branch, or the
+// ones of all the shards sorted by full shard id if branch is nil.
+func (s *QKCMasterBackend) GetTxPoolContent(branch *account.Branch, sender *account.Recipient, countOnly bool) ([]*rpc.ShardTxPool, error) {
+	if branch != nil {
+		slaveConn := s.GetOneSlaveConnById(branch.Value)
+		if slaveConn == nil {
+			return nil, ErrNoBranchConn
+		}
+		return slaveConn.GetTxPoolContent(*branch, sender, countOnly)
+	}
+
+	var (
+		g     errgroup.Group
+		conns = s.GetActiveSlaveConns()
+	)
+	rspList := make(chan []*rpc.ShardTxPool, len(conns))
+	for _, conn := range conns {
+		conn := conn
+		g.Go(func() error {
+			shards, err := conn.GetTxPoolContent(account.Branch{}, sender, countOnly)
+			// the shards the slave is a standby of are left to their active slave
+			pools := make([]*rpc.ShardTxPool, 0, len(shards))
+			for _, pool := range shards {
+				if s.IsActive(conn.GetSlaveID(), pool.Branch) {
+					pools = append(pools, pool)
+				}
+			}
+			rspList <- pools
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	branchToPool := make(map[uint32]*rpc.ShardTxPool)
+	for index := 0; index < len(conns); index++ {
+		for _, pool := range <-rspList {
+			branchToPool[pool.Branch] = pool
+		}
+	}
+	pools := make([]*rpc.ShardTxPool, 0, len(branchToPool))
+	for _, pool := range branchToPool {
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Branch < pools[j].Branch })
+	return pools, nil
+}
+
 func (s *QKCMasterBackend) GetCode(address *account.Address, height *uint64) ([]byte, error) {
 	fullShardID, err := s.clusterConfig.Quarkchain.GetFullShardIdByFullShardKey(address.FullShardKey)
 	if err != nil {
@@ -376,26 +449,18 @@ func (s *QKCMasterBackend) InsertMinedBlock(block types.IBlock) error {
 	return s.AddRootBlock(rBlock)
 }
 
+// AddMinorBlock adds a block mined outside the cluster to the active slave of
+// the shard, which replicates it to the standby ones.
 func (s *QKCMasterBackend) AddMinorBlock(branch uint32, mBlock *types.MinorBlock) error {
-	clients := s.GetSlaveConnsById(branch)
-	if len(clients) == 0 {
+	client := s.GetOneSlaveConnById(branch)
+	if client == nil {
 		return errors.New(fmt.Sprintf("slave is not exist, branch: %d", branch))
 	}
-	var (
-		g errgroup.Group
-	)
 	data, err := serialize.SerializeToBytes(&p2p.NewBlockMinor{Block: mBlock})
 	if err != nil {
 		return err
 	}
-	for _, cli := range clients {
-		cli := cli
-		g.Go(func() error {
-			err := cli.HandleNewMinorBlock(&rpc.P2PRedirectRequest{Branch: branch, Data: data})
-			return err
-		})
-	}
-	return g.Wait()
+	return client.HandleNewMinorBlock(&rpc.P2PRedirectRequest{Branch: branch, Data: data})
 }
 
 func (s *QKCMasterBackend) GetTip() uint64 {
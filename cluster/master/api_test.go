@@ -0,0 +1,170 @@
+package master
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/core"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	qrpc "github.com/QuarkChain/goquarkchain/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRootChain struct {
+	mu            sync.RWMutex
+	current       *types.RootBlock
+	blocks        map[common.Hash]*types.RootBlock
+	chainHeadFeed event.Feed
+	chainSideFeed event.Feed
+}
+
+func (c *testRootChain) CurrentBlock() *types.RootBlock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current
+}
+
+func (c *testRootChain) GetBlock(hash common.Hash) types.IBlock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if block, ok := c.blocks[hash]; ok {
+		return block
+	}
+	return nil
+}
+
+func (c *testRootChain) SubscribeChainHeadEvent(ch chan<- core.RootChainHeadEvent) event.Subscription {
+	return c.chainHeadFeed.Subscribe(ch)
+}
+
+func (c *testRootChain) SubscribeChainSideEvent(ch chan<- core.RootChainSideEvent) event.Subscription {
+	return c.chainSideFeed.Subscribe(ch)
+}
+
+func (c *testRootChain) appendBlock(parent *types.RootBlock, nonce uint64, fullShardIds ...uint32) *types.RootBlock {
+	block := parent.Header().CreateBlockToAppend(nil, nil, nil, &nonce, nil)
+	for _, id := range fullShardIds {
+		block.AddMinorBlockHeader(&types.MinorBlockHeader{Number: block.NumberU64(), Branch: account.NewBranch(id)})
+	}
+	block = block.Finalize(nil, nil, common.Hash{})
+	c.mu.Lock()
+	c.blocks[block.Hash()] = block
+	c.mu.Unlock()
+	return block
+}
+
+func (c *testRootChain) setHead(block *types.RootBlock) {
+	c.mu.Lock()
+	c.current = block
+	c.mu.Unlock()
+	c.chainHeadFeed.Send(core.RootChainHeadEvent{Block: block})
+}
+
+func TestRootChainSubscriptions(t *testing.T) {
+	genesis := core.NewGenesis(config.NewQuarkChainConfig()).CreateRootBlock()
+	chain := &testRootChain{current: genesis, blocks: map[common.Hash]*types.RootBlock{genesis.Hash(): genesis}}
+
+	server := qrpc.NewServer()
+	defer server.Stop()
+	assert.NoError(t, server.RegisterName("ws", NewPublicFilterAPI(chain)))
+	client := qrpc.DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	heads := make(chan map[string]interface{}, 10)
+	headsSub, err := client.Subscribe(ctx, "ws", heads, "newRootHeads")
+	assert.NoError(t, err)
+	defer headsSub.Unsubscribe()
+	reorgs := make(chan map[string]interface{}, 10)
+	reorgsSub, err := client.Subscribe(ctx, "ws", reorgs, "rootReorgs")
+	assert.NoError(t, err)
+	defer reorgsSub.Unsubscribe()
+	confirmations := make(chan map[string]interface{}, 10)
+	confirmationsSub, err := client.Subscribe(ctx, "ws", confirmations, "minorBlockConfirmations", hexutil.Uint(1))
+	assert.NoError(t, err)
+	defer confirmationsSub.Unsubscribe()
+
+	receive := func(ch chan map[string]interface{}) map[string]interface{} {
+		select {
+		case msg := <-ch:
+			return msg
+		case <-time.After(3 * time.Second):
+			t.Fatal("notification timeout")
+		}
+		return nil
+	}
+	// wait for the subscriptions to listen to the head events
+	for chain.chainHeadFeed.Send(core.RootChainHeadEvent{Block: genesis}) < 3 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// two root blocks joining the canonical chain with a single head event
+	b1 := chain.appendBlock(genesis, 0, 1, 65537)
+	b2 := chain.appendBlock(b1, 0, 1)
+	chain.setHead(b2)
+	assert.Equal(t, b1.Hash().Hex(), receive(heads)["hash"])
+	assert.Equal(t, b2.Hash().Hex(), receive(heads)["hash"])
+	for _, block := range []*types.RootBlock{b1, b2} {
+		confirmation := receive(confirmations)
+		assert.Equal(t, block.MinorBlockHeaders()[0].Hash().Hex(), confirmation["minorBlockHash"])
+		assert.Equal(t, block.Hash().Hex(), confirmation["rootBlockHash"])
+		assert.Equal(t, false, confirmation["reverted"])
+	}
+
+	// a fork from b1 replacing b2
+	b2f := chain.appendBlock(b1, 1, 1)
+	b3f := chain.appendBlock(b2f, 1)
+	chain.setHead(b3f)
+	head := receive(heads)
+	assert.Equal(t, b2.Hash().Hex(), head["hash"])
+	assert.Equal(t, true, head["removed"])
+	head = receive(heads)
+	assert.Equal(t, b2f.Hash().Hex(), head["hash"])
+	assert.Equal(t, false, head["removed"])
+	assert.Equal(t, b3f.Hash().Hex(), receive(heads)["hash"])
+	reorg := receive(reorgs)
+	assert.Equal(t, b1.Hash().Hex(), reorg["commonAncestorHash"])
+	assert.Equal(t, "0x1", reorg["commonAncestorHeight"])
+	assert.Equal(t, 1, len(reorg["revertedBlocks"].([]interface{})))
+	assert.Equal(t, b2.Hash().Hex(), reorg["revertedBlocks"].([]interface{})[0].(map[string]interface{})["hash"])
+	assert.Equal(t, 2, len(reorg["appliedBlocks"].([]interface{})))
+	confirmation := receive(confirmations)
+	assert.Equal(t, b2.Hash().Hex(), confirmation["rootBlockHash"])
+	assert.Equal(t, true, confirmation["reverted"])
+	confirmation = receive(confirmations)
+	assert.Equal(t, b2f.Hash().Hex(), confirmation["rootBlockHash"])
+	assert.Equal(t, false, confirmation["reverted"])
+
+	// a fork from b2f replacing b3f, which is only known from its side event
+	b3 := chain.appendBlock(b2f, 2)
+	chain.mu.Lock()
+	delete(chain.blocks, b3f.Hash())
+	chain.mu.Unlock()
+	for chain.chainSideFeed.Send(core.RootChainSideEvent{Block: b3f}) < 3 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	chain.setHead(b3)
+	head = receive(heads)
+	assert.Equal(t, b3f.Hash().Hex(), head["hash"])
+	assert.Equal(t, true, head["removed"])
+	assert.Equal(t, b3.Hash().Hex(), receive(heads)["hash"])
+	reorg = receive(reorgs)
+	assert.Equal(t, b2f.Hash().Hex(), reorg["commonAncestorHash"])
+	assert.Equal(t, b3f.Hash().Hex(), reorg["revertedBlocks"].([]interface{})[0].(map[string]interface{})["hash"])
+
+	select {
+	case msg := <-reorgs:
+		t.Errorf("unexpected reorg %v", msg)
+	case msg := <-confirmations:
+		t.Errorf("unexpected confirmation %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
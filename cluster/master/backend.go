@@ -57,6 +57,7 @@ type QKCMasterBackend struct {
 	ctx                *service.ServiceContext
 	gspc               *core.Genesis
 	lock               sync.RWMutex
+	migrationMu        sync.RWMutex // held to add root blocks, and exclusively to move a shard or add snapshot root blocks
 	engine             consensus.Engine
 	eventMux           *event.TypeMux
 	chainDb            ethdb.Database
@@ -176,7 +177,12 @@ func (s *QKCMasterBackend) Protocols() []p2p.Protocol {
 	return s.protocolManager.subProtocols
 }
 
-func (s *QKCMasterBackend) CheckDB() {
+// CheckDB checks the integrity of the root chain between the configured heights
+// and has the slaves check the minor blocks the root blocks confirm. The root
+// blocks are checked from the highest, stopping at the first inconsistency, and
+// the report has the lowest inconsistency found in each shard. A slave failing
+// to check its shards is recorded in the report and stops the check.
+func (s *QKCMasterBackend) CheckDB() (*DBCheckReport, error) {
 	startTime := time.Now()
 	defer log.Info("Integrity check completed!", "run time", time.Now().Sub(startTime).Seconds())
 
@@ -191,9 +197,10 @@ func (s *QKCMasterBackend) CheckDB() {
 		rb = s.rootBlockChain.GetBlockByNumber(uint64(fromHeight)).(*types.RootBlock)
 		log.Info("Starting from root block ", "height", fromHeight)
 	}
+	report := newDBCheckReport(rb.NumberU64(), toHeight)
 	if b := s.rootBlockChain.GetBlock(rb.Hash()); b == nil || b.Hash() != rb.Hash() {
-		log.Error("Root block mismatches local root block by hash", "height", rb.NumberU64())
-		return
+		report.setRootInconsistency(rb, errors.New("root block mismatches local root block by hash"))
+		return report, nil
 	}
 
 	size := s.clusterConfig.CheckDBRBlockBatch
@@ -206,17 +213,17 @@ func (s *QKCMasterBackend) CheckDB() {
 			}
 			count++
 			if s.rootBlockChain.GetBlockByNumber(rb.NumberU64()).Hash() != rb.Hash() {
-				log.Error("Root block mismatches canonical chain", "height", rb.NumberU64())
-				return
+				report.setRootInconsistency(rb, errors.New("root block mismatches canonical chain"))
+				return report, nil
 			}
 			prevRb := s.rootBlockChain.GetBlock(rb.ParentHash())
 			if prevRb == nil || prevRb.Hash() != rb.ParentHash() {
-				log.Error("Root block mismatches previous block hash", "height", rb.NumberU64())
-				return
+				report.setRootInconsistency(rb, errors.New("root block mismatches previous block hash"))
+				return report, nil
 			}
 			if prevRb.NumberU64()+1 != rb.NumberU64() {
-				log.Error("Root block no equal to previous block Height + 1", "height", rb.NumberU64())
-				return
+				report.setRootInconsistency(rb, errors.New("root block no equal to previous block height + 1"))
+				return report, nil
 			}
 			batch[size-index-1] = rb
 			rb = prevRb.(*types.RootBlock)
@@ -227,27 +234,40 @@ func (s *QKCMasterBackend) CheckDB() {
 			for _, slvConn := range s.GetSlaveConns() {
 				conn, block := slvConn, b.(*types.RootBlock)
 				g.Go(func() error {
-					return conn.CheckMinorBlocksInRoot(block)
+					results, err := conn.CheckMinorBlocksInRoot(block)
+					if err != nil {
+						report.addFailure(conn.GetSlaveID(), block, err)
+						return err
+					}
+					report.addShardResults(results)
+					return nil
 				})
 			}
 		}
 
-		_, err := s.rootBlockChain.InsertChain(batch)
+		n, err := s.rootBlockChain.InsertChain(batch)
 		if err != nil {
-			log.Error("Failed to check root block", "height", rb.NumberU64(), "error", err.Error())
-			return
+			if n >= len(batch) {
+				n = len(batch) - 1
+			}
+			log.Error("Failed to check root block", "height", batch[n].NumberU64(), "error", err.Error())
+			report.setRootInconsistency(batch[n].(*types.RootBlock), err)
 		}
 		if err := g.Wait(); err != nil {
 			log.Error("Failed to check root block ", "height", rb.NumberU64(), "error", err.Error())
-			return
+		}
+		report.CheckedRootBlocks += uint64(len(batch))
+		if report.Root != nil || len(report.Failures) > 0 {
+			return report, nil
 		}
 	}
+	return report, nil
 }
 
 // APIs return all apis for master Server
 func (s *QKCMasterBackend) APIs() []qrpc.API {
 	apis := qkcapi.GetAPIs(s)
-	return append(apis, []qrpc.API{
+	apis = append(apis, []qrpc.API{
 		{
 			Namespace: "grpc",
 			Version:   "3.0",
@@ -255,10 +275,21 @@ func (s *QKCMasterBackend) APIs() []qrpc.API {
 			Public:    false,
 		},
 	}...)
+	if s.ctx.WSIsAlive() {
+		apis = append(apis,
+			qrpc.API{
+				Namespace: "ws",
+				Version:   "3.0",
+				Service:   NewPublicFilterAPI(s.rootBlockChain),
+				Public:    true,
+			})
+	}
+	return apis
 }
 
 // Stop stop node -> stop qkcMaster
 func (s *QKCMasterBackend) Stop() error {
+	s.unregisterMetrics()
 	s.synchronizer.Close()
 	s.protocolManager.Stop()
 	s.miner.Stop()
@@ -318,6 +349,7 @@ func (s *QKCMasterBackend) SetMining(mining bool) {
 // 5:init shards
 func (s *QKCMasterBackend) Start() error {
 	s.protocolManager.Start(s.maxPeers)
+	s.registerMetrics()
 	// start heart beat pre 3 seconds.
 	s.updateShardStatsLoop()
 
@@ -395,12 +427,16 @@ func (s *QKCMasterBackend) Heartbeat() {
 				timeGap := time.Now()
 				s.ctx.SetTimestamp(timeGap)
 				for _, conn := range s.GetSlaveConns() {
-					normal = conn.HeartBeat()
-					if !normal {
-						s.SetMining(false)
-						s.shutdown <- syscall.SIGTERM
-						break
+					if conn.HeartBeat(s.ActiveShards(conn.GetSlaveID())) || s.failover(conn, s.rootBlockChain.CurrentBlock().Hash()) {
+						continue
 					}
+					normal = false
+					s.SetMining(false)
+					s.shutdown <- syscall.SIGTERM
+					break
+				}
+				for _, conn := range s.failedSlaveConns() {
+					go s.rejoin(conn)
 				}
 				log.Trace(s.logInfo, "heart beat duration", time.Now().Sub(timeGap).String())
 				time.Sleep(config.HeartbeatInterval)
@@ -429,7 +465,7 @@ func checkPing(slaveConn rpc.ISlaveConn, id []byte, chainMaskList []uint32) erro
 func (s *QKCMasterBackend) createRootBlockToMine(address account.Address) (*types.RootBlock, error) {
 	var (
 		g     errgroup.Group
-		conns = s.GetSlaveConns()
+		conns = s.GetActiveSlaveConns()
 	)
 	rspList := make(chan *rpc.GetUnconfirmedHeadersResponse, s.ConnCount())
 
@@ -437,6 +473,16 @@ func (s *QKCMasterBackend) createRootBlockToMine(address account.Address) (*type
 		conn := conn
 		g.Go(func() error {
 			rsp, err := conn.GetUnconfirmedHeaders()
+			if err == nil {
+				// the shards the slave is a standby of are left to their active slave
+				infos := make([]*rpc.HeadersInfo, 0, len(rsp.HeadersInfoList))
+				for _, info := range rsp.HeadersInfoList {
+					if s.IsActive(conn.GetSlaveID(), info.Branch) {
+						infos = append(infos, info)
+					}
+				}
+				rsp.HeadersInfoList = infos
+			}
 			rspList <- rsp
 			return err
 		})
@@ -486,13 +532,23 @@ func (s *QKCMasterBackend) createRootBlockToMine(address account.Address) (*type
 func (s *QKCMasterBackend) GetAccountData(address *account.Address, height *uint64) (map[uint32]*rpc.AccountBranchData, error) {
 	var (
 		g     errgroup.Group
-		conns = s.GetSlaveConns()
+		conns = s.GetActiveSlaveConns()
 	)
 	rspList := make(chan *rpc.GetAccountDataResponse, len(conns))
 	for _, conn := range conns {
 		conn := conn
 		g.Go(func() error {
 			rsp, err := conn.GetAccountData(address, height)
+			if err == nil {
+				// the shards the slave is a standby of are left to their active slave
+				list := make([]*rpc.AccountBranchData, 0, len(rsp.AccountBranchDataList))
+				for _, data := range rsp.AccountBranchDataList {
+					if s.IsActive(conn.GetSlaveID(), data.Branch) {
+						list = append(list, data)
+					}
+				}
+				rsp.AccountBranchDataList = list
+			}
 			rspList <- rsp
 			return err
 		})
@@ -550,6 +606,8 @@ func (s *QKCMasterBackend) SendMiningConfigToSlaves(mining bool) error {
 
 // AddRootBlock add root block to all slaves
 func (s *QKCMasterBackend) AddRootBlock(rootBlock *types.RootBlock) error {
+	s.migrationMu.RLock()
+	defer s.migrationMu.RUnlock()
 	block := s.rootBlockChain.CurrentBlock()
 	s.rootBlockChain.WriteCommittingHash(rootBlock.Hash())
 	_, err := s.rootBlockChain.InsertChain([]types.IBlock{rootBlock})
@@ -632,6 +690,11 @@ func (s *QKCMasterBackend) GetRootHashConfirmingMinorBlock(mBlockID []byte) comm
 	return s.rootBlockChain.GetRootBlockConfirmingMinorBlock(mBlockID)
 }
 
+// Done returns a channel which is closed when the master stops.
+func (s *QKCMasterBackend) Done() <-chan struct{} {
+	return s.exitCh
+}
+
 // UpdateTxCountHistory update Tx count queue
 func (s *QKCMasterBackend) UpdateTxCountHistory(txCount, xShardTxCount uint32, createTime uint64) {
 	s.lock.Lock()
@@ -0,0 +1,118 @@
+package master
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DBCheckRootBlock is the kind of the inconsistencies of the root chain.
+const DBCheckRootBlock = "root_block"
+
+// DBCheckXShardTxListExistence is how the lists of deposits received from the
+// neighbor shards are checked: only their existence is checked, the deposits
+// are not recomputed from the neighbor blocks.
+const DBCheckXShardTxListExistence = "existence"
+
+// DBCheckFailure is a slave failing to check its shards for a root block.
+type DBCheckFailure struct {
+	SlaveID string      `json:"slave_id"`
+	Height  uint64      `json:"height"`
+	Hash    common.Hash `json:"hash"`
+	Error   string      `json:"error"`
+}
+
+// DBCheckReport is the machine readable result of the database integrity check
+// of the cluster, with the first inconsistency found in the root chain and in
+// each shard.
+type DBCheckReport struct {
+	RootFrom          uint64               `json:"root_from"`
+	RootTo            uint64               `json:"root_to"`
+	CheckedRootBlocks uint64               `json:"checked_root_blocks"`
+	Root              *rpc.DBInconsistency `json:"root_inconsistency"`
+	Shards            []*rpc.ShardDBCheck  `json:"shards"`
+	Failures          []*DBCheckFailure    `json:"failures"`
+	XShardTxListCheck string               `json:"xshard_tx_list_check"`
+	shards            map[uint32]*rpc.ShardDBCheck
+	mu                sync.Mutex
+}
+
+func newDBCheckReport(from, to uint64) *DBCheckReport {
+	return &DBCheckReport{
+		RootFrom:          from,
+		RootTo:            to,
+		Shards:            make([]*rpc.ShardDBCheck, 0),
+		Failures:          make([]*DBCheckFailure, 0),
+		XShardTxListCheck: DBCheckXShardTxListExistence,
+		shards:            make(map[uint32]*rpc.ShardDBCheck),
+	}
+}
+
+func (r *DBCheckReport) setRootInconsistency(block *types.RootBlock, err error) {
+	r.Root = &rpc.DBInconsistency{Check: DBCheckRootBlock, Height: block.NumberU64(), Hash: block.Hash(), Error: err.Error()}
+}
+
+// addShardResults adds the results of the check of the shards of a slave for a
+// root block. The root blocks are checked in parallel, so the inconsistency of
+// the lowest block is kept for each shard.
+func (r *DBCheckReport) addShardResults(results []*rpc.ShardDBCheck) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, result := range results {
+		shard, ok := r.shards[result.FullShardId]
+		if !ok {
+			shard = &rpc.ShardDBCheck{FullShardId: result.FullShardId}
+			r.shards[result.FullShardId] = shard
+			r.Shards = append(r.Shards, shard)
+			sort.Slice(r.Shards, func(i, j int) bool { return r.Shards[i].FullShardId < r.Shards[j].FullShardId })
+		}
+		shard.CheckedBlocks += result.CheckedBlocks
+		shard.RerunBlocks += result.RerunBlocks
+		shard.AddUnverifiedRange(result.UnverifiedBlocks, result.UnverifiedFrom, result.UnverifiedTo)
+		if result.Inconsistency != nil && (shard.Inconsistency == nil || result.Inconsistency.Height < shard.Inconsistency.Height) {
+			shard.Inconsistency = result.Inconsistency
+		}
+	}
+}
+
+// addFailure records a slave failing to check its shards for a root block.
+func (r *DBCheckReport) addFailure(slaveID string, block *types.RootBlock, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Failures = append(r.Failures, &DBCheckFailure{SlaveID: slaveID, Height: block.NumberU64(), Hash: block.Hash(), Error: err.Error()})
+	sort.SliceStable(r.Failures, func(i, j int) bool { return r.Failures[i].Height < r.Failures[j].Height })
+}
+
+// Consistent returns whether no inconsistency was found and all the shards
+// were checked.
+func (r *DBCheckReport) Consistent() bool {
+	if r.Root != nil || len(r.Failures) > 0 {
+		return false
+	}
+	for _, shard := range r.Shards {
+		if shard.Inconsistency != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes the report as json to the file, or to the standard output if
+// the file is empty.
+func (r *DBCheckReport) Write(file string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,65 @@
+package master
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBCheckReport(t *testing.T) {
+	report := newDBCheckReport(10, 1)
+	assert.True(t, report.Consistent())
+
+	report.addShardResults([]*rpc.ShardDBCheck{
+		{FullShardId: 2, CheckedBlocks: 3, RerunBlocks: 3},
+		{FullShardId: 1, CheckedBlocks: 1, Inconsistency: &rpc.DBInconsistency{Check: "state", Height: 8}},
+	})
+	report.addShardResults([]*rpc.ShardDBCheck{
+		{FullShardId: 1, CheckedBlocks: 2, RerunBlocks: 1, Inconsistency: &rpc.DBInconsistency{Check: "body", Height: 5}},
+		{FullShardId: 2, CheckedBlocks: 2, UnverifiedBlocks: 2, UnverifiedFrom: 6, UnverifiedTo: 9},
+	})
+	report.addShardResults([]*rpc.ShardDBCheck{
+		{FullShardId: 1, CheckedBlocks: 2, Inconsistency: &rpc.DBInconsistency{Check: "tx_history", Height: 7}},
+		{FullShardId: 2, UnverifiedBlocks: 1, UnverifiedFrom: 3, UnverifiedTo: 3},
+	})
+	assert.False(t, report.Consistent())
+	assert.Equal(t, 2, len(report.Shards))
+	assert.Equal(t, uint32(1), report.Shards[0].FullShardId)
+	assert.Equal(t, uint32(5), report.Shards[0].CheckedBlocks)
+	assert.Equal(t, uint32(1), report.Shards[0].RerunBlocks)
+	assert.Equal(t, "body", report.Shards[0].Inconsistency.Check)
+	assert.Equal(t, uint32(2), report.Shards[1].FullShardId)
+	assert.Equal(t, uint32(5), report.Shards[1].CheckedBlocks)
+	assert.Equal(t, uint32(3), report.Shards[1].UnverifiedBlocks)
+	assert.Equal(t, uint64(3), report.Shards[1].UnverifiedFrom)
+	assert.Equal(t, uint64(9), report.Shards[1].UnverifiedTo)
+	assert.Equal(t, uint32(0), report.Shards[0].UnverifiedBlocks)
+	assert.Nil(t, report.Shards[1].Inconsistency)
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	var decoded DBCheckReport
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report.Shards, decoded.Shards)
+	assert.Nil(t, decoded.Root)
+	assert.Equal(t, DBCheckXShardTxListExistence, decoded.XShardTxListCheck)
+}
+
+func TestDBCheckReportFailure(t *testing.T) {
+	report := newDBCheckReport(10, 1)
+	report.addShardResults([]*rpc.ShardDBCheck{{FullShardId: 1, CheckedBlocks: 1}})
+	block := types.NewRootBlockWithHeader(&types.RootBlockHeader{Number: 9})
+	report.addFailure("S1", block, errors.New("connection refused"))
+	assert.False(t, report.Consistent())
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	var decoded DBCheckReport
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report.Shards, decoded.Shards)
+	assert.Equal(t, []*DBCheckFailure{{SlaveID: "S1", Height: 9, Hash: block.Hash(), Error: "connection refused"}}, decoded.Failures)
+}
@@ -0,0 +1,284 @@
+package master
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/p2p"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxFailoverEvents is how many failover events are kept for the RPC.
+const maxFailoverEvents = 100
+
+// failover stops using a slave which stopped answering heartbeats, for each
+// shard it was the active slave of the first standby slave of the shard is
+// promoted. It returns false when the slave was the last one running a shard,
+// the cluster cannot go on then. rootTip is kept to catch the slave up if it
+// answers heartbeats again, see rejoin.
+func (c *SlaveConnManager) failover(failed rpc.ISlaveConn, rootTip common.Hash) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slaveID := failed.GetSlaveID()
+	fullShardIds := make([]uint32, 0)
+	for fullShardId, conns := range c.branchToSlaveConns {
+		for _, conn := range conns {
+			if conn.GetSlaveID() != slaveID {
+				continue
+			}
+			if len(c.liveConns(conns)) < 2 {
+				log.Error("No slave left to fail over to", "slave", slaveID, "branch", fullShardId)
+				return false
+			}
+			fullShardIds = append(fullShardIds, fullShardId)
+		}
+	}
+	sort.Slice(fullShardIds, func(i, j int) bool { return fullShardIds[i] < fullShardIds[j] })
+
+	c.failed[slaveID] = rootTip
+	event := &rpc.FailoverEvent{
+		Timestamp:    uint64(time.Now().Unix()),
+		Slave:        slaveID,
+		Promoted:     make([]string, 0),
+		ShardToSlave: make(map[uint32]string),
+	}
+	for _, fullShardId := range fullShardIds {
+		if c.active[fullShardId] == slaveID {
+			// standby slaves are sorted last, the first one left is promoted
+			next := c.liveConns(c.branchToSlaveConns[fullShardId])[0].GetSlaveID()
+			c.active[fullShardId] = next
+			if !containsSlave(event.Promoted, next) {
+				event.Promoted = append(event.Promoted, next)
+			}
+		}
+		event.ShardToSlave[fullShardId] = c.active[fullShardId]
+	}
+	if len(c.failovers) == maxFailoverEvents {
+		c.failovers = c.failovers[1:]
+	}
+	c.failovers = append(c.failovers, event)
+	log.Warn("Slave stopped answering heartbeats, failed over", "slave", slaveID, "promoted", event.Promoted)
+	return true
+}
+
+func containsSlave(slaveIDs []string, slaveID string) bool {
+	for _, id := range slaveIDs {
+		if id == slaveID {
+			return true
+		}
+	}
+	return false
+}
+
+// failedSlaveConns returns the slaves which stopped answering heartbeats and
+// are not being caught up.
+func (c *SlaveConnManager) failedSlaveConns() []rpc.ISlaveConn {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	conns := make([]rpc.ISlaveConn, 0)
+	for _, conn := range c.clientPool {
+		if _, failed := c.failed[conn.GetSlaveID()]; failed && !c.rejoining[conn.GetSlaveID()] {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// startRejoin marks a failed slave as being caught up and returns the root tip
+// when it failed, false if it is caught up already.
+func (c *SlaveConnManager) startRejoin(slaveID string) (common.Hash, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rootTip, failed := c.failed[slaveID]
+	if !failed || c.rejoining[slaveID] {
+		return common.Hash{}, false
+	}
+	c.rejoining[slaveID] = true
+	return rootTip, true
+}
+
+// endRejoin puts a caught up slave back in the cluster, as a standby of its
+// shards, or leaves it failed.
+func (c *SlaveConnManager) endRejoin(slaveID string, caughtUp bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.rejoining, slaveID)
+	if caughtUp {
+		delete(c.failed, slaveID)
+	}
+}
+
+// rejoin catches a failed slave answering heartbeats again up with the
+// cluster, and puts it back as a standby of its shards. Root blocks are not
+// added to the cluster during the last catch up so that it misses none of
+// them.
+func (s *QKCMasterBackend) rejoin(conn rpc.ISlaveConn) {
+	slaveID := conn.GetSlaveID()
+	rootTip, ok := s.startRejoin(slaveID)
+	if !ok {
+		return
+	}
+	// the slave is a standby of all its shards once back
+	if !conn.HeartBeat([]uint32{}) {
+		s.endRejoin(slaveID, false)
+		return
+	}
+	log.Info("Failed slave answers heartbeats again, catching it up", "slave", slaveID)
+	rootTip, err := s.catchUp(conn, rootTip)
+	if err == nil {
+		s.migrationMu.Lock()
+		rootTip, err = s.catchUp(conn, rootTip)
+		s.endRejoin(slaveID, err == nil)
+		s.migrationMu.Unlock()
+	} else {
+		s.endRejoin(slaveID, false)
+	}
+	if err != nil {
+		log.Warn("Failed to catch failed slave up", "slave", slaveID, "err", err)
+		return
+	}
+	// the blocks mined until the slave is back get replicated to it
+	for _, fullShardId := range conn.GetFullShardList() {
+		if err := s.catchUpShard(conn, fullShardId); err != nil {
+			log.Warn("Failed to catch shard of rejoined slave up", "slave", slaveID, "branch", fmt.Sprintf("%x", fullShardId), "err", err)
+		}
+	}
+	log.Warn("Failed slave rejoined the cluster as a standby", "slave", slaveID, "rootTip", rootTip)
+}
+
+// catchUp sends a slave the root blocks from the last one shared with the
+// given one to the tip, and the blocks of its shards, it returns the root tip
+// sent.
+func (s *QKCMasterBackend) catchUp(conn rpc.ISlaveConn, rootTip common.Hash) (common.Hash, error) {
+	block, ok := s.rootBlockChain.GetBlock(rootTip).(*types.RootBlock)
+	if !ok {
+		return rootTip, fmt.Errorf("unknown root block %x", rootTip)
+	}
+	// root blocks of a fork are replaced from the last one of the canonical chain
+	for {
+		canonical := s.rootBlockChain.GetBlockByNumber(block.NumberU64())
+		if canonical != nil && canonical.Hash() == block.Hash() {
+			break
+		}
+		parentHash := block.ParentHash()
+		if block, ok = s.rootBlockChain.GetBlock(parentHash).(*types.RootBlock); !ok {
+			return rootTip, fmt.Errorf("unknown root block %x", parentHash)
+		}
+	}
+	tip := s.rootBlockChain.CurrentBlock()
+	for height := block.NumberU64() + 1; height <= tip.NumberU64(); height++ {
+		if block, ok = s.rootBlockChain.GetBlockByNumber(height).(*types.RootBlock); !ok {
+			return rootTip, fmt.Errorf("no root block at height %d", height)
+		}
+		// the last block of each shard confirmed by the root block, with its
+		// ancestors, are added before it
+		lastHeaders := make(map[uint32]*types.MinorBlockHeader)
+		for _, header := range block.MinorBlockHeaders() {
+			lastHeaders[header.Branch.Value] = header
+		}
+		for fullShardId, header := range lastHeaders {
+			if !conn.HasShard(fullShardId) {
+				continue
+			}
+			if err := s.replicateMinorBlocks(conn, fullShardId, header.Hash()); err != nil {
+				return rootTip, err
+			}
+		}
+		if err := conn.AddRootBlock(block, false); err != nil {
+			return rootTip, err
+		}
+		rootTip = block.Hash()
+	}
+	for _, fullShardId := range conn.GetFullShardList() {
+		if err := s.catchUpShard(conn, fullShardId); err != nil {
+			return rootTip, err
+		}
+	}
+	return rootTip, nil
+}
+
+// catchUpShard sends a slave the blocks of the shard up to the tip of the
+// active slave of the shard.
+func (s *QKCMasterBackend) catchUpShard(conn rpc.ISlaveConn, fullShardId uint32) error {
+	active := s.GetOneSlaveConnById(fullShardId)
+	if active == nil || active == conn {
+		return nil
+	}
+	height, err := s.GetLastMinorBlockByFullShardID(fullShardId)
+	if err != nil {
+		return err
+	}
+	tip, _, err := active.GetMinorBlockByHeight(&height, account.NewBranch(fullShardId), false)
+	if err != nil {
+		return err
+	}
+	return s.replicateMinorBlocks(conn, fullShardId, tip.Hash())
+}
+
+// replicateMinorBlocks sends a slave the block of the shard the active slave
+// of the shard has and the ancestors it misses, oldest first.
+func (s *QKCMasterBackend) replicateMinorBlocks(conn rpc.ISlaveConn, fullShardId uint32, hash common.Hash) error {
+	active := s.GetOneSlaveConnById(fullShardId)
+	if active == nil || active == conn {
+		return nil
+	}
+	branch := account.NewBranch(fullShardId)
+	blocks := make([]*types.MinorBlock, 0)
+	for {
+		if block, _, err := conn.GetMinorBlockByHash(hash, branch, false); err == nil && block != nil {
+			break
+		}
+		block, _, err := active.GetMinorBlockByHash(hash, branch, false)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, block)
+		if block.NumberU64() == 0 {
+			break
+		}
+		hash = block.ParentHash()
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		data, err := serialize.SerializeToBytes(&p2p.NewBlockMinor{Block: blocks[i]})
+		if err != nil {
+			return err
+		}
+		if err := conn.HandleNewMinorBlock(&rpc.P2PRedirectRequest{Branch: fullShardId, Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFailoverEvents returns the last failovers of the slaves, oldest first.
+func (c *SlaveConnManager) GetFailoverEvents() []*rpc.FailoverEvent {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	events := make([]*rpc.FailoverEvent, len(c.failovers))
+	copy(events, c.failovers)
+	return events
+}
+
+// replicateMinorBlock sends a block mined by the active slave of a shard to the
+// other slaves of the shard, so that the standby ones stay in sync.
+func (s *QKCMasterBackend) replicateMinorBlock(req *rpc.P2PRedirectRequest) {
+	active := s.GetOneSlaveConnById(req.Branch)
+	for _, conn := range s.GetSlaveConnsById(req.Branch) {
+		if conn == active {
+			continue
+		}
+		conn := conn
+		go func() {
+			if err := conn.HandleNewMinorBlock(req); err != nil {
+				log.Warn("Failed to replicate minor block", "slave", conn.GetSlaveID(), "branch", req.Branch, "err", err)
+			}
+		}()
+	}
+}
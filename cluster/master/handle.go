@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/monitoring"
 	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	qkcsync "github.com/QuarkChain/goquarkchain/cluster/sync"
 	qkcom "github.com/QuarkChain/goquarkchain/common"
@@ -107,6 +108,11 @@ func (pm *ProtocolManager) removePeer(id string) {
 	if err := pm.peers.Unregister(id); err != nil {
 		log.Error("Peer removal failed", "peer", id, "err", err)
 	}
+	for _, conn := range pm.slaveConns.GetSlaveConns() {
+		if err := conn.PeerDisconnected(id); err != nil {
+			log.Warn("Failed to notify slave of peer removal", "slave", conn.GetSlaveID(), "peer", id, "err", err)
+		}
+	}
 	// Hard disconnect at the networking layer
 	if peer != nil {
 		peer.Peer.Disconnect(p2p.DiscUselessPeer)
@@ -177,7 +183,7 @@ func (pm *ProtocolManager) handle(peer *Peer) error {
 	defer pm.removePeer(peer.id)
 	log.Info(pm.log, "peer add succ id ", peer.PeerID())
 
-	err := pm.synchronizer.AddTask(qkcsync.NewRootChainTask(peer, peer.RootHead(), pm.stats, pm.statsChan, pm.slaveConns))
+	err := pm.synchronizer.AddTask(qkcsync.NewRootChainTask(peer, peer.RootHead(), pm.stats, pm.statsChan, pm.slaveConns, pm.clusterConfig.FastSync))
 	if err != nil {
 		return err
 	}
@@ -379,6 +385,44 @@ func (pm *ProtocolManager) handleMsg(peer *Peer) error {
 			log.Warn(fmt.Sprintf("chan for rpc %d is missing", qkcMsg.RpcID))
 		}
 
+	case qkcMsg.Op == p2p.GetMinorStateNodeListRequestMsg:
+		go func() {
+			resp, err := pm.HandleGetMinorStateNodeListRequest(peer.id, qkcMsg.MetaData.Branch, qkcMsg.Data)
+			if err != nil {
+				peer.handleMsgErr = err
+			}
+			err = peer.SendResponseWithData(p2p.GetMinorStateNodeListResponseMsg, p2p.Metadata{Branch: qkcMsg.MetaData.Branch}, qkcMsg.RpcID, resp)
+			if err != nil {
+				peer.handleMsgErr = err
+			}
+		}()
+
+	case qkcMsg.Op == p2p.GetMinorStateNodeListResponseMsg:
+		if c := peer.getChan(qkcMsg.RpcID); c != nil {
+			c <- qkcMsg.Data
+		} else {
+			log.Warn(fmt.Sprintf("chan for rpc %d is missing", qkcMsg.RpcID))
+		}
+
+	case qkcMsg.Op == p2p.GetCrossShardTxListRequestMsg:
+		go func() {
+			resp, err := pm.HandleGetCrossShardTxListRequest(peer.id, qkcMsg.MetaData.Branch, qkcMsg.Data)
+			if err != nil {
+				peer.handleMsgErr = err
+			}
+			err = peer.SendResponseWithData(p2p.GetCrossShardTxListResponseMsg, p2p.Metadata{Branch: qkcMsg.MetaData.Branch}, qkcMsg.RpcID, resp)
+			if err != nil {
+				peer.handleMsgErr = err
+			}
+		}()
+
+	case qkcMsg.Op == p2p.GetCrossShardTxListResponseMsg:
+		if c := peer.getChan(qkcMsg.RpcID); c != nil {
+			c <- qkcMsg.Data
+		} else {
+			log.Warn(fmt.Sprintf("chan for rpc %d is missing", qkcMsg.RpcID))
+		}
+
 	default:
 		return fmt.Errorf("unknown msg code %d", qkcMsg.Op)
 	}
@@ -398,7 +442,8 @@ func (pm *ProtocolManager) HandleNewRootTip(tip *p2p.Tip, peer *Peer) error {
 	}
 	peer.SetRootHead(tip.RootBlockHeader)
 	if tip.RootBlockHeader.NumberU64() > pm.rootBlockChain.CurrentBlock().NumberU64() {
-		err := pm.synchronizer.AddTask(qkcsync.NewRootChainTask(peer, tip.RootBlockHeader, pm.stats, pm.statsChan, pm.slaveConns))
+		monitoring.Default().BlockSeen(tip.RootBlockHeader.Hash(), peer.id)
+		err := pm.synchronizer.AddTask(qkcsync.NewRootChainTask(peer, tip.RootBlockHeader, pm.stats, pm.statsChan, pm.slaveConns, pm.clusterConfig.FastSync))
 		if err != nil {
 			log.Error("Failed to add root chain task,", "hash", tip.RootBlockHeader.Hash(), "height", tip.RootBlockHeader.NumberU64())
 		}
@@ -634,6 +679,32 @@ func (pm *ProtocolManager) HandleGetMinorBlockListRequest(peerId string, branch
 	return result, nil
 }
 
+func (pm *ProtocolManager) HandleGetMinorStateNodeListRequest(peerId string, branch uint32, data []byte) ([]byte, error) {
+	conn := pm.slaveConns.GetOneSlaveConnById(branch)
+	if conn == nil {
+		return nil, fmt.Errorf("invalid peerID %s for branch %d", peerId, branch)
+	}
+	result, err := conn.GetMinorStateNodeList(&rpc.P2PRedirectRequest{Branch: branch, PeerID: peerId, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("branch %d HandleGetMinorStateNodeListRequest failed with error: %v", branch, err.Error())
+	}
+
+	return result, nil
+}
+
+func (pm *ProtocolManager) HandleGetCrossShardTxListRequest(peerId string, branch uint32, data []byte) ([]byte, error) {
+	conn := pm.slaveConns.GetOneSlaveConnById(branch)
+	if conn == nil {
+		return nil, fmt.Errorf("invalid peerID %s for branch %d", peerId, branch)
+	}
+	result, err := conn.GetCrossShardTxList(&rpc.P2PRedirectRequest{Branch: branch, PeerID: peerId, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("branch %d HandleGetCrossShardTxListRequest failed with error: %v", branch, err.Error())
+	}
+
+	return result, nil
+}
+
 func (pm *ProtocolManager) BroadcastTip(header *types.RootBlockHeader) {
 	for _, peer := range pm.peers.Peers() {
 		if peer.RootHead() != nil && header.Number <= peer.RootHead().Number {
@@ -659,6 +730,7 @@ func (pm *ProtocolManager) tipBroadcastLoop() {
 		select {
 		case event := <-pm.chainHeadChan:
 			pm.BroadcastTip(event.Block.Header())
+			monitoring.Default().BlockImported(monitoring.RootChain, event.Block.Header())
 
 		// Err() channel will be closed when unsubscribing.
 		case <-pm.chainHeadEventSub.Err():
@@ -706,7 +778,7 @@ func (pm *ProtocolManager) synchronise(peer *Peer) {
 		return
 	}
 	if peer.RootHead() != nil {
-		err := pm.synchronizer.AddTask(qkcsync.NewRootChainTask(peer, peer.RootHead(), pm.stats, pm.statsChan, pm.slaveConns))
+		err := pm.synchronizer.AddTask(qkcsync.NewRootChainTask(peer, peer.RootHead(), pm.stats, pm.statsChan, pm.slaveConns, pm.clusterConfig.FastSync))
 		if err != nil {
 			log.Error("AddTask to synchronizer.", "error", err.Error())
 		}
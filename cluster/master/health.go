@@ -0,0 +1,45 @@
+package master
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+)
+
+// heartbeatTimeout is how long a slave may go without answering a heartbeat
+// before the master is no longer ready.
+const heartbeatTimeout = 3 * config.HeartbeatInterval
+
+// Unready returns why the master is not ready to serve: the root chain is
+// syncing, a slave missed its heartbeats or the root tip lags the tip of the
+// peers.
+func (s *QKCMasterBackend) Unready() []string {
+	reasons := make([]string, 0)
+	if s.synchronizer.IsSyncing() {
+		reasons = append(reasons, "root chain is syncing")
+	}
+	now := time.Now()
+	for _, conn := range s.GetSlaveConns() {
+		slave, ok := conn.(*SlaveConnection)
+		if !ok {
+			continue
+		}
+		if last := slave.LastHeartbeat(); last.IsZero() {
+			reasons = append(reasons, fmt.Sprintf("no heartbeat from slave %s yet", slave.GetSlaveID()))
+		} else if now.Sub(last) > heartbeatTimeout {
+			reasons = append(reasons, fmt.Sprintf("missed heartbeat of slave %s for %v", slave.GetSlaveID(), now.Sub(last).Round(time.Second)))
+		}
+	}
+	tip := s.rootBlockChain.CurrentBlock().NumberU64()
+	var peerTip uint64
+	for _, peer := range s.protocolManager.peers.Peers() {
+		if head := peer.RootHead(); head != nil && head.NumberU64() > peerTip {
+			peerTip = head.NumberU64()
+		}
+	}
+	if peerTip > tip+s.clusterConfig.Readiness.MaxRootTipLag {
+		reasons = append(reasons, fmt.Sprintf("root tip %d lags the tip %d of the peers", tip, peerTip))
+	}
+	return reasons
+}
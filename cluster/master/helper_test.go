@@ -306,6 +306,7 @@ func newFakeConnManager(n int, ctrl *gomock.Controller) *fakeConnManager {
 	conns := make([]rpc.ISlaveConn, 0, n)
 	for i := 0; i < n; i++ {
 		sc := mock_master.NewMockISlaveConn(ctrl)
+		sc.EXPECT().PeerDisconnected(gomock.Any()).Return(nil).AnyTimes()
 		conns = append(conns, sc)
 	}
 
@@ -2,6 +2,7 @@ package master
 
 import (
 	"context"
+	"fmt"
 	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	"github.com/QuarkChain/goquarkchain/serialize"
 	"sync"
@@ -25,6 +26,10 @@ func (m *MasterServerSideOp) AddMinorBlockHeader(ctx context.Context, req *rpc.R
 	if err := serialize.DeserializeFromBytes(req.Data, data); err != nil {
 		return nil, err
 	}
+	// a slave failed over keeps mining until it notices, its blocks are fenced
+	if err := m.checkActive(data.SlaveID, data.MinorBlockHeader.Branch.Value); err != nil {
+		return nil, err
+	}
 	m.master.rootBlockChain.AddValidatedMinorBlockHeader(data.MinorBlockHeader.Hash(), data.CoinbaseAmountMap)
 	m.master.UpdateShardStatus(data.ShardStats)
 	m.master.UpdateTxCountHistory(data.TxCount, data.XShardTxCount, data.MinorBlockHeader.Time)
@@ -47,12 +52,26 @@ func (m *MasterServerSideOp) AddMinorBlockHeaderList(ctx context.Context, req *r
 	if err := serialize.DeserializeFromBytes(req.Data, gReq); err != nil {
 		return nil, err
 	}
+	for _, header := range gReq.MinorBlockHeaderList {
+		if err := m.checkActive(gReq.SlaveID, header.Branch.Value); err != nil {
+			return nil, err
+		}
+	}
 	for _, header := range gReq.MinorBlockHeaderList {
 		m.master.rootBlockChain.AddValidatedMinorBlockHeader(header.Hash(), header.CoinbaseAmount)
 	}
 	return &rpc.Response{RpcId: req.RpcId}, nil
 }
 
+// checkActive rejects the minor block headers and the shard status sent by a
+// slave which is not the active slave of the shard.
+func (m *MasterServerSideOp) checkActive(slaveID string, fullShardId uint32) error {
+	if !m.master.IsActive(slaveID, fullShardId) {
+		return fmt.Errorf("slave %s is not the active slave of shard %x", slaveID, fullShardId)
+	}
+	return nil
+}
+
 // p2p apis
 func (m *MasterServerSideOp) BroadcastNewTip(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
 	broadcastTipReq := new(rpc.BroadcastNewTip)
@@ -88,6 +107,10 @@ func (m *MasterServerSideOp) BroadcastNewMinorBlock(ctx context.Context, req *rp
 	if err != nil {
 		return nil, err
 	}
+	if res.PeerID == "" {
+		// the blocks mined by the cluster are not announced by any peer
+		m.master.replicateMinorBlock(res)
+	}
 	return &rpc.Response{}, nil
 }
 
@@ -152,3 +175,43 @@ func (m *MasterServerSideOp) GetMinorBlockHeaderList(ctx context.Context, req *r
 		RpcId: req.RpcId,
 	}, nil
 }
+
+func (m *MasterServerSideOp) GetMinorStateNodeList(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		rep = new(rpc.P2PRedirectRequest)
+		err error
+	)
+
+	if err = serialize.DeserializeFromBytes(req.Data, rep); err != nil {
+		return nil, err
+	}
+	data, err := m.p2pApi.GetMinorStateNodeList(rep)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpc.Response{
+		Data:  data,
+		RpcId: req.RpcId,
+	}, nil
+}
+
+func (m *MasterServerSideOp) GetCrossShardTxList(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		rep = new(rpc.P2PRedirectRequest)
+		err error
+	)
+
+	if err = serialize.DeserializeFromBytes(req.Data, rep); err != nil {
+		return nil, err
+	}
+	data, err := m.p2pApi.GetCrossShardTxList(rep)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpc.Response{
+		Data:  data,
+		RpcId: req.RpcId,
+	}, nil
+}
@@ -2,6 +2,7 @@ package master
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
@@ -130,6 +131,18 @@ func (c *fakeRpcClient) Call(hostport string, req *rpc.Request) (*rpc.Response,
 		return &rpc.Response{Data: data}, nil
 	case rpc.OpGetMine:
 		return &rpc.Response{}, nil
+	case rpc.OpExportShard, rpc.OpImportShard, rpc.OpShardMoved:
+		if c.chanOP != nil {
+			c.chanOP <- req.Op
+		}
+		if req.Op != rpc.OpImportShard {
+			return &rpc.Response{}, nil
+		}
+		data, err := serialize.SerializeToBytes(rpc.ImportShardResponse{Copied: 10, Total: 10})
+		if err != nil {
+			return nil, err
+		}
+		return &rpc.Response{Data: data}, nil
 	case rpc.OpAddRootBlock:
 		rsp := new(rpc.AddRootBlockResponse)
 		rsp.Switched = false
@@ -460,7 +473,7 @@ func TestExecuteTransaction(t *testing.T) {
 		EvmTx:  evmTx,
 		TxType: types.EvmTx,
 	}
-	data, err := master.ExecuteTransaction(tx, &add1, nil)
+	data, err := master.ExecuteTransaction(tx, &add1, nil, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, data, []byte("qkc"))
 
@@ -469,7 +482,7 @@ func TestExecuteTransaction(t *testing.T) {
 		EvmTx:  evmTx,
 		TxType: types.EvmTx,
 	}
-	_, err = master.ExecuteTransaction(tx, &add1, nil)
+	_, err = master.ExecuteTransaction(tx, &add1, nil, nil)
 	assert.Error(t, err)
 }
 
@@ -570,7 +583,7 @@ func TestEstimateGas(t *testing.T) {
 		EvmTx:  evmTx,
 		TxType: types.EvmTx,
 	}
-	data, err := master.EstimateGas(tx, &add1)
+	data, err := master.EstimateGas(tx, &add1, nil)
 	assert.NoError(t, err)
 	if !tx.EvmTx.IsCrossShard() {
 		assert.Equal(t, data, uint32(123))
@@ -583,7 +596,7 @@ func TestEstimateGas(t *testing.T) {
 		EvmTx:  evmTx,
 		TxType: types.EvmTx,
 	}
-	data, err = master.EstimateGas(tx, &add1)
+	data, err = master.EstimateGas(tx, &add1, nil)
 	assert.Error(t, err)
 }
 
@@ -666,3 +679,153 @@ func findNonce(engine consensus.Engine, header *types.RootBlockHeader, difficalt
 		header.Nonce = header.Nonce + 1
 	}
 }
+
+func TestUnready(t *testing.T) {
+	master := initEnv(t, nil)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(master.Unready()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("master not ready: %v", master.Unready())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	slave := master.GetSlaveConns()[0].(*SlaveConnection)
+	slave.mu.Lock()
+	slave.lastHeartbeat = time.Now().Add(-time.Minute)
+	slave.mu.Unlock()
+	tip := master.rootBlockChain.CurrentBlock().Header()
+	peerTip := &types.RootBlockHeader{Number: tip.Number + uint32(master.clusterConfig.Readiness.MaxRootTipLag) + 1}
+	master.protocolManager.peers.peers["peer"] = &Peer{id: "peer", head: &peerHead{rootTip: peerTip}}
+
+	reasons := master.Unready()
+	assert.Equal(t, 2, len(reasons))
+	assert.Contains(t, reasons[0], "missed heartbeat of slave "+slave.GetSlaveID())
+	assert.Equal(t, fmt.Sprintf("root tip %d lags the tip %d of the peers", tip.Number, peerTip.Number), reasons[1])
+}
+
+func TestFailover(t *testing.T) {
+	var (
+		s0 = &SlaveConnection{slaveID: "S0", shardMaskList: []uint32{1, 0x10001}}
+		s1 = &SlaveConnection{slaveID: "S1", shardMaskList: []uint32{1, 0x10001}}
+		s2 = &SlaveConnection{slaveID: "S2", shardMaskList: []uint32{0x10001}}
+		c  = &SlaveConnManager{
+			clientPool: []rpc.ISlaveConn{s0, s1, s2},
+			branchToSlaveConns: map[uint32][]rpc.ISlaveConn{
+				1:       {s0, s1},
+				0x10001: {s2, s0, s1},
+			},
+			active:    map[uint32]string{1: "S0", 0x10001: "S2"},
+			failed:    make(map[string]common.Hash),
+			rejoining: make(map[string]bool),
+			failovers: make([]*rpc.FailoverEvent, 0),
+		}
+		rootTip = common.HexToHash("0x01")
+	)
+	assert.Equal(t, s0, c.GetOneSlaveConnById(1))
+	assert.Equal(t, []rpc.ISlaveConn{s0, s1}, c.GetSlaveConnsById(1))
+	assert.Equal(t, []rpc.ISlaveConn{s0, s2}, c.GetActiveSlaveConns())
+	assert.Equal(t, []uint32{1}, c.ActiveShards("S0"))
+	assert.Equal(t, []uint32{}, c.ActiveShards("S1"))
+
+	// S1 is only promoted for the shard S0 was the active slave of
+	assert.True(t, c.failover(s0, rootTip))
+	assert.Equal(t, s1, c.GetOneSlaveConnById(1))
+	assert.Equal(t, s2, c.GetOneSlaveConnById(0x10001))
+	assert.Equal(t, []uint32{1}, c.ActiveShards("S1"))
+	assert.False(t, c.IsActive("S1", 0x10001))
+	assert.Equal(t, []rpc.ISlaveConn{s1, s2}, c.GetSlaveConns())
+	assert.Equal(t, []rpc.ISlaveConn{s1, s2}, c.GetActiveSlaveConns())
+	events := c.GetFailoverEvents()
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "S0", events[0].Slave)
+	assert.Equal(t, []string{"S1"}, events[0].Promoted)
+	assert.Equal(t, map[uint32]string{1: "S1", 0x10001: "S2"}, events[0].ShardToSlave)
+
+	// S0 rejoins as a standby of its shards once caught up
+	assert.Equal(t, []rpc.ISlaveConn{s0}, c.failedSlaveConns())
+	tip, ok := c.startRejoin("S0")
+	assert.True(t, ok)
+	assert.Equal(t, rootTip, tip)
+	_, ok = c.startRejoin("S0")
+	assert.False(t, ok)
+	assert.Equal(t, []rpc.ISlaveConn{}, c.failedSlaveConns())
+	c.endRejoin("S0", true)
+	assert.Equal(t, []rpc.ISlaveConn{s0, s1, s2}, c.GetSlaveConns())
+	assert.Equal(t, []uint32{}, c.ActiveShards("S0"))
+	assert.Equal(t, s1, c.GetOneSlaveConnById(1))
+
+	// no slave is left to run shard 1 once S0 fails again
+	assert.True(t, c.failover(s0, rootTip))
+	assert.False(t, c.failover(s1, rootTip))
+	assert.Equal(t, 2, len(c.GetFailoverEvents()))
+}
+
+func TestAddMinorBlockHeaderListFenced(t *testing.T) {
+	master := initEnv(t, nil)
+	op := NewServerSideOp(master)
+	fullShardId := master.clusterConfig.SlaveList[0].FullShardList[0]
+	header := &types.MinorBlockHeader{Branch: account.Branch{Value: fullShardId}, Number: 1}
+	addHeaders := func(slaveID string) error {
+		data, err := serialize.SerializeToBytes(&rpc.AddMinorBlockHeaderListRequest{
+			MinorBlockHeaderList: []*types.MinorBlockHeader{header},
+			SlaveID:              slaveID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = op.AddMinorBlockHeaderList(nil, &rpc.Request{Data: data})
+		return err
+	}
+
+	// the headers of a slave which is not active for the shard are rejected
+	assert.Error(t, addHeaders("unknown"))
+	assert.False(t, master.rootBlockChain.IsMinorBlockValidated(header.Hash()))
+
+	activeID := master.GetOneSlaveConnById(fullShardId).GetSlaveID()
+	assert.NoError(t, addHeaders(activeID))
+	assert.True(t, master.rootBlockChain.IsMinorBlockValidated(header.Hash()))
+}
+
+func TestMigrateShard(t *testing.T) {
+	chanOp := make(chan uint32, 100)
+	master := initEnv(t, chanOp)
+	fullShardId := master.clusterConfig.SlaveList[0].FullShardList[0]
+	slaveConn := func(id string) rpc.ISlaveConn {
+		for _, conn := range master.GetSlaveConns() {
+			if conn.GetSlaveID() == id {
+				return conn
+			}
+		}
+		return nil
+	}
+
+	assert.Error(t, master.MigrateShard(fullShardId, "S1", "S2"))
+	assert.Error(t, master.MigrateShard(fullShardId, "S0", "S0"))
+	assert.Error(t, master.MigrateShard(fullShardId, "S0", "S9"))
+	assert.Equal(t, 0, len(chanOp))
+
+	assert.NoError(t, master.MigrateShard(fullShardId, "S0", "S1"))
+	// the shard is copied while it runs, then stopped for the last copy
+	for _, op := range []uint32{rpc.OpExportShard, rpc.OpImportShard, rpc.OpExportShard, rpc.OpImportShard} {
+		assert.Equal(t, op, <-chanOp)
+	}
+	for range master.GetSlaveConns() {
+		assert.Equal(t, uint32(rpc.OpShardMoved), <-chanOp)
+	}
+	var migrations []*rpc.ShardMigration
+	for i := 0; i < 100; i++ {
+		if migrations = master.GetShardMigrations(); migrations[0].Status != migrationCopying {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, len(migrations))
+	assert.Equal(t, migrationDone, migrations[0].Status)
+	assert.Equal(t, uint64(20), migrations[0].Copied)
+	assert.Equal(t, "S1", master.GetOneSlaveConnById(fullShardId).GetSlaveID())
+	assert.False(t, slaveConn("S0").HasShard(fullShardId))
+	assert.True(t, slaveConn("S1").HasShard(fullShardId))
+	assert.Equal(t, slaveConn("S1").GetFullShardList(), master.clusterConfig.SlaveList[1].FullShardList)
+	assert.Error(t, master.MigrateShard(fullShardId, "S0", "S1"))
+}
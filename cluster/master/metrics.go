@@ -0,0 +1,30 @@
+package master
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var masterMetrics = []string{"master/root/height", "master/peers", "master/syncing"}
+
+// registerMetrics reports the state of the root chain and the peers to the
+// metrics registry.
+func (s *QKCMasterBackend) registerMetrics() {
+	metrics.NewRegisteredFunctionalGauge("master/root/height", nil, func() int64 {
+		return int64(s.rootBlockChain.CurrentBlock().NumberU64())
+	})
+	metrics.NewRegisteredFunctionalGauge("master/peers", nil, func() int64 {
+		return int64(s.protocolManager.peers.Len())
+	})
+	metrics.NewRegisteredFunctionalGauge("master/syncing", nil, func() int64 {
+		if s.synchronizer.IsSyncing() {
+			return 1
+		}
+		return 0
+	})
+}
+
+func (s *QKCMasterBackend) unregisterMetrics() {
+	for _, name := range masterMetrics {
+		metrics.DefaultRegistry.Unregister(name)
+	}
+}
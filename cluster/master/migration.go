@@ -0,0 +1,258 @@
+package master
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// migrationCatchUpSize is how many bytes of the database of a shard a round
+	// of the copy may copy for the shard to be stopped for the last round.
+	migrationCatchUpSize = 64 << 20
+	// maxMigrationRounds bounds the rounds of the copy of a shard database,
+	// which does not end if the database changes faster than it is copied.
+	maxMigrationRounds = 1000
+
+	// statuses of the shard migrations
+	migrationCopying = "copying"
+	migrationDone    = "done"
+	migrationFailed  = "failed"
+)
+
+// MigrateShard moves a shard from a slave to another one while the cluster is
+// online. The database of the shard is copied in the background while the
+// shard keeps running, in rounds catching up with the blocks it adds, see
+// GetShardMigrations for the progress. The shard is only stopped for the last
+// round, during which root blocks are not added to the cluster so that the
+// shard does not miss any of them. The shard lists of the slaves are then
+// written to the cluster config file.
+func (s *QKCMasterBackend) MigrateShard(fullShardId uint32, fromID, toID string) error {
+	from, to, err := s.startMigration(fullShardId, fromID, toID)
+	if err != nil {
+		return err
+	}
+	req := &rpc.ExportShardRequest{Branch: fullShardId, RootTip: s.rootBlockChain.CurrentBlock()}
+	if err := from.ExportShard(req); err != nil {
+		err = fmt.Errorf("failed to export shard %x on slave %s: %v", fullShardId, fromID, err)
+		s.endMigration(fullShardId, err)
+		return err
+	}
+
+	log.Info("Migrating shard", "branch", fmt.Sprintf("%x", fullShardId), "from", fromID, "to", toID)
+	go func() {
+		err := s.migrateShard(fullShardId, from, to)
+		if err != nil {
+			log.Error("Failed to migrate shard", "branch", fmt.Sprintf("%x", fullShardId), "from", fromID, "to", toID, "err", err)
+		} else {
+			log.Info("Migrated shard", "branch", fmt.Sprintf("%x", fullShardId), "from", fromID, "to", toID)
+		}
+		s.endMigration(fullShardId, err)
+	}()
+	return nil
+}
+
+// migrateShard copies the database of a shard exported by the slave from to
+// the slave to, until the rounds of the copy catch up with the shard.
+func (s *QKCMasterBackend) migrateShard(fullShardId uint32, from, to rpc.ISlaveConn) error {
+	req := &rpc.ImportShardRequest{Branch: fullShardId, Source: s.slaveTarget(from.GetSlaveID())}
+	for round := 1; ; round++ {
+		if round > maxMigrationRounds {
+			return s.cancelMigration(fullShardId, from, to, s.rootBlockChain.CurrentBlock(),
+				errors.New("the shard database changes faster than it is copied"))
+		}
+		rsp, err := to.ImportShard(req)
+		if err != nil {
+			return s.cancelMigration(fullShardId, from, to, s.rootBlockChain.CurrentBlock(),
+				fmt.Errorf("failed to copy shard %x to slave %s: %v", fullShardId, to.GetSlaveID(), err))
+		}
+		s.migrationProgress(fullShardId, rsp)
+		log.Info("Copied shard database", "branch", fmt.Sprintf("%x", fullShardId), "to", to.GetSlaveID(), "round", round,
+			"copied", rsp.Copied, "pending", rsp.Pending, "total", rsp.Total)
+		if rsp.Pending == 0 && rsp.Copied <= migrationCatchUpSize {
+			return s.cutOver(fullShardId, from, to)
+		}
+	}
+}
+
+// cutOver stops the shard on the slave from for the last round of the copy of
+// its database, and runs it on the slave to.
+func (s *QKCMasterBackend) cutOver(fullShardId uint32, from, to rpc.ISlaveConn) error {
+	s.migrationMu.Lock()
+	defer s.migrationMu.Unlock()
+
+	rootTip := s.rootBlockChain.CurrentBlock()
+	fromID, toID := from.GetSlaveID(), to.GetSlaveID()
+	// the slaves may have failed during the copy
+	if _, _, err := s.checkMigration(fullShardId, fromID, toID); err != nil {
+		return s.cancelMigration(fullShardId, from, to, rootTip, err)
+	}
+	if err := from.ExportShard(&rpc.ExportShardRequest{Branch: fullShardId, RootTip: rootTip, Stop: true}); err != nil {
+		return s.cancelMigration(fullShardId, from, to, rootTip,
+			fmt.Errorf("failed to stop shard %x on slave %s: %v", fullShardId, fromID, err))
+	}
+	req := &rpc.ImportShardRequest{Branch: fullShardId, Source: s.slaveTarget(fromID), RootTip: rootTip, Final: true}
+	rsp, err := to.ImportShard(req)
+	if err != nil {
+		return s.cancelMigration(fullShardId, from, to, rootTip,
+			fmt.Errorf("failed to copy shard %x to slave %s: %v", fullShardId, toID, err))
+	}
+	s.migrationProgress(fullShardId, rsp)
+
+	s.moveShard(fullShardId, to)
+	moved := &rpc.ShardMovedRequest{Branch: fullShardId, From: fromID, To: toID, Target: s.slaveTarget(toID)}
+	for _, conn := range s.GetSlaveConns() {
+		// the old copy is retired by the slave it moved from
+		if err := conn.ShardMoved(moved); err != nil {
+			log.Error("Failed to tell slave a shard moved", "slave", conn.GetSlaveID(), "branch", fmt.Sprintf("%x", fullShardId), "err", err)
+		}
+	}
+	s.saveFullShardLists(from, to)
+	return nil
+}
+
+// cancelMigration runs the shard again on the slave from and drops its copy on
+// the slave to, it returns err.
+func (s *QKCMasterBackend) cancelMigration(fullShardId uint32, from, to rpc.ISlaveConn, rootTip *types.RootBlock, err error) error {
+	if cerr := from.ExportShard(&rpc.ExportShardRequest{Branch: fullShardId, RootTip: rootTip, Cancel: true}); cerr != nil {
+		log.Error("Failed to run shard again after a failed migration", "branch", fmt.Sprintf("%x", fullShardId), "slave", from.GetSlaveID(), "err", cerr)
+	}
+	if _, cerr := to.ImportShard(&rpc.ImportShardRequest{Branch: fullShardId, Cancel: true}); cerr != nil {
+		log.Error("Failed to drop the copy of a shard after a failed migration", "branch", fmt.Sprintf("%x", fullShardId), "slave", to.GetSlaveID(), "err", cerr)
+	}
+	return err
+}
+
+// saveFullShardLists writes the shards run by the slaves to the cluster config,
+// so that the slaves run the shards moved between them once restarted.
+func (s *QKCMasterBackend) saveFullShardLists(conns ...rpc.ISlaveConn) {
+	lists := make(map[string][]uint32, len(conns))
+	for _, conn := range conns {
+		lists[conn.GetSlaveID()] = conn.GetFullShardList()
+	}
+	for _, slave := range s.clusterConfig.SlaveList {
+		if list, ok := lists[slave.ID]; ok {
+			slave.FullShardList = list
+		}
+	}
+	if s.clusterConfig.ConfigFile == "" {
+		log.Warn("No cluster config file to save the shards of the slaves to", "shards", lists)
+		return
+	}
+	if err := config.UpdateFullShardLists(s.clusterConfig.ConfigFile, lists); err != nil {
+		log.Error("Failed to save the shards of the slaves", "file", s.clusterConfig.ConfigFile, "err", err)
+	}
+}
+
+// startMigration records a migration of a shard, it returns the slave running
+// the shard and the one it moves to.
+func (c *SlaveConnManager) startMigration(fullShardId uint32, fromID, toID string) (from, to rpc.ISlaveConn, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if m, ok := c.migrations[fullShardId]; ok && m.Status == migrationCopying {
+		return nil, nil, fmt.Errorf("shard %x is being migrated to slave %s", fullShardId, m.To)
+	}
+	if from, to, err = c.migrationConns(fullShardId, fromID, toID); err != nil {
+		return nil, nil, err
+	}
+	c.migrations[fullShardId] = &rpc.ShardMigration{
+		Branch:    fullShardId,
+		From:      fromID,
+		To:        toID,
+		Timestamp: uint64(time.Now().Unix()),
+		Status:    migrationCopying,
+	}
+	return from, to, nil
+}
+
+func (c *SlaveConnManager) migrationProgress(fullShardId uint32, rsp *rpc.ImportShardResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.migrations[fullShardId]; ok {
+		m.Copied += rsp.Copied
+		m.Total = rsp.Total
+	}
+}
+
+func (c *SlaveConnManager) endMigration(fullShardId uint32, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.migrations[fullShardId]
+	if !ok {
+		return
+	}
+	if err != nil {
+		m.Status, m.Error = migrationFailed, err.Error()
+	} else {
+		m.Status = migrationDone
+	}
+}
+
+// GetShardMigrations returns the last migration of each shard moved between
+// slaves, by shard.
+func (c *SlaveConnManager) GetShardMigrations() []*rpc.ShardMigration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	migrations := make([]*rpc.ShardMigration, 0, len(c.migrations))
+	for _, m := range c.migrations {
+		migration := *m
+		migrations = append(migrations, &migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Branch < migrations[j].Branch })
+	return migrations
+}
+
+// checkMigration returns the slave running the shard and the one it moves to.
+func (c *SlaveConnManager) checkMigration(fullShardId uint32, fromID, toID string) (from, to rpc.ISlaveConn, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.migrationConns(fullShardId, fromID, toID)
+}
+
+func (c *SlaveConnManager) migrationConns(fullShardId uint32, fromID, toID string) (from, to rpc.ISlaveConn, err error) {
+	conns := c.liveConns(c.branchToSlaveConns[fullShardId])
+	switch {
+	case len(conns) == 0:
+		return nil, nil, fmt.Errorf("no slave runs shard %x", fullShardId)
+	case len(conns) > 1:
+		return nil, nil, fmt.Errorf("shard %x is replicated on standby slaves, it cannot be migrated", fullShardId)
+	case conns[0].GetSlaveID() != fromID:
+		return nil, nil, fmt.Errorf("shard %x is not run by slave %s", fullShardId, fromID)
+	case fromID == toID:
+		return nil, nil, errors.New("shard migrated to the slave running it")
+	}
+	for _, conn := range c.liveConns(c.clientPool) {
+		if conn.GetSlaveID() == toID {
+			to = conn
+		}
+	}
+	if to == nil {
+		return nil, nil, fmt.Errorf("no slave %s", toID)
+	}
+	return conns[0], to, nil
+}
+
+// moveShard routes the requests to the shard to the slave it moved to.
+func (c *SlaveConnManager) moveShard(fullShardId uint32, to rpc.ISlaveConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.branchToSlaveConns[fullShardId] = []rpc.ISlaveConn{to}
+	c.active[fullShardId] = to.GetSlaveID()
+}
+
+// slaveTarget returns the gRPC host:port of the slave.
+func (s *QKCMasterBackend) slaveTarget(slaveID string) string {
+	for _, slave := range s.clusterConfig.SlaveList {
+		if slave.ID == slaveID {
+			return fmt.Sprintf("%s:%d", slave.IP, slave.Port)
+		}
+	}
+	return ""
+}
@@ -89,6 +89,22 @@ func (api *PrivateP2PAPI) GetMinorBlockHeaderList(req *rpc.P2PRedirectRequest) (
 	return peer.GetMinorBlockHeaderList(req)
 }
 
+func (api *PrivateP2PAPI) GetMinorStateNodeList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	peer := api.peers.Peer(req.PeerID)
+	if peer == nil {
+		return nil, errNotRegistered
+	}
+	return peer.GetMinorStateNodeList(req)
+}
+
+func (api *PrivateP2PAPI) GetCrossShardTxList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	peer := api.peers.Peer(req.PeerID)
+	if peer == nil {
+		return nil, errNotRegistered
+	}
+	return peer.GetCrossShardTxList(req)
+}
+
 func (api *PrivateP2PAPI) MinorHead(req *rpc.MinorHeadRequest) (*types.MinorBlockHeader, error) {
 	peer := api.peers.Peer(req.PeerID)
 	if peer == nil {
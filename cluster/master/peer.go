@@ -442,6 +442,66 @@ func (p *Peer) GetMinorBlockList(req *rpc.P2PRedirectRequest) ([]byte, error) {
 	}
 }
 
+func (p *Peer) requestMinorStateNodeList(rpcId uint64, req *rpc.P2PRedirectRequest) error {
+	msg, err := p2p.MakeMsgWithSerializedData(p2p.GetMinorStateNodeListRequestMsg, rpcId, p2p.Metadata{Branch: req.Branch}, req.Data)
+	if err != nil {
+		return err
+	}
+	return p.rw.WriteMsg(msg)
+}
+
+func (p *Peer) GetMinorStateNodeList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	rpcId, rpcchan := p.getRpcIdWithChan()
+	defer p.deleteChan(rpcId)
+
+	err := p.requestMinorStateNodeList(rpcId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.NewTimer(requestTimeout)
+	select {
+	case obj := <-rpcchan:
+		if ret, ok := obj.([]byte); !ok {
+			panic("invalid return result in GetMinorStateNodeList")
+		} else {
+			return ret, nil
+		}
+	case <-timeout.C:
+		return nil, fmt.Errorf("peer %v return GetMinorStateNodeList disc Read Time out for rpcid %d", p.id, rpcId)
+	}
+}
+
+func (p *Peer) requestCrossShardTxList(rpcId uint64, req *rpc.P2PRedirectRequest) error {
+	msg, err := p2p.MakeMsgWithSerializedData(p2p.GetCrossShardTxListRequestMsg, rpcId, p2p.Metadata{Branch: req.Branch}, req.Data)
+	if err != nil {
+		return err
+	}
+	return p.rw.WriteMsg(msg)
+}
+
+func (p *Peer) GetCrossShardTxList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	rpcId, rpcchan := p.getRpcIdWithChan()
+	defer p.deleteChan(rpcId)
+
+	err := p.requestCrossShardTxList(rpcId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.NewTimer(requestTimeout)
+	select {
+	case obj := <-rpcchan:
+		if ret, ok := obj.([]byte); !ok {
+			panic("invalid return result in GetCrossShardTxList")
+		} else {
+			return ret, nil
+		}
+	case <-timeout.C:
+		return nil, fmt.Errorf("peer %v return GetCrossShardTxList disc Read Time out for rpcid %d", p.id, rpcId)
+	}
+}
+
 func (p *Peer) SendResponseWithData(op p2p.P2PCommandOp, metadata p2p.Metadata, rpcId uint64, data []byte) error {
 	msg, err := p2p.MakeMsgWithSerializedData(op, rpcId, metadata, data)
 	if err != nil {
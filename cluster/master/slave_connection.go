@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,14 +22,27 @@ import (
 type SlaveConnManager struct {
 	count              int
 	clientPool         []rpc.ISlaveConn
-	branchToSlaveConns map[uint32][]rpc.ISlaveConn
+	branchToSlaveConns map[uint32][]rpc.ISlaveConn // active slaves first
 	logInfo            string
+
+	mu        sync.RWMutex
+	active    map[uint32]string      // active slave of each shard by id, the other slaves of the shard are standbys
+	failed    map[string]common.Hash // root tip when slaves stopped answering heartbeats by id
+	rejoining map[string]bool        // failed slaves answering heartbeats again, being caught up
+	failovers []*rpc.FailoverEvent
+	// last migration of each shard moved between slaves
+	migrations map[uint32]*rpc.ShardMigration
 }
 
 func (s *SlaveConnManager) InitConnManager(cfg *config.ClusterConfig) error {
 	s.clientPool = make([]rpc.ISlaveConn, 0, len(cfg.SlaveList))
 	s.branchToSlaveConns = make(map[uint32][]rpc.ISlaveConn)
 	s.logInfo = "slave connection manager"
+	s.active = make(map[uint32]string)
+	s.failed = make(map[string]common.Hash)
+	s.rejoining = make(map[string]bool)
+	s.failovers = make([]*rpc.FailoverEvent, 0)
+	s.migrations = make(map[uint32]*rpc.ShardMigration)
 
 	fullShardIds := cfg.Quarkchain.GetGenesisShardIds()
 	for _, cfg := range cfg.SlaveList {
@@ -46,31 +60,107 @@ func (s *SlaveConnManager) InitConnManager(cfg *config.ClusterConfig) error {
 		for _, fullShardID := range fullShardIds {
 			if client.HasShard(fullShardID) {
 				s.branchToSlaveConns[fullShardID] = append(s.branchToSlaveConns[fullShardID], client)
-				log.Info(s.logInfo, "branch:", fmt.Sprintf("%x", fullShardID), "is run by slave", client.GetSlaveID())
+				if _, ok := s.active[fullShardID]; !ok && !cfg.Standby {
+					s.active[fullShardID] = cfg.ID
+				}
+				log.Info(s.logInfo, "branch:", fmt.Sprintf("%x", fullShardID), "is run by slave", client.GetSlaveID(), "standby", cfg.Standby)
 			}
 		}
 	}
 	s.count = len(s.clientPool)
 
+	for fullShardID, conns := range s.branchToSlaveConns {
+		activeID, ok := s.active[fullShardID]
+		if !ok {
+			return fmt.Errorf("no active slave for branch %x", fullShardID)
+		}
+		sort.SliceStable(conns, func(i, j int) bool {
+			return conns[i].GetSlaveID() == activeID && conns[j].GetSlaveID() != activeID
+		})
+	}
 	return nil
 }
 
+// GetOneSlaveConnById returns the active slave of the shard, which mines it and
+// serves its reads and writes.
 func (c *SlaveConnManager) GetOneSlaveConnById(fullShardId uint32) rpc.ISlaveConn {
-	if conns, ok := c.branchToSlaveConns[fullShardId]; ok {
-		return conns[0]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, conn := range c.branchToSlaveConns[fullShardId] {
+		if conn.GetSlaveID() == c.active[fullShardId] {
+			return conn
+		}
 	}
 	return nil
 }
 
+// GetSlaveConnsById returns the slaves running the shard, standby ones included.
 func (c *SlaveConnManager) GetSlaveConnsById(fullShardId uint32) []rpc.ISlaveConn {
-	if conns, ok := c.branchToSlaveConns[fullShardId]; ok {
-		return conns
-	}
-	return nil
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.liveConns(c.branchToSlaveConns[fullShardId])
 }
 
+// GetSlaveConns returns the slaves of the cluster, standby ones included.
 func (c *SlaveConnManager) GetSlaveConns() []rpc.ISlaveConn {
-	return c.clientPool
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.liveConns(c.clientPool)
+}
+
+// GetActiveSlaveConns returns the slaves of the cluster which are the active
+// slave of a shard at least, the answers of a slave about the shards it is a
+// standby of are to be skipped, see IsActive.
+func (c *SlaveConnManager) GetActiveSlaveConns() []rpc.ISlaveConn {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	conns := make([]rpc.ISlaveConn, 0, len(c.clientPool))
+	for _, conn := range c.liveConns(c.clientPool) {
+		if len(c.activeShards(conn.GetSlaveID())) > 0 {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// IsActive reports whether the slave is the active slave of the shard.
+func (c *SlaveConnManager) IsActive(slaveID string, fullShardId uint32) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.active[fullShardId] == slaveID
+}
+
+// ActiveShards returns the shards the slave is the active slave of, it is a
+// standby of its other shards.
+func (c *SlaveConnManager) ActiveShards(slaveID string) []uint32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.activeShards(slaveID)
+}
+
+func (c *SlaveConnManager) activeShards(slaveID string) []uint32 {
+	fullShardIds := make([]uint32, 0)
+	for fullShardId, activeID := range c.active {
+		if activeID == slaveID {
+			fullShardIds = append(fullShardIds, fullShardId)
+		}
+	}
+	sort.Slice(fullShardIds, func(i, j int) bool { return fullShardIds[i] < fullShardIds[j] })
+	return fullShardIds
+}
+
+// liveConns filters out the slaves which stopped answering heartbeats.
+func (c *SlaveConnManager) liveConns(conns []rpc.ISlaveConn) []rpc.ISlaveConn {
+	if len(conns) == 0 {
+		return nil
+	}
+	live := make([]rpc.ISlaveConn, 0, len(conns))
+	for _, conn := range conns {
+		if _, failed := c.failed[conn.GetSlaveID()]; !failed {
+			live = append(live, conn)
+		}
+	}
+	return live
 }
 
 func (c *SlaveConnManager) ConnCount() int {
@@ -84,6 +174,7 @@ type SlaveConnection struct {
 	slaveID       string
 	logInfo       string
 	mu            sync.Mutex
+	lastHeartbeat time.Time
 }
 
 // create slave connection manager
@@ -103,25 +194,44 @@ func (s *SlaveConnection) GetSlaveID() string {
 }
 
 func (s *SlaveConnection) GetFullShardList() []uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.shardMaskList
 }
 
-func (s *SlaveConnection) HeartBeat() bool {
+// HeartBeat checks the slave is alive and tells it the shards it is the active
+// slave of, it is a standby of the other ones.
+func (s *SlaveConnection) HeartBeat(activeShards []uint32) bool {
+	data, err := serialize.SerializeToBytes(activeShards)
+	if err != nil {
+		return false
+	}
 	var tryTimes = 3
 	for tryTimes > 0 {
-		req := rpc.Request{Op: rpc.OpHeartBeat, Data: nil}
+		req := rpc.Request{Op: rpc.OpHeartBeat, Data: data}
 		_, err := s.client.Call(s.target, &req)
 		if err != nil {
 			time.Sleep(time.Duration(1) * time.Second)
 			tryTimes -= 1
 			continue
 		}
+		s.mu.Lock()
+		s.lastHeartbeat = time.Now()
+		s.mu.Unlock()
 		return true
 	}
-	log.Error(s.logInfo, "heartBeat err", "will shut down")
+	log.Error(s.logInfo, "heartBeat err", s.slaveID)
 	return false
 }
 
+// LastHeartbeat returns when the slave last answered a heartbeat, zero if it
+// never did.
+func (s *SlaveConnection) LastHeartbeat() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastHeartbeat
+}
+
 func (s *SlaveConnection) MasterInfo(ip string, port uint16, rootTip *types.RootBlock) error {
 	if rootTip == nil {
 		return errors.New("send MasterInfo failed :rootTip is nil")
@@ -188,6 +298,8 @@ func (s *SlaveConnection) SendConnectToSlaves(slaveInfoLst []*rpc.SlaveInfo) err
 }
 
 func (s *SlaveConnection) HasShard(fullShardID uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	for _, v := range s.shardMaskList {
 		if v == fullShardID {
 			return true
@@ -213,9 +325,10 @@ func (s *SlaveConnection) AddTransaction(tx *types.Transaction) error {
 
 }
 
-func (s *SlaveConnection) ExecuteTransaction(tx *types.Transaction, fromAddress *account.Address, height *uint64) ([]byte, error) {
+func (s *SlaveConnection) ExecuteTransaction(tx *types.Transaction, fromAddress *account.Address, height *uint64,
+	overrides []*rpc.StateOverride) ([]byte, error) {
 	var (
-		req = rpc.ExecuteTransactionRequest{Tx: tx, FromAddress: fromAddress, BlockHeight: height}
+		req = rpc.ExecuteTransactionRequest{Tx: tx, FromAddress: fromAddress, BlockHeight: height, StateOverrides: overrides}
 		rsp = new(rpc.ExecuteTransactionResponse)
 		res = new(rpc.Response)
 	)
@@ -346,11 +459,12 @@ func (s *SlaveConnection) GetLogs(args *qrpc.FilterQuery) ([]*types.Log, error)
 
 }
 
-func (s *SlaveConnection) EstimateGas(tx *types.Transaction, fromAddress *account.Address) (uint32, error) {
+func (s *SlaveConnection) EstimateGas(tx *types.Transaction, fromAddress *account.Address, overrides []*rpc.StateOverride) (uint32, error) {
 	var (
 		req = rpc.EstimateGasRequest{
-			Tx:          tx,
-			FromAddress: fromAddress,
+			Tx:             tx,
+			FromAddress:    fromAddress,
+			StateOverrides: overrides,
 		}
 		rsp = new(rpc.EstimateGasResponse)
 		res = new(rpc.Response)
@@ -618,6 +732,30 @@ func (s *SlaveConnection) GetMinorBlockHeaderList(req *rpc.P2PRedirectRequest) (
 	return res.Data, nil
 }
 
+func (s *SlaveConnection) GetMinorStateNodeList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetMinorStateNodeList, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func (s *SlaveConnection) GetCrossShardTxList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetCrossShardTxList, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
 func (s *SlaveConnection) HandleNewTip(request *rpc.HandleNewTipRequest) error {
 	bytes, err := serialize.SerializeToBytes(request)
 	if err != nil {
@@ -658,6 +796,31 @@ func (s *SlaveConnection) AddBlockListForSync(request *rpc.AddBlockListForSyncRe
 	return shardStatus, nil
 }
 
+func (s *SlaveConnection) SyncShardState(request *rpc.SyncShardStateRequest) (*rpc.SyncShardStateResponse, error) {
+	bytes, err := serialize.SerializeToBytes(request)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpSyncShardState, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	gRes := new(rpc.SyncShardStateResponse)
+	if err = serialize.DeserializeFromBytes(res.Data, gRes); err != nil {
+		return nil, err
+	}
+	return gRes, nil
+}
+
+func (s *SlaveConnection) PeerDisconnected(peerID string) error {
+	bytes, err := serialize.SerializeToBytes(peerID)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Call(s.target, &rpc.Request{Op: rpc.OpPeerDisconnected, Data: bytes})
+	return err
+}
+
 func (s *SlaveConnection) SetMining(mining bool) error {
 	bytes, err := serialize.SerializeToBytes(mining)
 	if err != nil {
@@ -667,13 +830,20 @@ func (s *SlaveConnection) SetMining(mining bool) error {
 	return err
 }
 
-func (s *SlaveConnection) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) error {
+func (s *SlaveConnection) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) ([]*rpc.ShardDBCheck, error) {
 	bytes, err := serialize.SerializeToBytes(rootBlock)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = s.client.Call(s.target, &rpc.Request{Op: rpc.OpCheckMinorBlocksInRoot, Data: bytes})
-	return err
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpCheckMinorBlocksInRoot, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	var gRes rpc.CheckMinorBlocksInRootResponse
+	if err = serialize.DeserializeFromBytes(res.Data, &gRes); err != nil {
+		return nil, err
+	}
+	return gRes.ShardList, nil
 }
 
 // get minor block by hash or by height
@@ -718,3 +888,194 @@ func (s *SlaveConnection) GetRootChainStakes(address account.Address, lastMinor
 	}
 	return getRootChainStakesResponse.Stakes, getRootChainStakesResponse.Signer, nil
 }
+
+func (s *SlaveConnection) TraceTransaction(txHash common.Hash, branch account.Branch, config *rpc.TraceConfig) ([]byte, error) {
+	var (
+		req = rpc.TraceTransactionRequest{TxHash: txHash, Branch: branch.Value, Config: config}
+		rsp = new(rpc.TraceResponse)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpTraceTransaction, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp.Result, nil
+}
+
+func (s *SlaveConnection) TraceMinorBlock(blockHash common.Hash, branch account.Branch, config *rpc.TraceConfig) ([]byte, error) {
+	var (
+		req = rpc.TraceMinorBlockRequest{MinorBlockHash: blockHash, Branch: branch.Value, Config: config}
+		rsp = new(rpc.TraceResponse)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpTraceMinorBlock, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp.Result, nil
+}
+
+func (s *SlaveConnection) GetProof(address *account.Address, storageKeys []common.Hash, height *uint64) (*rpc.AccountProof, error) {
+	var (
+		req = rpc.GetProofRequest{Address: address, StorageKeys: storageKeys, BlockHeight: height}
+		rsp = new(rpc.AccountProof)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetProof, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (s *SlaveConnection) GetPendingTransactionHashes(branch account.Branch) ([]common.Hash, error) {
+	var (
+		req = rpc.GetPendingTransactionHashesRequest{Branch: branch.Value}
+		rsp = new(rpc.GetPendingTransactionHashesResponse)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetPendingTransactionHashes, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp.TxHashes, nil
+}
+
+func (s *SlaveConnection) GetXShardDepositStatus(txHash common.Hash, branch account.Branch, sourceBlockHash, rootBlockHash common.Hash) (*rpc.XShardDepositStatus, error) {
+	var (
+		req = rpc.GetXShardDepositStatusRequest{Branch: branch.Value, TxHash: txHash, MinorBlockHash: sourceBlockHash, RootBlockHash: rootBlockHash}
+		rsp = new(rpc.XShardDepositStatus)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetXShardDepositStatus, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (s *SlaveConnection) GetFeeHistory(branch account.Branch, tokenID, blockCount uint64, percentiles []uint32) (*rpc.FeeHistory, error) {
+	var (
+		req = rpc.GetFeeHistoryRequest{Branch: branch.Value, TokenID: tokenID, BlockCount: blockCount, Percentiles: percentiles}
+		rsp = new(rpc.FeeHistory)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetFeeHistory, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// GetTxPoolContent returns the transaction pools of all the shards of the slave
+// if the value of branch is 0.
+func (s *SlaveConnection) GetTxPoolContent(branch account.Branch, sender *account.Recipient, countOnly bool) ([]*rpc.ShardTxPool, error) {
+	var (
+		req = rpc.GetTxPoolContentRequest{Branch: branch.Value, Sender: sender, CountOnly: countOnly}
+		rsp = new(rpc.GetTxPoolContentResponse)
+	)
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetTxPoolContent, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	if err = serialize.Deserialize(serialize.NewByteBuffer(res.Data), rsp); err != nil {
+		return nil, err
+	}
+	return rsp.Shards, nil
+}
+
+// ExportShard makes the slave serve the database of the shard to the slave it
+// moves to, stops the shard for the last copy or cancels the export.
+func (s *SlaveConnection) ExportShard(req *rpc.ExportShardRequest) error {
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Call(s.target, &rpc.Request{Op: rpc.OpExportShard, Data: bytes})
+	return err
+}
+
+// ImportShard copies a round of the database of the shard from the slave it
+// moves from, the slave runs the shard after the final one.
+func (s *SlaveConnection) ImportShard(req *rpc.ImportShardRequest) (*rpc.ImportShardResponse, error) {
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpImportShard, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	rsp := new(rpc.ImportShardResponse)
+	if err := serialize.DeserializeFromBytes(res.Data, rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// ShardMoved updates the shards of the slave when it is one of the two ends of
+// the move, and tells it where to send the messages to the shard from now on.
+func (s *SlaveConnection) ShardMoved(req *rpc.ShardMovedRequest) error {
+	s.mu.Lock()
+	shardMaskList := make([]uint32, 0, len(s.shardMaskList)+1)
+	for _, id := range s.shardMaskList {
+		if id != req.Branch {
+			shardMaskList = append(shardMaskList, id)
+		}
+	}
+	if s.slaveID == req.To {
+		shardMaskList = append(shardMaskList, req.Branch)
+		sort.Slice(shardMaskList, func(i, j int) bool { return shardMaskList[i] < shardMaskList[j] })
+	}
+	if s.slaveID == req.From || s.slaveID == req.To {
+		s.shardMaskList = shardMaskList
+	}
+	s.mu.Unlock()
+
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Call(s.target, &rpc.Request{Op: rpc.OpShardMoved, Data: bytes})
+	return err
+}
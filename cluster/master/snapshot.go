@@ -0,0 +1,60 @@
+package master
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AddSnapshotRootBlocks adds root blocks of a state snapshot to the root chain
+// of a cluster the shards of which are restored from the snapshot afterwards.
+// The minor blocks they confirm cannot be run by the shards, nor the PoSW
+// stakes of their miners checked, so the minor block headers are only checked
+// to make a chain for each shard and by verifyHeader, with what does not need
+// the state of the shard, before they are marked as validated. The root blocks
+// themselves are fully checked. They are not broadcast to the slaves.
+func (s *QKCMasterBackend) AddSnapshotRootBlocks(blocks []*types.RootBlock, verifyHeader func(*types.MinorBlockHeader) error) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	// held exclusively so that no root block is added after the tip is checked
+	s.migrationMu.Lock()
+	defer s.migrationMu.Unlock()
+	if blocks[0].ParentHash() != s.rootBlockChain.CurrentBlock().Hash() {
+		return errors.New("snapshot root blocks must extend the root tip")
+	}
+
+	latest := s.rootBlockChain.GetLatestMinorBlockHeaders(blocks[0].ParentHash())
+	for _, block := range blocks {
+		for _, header := range block.MinorBlockHeaders() {
+			fullShardId := header.Branch.GetFullShardID()
+			prev, ok := latest[fullShardId]
+			if ok && (header.Number != prev.Number+1 || header.ParentHash != prev.Hash()) {
+				return fmt.Errorf("minor block %x of root block %d does not extend minor block %x", header.Hash(), block.NumberU64(), prev.Hash())
+			}
+			if !ok && header.Number != 0 {
+				return fmt.Errorf("first minor block %x of shard %x is not a genesis block", header.Hash(), fullShardId)
+			}
+			if err := verifyHeader(header); err != nil {
+				return fmt.Errorf("minor block %x of root block %d: %v", header.Hash(), block.NumberU64(), err)
+			}
+			latest[fullShardId] = header
+		}
+	}
+
+	chain := make([]types.IBlock, 0, len(blocks))
+	hashes := make([]common.Hash, 0, len(blocks))
+	for _, block := range blocks {
+		for _, header := range block.MinorBlockHeaders() {
+			s.rootBlockChain.AddValidatedMinorBlockHeader(header.Hash(), header.CoinbaseAmount)
+		}
+		chain = append(chain, block)
+		hashes = append(hashes, block.Hash())
+	}
+	s.rootBlockChain.SetPoSWStakesTrusted(hashes, true)
+	defer s.rootBlockChain.SetPoSWStakesTrusted(hashes, false)
+	_, err := s.rootBlockChain.InsertChain(chain)
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/monitoring"
 	"github.com/QuarkChain/goquarkchain/cluster/service"
 	"github.com/QuarkChain/goquarkchain/consensus"
 	"github.com/QuarkChain/goquarkchain/core/types"
@@ -160,6 +161,8 @@ func (m *Miner) resultLoop() {
 				time.Sleep(time.Duration(3) * time.Second)
 				coinbase := block.Coinbase()
 				m.commit(&coinbase)
+			} else {
+				monitoring.Default().MinedBlock(block)
 			}
 
 		case <-m.exitCh:
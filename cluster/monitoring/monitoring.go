@@ -0,0 +1,347 @@
+// Package monitoring posts the events of the cluster to the topics of a Kafka
+// REST proxy as configured by the MONITORING section of the cluster config.
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// RootChain is the chain name of the records about root blocks.
+	RootChain = "R"
+
+	contentType    = "application/vnd.kafka.json.v2+json"
+	batchSize      = 100
+	queueSize      = 4096
+	maxRetries     = 3
+	requestTimeout = 5 * time.Second
+
+	maxSeenBlocks = 1024
+	seenBlockTTL  = 10 * time.Minute
+)
+
+var (
+	flushInterval = time.Second
+	retryBackoff  = 500 * time.Millisecond
+)
+
+// ShardChain returns the chain name of the records about the blocks of a shard.
+func ShardChain(fullShardID uint32) string {
+	return fmt.Sprintf("%d", fullShardID)
+}
+
+type record struct {
+	topic string
+	value map[string]interface{}
+}
+
+type seenBlock struct {
+	peer string
+	time time.Time
+}
+
+// Sink batches the records posted to it and sends them to the Kafka REST proxy
+// in the background. Records are dropped rather than blocking the caller when
+// the proxy cannot keep up or is down, and the sending of a batch is retried a
+// few times while the proxy is up. A nil sink drops every record.
+type Sink struct {
+	url              string
+	network          string
+	cluster          string
+	source           string
+	minerTopic       string
+	propagationTopic string
+	errorsTopic      string
+
+	client  *http.Client
+	queue   chan *record
+	quit    chan struct{}
+	wg      sync.WaitGroup
+	dropped uint64 // records dropped since the sink started, atomic
+	down    bool   // whether the last batch failed, only used by the loop
+
+	seenMu sync.Mutex
+	seen   map[common.Hash]*seenBlock
+}
+
+// New starts a sink posting to the proxy of cfg, it returns nil when no proxy
+// is configured. Source identifies the process sending the records.
+func New(cfg *config.MonitoringConfig, source string) *Sink {
+	if cfg == nil || cfg.KafkaRestAddress == "" {
+		return nil
+	}
+	url := strings.TrimSuffix(cfg.KafkaRestAddress, "/")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "http://" + url
+	}
+	s := &Sink{
+		url:              url,
+		network:          cfg.NetworkName,
+		cluster:          cfg.ClusterID,
+		source:           source,
+		minerTopic:       cfg.MinerTopic,
+		propagationTopic: cfg.PropagationTopic,
+		errorsTopic:      cfg.Errors,
+		client:           &http.Client{Timeout: requestTimeout},
+		queue:            make(chan *record, queueSize),
+		quit:             make(chan struct{}),
+		seen:             make(map[common.Hash]*seenBlock),
+	}
+	s.wg.Add(1)
+	go s.loop()
+	return s
+}
+
+var defaultSink atomic.Value
+
+// SetDefault sets the sink the events of the process are posted to.
+func SetDefault(s *Sink) {
+	defaultSink.Store(s)
+}
+
+// Default returns the sink the events of the process are posted to, nil if
+// monitoring is disabled.
+func Default() *Sink {
+	s, _ := defaultSink.Load().(*Sink)
+	return s
+}
+
+// Stop sends the queued records and stops the sink.
+func (s *Sink) Stop() {
+	if s == nil {
+		return
+	}
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// Dropped returns the number of records which could not be sent.
+func (s *Sink) Dropped() uint64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// post queues value for topic with the fields identifying the sender, the
+// record is dropped if the queue is full.
+func (s *Sink) post(topic string, value map[string]interface{}) {
+	if topic == "" {
+		return
+	}
+	value["time"] = time.Now().Unix()
+	value["network"] = s.network
+	value["cluster"] = s.cluster
+	value["source"] = s.source
+	select {
+	case s.queue <- &record{topic: topic, value: value}:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// MinedBlock posts a block mined by the cluster to the miner topic.
+func (s *Sink) MinedBlock(block types.IBlock) {
+	if s == nil {
+		return
+	}
+	chain, items := RootChain, len(block.Content())
+	if mBlock, ok := block.(*types.MinorBlock); ok {
+		chain = ShardChain(mBlock.Branch().Value)
+	}
+	s.post(s.minerTopic, map[string]interface{}{
+		"chain":      chain,
+		"hash":       block.Hash().Hex(),
+		"height":     block.NumberU64(),
+		"coinbase":   block.Coinbase().ToHex(),
+		"difficulty": block.Difficulty().String(),
+		"createTime": block.Time(),
+		"items":      items, // transactions of minor blocks, minor headers of root blocks
+		"size":       float64(block.GetSize()),
+	})
+}
+
+// BlockSeen records when a block is first announced by a peer, the timing is
+// posted to the propagation topic once the block is imported.
+func (s *Sink) BlockSeen(hash common.Hash, peer string) {
+	if s == nil {
+		return
+	}
+	now := time.Now()
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	if _, ok := s.seen[hash]; ok {
+		return
+	}
+	if len(s.seen) >= maxSeenBlocks {
+		for h, b := range s.seen {
+			if now.Sub(b.time) > seenBlockTTL {
+				delete(s.seen, h)
+			}
+		}
+		if len(s.seen) >= maxSeenBlocks {
+			return
+		}
+	}
+	s.seen[hash] = &seenBlock{peer: peer, time: now}
+}
+
+// BlockImported posts the propagation timing of a block announced by a peer,
+// blocks never seen from a peer are ignored.
+func (s *Sink) BlockImported(chain string, header types.IHeader) {
+	if s == nil {
+		return
+	}
+	hash := header.Hash()
+	s.seenMu.Lock()
+	seen, ok := s.seen[hash]
+	delete(s.seen, hash)
+	s.seenMu.Unlock()
+	if !ok {
+		return
+	}
+	now := time.Now()
+	s.post(s.propagationTopic, map[string]interface{}{
+		"chain":      chain,
+		"hash":       hash.Hex(),
+		"height":     header.NumberU64(),
+		"peer":       seen.peer,
+		"createTime": header.GetTime(),
+		"firstSeen":  seen.time.UnixNano() / int64(time.Millisecond),
+		"imported":   now.UnixNano() / int64(time.Millisecond),
+		"importMs":   now.Sub(seen.time).Nanoseconds() / int64(time.Millisecond),
+	})
+}
+
+// ErrorHandler returns a log handler posting the error logs to the errors topic.
+func (s *Sink) ErrorHandler() log.Handler {
+	return log.FuncHandler(func(r *log.Record) error {
+		if s == nil || r.Lvl > log.LvlError {
+			return nil
+		}
+		ctx := make(map[string]string, len(r.Ctx)/2)
+		for i := 0; i+1 < len(r.Ctx); i += 2 {
+			ctx[fmt.Sprint(r.Ctx[i])] = fmt.Sprint(r.Ctx[i+1])
+		}
+		s.post(s.errorsTopic, map[string]interface{}{
+			"level":  r.Lvl.String(),
+			"msg":    r.Msg,
+			"caller": fmt.Sprint(r.Call),
+			"ctx":    ctx,
+		})
+		return nil
+	})
+}
+
+func (s *Sink) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var (
+		batches = make(map[string][]interface{})
+		pending int
+	)
+	flush := func() {
+		for topic, values := range batches {
+			s.send(topic, values)
+		}
+		batches = make(map[string][]interface{})
+		pending = 0
+	}
+	for {
+		select {
+		case r := <-s.queue:
+			batches[r.topic] = append(batches[r.topic], r.value)
+			if pending++; pending >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.quit:
+			for {
+				select {
+				case r := <-s.queue:
+					batches[r.topic] = append(batches[r.topic], r.value)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send posts a batch of records to topic, retrying while the proxy is up. A
+// single attempt is made while it is down so the queue keeps draining.
+func (s *Sink) send(topic string, values []interface{}) {
+	records := make([]map[string]interface{}, len(values))
+	for i, value := range values {
+		records[i] = map[string]interface{}{"value": value}
+	}
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		log.Warn("Failed to encode monitoring records", "topic", topic, "err", err)
+		atomic.AddUint64(&s.dropped, uint64(len(values)))
+		return
+	}
+
+	attempts := maxRetries
+	if s.down {
+		attempts = 1
+	}
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(retryBackoff << uint(i-1)):
+			case <-s.quit:
+			}
+		}
+		retry, err := s.postBatch(topic, body)
+		if err == nil {
+			if s.down {
+				s.down = false
+				log.Info("Monitoring endpoint is back", "url", s.url, "dropped", s.Dropped())
+			}
+			return
+		}
+		if !retry || i == attempts-1 {
+			if !s.down {
+				s.down = true
+				log.Warn("Failed to send monitoring records", "url", s.url, "topic", topic, "err", err)
+			}
+			break
+		}
+	}
+	atomic.AddUint64(&s.dropped, uint64(len(values)))
+}
+
+// postBatch posts an encoded batch and reports whether a failure is worth
+// retrying.
+func (s *Sink) postBatch(topic string, body []byte) (bool, error) {
+	resp, err := s.client.Post(s.url+"/topics/"+topic, contentType, bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status %s", resp.Status)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,148 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// kafkaRest stands in for a Kafka REST proxy, failing the first requests.
+type kafkaRest struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+	records  map[string][]map[string]interface{}
+}
+
+func (k *kafkaRest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.requests++
+	if k.failures > 0 {
+		k.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("Content-Type") != contentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		Records []struct {
+			Value map[string]interface{} `json:"value"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	topic := r.URL.Path[len("/topics/"):]
+	for _, record := range req.Records {
+		k.records[topic] = append(k.records[topic], record.Value)
+	}
+	w.Write([]byte(`{"offsets":[]}`))
+}
+
+func newTestSink(failures int) (*Sink, *kafkaRest, func()) {
+	retryBackoff = time.Millisecond
+	k := &kafkaRest{failures: failures, records: make(map[string][]map[string]interface{})}
+	server := httptest.NewServer(k)
+	cfg := config.NewMonitoringConfig()
+	cfg.NetworkName = "testnet"
+	cfg.KafkaRestAddress = server.Listener.Addr().String()
+	return New(cfg, "S0"), k, server.Close
+}
+
+func newTestBlock(height uint64) *types.MinorBlock {
+	header := &types.MinorBlockHeader{
+		Number:     height,
+		Branch:     account.Branch{Value: 1},
+		Time:       uint64(time.Now().Unix()),
+		Difficulty: big.NewInt(1000),
+		Coinbase:   account.CreatEmptyAddress(0),
+	}
+	return types.NewMinorBlock(header, &types.MinorBlockMeta{}, nil, nil, nil)
+}
+
+func TestSinkRecords(t *testing.T) {
+	sink, k, closeServer := newTestSink(1)
+	defer closeServer()
+
+	block := newTestBlock(12)
+	sink.MinedBlock(block)
+	sink.BlockImported(ShardChain(1), newTestBlock(13).Header()) // never seen from a peer
+	sink.BlockSeen(block.Hash(), "peer1")
+	sink.BlockSeen(block.Hash(), "peer2")
+	sink.BlockImported(ShardChain(1), block.Header())
+	logger := log.New()
+	logger.SetHandler(sink.ErrorHandler())
+	logger.Warn("not posted")
+	logger.Error("Failed to add minor block", "height", 12)
+	sink.Stop()
+
+	if sink.Dropped() != 0 {
+		t.Errorf("dropped %d records", sink.Dropped())
+	}
+	mined := k.records["qkc_miner"]
+	if len(mined) != 1 || mined[0]["hash"] != block.Hash().Hex() || mined[0]["chain"] != "1" ||
+		mined[0]["height"] != float64(12) || mined[0]["network"] != "testnet" || mined[0]["source"] != "S0" {
+		t.Errorf("unexpected miner records %v", mined)
+	}
+	propagation := k.records["block_propagation"]
+	if len(propagation) != 1 || propagation[0]["hash"] != block.Hash().Hex() || propagation[0]["peer"] != "peer1" {
+		t.Errorf("unexpected propagation records %v", propagation)
+	}
+	errs := k.records["error"]
+	if len(errs) != 1 || errs[0]["msg"] != "Failed to add minor block" ||
+		errs[0]["ctx"].(map[string]interface{})["height"] != "12" {
+		t.Errorf("unexpected error records %v", errs)
+	}
+}
+
+func TestSinkEndpointDown(t *testing.T) {
+	sink, k, closeServer := newTestSink(0)
+	closeServer()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize*2; i++ {
+			sink.MinedBlock(newTestBlock(uint64(i)))
+		}
+		sink.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("sink blocked while the endpoint is down")
+	}
+	if sink.Dropped() != queueSize*2 {
+		t.Errorf("dropped %d records, want %d", sink.Dropped(), queueSize*2)
+	}
+	if k.requests != 0 {
+		t.Errorf("unexpected requests %d", k.requests)
+	}
+}
+
+func TestNilSink(t *testing.T) {
+	if sink := New(config.NewMonitoringConfig(), "master"); sink != nil {
+		t.Fatal("sink created without a proxy address")
+	}
+	var sink *Sink
+	sink.MinedBlock(newTestBlock(1))
+	sink.BlockSeen(newTestBlock(1).Hash(), "peer")
+	sink.BlockImported(RootChain, newTestBlock(1).Header())
+	sink.ErrorHandler().Log(&log.Record{Lvl: log.LvlError})
+	sink.Stop()
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 )
@@ -47,6 +48,19 @@ const (
 	OpSubmitWork
 	OpAddMinorBlockListForSync
 	OpGetRootChainStakes
+	OpTraceTransaction
+	OpTraceMinorBlock
+	OpGetProof
+	OpGetPendingTransactionHashes
+	OpGetXShardDepositStatus
+	OpGetFeeHistory
+	OpGetTxPoolContent
+	OpExportShard
+	OpImportShard
+	OpShardMoved
+	OpGetShardData
+	OpSyncShardState
+	OpPeerDisconnected
 	// p2p api
 	OpBroadcastNewTip
 	OpBroadcastTransactions
@@ -60,6 +74,8 @@ const (
 	OpSetMining
 	OpAddMinorBlockHeaderList
 	OpCheckMinorBlocksInRoot
+	OpGetMinorStateNodeList
+	OpGetCrossShardTxList
 
 	MasterServer = serverType(1)
 	SlaveServer  = serverType(0)
@@ -79,6 +95,8 @@ var (
 		OpGetMinorBlockList:               {name: "GetMinorBlockList"},
 		OpGetMinorBlockHeaderList:         {name: "GetMinorBlockHeaderList"},
 		OpGetMinorBlockHeaderListWithSkip: {name: "GetMinorBlockHeaderListWithSkip"},
+		OpGetMinorStateNodeList:           {name: "GetMinorStateNodeList"},
+		OpGetCrossShardTxList:             {name: "GetCrossShardTxList"},
 	}
 	// slave apis
 	slaveApis = map[uint32]opType{
@@ -112,6 +130,19 @@ var (
 		OpSetMining:                   {name: "SetMining"},
 		OpCheckMinorBlocksInRoot:      {name: "CheckMinorBlocksInRoot"},
 		OpGetRootChainStakes:          {name: "GetRootChainStakes"},
+		OpTraceTransaction:            {name: "TraceTransaction"},
+		OpTraceMinorBlock:             {name: "TraceMinorBlock"},
+		OpGetProof:                    {name: "GetProof"},
+		OpGetPendingTransactionHashes: {name: "GetPendingTransactionHashes"},
+		OpGetXShardDepositStatus:      {name: "GetXShardDepositStatus"},
+		OpGetFeeHistory:               {name: "GetFeeHistory"},
+		OpGetTxPoolContent:            {name: "GetTxPoolContent"},
+		OpExportShard:                 {name: "ExportShard"},
+		OpImportShard:                 {name: "ImportShard"},
+		OpShardMoved:                  {name: "ShardMoved"},
+		OpGetShardData:                {name: "GetShardData"},
+		OpSyncShardState:              {name: "SyncShardState"},
+		OpPeerDisconnected:            {name: "PeerDisconnected"},
 		// p2p api
 		OpGetMinorBlockList:               {name: "GetMinorBlockList"},
 		OpGetMinorBlockHeaderList:         {name: "GetMinorBlockHeaderList"},
@@ -119,6 +150,8 @@ var (
 		OpHandleNewTip:                    {name: "HandleNewTip"},
 		OpAddTransactions:                 {name: "AddTransactions"},
 		OpHandleNewMinorBlock:             {name: "HandleNewMinorBlock"},
+		OpGetMinorStateNodeList:           {name: "GetMinorStateNodeList"},
+		OpGetCrossShardTxList:             {name: "GetCrossShardTxList"},
 	}
 )
 
@@ -185,10 +218,14 @@ func (c *rpcClient) getConn(hostport string) (*opNode, error) {
 }
 
 func (c *rpcClient) grpcOp(hostport string, req *Request) (*Response, error) {
-
-	node, err := c.getConn(hostport)
-	if err != nil {
-		return nil, err
+	// the servers of a single process cluster are called directly
+	client, ok := getInProcService(hostport, c.tp)
+	if !ok {
+		node, err := c.getConn(hostport)
+		if err != nil {
+			return nil, err
+		}
+		client = node.client
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
@@ -198,11 +235,15 @@ func (c *rpcClient) grpcOp(hostport string, req *Request) (*Response, error) {
 		res *Response
 	)
 
-	rs := node.client.MethodByName(c.funcs[req.Op].name).Call(val)
+	// the latencies and failures are reported as grpc/<op>/duration and errors
+	name := c.funcs[req.Op].name
+	start := time.Now()
+	rs := client.MethodByName(name).Call(val)
+	metrics.GetOrRegisterTimer("grpc/"+name+"/duration", nil).UpdateSince(start)
 
 	if !rs[1].IsNil() {
-		err = rs[1].Interface().(error)
-		return nil, err
+		metrics.GetOrRegisterCounter("grpc/"+name+"/errors", nil).Inc(1)
+		return nil, rs[1].Interface().(error)
 	} else if !rs[0].IsNil() {
 		res = rs[0].Interface().(*Response)
 		return res, nil
@@ -212,6 +253,9 @@ func (c *rpcClient) grpcOp(hostport string, req *Request) (*Response, error) {
 
 func (c *rpcClient) addConn(hostport string) (*opNode, error) {
 	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if t := getTLS(); t != nil {
+		opts = []grpc.DialOption{t.DialOption(hostport)}
+	}
 	conn, err := grpc.Dial(hostport, opts...)
 	if err != nil {
 		return nil, err
@@ -11,7 +11,11 @@ import (
 )
 
 func StartGRPCServer(hostport string, apis []rpc.API) (net.Listener, *grpc.Server, error) {
-	handler := grpc.NewServer()
+	var opts []grpc.ServerOption
+	if t := getTLS(); t != nil {
+		opts = append(opts, t.ServerOption())
+	}
+	handler := grpc.NewServer(opts...)
 	for _, api := range apis {
 		if qcom.IsNil(api.Service) {
 			panic(fmt.Sprintf("%s service is nil", api.Namespace))
@@ -58,3 +58,43 @@ func TestGRPCAPI(t *testing.T) {
 	}
 	handler.Stop()
 }
+
+func TestInProcServer(t *testing.T) {
+	var (
+		apis = []rpc.API{
+			{
+				Namespace: "rpc." + reflect.TypeOf(MasterServerSideOp{}).Name(),
+				Version:   "3.0",
+				Service:   NewMasterTestOp(),
+				Public:    false,
+			},
+		}
+		cfg      = testSlaveConfig(1)
+		hostport = fmt.Sprintf("%s:%d", cfg.IP, cfg.Port)
+	)
+
+	srv, err := StartInProcServer(hostport, apis)
+	if err != nil {
+		t.Fatalf("failed to create in-process server %v", err)
+	}
+	if _, err := StartInProcServer(hostport, apis); err == nil {
+		t.Fatal("in-process server started twice")
+	}
+
+	cli := NewClient(MasterServer).(*rpcClient)
+	res, err := cli.Call(hostport, &Request{Op: OpAddMinorBlockHeader, Data: []byte(fmt.Sprintf("%s op request", cli.GetOpName(OpAddMinorBlockHeader)))})
+	if err != nil {
+		t.Fatalf("request master function %s %v", cli.GetOpName(OpAddMinorBlockHeader), err)
+	}
+	if string(res.Data) != fmt.Sprintf("%s response", cli.GetOpName(OpAddMinorBlockHeader)) {
+		t.Fatalf("response data %s is not the value of expection", string(res.Data))
+	}
+	if len(cli.connVals) != 0 {
+		t.Fatal("in-process call made over grpc")
+	}
+
+	srv.Stop()
+	if _, ok := getInProcService(hostport, MasterServer); ok {
+		t.Fatal("in-process server still serving after stop")
+	}
+}
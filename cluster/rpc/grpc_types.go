@@ -44,6 +44,28 @@ type ShardStatus struct {
 	LastBlockTime      uint64
 }
 
+// FailoverEvent records a slave which stopped answering heartbeats, the standby
+// slaves promoted then and the slave serving the writes of each of its shards.
+type FailoverEvent struct {
+	Timestamp    uint64
+	Slave        string
+	Promoted     []string
+	ShardToSlave map[uint32]string
+}
+
+// ShardMigration is the progress of a shard moving from the slave From to the
+// slave To, Status is "copying", "done" or "failed" with Error.
+type ShardMigration struct {
+	Branch    uint32
+	From      string
+	To        string
+	Timestamp uint64
+	Status    string
+	Copied    uint64
+	Total     uint64
+	Error     string
+}
+
 // Master instructs a slave to connect to other slaves
 type ConnectToSlavesRequest struct {
 	SlaveInfoList []*SlaveInfo `json:"slave_info_list" gencodec:"required" bytesizeofslicelen:"4"`
@@ -159,10 +181,32 @@ type GetTransactionResponse struct {
 	Index      uint32            `json:"index" gencodec:"required"`
 }
 
+// StorageEntry is a single storage slot of a StateOverride.
+type StorageEntry struct {
+	Key   common.Hash `json:"key" gencodec:"required"`
+	Value common.Hash `json:"value" gencodec:"required"`
+}
+
+// StateOverride replaces the state of an account before a call or gas estimation
+// is executed, the state of the chain itself is never modified.
+// Storage replaces the whole storage of the account if ReplaceStorage is set,
+// otherwise only the given slots are changed; Code is only applied if
+// OverrideCode is set so that the code of an account can be cleared.
+type StateOverride struct {
+	Address        account.Recipient         `json:"address" gencodec:"required"`
+	Balances       []*types.TokenBalancePair `json:"balances" bytesizeofslicelen:"4"`
+	Nonce          *uint64                   `json:"nonce" ser:"nil"`
+	OverrideCode   bool                      `json:"override_code"`
+	Code           []byte                    `json:"code" bytesizeofslicelen:"4"`
+	Storage        []*StorageEntry           `json:"storage" bytesizeofslicelen:"4"`
+	ReplaceStorage bool                      `json:"replace_storage"`
+}
+
 type ExecuteTransactionRequest struct {
-	Tx          *types.Transaction `json:"tx" gencodec:"required"`
-	FromAddress *account.Address   `json:"from_address" gencodec:"required"`
-	BlockHeight *uint64            `json:"block_height" ser:"nil"`
+	Tx             *types.Transaction `json:"tx" gencodec:"required"`
+	FromAddress    *account.Address   `json:"from_address" gencodec:"required"`
+	BlockHeight    *uint64            `json:"block_height" ser:"nil"`
+	StateOverrides []*StateOverride   `json:"state_overrides" bytesizeofslicelen:"4"`
 }
 
 type ExecuteTransactionResponse struct {
@@ -294,6 +338,9 @@ type AddMinorBlockHeaderRequest struct {
 	XShardTxCount     uint32                  `json:"x_shard_tx_count" gencodec:"required"`
 	CoinbaseAmountMap *types.TokenBalances    `json:"coinbase_amount_map" gencodec:"required"`
 	ShardStats        *ShardStatus            `json:"shard_stats" gencodec:"required"`
+	// SlaveID is the slave sending the header, only the active slave of the
+	// shard is listened to
+	SlaveID string `json:"slave_id" gencodec:"required"`
 }
 
 type AddMinorBlockHeaderResponse struct {
@@ -302,6 +349,7 @@ type AddMinorBlockHeaderResponse struct {
 
 type AddMinorBlockHeaderListRequest struct {
 	MinorBlockHeaderList []*types.MinorBlockHeader `json:"minor_block_header_list" gencodec:"required" bytesizeofslicelen:"4"`
+	SlaveID              string                    `json:"slave_id" gencodec:"required"`
 }
 
 type CrossShardTransactionList struct {
@@ -322,6 +370,9 @@ type AddBlockListForSyncRequest struct {
 	Branch             uint32        `json:"branch" gencodec:"required"`
 	PeerId             string        `json:"peer_id" gencodec:"required"`
 	MinorBlockHashList []common.Hash `json:"minor_block_list" gencodec:"required" bytesizeofslicelen:"4"`
+	// FastSync asks the shard to add the blocks without executing them if it
+	// has not executed any block yet, see SyncShardStateRequest.
+	FastSync bool `json:"fast_sync"`
 }
 
 type AddBlockListForSyncResponse struct {
@@ -339,8 +390,9 @@ type GetLogResponse struct {
 }
 
 type EstimateGasRequest struct {
-	Tx          *types.Transaction `json:"tx" gencodec:"required"`
-	FromAddress *account.Address   `json:"from_address" gencodec:"required"`
+	Tx             *types.Transaction `json:"tx" gencodec:"required"`
+	FromAddress    *account.Address   `json:"from_address" gencodec:"required"`
+	StateOverrides []*StateOverride   `json:"state_overrides" bytesizeofslicelen:"4"`
 }
 
 type EstimateGasResponse struct {
@@ -413,6 +465,318 @@ type GetRootChainStakesResponse struct {
 	Signer *account.Recipient `json:"signer" gencodec:"required"`
 }
 
+type TraceConfig struct {
+	DisableMemory  bool   `json:"disable_memory" gencodec:"required"`
+	DisableStack   bool   `json:"disable_stack" gencodec:"required"`
+	DisableStorage bool   `json:"disable_storage" gencodec:"required"`
+	Limit          uint32 `json:"limit" gencodec:"required"`
+	Tracer         string `json:"tracer" gencodec:"required"`
+}
+
+type TraceTransactionRequest struct {
+	TxHash common.Hash  `json:"tx_hash" gencodec:"required"`
+	Branch uint32       `json:"branch" gencodec:"required"`
+	Config *TraceConfig `json:"config" ser:"nil"`
+}
+
+type TraceMinorBlockRequest struct {
+	MinorBlockHash common.Hash  `json:"minor_block_hash" gencodec:"required"`
+	Branch         uint32       `json:"branch" gencodec:"required"`
+	Config         *TraceConfig `json:"config" ser:"nil"`
+}
+
+// TraceResponse carries the json encoded trace result, as the result
+// layout depends on the tracer used by slave.
+type TraceResponse struct {
+	Result []byte `json:"result" gencodec:"required" bytesizeofslicelen:"4"`
+}
+
+// MerkleProof is the list of RLP encoded trie nodes on the path from the root
+// of a trie to one of its leaves.
+type MerkleProof [][]byte
+
+func (p *MerkleProof) Serialize(w *[]byte) error {
+	if err := serialize.Serialize(w, uint32(len(*p))); err != nil {
+		return err
+	}
+	for _, node := range *p {
+		if err := serialize.SerializeWithTags(w, node, serialize.Tags{ByteSizeOfSliceLen: 4}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *MerkleProof) Deserialize(bb *serialize.ByteBuffer) error {
+	size, err := bb.GetUInt32()
+	if err != nil {
+		return err
+	}
+	proof := make(MerkleProof, 0, size)
+	for i := uint32(0); i < size; i++ {
+		node, err := bb.GetVarBytes(4)
+		if err != nil {
+			return err
+		}
+		proof = append(proof, node)
+	}
+	*p = proof
+	return nil
+}
+
+type GetPendingTransactionHashesRequest struct {
+	Branch uint32 `json:"branch" gencodec:"required"`
+}
+
+type GetPendingTransactionHashesResponse struct {
+	TxHashes []common.Hash `json:"tx_hashes" bytesizeofslicelen:"4"`
+}
+
+type GetProofRequest struct {
+	Address     *account.Address `json:"address" gencodec:"required"`
+	StorageKeys []common.Hash    `json:"storage_keys" bytesizeofslicelen:"4"`
+	BlockHeight *uint64          `json:"block_height" ser:"nil"`
+}
+
+// TokenBalanceProof is a token balance of an account, Proof is against the
+// token trie root and is empty if the balances are kept in the account itself.
+type TokenBalanceProof struct {
+	TokenID uint64      `json:"token_id" gencodec:"required"`
+	Balance *big.Int    `json:"balance" gencodec:"required"`
+	Proof   MerkleProof `json:"proof" gencodec:"required"`
+}
+
+// StorageProof is a storage slot of an account with its proof against the
+// storage root of the account.
+type StorageProof struct {
+	Key   common.Hash `json:"key" gencodec:"required"`
+	Value common.Hash `json:"value" gencodec:"required"`
+	Proof MerkleProof `json:"proof" gencodec:"required"`
+}
+
+// AccountProof is the state of an account with its proofs against the state
+// root of a minor block; AccountRLP is empty if the account does not exist.
+type AccountProof struct {
+	MinorBlockHash   common.Hash          `json:"minor_block_hash" gencodec:"required"`
+	MinorBlockHeight uint64               `json:"minor_block_height" gencodec:"required"`
+	StateRoot        common.Hash          `json:"state_root" gencodec:"required"`
+	AccountRLP       []byte               `json:"account_rlp" bytesizeofslicelen:"4"`
+	AccountProof     MerkleProof          `json:"account_proof" gencodec:"required"`
+	Nonce            uint64               `json:"nonce" gencodec:"required"`
+	CodeHash         common.Hash          `json:"code_hash" gencodec:"required"`
+	StorageRoot      common.Hash          `json:"storage_root" gencodec:"required"`
+	TokenTrieRoot    common.Hash          `json:"token_trie_root" gencodec:"required"`
+	Balances         []*TokenBalanceProof `json:"balances" bytesizeofslicelen:"4"`
+	StorageProofs    []*StorageProof      `json:"storage_proofs" bytesizeofslicelen:"4"`
+}
+
+// GetXShardDepositStatusRequest asks the destination shard of a cross-shard
+// transaction whether its deposit has been applied, MinorBlockHash is the
+// source minor block sending the deposit and RootBlockHash the root block
+// confirming it.
+type GetXShardDepositStatusRequest struct {
+	Branch         uint32      `json:"branch" gencodec:"required"`
+	TxHash         common.Hash `json:"tx_hash" gencodec:"required"`
+	MinorBlockHash common.Hash `json:"minor_block_hash" gencodec:"required"`
+	RootBlockHash  common.Hash `json:"root_block_hash" gencodec:"required"`
+}
+
+// XShardDepositStatus is the status of a deposit in its destination shard.
+// DepositCursor is the position of the deposit in the cross-shard transactions
+// of the shard, it is only known after the shard received the confirming root
+// block. MinorBlockHash and MinorBlockHeight are the minor block applying the
+// deposit if Applied.
+type XShardDepositStatus struct {
+	RootBlockReceived bool                     `json:"root_block_received" gencodec:"required"`
+	Applied           bool                     `json:"applied" gencodec:"required"`
+	DepositCursor     types.XShardTxCursorInfo `json:"deposit_cursor" gencodec:"required"`
+	TipCursor         types.XShardTxCursorInfo `json:"tip_cursor" gencodec:"required"`
+	MinorBlockHash    common.Hash              `json:"minor_block_hash" gencodec:"required"`
+	MinorBlockHeight  uint64                   `json:"minor_block_height" gencodec:"required"`
+}
+
+// GetFeeHistoryRequest asks for the gas prices paid in TokenID by the last
+// BlockCount blocks of a shard, Percentiles are in basis points.
+type GetFeeHistoryRequest struct {
+	Branch      uint32   `json:"branch" gencodec:"required"`
+	TokenID     uint64   `json:"token_id" gencodec:"required"`
+	BlockCount  uint64   `json:"block_count" gencodec:"required"`
+	Percentiles []uint32 `json:"percentiles" bytesizeofslicelen:"4"`
+}
+
+// FeeHistoryBlock holds the requested percentiles of the gas prices paid in
+// the token by the transactions of a block, both in the token and converted
+// to the default chain token.
+type FeeHistoryBlock struct {
+	Height                     uint64   `json:"height" gencodec:"required"`
+	GasUsed                    uint64   `json:"gas_used" gencodec:"required"`
+	GasLimit                   uint64   `json:"gas_limit" gencodec:"required"`
+	GasPrices                  []uint64 `json:"gas_prices" bytesizeofslicelen:"4"`
+	DefaultChainTokenGasPrices []uint64 `json:"default_chain_token_gas_prices" bytesizeofslicelen:"4"`
+}
+
+type FeeHistory struct {
+	Blocks []*FeeHistoryBlock `json:"blocks" bytesizeofslicelen:"4"`
+}
+
+// GetTxPoolContentRequest asks for the transaction pools of the shards of a
+// slave, or of Branch only if it is set. The transactions are only returned
+// unless CountOnly, and only the ones of Sender if it is set.
+type GetTxPoolContentRequest struct {
+	Branch    uint32             `json:"branch" gencodec:"required"`
+	Sender    *account.Recipient `json:"sender" ser:"nil"`
+	CountOnly bool               `json:"count_only" gencodec:"required"`
+}
+
+// TxPoolTx is a transaction in a pool, Seen is the unix time it entered the pool.
+type TxPoolTx struct {
+	Tx   *types.Transaction `json:"tx" gencodec:"required"`
+	Seen uint64             `json:"seen" gencodec:"required"`
+}
+
+// TxPoolAccount holds the transactions of a sender in a pool, Nonce is the
+// nonce of the sender in the state of the tip.
+type TxPoolAccount struct {
+	Address account.Recipient `json:"address" gencodec:"required"`
+	Nonce   uint64            `json:"nonce" gencodec:"required"`
+	Pending []*TxPoolTx       `json:"pending" bytesizeofslicelen:"4"`
+	Queued  []*TxPoolTx       `json:"queued" bytesizeofslicelen:"4"`
+}
+
+type ShardTxPool struct {
+	Branch   uint32           `json:"branch" gencodec:"required"`
+	Pending  uint32           `json:"pending" gencodec:"required"`
+	Queued   uint32           `json:"queued" gencodec:"required"`
+	Accounts []*TxPoolAccount `json:"accounts" bytesizeofslicelen:"4"`
+}
+
+type GetTxPoolContentResponse struct {
+	Shards []*ShardTxPool `json:"shards" bytesizeofslicelen:"4"`
+}
+
+// ExportShardRequest asks the slave running Branch to serve its database to
+// another slave while it keeps running it, with Stop to stop it for the last
+// copy, or with Cancel to end the export and run it again from RootTip if it
+// was stopped.
+type ExportShardRequest struct {
+	Branch  uint32           `json:"branch" gencodec:"required"`
+	RootTip *types.RootBlock `json:"root_tip" gencodec:"required"`
+	Stop    bool             `json:"stop" gencodec:"required"`
+	Cancel  bool             `json:"cancel" gencodec:"required"`
+}
+
+// ImportShardRequest asks a slave to copy a round of the database of Branch
+// from the slave at Source. With Final the shard is stopped on Source, the copy
+// is completed and the slave runs the shard from RootTip; with Cancel the copy
+// is dropped.
+type ImportShardRequest struct {
+	Branch  uint32           `json:"branch" gencodec:"required"`
+	Source  string           `json:"source" gencodec:"required"`
+	RootTip *types.RootBlock `json:"root_tip" gencodec:"required"`
+	Final   bool             `json:"final" gencodec:"required"`
+	Cancel  bool             `json:"cancel" gencodec:"required"`
+}
+
+// ImportShardResponse tells how many bytes of the database of a shard a round
+// copied, how many changed on the source are left for the next rounds, and the
+// size of the database.
+type ImportShardResponse struct {
+	Copied  uint64 `json:"copied" gencodec:"required"`
+	Pending uint64 `json:"pending" gencodec:"required"`
+	Total   uint64 `json:"total" gencodec:"required"`
+}
+
+// ShardMovedRequest tells the slaves Branch moved from the slave From to the
+// slave To, which listens at Target.
+type ShardMovedRequest struct {
+	Branch uint32 `json:"branch" gencodec:"required"`
+	From   string `json:"from" gencodec:"required"`
+	To     string `json:"to" gencodec:"required"`
+	Target string `json:"target" gencodec:"required"`
+}
+
+// GetShardDataRequest asks for the file names of the database of a shard
+// being exported when File is empty, or for a chunk of File from Offset.
+type GetShardDataRequest struct {
+	Branch uint32 `json:"branch" gencodec:"required"`
+	File   string `json:"file" gencodec:"required"`
+	Offset uint64 `json:"offset" gencodec:"required"`
+}
+
+type ShardDataFile struct {
+	Name string `json:"name" gencodec:"required"`
+	Size uint64 `json:"size" gencodec:"required"`
+}
+
+type GetShardDataResponse struct {
+	Files []*ShardDataFile `json:"files" bytesizeofslicelen:"4"`
+	Data  []byte           `json:"data" bytesizeofslicelen:"4"`
+}
+
+// SyncShardStateRequest asks a shard that added blocks without executing them
+// to download the state of the last one from a peer, and then to execute the
+// blocks it adds again.
+type SyncShardStateRequest struct {
+	Branch uint32 `json:"branch" gencodec:"required"`
+	PeerId string `json:"peer_id" gencodec:"required"`
+}
+
+// SyncShardStateResponse tells whether the state is downloaded, as a shard
+// stops after a while to keep the call within the rpc timeout, and the number
+// of state entries downloaded so far.
+type SyncShardStateResponse struct {
+	Done     bool   `json:"done" gencodec:"required"`
+	Progress uint64 `json:"progress" gencodec:"required"`
+}
+
+// DBInconsistency is the first data of a chain found inconsistent with the
+// rest of its database by the integrity check.
+type DBInconsistency struct {
+	Check  string      `json:"check" gencodec:"required"`
+	Height uint64      `json:"height" gencodec:"required"`
+	Hash   common.Hash `json:"hash" gencodec:"required"`
+	Error  string      `json:"error" gencodec:"required"`
+}
+
+// ShardDBCheck is the result of the database integrity check of a shard. The
+// blocks whose parent state is pruned, and cannot be regenerated, are counted
+// as unverified instead of checked, with the lowest and highest of them.
+type ShardDBCheck struct {
+	FullShardId      uint32           `json:"full_shard_id" gencodec:"required"`
+	CheckedBlocks    uint32           `json:"checked_blocks" gencodec:"required"`
+	RerunBlocks      uint32           `json:"rerun_blocks" gencodec:"required"`
+	UnverifiedBlocks uint32           `json:"unverified_blocks" gencodec:"required"`
+	UnverifiedFrom   uint64           `json:"unverified_from" gencodec:"required"`
+	UnverifiedTo     uint64           `json:"unverified_to" gencodec:"required"`
+	Inconsistency    *DBInconsistency `json:"inconsistency" ser:"nil"`
+}
+
+// AddUnverified counts a block at height as unverified.
+func (c *ShardDBCheck) AddUnverified(height uint64) {
+	c.AddUnverifiedRange(1, height, height)
+}
+
+// AddUnverifiedRange counts blocks between the heights from and to as
+// unverified.
+func (c *ShardDBCheck) AddUnverifiedRange(count uint32, from, to uint64) {
+	if count == 0 {
+		return
+	}
+	if c.UnverifiedBlocks == 0 || from < c.UnverifiedFrom {
+		c.UnverifiedFrom = from
+	}
+	if c.UnverifiedBlocks == 0 || to > c.UnverifiedTo {
+		c.UnverifiedTo = to
+	}
+	c.UnverifiedBlocks += count
+}
+
+// CheckMinorBlocksInRootResponse has the results of the database integrity
+// check of the shards of a slave for the minor blocks a root block confirms.
+type CheckMinorBlocksInRootResponse struct {
+	ShardList []*ShardDBCheck `json:"shard_list" gencodec:"required" bytesizeofslicelen:"4"`
+}
+
 type P2PRedirectRequest struct {
 	PeerID string `json:"peerid" gencodec:"required"`
 	Branch uint32
@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	qcom "github.com/QuarkChain/goquarkchain/common"
+	"github.com/QuarkChain/goquarkchain/rpc"
+)
+
+var (
+	inProcServers   = make(map[string]*InProcServer)
+	inProcServersMu sync.RWMutex
+)
+
+// InProcServer serves the master or a slave of a single process cluster to the
+// clients of the other ones, which call its ops directly instead of over gRPC.
+type InProcServer struct {
+	hostport string
+	services map[serverType]reflect.Value
+}
+
+// StartInProcServer serves apis to the clients of the process calling
+// hostport, as StartGRPCServer does over the network.
+func StartInProcServer(hostport string, apis []rpc.API) (*InProcServer, error) {
+	srv := &InProcServer{hostport: hostport, services: make(map[serverType]reflect.Value)}
+	for _, api := range apis {
+		if qcom.IsNil(api.Service) {
+			panic(fmt.Sprintf("%s service is nil", api.Namespace))
+		}
+		srvsplit := strings.Split(reflect.TypeOf(api.Service).String(), ".")
+		svrname := srvsplit[len(srvsplit)-1]
+		switch {
+		case strings.HasSuffix(_MasterServerSideOp_serviceDesc.ServiceName, svrname):
+			srv.services[MasterServer] = reflect.ValueOf(api.Service)
+		case strings.HasSuffix(_SlaveServerSideOp_serviceDesc.ServiceName, svrname):
+			srv.services[SlaveServer] = reflect.ValueOf(api.Service)
+		}
+	}
+
+	inProcServersMu.Lock()
+	defer inProcServersMu.Unlock()
+	if _, ok := inProcServers[hostport]; ok {
+		return nil, fmt.Errorf("in-process server %s already started", hostport)
+	}
+	inProcServers[hostport] = srv
+	return srv, nil
+}
+
+// Stop stops serving the clients of the process.
+func (s *InProcServer) Stop() {
+	inProcServersMu.Lock()
+	defer inProcServersMu.Unlock()
+	if inProcServers[s.hostport] == s {
+		delete(inProcServers, s.hostport)
+	}
+}
+
+// getInProcService returns the service of type tp served in the process at
+// hostport, if any.
+func getInProcService(hostport string, tp serverType) (reflect.Value, bool) {
+	inProcServersMu.RLock()
+	defer inProcServersMu.RUnlock()
+	srv, ok := inProcServers[hostport]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	service, ok := srv.services[tp]
+	return service, ok
+}
@@ -35,28 +35,35 @@ type ISlaveConn interface {
 	GetMinorBlocks(request *P2PRedirectRequest) ([]byte, error)
 	GetMinorBlockHeaderList(req *P2PRedirectRequest) ([]byte, error)
 	GetMinorBlockHeaderListWithSkip(req *P2PRedirectRequest) ([]byte, error)
+	GetMinorStateNodeList(req *P2PRedirectRequest) ([]byte, error)
+	GetCrossShardTxList(req *P2PRedirectRequest) ([]byte, error)
 	HandleNewTip(request *HandleNewTipRequest) error
 	HandleNewMinorBlock(request *P2PRedirectRequest) error
 	AddBlockListForSync(request *AddBlockListForSyncRequest) (*ShardStatus, error)
+	// SyncShardState downloads part of the state of a fast synced shard, and
+	// tells whether all of it is downloaded.
+	SyncShardState(request *SyncShardStateRequest) (*SyncShardStateResponse, error)
+	// PeerDisconnected tells the shards to forget what they know of the peer.
+	PeerDisconnected(peerID string) error
 	GetSlaveID() string
 	GetFullShardList() []uint32
 	MasterInfo(ip string, port uint16, rootTip *types.RootBlock) error
 	HasShard(fullShardID uint32) bool
 	SendPing() ([]byte, []uint32, error)
-	HeartBeat() bool
+	HeartBeat(activeShards []uint32) bool
 	GetUnconfirmedHeaders() (*GetUnconfirmedHeadersResponse, error)
 	GetAccountData(address *account.Address, height *uint64) (*GetAccountDataResponse, error)
 	AddRootBlock(rootBlock *types.RootBlock, expectSwitch bool) error
 	GenTx(numTxPerShard, xShardPercent uint32, tx *types.Transaction) error
 	SendMiningConfigToSlaves(artificialTxConfig *ArtificialTxConfig, mining bool) error
 	AddTransaction(tx *types.Transaction) error
-	ExecuteTransaction(tx *types.Transaction, fromAddress *account.Address, height *uint64) ([]byte, error)
+	ExecuteTransaction(tx *types.Transaction, fromAddress *account.Address, height *uint64, overrides []*StateOverride) ([]byte, error)
 	GetTransactionByHash(txHash common.Hash, branch account.Branch) (*types.MinorBlock, uint32, error)
 	GetTransactionReceipt(txHash common.Hash, branch account.Branch) (*types.MinorBlock, uint32, *types.Receipt, error)
 	GetTransactionsByAddress(address *account.Address, start []byte, limit uint32, transferTokenID *uint64) ([]*TransactionDetail, []byte, error)
 	GetAllTx(branch account.Branch, start []byte, limit uint32) ([]*TransactionDetail, []byte, error)
 	GetLogs(args *rpc.FilterQuery) ([]*types.Log, error)
-	EstimateGas(tx *types.Transaction, fromAddress *account.Address) (uint32, error)
+	EstimateGas(tx *types.Transaction, fromAddress *account.Address, overrides []*StateOverride) (uint32, error)
 	GetStorageAt(address *account.Address, key common.Hash, height *uint64) (common.Hash, error)
 	GetCode(address *account.Address, height *uint64) ([]byte, error)
 	GasPrice(branch account.Branch, tokenID uint64) (uint64, error)
@@ -64,5 +71,15 @@ type ISlaveConn interface {
 	SubmitWork(work *SubmitWorkRequest) (success bool, err error)
 	SetMining(mining bool) error
 	GetRootChainStakes(address account.Address, lastMinor common.Hash) (*big.Int, *account.Recipient, error)
-	CheckMinorBlocksInRoot(rootBlock *types.RootBlock) error
+	CheckMinorBlocksInRoot(rootBlock *types.RootBlock) ([]*ShardDBCheck, error)
+	TraceTransaction(txHash common.Hash, branch account.Branch, config *TraceConfig) ([]byte, error)
+	TraceMinorBlock(blockHash common.Hash, branch account.Branch, config *TraceConfig) ([]byte, error)
+	GetProof(address *account.Address, storageKeys []common.Hash, height *uint64) (*AccountProof, error)
+	GetPendingTransactionHashes(branch account.Branch) ([]common.Hash, error)
+	GetXShardDepositStatus(txHash common.Hash, branch account.Branch, sourceBlockHash, rootBlockHash common.Hash) (*XShardDepositStatus, error)
+	GetFeeHistory(branch account.Branch, tokenID, blockCount uint64, percentiles []uint32) (*FeeHistory, error)
+	GetTxPoolContent(branch account.Branch, sender *account.Recipient, countOnly bool) ([]*ShardTxPool, error)
+	ExportShard(req *ExportShardRequest) error
+	ImportShard(req *ImportShardRequest) (*ImportShardResponse, error)
+	ShardMoved(req *ShardMovedRequest) error
 }
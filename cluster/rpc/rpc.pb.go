@@ -136,44 +136,54 @@ func init() {
 func init() { proto.RegisterFile("rpc.proto", fileDescriptor_77a6da22d6a3feb1) }
 
 var fileDescriptor_77a6da22d6a3feb1 = []byte{
-	// 590 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x96, 0x5b, 0x4f, 0x13, 0x41,
-	0x14, 0xc7, 0x2d, 0x77, 0x8e, 0x05, 0xc3, 0x22, 0xd0, 0xe8, 0x83, 0x84, 0x44, 0x53, 0x51, 0xd1,
-	0x70, 0x27, 0xf1, 0xc1, 0x2d, 0xe0, 0x42, 0x02, 0x4a, 0x76, 0x6b, 0xf0, 0xcd, 0x0c, 0x33, 0x87,
-	0xee, 0xa4, 0xed, 0xcc, 0x3a, 0x73, 0x8a, 0xe5, 0x93, 0xfa, 0x4d, 0x7c, 0x36, 0xdb, 0x92, 0x96,
-	0x4d, 0x24, 0x33, 0x7d, 0xf5, 0xad, 0xcd, 0x9c, 0xdf, 0x9c, 0x33, 0xff, 0x73, 0x5b, 0x98, 0x35,
-	0x19, 0xdf, 0xc8, 0x8c, 0x26, 0x1d, 0x8c, 0x9b, 0x8c, 0xaf, 0x1d, 0xc1, 0x74, 0x8c, 0x3f, 0x3b,
-	0x68, 0x29, 0x98, 0x87, 0x31, 0x9d, 0x55, 0x4a, 0xab, 0xa5, 0xea, 0x5c, 0x3c, 0xa6, 0xb3, 0x60,
-	0x09, 0xa6, 0x4c, 0xc6, 0x7f, 0x48, 0x51, 0x19, 0x5b, 0x2d, 0x55, 0xc7, 0xe3, 0x49, 0x93, 0xf1,
-	0x53, 0x11, 0x04, 0x30, 0x21, 0x18, 0xb1, 0xca, 0xe4, 0x6a, 0xa9, 0x5a, 0x8e, 0x7b, 0xbf, 0xd7,
-	0x76, 0x60, 0x26, 0x46, 0x9b, 0x69, 0x65, 0x71, 0x70, 0x5e, 0x1a, 0x9e, 0x3f, 0x70, 0xd5, 0xe6,
-	0xef, 0x71, 0x08, 0xce, 0x99, 0x25, 0x34, 0x09, 0x9a, 0x1b, 0x34, 0x89, 0x14, 0xf8, 0x35, 0x0b,
-	0xb6, 0x61, 0x31, 0x14, 0xe2, 0x5c, 0x2a, 0x6d, 0x6a, 0x2d, 0xcd, 0x9b, 0x27, 0xc8, 0x04, 0x9a,
-	0xa0, 0xbc, 0x91, 0xc7, 0x7e, 0x17, 0xed, 0xb3, 0xb9, 0xbb, 0x7f, 0x7d, 0xaf, 0x6b, 0x8f, 0x82,
-	0x7d, 0x58, 0xf9, 0x07, 0x75, 0x26, 0x2d, 0xb9, 0xc8, 0x0f, 0xf0, 0xa4, 0x66, 0x34, 0x13, 0x9c,
-	0x59, 0xfa, 0x82, 0xbf, 0xea, 0x32, 0x73, 0x11, 0xbb, 0xb0, 0x34, 0x20, 0xea, 0x86, 0x29, 0xcb,
-	0x38, 0x49, 0xad, 0xac, 0x8b, 0xdb, 0x83, 0xe5, 0xfb, 0x9e, 0x86, 0xc1, 0xba, 0xc0, 0x4d, 0x58,
-	0x88, 0x90, 0x86, 0xf6, 0x3e, 0xcf, 0xda, 0x87, 0x95, 0x02, 0xe3, 0x2f, 0xc8, 0x27, 0x78, 0xf1,
-	0x00, 0x79, 0x29, 0x29, 0x4d, 0x9a, 0x4e, 0x81, 0x36, 0xff, 0x94, 0x61, 0x21, 0x69, 0xb1, 0x1b,
-	0x2c, 0x24, 0x76, 0x1d, 0x66, 0x53, 0x64, 0x86, 0x6a, 0xc8, 0x9c, 0x31, 0xbc, 0x01, 0xe8, 0x97,
-	0xc6, 0xa9, 0xba, 0xd6, 0x2e, 0xe3, 0x97, 0x30, 0x71, 0x21, 0x55, 0xc3, 0x65, 0xf6, 0x0a, 0x26,
-	0x23, 0x54, 0xf5, 0xae, 0xcb, 0xee, 0x1d, 0x94, 0x43, 0x21, 0x62, 0xad, 0xc9, 0x2b, 0x39, 0x07,
-	0x50, 0x89, 0x90, 0xbe, 0x29, 0xae, 0xd5, 0xb5, 0x34, 0x6d, 0x14, 0xfe, 0x4a, 0xbf, 0x87, 0xf9,
-	0x08, 0x29, 0xe4, 0x5c, 0x77, 0x14, 0x1d, 0xe5, 0xad, 0xe2, 0x06, 0x42, 0x21, 0xee, 0xd5, 0x9c,
-	0x0b, 0xd8, 0x80, 0xb9, 0x42, 0x2e, 0xfd, 0x22, 0x1a, 0xc1, 0xc1, 0x16, 0x04, 0xc7, 0x5d, 0xe4,
-	0x1d, 0xc2, 0x11, 0xa0, 0x5d, 0x58, 0x2a, 0x7a, 0x89, 0x91, 0xa3, 0xcc, 0x9c, 0x7a, 0x7d, 0x84,
-	0xe7, 0x45, 0x2e, 0x17, 0xb9, 0x76, 0x1b, 0x0a, 0x61, 0xd0, 0x3a, 0xdb, 0xef, 0x35, 0xcc, 0xe4,
-	0x6a, 0xb7, 0x5a, 0xee, 0x12, 0xa8, 0xc2, 0x74, 0x84, 0x74, 0xa6, 0x1b, 0xce, 0x4b, 0xdf, 0xc2,
-	0xe3, 0x63, 0x4b, 0xb2, 0xcd, 0x08, 0x23, 0x66, 0x3d, 0x4a, 0x2b, 0x42, 0x4a, 0x48, 0x1b, 0xd6,
-	0xc0, 0x90, 0xfc, 0xc2, 0x38, 0xd4, 0x02, 0x7d, 0xde, 0xc6, 0xec, 0x85, 0x91, 0x1c, 0xfd, 0x2e,
-	0xbd, 0xd4, 0xa6, 0xe9, 0xd1, 0x84, 0x49, 0xe7, 0xaa, 0x2d, 0xbd, 0x8c, 0xb7, 0x20, 0x88, 0x90,
-	0xf2, 0xae, 0x39, 0x4c, 0x99, 0x54, 0x09, 0xb1, 0x26, 0x5a, 0x8f, 0xd9, 0x1b, 0x0a, 0xf1, 0xdd,
-	0xa6, 0xcc, 0x88, 0x7a, 0xd7, 0xa7, 0x65, 0x76, 0xe0, 0x69, 0x8d, 0x11, 0x4f, 0x47, 0xc4, 0x0e,
-	0xa0, 0x52, 0x58, 0x0f, 0x39, 0xf3, 0x59, 0x9b, 0xe4, 0x56, 0x71, 0x17, 0xba, 0x0e, 0xb3, 0x49,
-	0xaf, 0x85, 0x3c, 0x46, 0xcc, 0x1e, 0x2c, 0x1f, 0xa6, 0xc8, 0x9b, 0x43, 0x47, 0xf6, 0x54, 0xe5,
-	0x9a, 0xfc, 0x67, 0x13, 0x3e, 0x2f, 0xe4, 0x13, 0xa6, 0x44, 0x0b, 0xfd, 0x36, 0x66, 0x3f, 0xcf,
-	0xa3, 0xec, 0xca, 0x6d, 0x58, 0x1c, 0x38, 0xf0, 0x1e, 0x5f, 0x57, 0x53, 0xbd, 0x6f, 0x9b, 0xad,
-	0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x1c, 0x14, 0x88, 0x73, 0xe8, 0x08, 0x00, 0x00,
+	// 743 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x97, 0xdd, 0x4e, 0x1b, 0x47,
+	0x14, 0xc7, 0x6b, 0xbe, 0x39, 0x35, 0x50, 0x96, 0x02, 0x56, 0x7b, 0x51, 0x84, 0xd4, 0xca, 0xa5,
+	0x85, 0x52, 0x3e, 0x02, 0x48, 0xb9, 0x88, 0x3f, 0x60, 0x6d, 0x09, 0x88, 0xe5, 0x75, 0x04, 0x77,
+	0xd1, 0x30, 0x73, 0xb0, 0x47, 0xb6, 0x67, 0x36, 0x33, 0xc7, 0xc4, 0x3c, 0x60, 0x5e, 0x26, 0x4f,
+	0x11, 0xed, 0x9a, 0x60, 0x5b, 0x0a, 0x9a, 0xf1, 0x4d, 0x2e, 0x72, 0x67, 0x6b, 0xff, 0xbf, 0x39,
+	0x67, 0xcf, 0xfc, 0xcf, 0x99, 0x59, 0x58, 0x34, 0x31, 0xdf, 0x8b, 0x8d, 0x26, 0x1d, 0x4c, 0x9b,
+	0x98, 0x6f, 0x97, 0x61, 0xbe, 0x8e, 0x1f, 0x7a, 0x68, 0x29, 0x58, 0x86, 0x29, 0x1d, 0xe7, 0x32,
+	0x5b, 0x99, 0xfc, 0x52, 0x7d, 0x4a, 0xc7, 0xc1, 0x3a, 0xcc, 0x99, 0x98, 0xbf, 0x97, 0x22, 0x37,
+	0xb5, 0x95, 0xc9, 0x4f, 0xd7, 0x67, 0x4d, 0xcc, 0xab, 0x22, 0x08, 0x60, 0x46, 0x30, 0x62, 0xb9,
+	0xd9, 0xad, 0x4c, 0x3e, 0x5b, 0x4f, 0x7f, 0x6f, 0x1f, 0xc3, 0x42, 0x1d, 0x6d, 0xac, 0x95, 0xc5,
+	0xe7, 0xe7, 0x99, 0xe1, 0xf3, 0x17, 0x96, 0x3a, 0xf8, 0x34, 0x03, 0xc1, 0x15, 0xb3, 0x84, 0x26,
+	0x42, 0xf3, 0x80, 0x26, 0x92, 0x02, 0xdf, 0xc6, 0xc1, 0x11, 0xac, 0x15, 0x84, 0xb8, 0x92, 0x4a,
+	0x9b, 0x62, 0x47, 0xf3, 0x76, 0x05, 0x99, 0x40, 0x13, 0x64, 0xf7, 0x92, 0xdc, 0x9f, 0xb2, 0xfd,
+	0x6d, 0xe9, 0xe9, 0xdf, 0x20, 0xea, 0xf6, 0x4f, 0xc1, 0x29, 0x6c, 0x7e, 0x83, 0xba, 0x94, 0x96,
+	0x5c, 0xe4, 0x3e, 0xac, 0x14, 0x8d, 0x66, 0x82, 0x33, 0x4b, 0xd7, 0xf8, 0xb1, 0x21, 0x63, 0x17,
+	0xf1, 0x0a, 0xd6, 0x9f, 0x89, 0x86, 0x61, 0xca, 0x32, 0x4e, 0x52, 0x2b, 0xeb, 0xe2, 0x4e, 0x60,
+	0x63, 0x34, 0xd2, 0x30, 0x59, 0x17, 0x78, 0x00, 0xab, 0x21, 0xd2, 0x50, 0xef, 0xf3, 0x5a, 0xa7,
+	0xb0, 0x39, 0xc6, 0xf8, 0x17, 0xe4, 0x0d, 0xfc, 0xf1, 0x02, 0x79, 0x23, 0xa9, 0x15, 0xb5, 0xbd,
+	0x0a, 0xf4, 0x75, 0x85, 0x88, 0x18, 0xe1, 0xb5, 0x16, 0xe8, 0x13, 0xf9, 0x08, 0xd6, 0x42, 0xa4,
+	0x92, 0xd1, 0xd6, 0x46, 0x2d, 0x66, 0x44, 0xa3, 0xef, 0x41, 0x1d, 0x7c, 0x0e, 0x60, 0x35, 0xea,
+	0xb0, 0x07, 0x1c, 0xb3, 0xd1, 0x0e, 0x2c, 0xb6, 0x90, 0x19, 0x2a, 0x22, 0x73, 0xc6, 0xfd, 0x07,
+	0x60, 0x60, 0xc4, 0xaa, 0xba, 0xd7, 0x2e, 0xf1, 0x9f, 0x30, 0x53, 0x93, 0xaa, 0xe9, 0x92, 0xfd,
+	0x05, 0xb3, 0x21, 0xaa, 0x46, 0xdf, 0xa5, 0xdb, 0x85, 0x6c, 0x41, 0x88, 0xba, 0xd6, 0xe4, 0x65,
+	0x85, 0x33, 0xc8, 0x85, 0x48, 0xef, 0x14, 0xd7, 0xea, 0x5e, 0x9a, 0x2e, 0x0a, 0xff, 0x7d, 0xfd,
+	0x0f, 0x96, 0x43, 0xa4, 0x02, 0xe7, 0xba, 0xa7, 0xa8, 0x9c, 0x34, 0xa6, 0x1b, 0x28, 0x08, 0x31,
+	0xe2, 0x70, 0x17, 0xb0, 0x07, 0x4b, 0x63, 0xce, 0xf1, 0xcb, 0x68, 0x82, 0x00, 0x87, 0x10, 0x9c,
+	0xf7, 0x91, 0xf7, 0x08, 0x27, 0x80, 0x06, 0x6e, 0x1c, 0x01, 0xea, 0xc8, 0x51, 0xc6, 0xce, 0x7a,
+	0xbd, 0x86, 0xdf, 0xc7, 0xb9, 0xa4, 0xc8, 0xc5, 0xc7, 0x82, 0x10, 0x06, 0xad, 0xb3, 0xd9, 0xff,
+	0x86, 0x85, 0xa4, 0xda, 0x9d, 0x8e, 0xdb, 0x02, 0x79, 0x98, 0x0f, 0x91, 0x2e, 0x75, 0xd3, 0xb9,
+	0xe8, 0xbf, 0xf0, 0xf3, 0xb9, 0x25, 0xd9, 0x65, 0x84, 0x21, 0xb3, 0x1e, 0xd6, 0x0a, 0x91, 0x22,
+	0xd2, 0x86, 0x35, 0xb1, 0x40, 0x7e, 0x69, 0x94, 0xb4, 0x40, 0x9f, 0x77, 0x63, 0xb6, 0x66, 0x24,
+	0x47, 0xbf, 0x45, 0x6f, 0xb4, 0x69, 0x7b, 0x34, 0x61, 0xd4, 0xbb, 0xeb, 0x4a, 0x2f, 0xf1, 0x21,
+	0x04, 0x21, 0x52, 0xd2, 0x35, 0xa5, 0x16, 0x93, 0x2a, 0x22, 0xd6, 0x46, 0x67, 0x3d, 0xfe, 0x87,
+	0x5f, 0x1a, 0x86, 0xf1, 0x49, 0xbc, 0xb3, 0x0f, 0x2b, 0x29, 0xe2, 0xef, 0xe9, 0xc1, 0xbe, 0xd7,
+	0x8c, 0xd6, 0xf7, 0x7e, 0x06, 0xab, 0xa1, 0x12, 0x52, 0x35, 0x47, 0x92, 0xaa, 0x30, 0xdb, 0x42,
+	0x9f, 0xd3, 0x24, 0x44, 0xba, 0x4d, 0x07, 0x65, 0x19, 0x63, 0x6d, 0x25, 0x25, 0xc3, 0xb6, 0x67,
+	0xfd, 0xba, 0xf4, 0x02, 0xb1, 0x22, 0x2d, 0x69, 0xf3, 0xe8, 0x51, 0xb6, 0xa4, 0x0f, 0xfa, 0x35,
+	0xad, 0x3b, 0x25, 0xad, 0x08, 0x15, 0xf9, 0xf8, 0xb4, 0x1f, 0x6b, 0x43, 0x69, 0x76, 0x1e, 0xea,
+	0x6a, 0xd7, 0x5b, 0x9d, 0xf8, 0x24, 0xd1, 0x5d, 0xe9, 0x07, 0x14, 0x1e, 0xfb, 0x57, 0x10, 0xe2,
+	0xd6, 0x7a, 0x9f, 0x26, 0xc1, 0x31, 0xfc, 0x5a, 0x64, 0xc4, 0x5b, 0x13, 0x62, 0x67, 0x90, 0x1b,
+	0xbb, 0x7f, 0x24, 0xcc, 0x85, 0x36, 0xd1, 0xa3, 0xe2, 0x2e, 0x74, 0x07, 0x16, 0xa3, 0x74, 0x6a,
+	0x7a, 0x9c, 0x2a, 0x27, 0xb0, 0x51, 0x6a, 0x21, 0x6f, 0x0f, 0x03, 0xd9, 0xaa, 0x4a, 0xda, 0xc0,
+	0x73, 0x16, 0xa4, 0x66, 0xf1, 0x1b, 0xfd, 0x49, 0xea, 0xa9, 0x3e, 0x3d, 0xc2, 0x3d, 0x4c, 0x52,
+	0x43, 0x34, 0x65, 0x69, 0xb9, 0x56, 0x0a, 0x39, 0xa1, 0xf8, 0xd1, 0x6e, 0x35, 0xbb, 0x90, 0xad,
+	0x30, 0x25, 0x3a, 0xe8, 0x77, 0x4b, 0x1c, 0x58, 0x6f, 0x92, 0xfb, 0xe1, 0x11, 0xac, 0x3d, 0x07,
+	0xf0, 0x1f, 0x38, 0xdf, 0xf5, 0xb2, 0x75, 0x37, 0x97, 0x7e, 0x3d, 0x1c, 0x7e, 0x19, 0x00, 0xc5,
+	0xe3, 0x62, 0xbe, 0x4a, 0x0c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -197,6 +207,8 @@ type MasterServerSideOpClient interface {
 	GetMinorBlockList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	GetMinorBlockHeaderList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	GetMinorBlockHeaderListWithSkip(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetMinorStateNodeList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetCrossShardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 }
 
 type masterServerSideOpClient struct {
@@ -279,6 +291,24 @@ func (c *masterServerSideOpClient) GetMinorBlockHeaderListWithSkip(ctx context.C
 	return out, nil
 }
 
+func (c *masterServerSideOpClient) GetMinorStateNodeList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.MasterServerSideOp/GetMinorStateNodeList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterServerSideOpClient) GetCrossShardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.MasterServerSideOp/GetCrossShardTxList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MasterServerSideOpServer is the server API for MasterServerSideOp service.
 type MasterServerSideOpServer interface {
 	AddMinorBlockHeader(context.Context, *Request) (*Response, error)
@@ -290,6 +320,8 @@ type MasterServerSideOpServer interface {
 	GetMinorBlockList(context.Context, *Request) (*Response, error)
 	GetMinorBlockHeaderList(context.Context, *Request) (*Response, error)
 	GetMinorBlockHeaderListWithSkip(context.Context, *Request) (*Response, error)
+	GetMinorStateNodeList(context.Context, *Request) (*Response, error)
+	GetCrossShardTxList(context.Context, *Request) (*Response, error)
 }
 
 // UnimplementedMasterServerSideOpServer can be embedded to have forward compatible implementations.
@@ -320,6 +352,12 @@ func (*UnimplementedMasterServerSideOpServer) GetMinorBlockHeaderList(ctx contex
 func (*UnimplementedMasterServerSideOpServer) GetMinorBlockHeaderListWithSkip(ctx context.Context, req *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMinorBlockHeaderListWithSkip not implemented")
 }
+func (*UnimplementedMasterServerSideOpServer) GetMinorStateNodeList(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMinorStateNodeList not implemented")
+}
+func (*UnimplementedMasterServerSideOpServer) GetCrossShardTxList(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCrossShardTxList not implemented")
+}
 
 func RegisterMasterServerSideOpServer(s *grpc.Server, srv MasterServerSideOpServer) {
 	s.RegisterService(&_MasterServerSideOp_serviceDesc, srv)
@@ -469,6 +507,42 @@ func _MasterServerSideOp_GetMinorBlockHeaderListWithSkip_Handler(srv interface{}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MasterServerSideOp_GetMinorStateNodeList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServerSideOpServer).GetMinorStateNodeList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.MasterServerSideOp/GetMinorStateNodeList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServerSideOpServer).GetMinorStateNodeList(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MasterServerSideOp_GetCrossShardTxList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServerSideOpServer).GetCrossShardTxList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.MasterServerSideOp/GetCrossShardTxList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServerSideOpServer).GetCrossShardTxList(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _MasterServerSideOp_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.MasterServerSideOp",
 	HandlerType: (*MasterServerSideOpServer)(nil),
@@ -505,6 +579,14 @@ var _MasterServerSideOp_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetMinorBlockHeaderListWithSkip",
 			Handler:    _MasterServerSideOp_GetMinorBlockHeaderListWithSkip_Handler,
 		},
+		{
+			MethodName: "GetMinorStateNodeList",
+			Handler:    _MasterServerSideOp_GetMinorStateNodeList_Handler,
+		},
+		{
+			MethodName: "GetCrossShardTxList",
+			Handler:    _MasterServerSideOp_GetCrossShardTxList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
@@ -537,12 +619,25 @@ type SlaveServerSideOpClient interface {
 	GetWork(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	SubmitWork(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	GetRootChainStakes(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	TraceTransaction(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	TraceMinorBlock(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetProof(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetPendingTransactionHashes(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetXShardDepositStatus(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetFeeHistory(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetTxPoolContent(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	ExportShard(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	ImportShard(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	ShardMoved(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	// APIs for neighbor slaves
 	AddXshardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	BatchAddXshardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	AddMinorBlockListForSync(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	SetMining(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	CheckMinorBlocksInRoot(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetShardData(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	SyncShardState(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	PeerDisconnected(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	// p2p apis
 	GetMinorBlockList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	GetMinorBlockHeaderList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
@@ -550,6 +645,8 @@ type SlaveServerSideOpClient interface {
 	HandleNewTip(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	AddTransactions(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	HandleNewMinorBlock(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetMinorStateNodeList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetCrossShardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 }
 
 type slaveServerSideOpClient struct {
@@ -758,6 +855,96 @@ func (c *slaveServerSideOpClient) GetRootChainStakes(ctx context.Context, in *Re
 	return out, nil
 }
 
+func (c *slaveServerSideOpClient) TraceTransaction(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/TraceTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) TraceMinorBlock(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/TraceMinorBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) GetProof(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetProof", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) GetPendingTransactionHashes(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetPendingTransactionHashes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) GetXShardDepositStatus(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetXShardDepositStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) GetFeeHistory(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetFeeHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) GetTxPoolContent(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetTxPoolContent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) ExportShard(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/ExportShard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) ImportShard(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/ImportShard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) ShardMoved(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/ShardMoved", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slaveServerSideOpClient) AddXshardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/AddXshardTxList", in, out, opts...)
@@ -803,6 +990,33 @@ func (c *slaveServerSideOpClient) CheckMinorBlocksInRoot(ctx context.Context, in
 	return out, nil
 }
 
+func (c *slaveServerSideOpClient) GetShardData(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetShardData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) SyncShardState(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/SyncShardState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) PeerDisconnected(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/PeerDisconnected", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *slaveServerSideOpClient) GetMinorBlockList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetMinorBlockList", in, out, opts...)
@@ -857,6 +1071,24 @@ func (c *slaveServerSideOpClient) HandleNewMinorBlock(ctx context.Context, in *R
 	return out, nil
 }
 
+func (c *slaveServerSideOpClient) GetMinorStateNodeList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetMinorStateNodeList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *slaveServerSideOpClient) GetCrossShardTxList(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/rpc.SlaveServerSideOp/GetCrossShardTxList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SlaveServerSideOpServer is the server API for SlaveServerSideOp service.
 type SlaveServerSideOpServer interface {
 	HeartBeat(context.Context, *Request) (*Response, error)
@@ -882,12 +1114,25 @@ type SlaveServerSideOpServer interface {
 	GetWork(context.Context, *Request) (*Response, error)
 	SubmitWork(context.Context, *Request) (*Response, error)
 	GetRootChainStakes(context.Context, *Request) (*Response, error)
+	TraceTransaction(context.Context, *Request) (*Response, error)
+	TraceMinorBlock(context.Context, *Request) (*Response, error)
+	GetProof(context.Context, *Request) (*Response, error)
+	GetPendingTransactionHashes(context.Context, *Request) (*Response, error)
+	GetXShardDepositStatus(context.Context, *Request) (*Response, error)
+	GetFeeHistory(context.Context, *Request) (*Response, error)
+	GetTxPoolContent(context.Context, *Request) (*Response, error)
+	ExportShard(context.Context, *Request) (*Response, error)
+	ImportShard(context.Context, *Request) (*Response, error)
+	ShardMoved(context.Context, *Request) (*Response, error)
 	// APIs for neighbor slaves
 	AddXshardTxList(context.Context, *Request) (*Response, error)
 	BatchAddXshardTxList(context.Context, *Request) (*Response, error)
 	AddMinorBlockListForSync(context.Context, *Request) (*Response, error)
 	SetMining(context.Context, *Request) (*Response, error)
 	CheckMinorBlocksInRoot(context.Context, *Request) (*Response, error)
+	GetShardData(context.Context, *Request) (*Response, error)
+	SyncShardState(context.Context, *Request) (*Response, error)
+	PeerDisconnected(context.Context, *Request) (*Response, error)
 	// p2p apis
 	GetMinorBlockList(context.Context, *Request) (*Response, error)
 	GetMinorBlockHeaderList(context.Context, *Request) (*Response, error)
@@ -895,6 +1140,8 @@ type SlaveServerSideOpServer interface {
 	HandleNewTip(context.Context, *Request) (*Response, error)
 	AddTransactions(context.Context, *Request) (*Response, error)
 	HandleNewMinorBlock(context.Context, *Request) (*Response, error)
+	GetMinorStateNodeList(context.Context, *Request) (*Response, error)
+	GetCrossShardTxList(context.Context, *Request) (*Response, error)
 }
 
 // UnimplementedSlaveServerSideOpServer can be embedded to have forward compatible implementations.
@@ -967,6 +1214,36 @@ func (*UnimplementedSlaveServerSideOpServer) SubmitWork(ctx context.Context, req
 func (*UnimplementedSlaveServerSideOpServer) GetRootChainStakes(ctx context.Context, req *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRootChainStakes not implemented")
 }
+func (*UnimplementedSlaveServerSideOpServer) TraceTransaction(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TraceTransaction not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) TraceMinorBlock(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TraceMinorBlock not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) GetProof(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProof not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) GetPendingTransactionHashes(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPendingTransactionHashes not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) GetXShardDepositStatus(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetXShardDepositStatus not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) GetFeeHistory(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFeeHistory not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) GetTxPoolContent(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTxPoolContent not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) ExportShard(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportShard not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) ImportShard(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportShard not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) ShardMoved(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShardMoved not implemented")
+}
 func (*UnimplementedSlaveServerSideOpServer) AddXshardTxList(ctx context.Context, req *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddXshardTxList not implemented")
 }
@@ -982,6 +1259,15 @@ func (*UnimplementedSlaveServerSideOpServer) SetMining(ctx context.Context, req
 func (*UnimplementedSlaveServerSideOpServer) CheckMinorBlocksInRoot(ctx context.Context, req *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckMinorBlocksInRoot not implemented")
 }
+func (*UnimplementedSlaveServerSideOpServer) GetShardData(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShardData not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) SyncShardState(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncShardState not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) PeerDisconnected(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PeerDisconnected not implemented")
+}
 func (*UnimplementedSlaveServerSideOpServer) GetMinorBlockList(ctx context.Context, req *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMinorBlockList not implemented")
 }
@@ -1000,6 +1286,12 @@ func (*UnimplementedSlaveServerSideOpServer) AddTransactions(ctx context.Context
 func (*UnimplementedSlaveServerSideOpServer) HandleNewMinorBlock(ctx context.Context, req *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandleNewMinorBlock not implemented")
 }
+func (*UnimplementedSlaveServerSideOpServer) GetMinorStateNodeList(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMinorStateNodeList not implemented")
+}
+func (*UnimplementedSlaveServerSideOpServer) GetCrossShardTxList(ctx context.Context, req *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCrossShardTxList not implemented")
+}
 
 func RegisterSlaveServerSideOpServer(s *grpc.Server, srv SlaveServerSideOpServer) {
 	s.RegisterService(&_SlaveServerSideOp_serviceDesc, srv)
@@ -1401,6 +1693,186 @@ func _SlaveServerSideOp_GetRootChainStakes_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlaveServerSideOp_TraceTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).TraceTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/TraceTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).TraceTransaction(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_TraceMinorBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).TraceMinorBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/TraceMinorBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).TraceMinorBlock(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_GetProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetProof",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetProof(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_GetPendingTransactionHashes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetPendingTransactionHashes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetPendingTransactionHashes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetPendingTransactionHashes(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_GetXShardDepositStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetXShardDepositStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetXShardDepositStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetXShardDepositStatus(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_GetFeeHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetFeeHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetFeeHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetFeeHistory(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_GetTxPoolContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetTxPoolContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetTxPoolContent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetTxPoolContent(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_ExportShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).ExportShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/ExportShard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).ExportShard(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_ImportShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).ImportShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/ImportShard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).ImportShard(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_ShardMoved_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).ShardMoved(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/ShardMoved",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).ShardMoved(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlaveServerSideOp_AddXshardTxList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Request)
 	if err := dec(in); err != nil {
@@ -1491,6 +1963,60 @@ func _SlaveServerSideOp_CheckMinorBlocksInRoot_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlaveServerSideOp_GetShardData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetShardData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetShardData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetShardData(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_SyncShardState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).SyncShardState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/SyncShardState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).SyncShardState(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_PeerDisconnected_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).PeerDisconnected(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/PeerDisconnected",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).PeerDisconnected(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SlaveServerSideOp_GetMinorBlockList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Request)
 	if err := dec(in); err != nil {
@@ -1599,6 +2125,42 @@ func _SlaveServerSideOp_HandleNewMinorBlock_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SlaveServerSideOp_GetMinorStateNodeList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetMinorStateNodeList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetMinorStateNodeList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetMinorStateNodeList(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SlaveServerSideOp_GetCrossShardTxList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SlaveServerSideOpServer).GetCrossShardTxList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.SlaveServerSideOp/GetCrossShardTxList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SlaveServerSideOpServer).GetCrossShardTxList(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _SlaveServerSideOp_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.SlaveServerSideOp",
 	HandlerType: (*SlaveServerSideOpServer)(nil),
@@ -1691,6 +2253,46 @@ var _SlaveServerSideOp_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetRootChainStakes",
 			Handler:    _SlaveServerSideOp_GetRootChainStakes_Handler,
 		},
+		{
+			MethodName: "TraceTransaction",
+			Handler:    _SlaveServerSideOp_TraceTransaction_Handler,
+		},
+		{
+			MethodName: "TraceMinorBlock",
+			Handler:    _SlaveServerSideOp_TraceMinorBlock_Handler,
+		},
+		{
+			MethodName: "GetProof",
+			Handler:    _SlaveServerSideOp_GetProof_Handler,
+		},
+		{
+			MethodName: "GetPendingTransactionHashes",
+			Handler:    _SlaveServerSideOp_GetPendingTransactionHashes_Handler,
+		},
+		{
+			MethodName: "GetXShardDepositStatus",
+			Handler:    _SlaveServerSideOp_GetXShardDepositStatus_Handler,
+		},
+		{
+			MethodName: "GetFeeHistory",
+			Handler:    _SlaveServerSideOp_GetFeeHistory_Handler,
+		},
+		{
+			MethodName: "GetTxPoolContent",
+			Handler:    _SlaveServerSideOp_GetTxPoolContent_Handler,
+		},
+		{
+			MethodName: "ExportShard",
+			Handler:    _SlaveServerSideOp_ExportShard_Handler,
+		},
+		{
+			MethodName: "ImportShard",
+			Handler:    _SlaveServerSideOp_ImportShard_Handler,
+		},
+		{
+			MethodName: "ShardMoved",
+			Handler:    _SlaveServerSideOp_ShardMoved_Handler,
+		},
 		{
 			MethodName: "AddXshardTxList",
 			Handler:    _SlaveServerSideOp_AddXshardTxList_Handler,
@@ -1711,6 +2313,18 @@ var _SlaveServerSideOp_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CheckMinorBlocksInRoot",
 			Handler:    _SlaveServerSideOp_CheckMinorBlocksInRoot_Handler,
 		},
+		{
+			MethodName: "GetShardData",
+			Handler:    _SlaveServerSideOp_GetShardData_Handler,
+		},
+		{
+			MethodName: "SyncShardState",
+			Handler:    _SlaveServerSideOp_SyncShardState_Handler,
+		},
+		{
+			MethodName: "PeerDisconnected",
+			Handler:    _SlaveServerSideOp_PeerDisconnected_Handler,
+		},
 		{
 			MethodName: "GetMinorBlockList",
 			Handler:    _SlaveServerSideOp_GetMinorBlockList_Handler,
@@ -1735,6 +2349,14 @@ var _SlaveServerSideOp_serviceDesc = grpc.ServiceDesc{
 			MethodName: "HandleNewMinorBlock",
 			Handler:    _SlaveServerSideOp_HandleNewMinorBlock_Handler,
 		},
+		{
+			MethodName: "GetMinorStateNodeList",
+			Handler:    _SlaveServerSideOp_GetMinorStateNodeList_Handler,
+		},
+		{
+			MethodName: "GetCrossShardTxList",
+			Handler:    _SlaveServerSideOp_GetCrossShardTxList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
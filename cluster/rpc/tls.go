@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	clusterTLS   *TLS
+	clusterTLSMu sync.RWMutex
+)
+
+// SetTLS sets the credentials used by the gRPC servers and clients of the
+// process, nil for plaintext links.
+func SetTLS(t *TLS) {
+	clusterTLSMu.Lock()
+	defer clusterTLSMu.Unlock()
+	clusterTLS = t
+}
+
+func getTLS() *TLS {
+	clusterTLSMu.RLock()
+	defer clusterTLSMu.RUnlock()
+	return clusterTLS
+}
+
+// TLS holds the credentials of the master or of a slave for its gRPC links and
+// the identities of the other processes of the cluster.
+type TLS struct {
+	certificate tls.Certificate
+	roots       *x509.CertPool
+	mutual      bool
+	identities  map[string]string   // identity of the slaves by gRPC host:port
+	allowed     map[string]struct{} // identities of the master and the slaves
+}
+
+// NewTLS loads the credentials of the process configured by own, it returns
+// nil when the links of the cluster are plaintext. Either the master and all
+// the slaves or none of them configure TLS.
+func NewTLS(own *config.GRPCTLSConfig, cluster *config.ClusterConfig) (*TLS, error) {
+	configs := []*config.GRPCTLSConfig{cluster.Master.TLS}
+	for _, slave := range cluster.SlaveList {
+		configs = append(configs, slave.TLS)
+	}
+	enabled := 0
+	for _, cfg := range configs {
+		if cfg != nil {
+			if cfg.Identity == "" {
+				return nil, errors.New("TLS identity is not set")
+			}
+			enabled++
+		}
+	}
+	if enabled != 0 && enabled != len(configs) {
+		return nil, errors.New("TLS should be configured on the master and all the slaves or none of them")
+	}
+	if own == nil {
+		return nil, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(own.CertFile, own.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+	pem, err := ioutil.ReadFile(own.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA bundle: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificate in TLS CA bundle")
+	}
+	t := &TLS{
+		certificate: certificate,
+		roots:       roots,
+		mutual:      own.MutualTLS,
+		identities:  make(map[string]string),
+		allowed:     make(map[string]struct{}),
+	}
+	for _, cfg := range configs {
+		t.allowed[cfg.Identity] = struct{}{}
+	}
+	for _, slave := range cluster.SlaveList {
+		t.identities[fmt.Sprintf("%s:%d", slave.IP, slave.Port)] = slave.TLS.Identity
+	}
+	return t, nil
+}
+
+// ServerOption returns the credentials of the gRPC server, which requires the
+// clients to present a certificate of the cluster with MutualTLS.
+func (t *TLS) ServerOption() grpc.ServerOption {
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{t.certificate},
+		ClientAuth:   tls.NoClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if t.mutual {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = t.roots
+		cfg.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			return verifyIdentity(chains[0][0], t.allowed)
+		}
+	}
+	return grpc.Creds(credentials.NewTLS(cfg))
+}
+
+// DialOption returns the credentials of the connections to the gRPC server at
+// hostport, which must present the certificate of the slave listening there,
+// or of any process of the cluster for other addresses such as the master's.
+func (t *TLS) DialOption(hostport string) grpc.DialOption {
+	allowed := t.allowed
+	if identity, ok := t.identities[hostport]; ok {
+		allowed = map[string]struct{}{identity: {}}
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{t.certificate},
+		MinVersion:   tls.VersionTLS12,
+		// the chain and identity are verified below as the expected identity
+		// does not always match the address dialed
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			certs := make([]*x509.Certificate, len(rawCerts))
+			for i, raw := range rawCerts {
+				cert, err := x509.ParseCertificate(raw)
+				if err != nil {
+					return err
+				}
+				certs[i] = cert
+			}
+			if len(certs) == 0 {
+				return errors.New("no server certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range certs[1:] {
+				intermediates.AddCert(cert)
+			}
+			if _, err := certs[0].Verify(x509.VerifyOptions{Roots: t.roots, Intermediates: intermediates}); err != nil {
+				return err
+			}
+			return verifyIdentity(certs[0], allowed)
+		},
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg))
+}
+
+// verifyIdentity checks cert is issued for one of the allowed identities.
+func verifyIdentity(cert *x509.Certificate, allowed map[string]struct{}) error {
+	if _, ok := allowed[cert.Subject.CommonName]; ok {
+		return nil
+	}
+	for _, name := range cert.DNSNames {
+		if _, ok := allowed[name]; ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected peer identity %q", cert.Subject.CommonName)
+}
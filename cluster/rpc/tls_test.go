@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/rpc"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T, dir, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	ca := &testCA{cert: cert, key: key, dir: dir}
+	ca.write(t, name+".ca.pem", "CERTIFICATE", der)
+	return ca
+}
+
+func (ca *testCA) write(t *testing.T, file, blockType string, der []byte) string {
+	path := filepath.Join(ca.dir, file)
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// issue returns the TLS config of a process holding a certificate for identity.
+func (ca *testCA) issue(t *testing.T, identity string) *config.GRPCTLSConfig {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: identity},
+		DNSNames:     []string{identity},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.GRPCTLSConfig{
+		CertFile:  ca.write(t, identity+".pem", "CERTIFICATE", der),
+		KeyFile:   ca.write(t, identity+".key", "EC PRIVATE KEY", keyDer),
+		CAFile:    filepath.Join(ca.dir, ca.cert.Subject.CommonName+".ca.pem"),
+		Identity:  identity,
+		MutualTLS: true,
+	}
+}
+
+func TestGRPCMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grpc-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetTLS(nil)
+
+	var (
+		ca      = newTestCA(t, dir, "cluster-ca")
+		rogueCA = newTestCA(t, dir, "rogue-ca")
+		slave   = testSlaveConfig(10)
+		cluster = config.NewClusterConfig()
+	)
+	cluster.Master.TLS = ca.issue(t, "master")
+	slave.TLS = ca.issue(t, "s0.cluster")
+	cluster.SlaveList = []*config.SlaveConfig{slave}
+	hostport := fmt.Sprintf("%s:%d", slave.IP, slave.Port)
+
+	load := func(own *config.GRPCTLSConfig) *TLS {
+		tlsCreds, err := NewTLS(own, cluster)
+		if err != nil {
+			t.Fatalf("failed to load TLS config: %v", err)
+		}
+		return tlsCreds
+	}
+	SetTLS(load(slave.TLS))
+	apis := []rpc.API{
+		{
+			Namespace: "rpc." + reflect.TypeOf(MasterServerSideOp{}).Name(),
+			Version:   "3.0",
+			Service:   NewMasterTestOp(),
+			Public:    false,
+		},
+	}
+	listener, handler, err := StartGRPCServer(hostport, apis)
+	if err != nil {
+		t.Fatalf("failed to create grpc server %v", err)
+	}
+	defer handler.Stop()
+	defer listener.Close()
+
+	call := func(clientTLS *TLS) error {
+		SetTLS(clientTLS)
+		cli := NewClient(MasterServer)
+		defer cli.Close()
+		_, err := cli.Call(hostport, &Request{Op: OpAddMinorBlockHeader, Data: []byte("op request")})
+		return err
+	}
+	if err := call(load(cluster.Master.TLS)); err != nil {
+		t.Fatalf("master rejected by the slave: %v", err)
+	}
+	if err := call(nil); err == nil {
+		t.Error("plaintext client accepted")
+	}
+	if err := call(load(rogueCA.issue(t, "master"))); err == nil {
+		t.Error("client of another CA accepted")
+	}
+	if err := call(load(ca.issue(t, "intruder"))); err == nil {
+		t.Error("client with an unknown identity accepted")
+	}
+
+	// the master expects the identity of the slave at its address
+	slave.TLS.Identity = "s1.cluster"
+	if err := call(load(cluster.Master.TLS)); err == nil {
+		t.Error("slave with an unexpected identity accepted")
+	}
+	slave.TLS.Identity = "s0.cluster"
+
+	cluster.Master.TLS = nil
+	if _, err := NewTLS(slave.TLS, cluster); err == nil {
+		t.Error("partial TLS config accepted")
+	}
+}
@@ -64,6 +64,16 @@ func (m *MasterServerSideOp) GetMinorBlockHeaderList(ctx context.Context, req *R
 		RpcId: req.RpcId,
 	}, nil
 }
+func (m *MasterServerSideOp) GetMinorStateNodeList(ctx context.Context, req *Request) (*Response, error) {
+	return &Response{
+		RpcId: req.RpcId,
+	}, nil
+}
+func (m *MasterServerSideOp) GetCrossShardTxList(ctx context.Context, req *Request) (*Response, error) {
+	return &Response{
+		RpcId: req.RpcId,
+	}, nil
+}
 
 func (m *MasterServerSideOp) MinorHead(ctx context.Context, req *Request) (*Response, error) {
 	return &Response{
@@ -12,6 +12,7 @@ import (
 
 	"github.com/QuarkChain/goquarkchain/p2p"
 	"github.com/QuarkChain/goquarkchain/rpc"
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
@@ -68,6 +69,14 @@ type Config struct {
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
 
+	// RateLimit configures the limits of the calls served by the public HTTP and
+	// websocket RPC interfaces, nil leaves them unlimited.
+	RateLimit *rpcconfig.RateLimitConfig `toml:",omitempty"`
+
+	// HTTPPrivAuth configures the credentials required by the private HTTP RPC
+	// interface, nil leaves it open to anyone able to reach it.
+	HTTPPrivAuth *rpcconfig.AuthConfig `toml:",omitempty"`
+
 	// WSOrigins is the list of domain to accept websocket requests from. Please be
 	// aware that the server can only act upon the HTTP request the client sends and
 	// cannot verify the validity of the request header.
@@ -80,12 +89,23 @@ type Config struct {
 
 	WSEndpoint string
 
+	// MetricsEndpoint is the address serving the metrics in the Prometheus text
+	// format on /metrics, empty disables it.
+	MetricsEndpoint string `toml:",omitempty"`
+
+	// HealthEndpoint is the address serving the liveness probe on /healthz and
+	// the readiness probe on /readyz, empty disables them.
+	HealthEndpoint string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
 	GRPCModules []string `toml:",omitempty"`
 	// grpc service endpoint
 	GRPCEndpoint string
+	// GRPCInProcess serves the grpc services at GRPCEndpoint to the master and
+	// slaves running in the same process only, without listening on it.
+	GRPCInProcess bool `toml:",omitempty"`
 
 	staticNodesWarning     bool
 	trustedNodesWarning    bool
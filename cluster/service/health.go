@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HealthChecker is implemented by the services able to tell whether they are
+// ready to serve, which is reported by the readiness probe of the node.
+type HealthChecker interface {
+	// Unready returns the reasons the service is not ready, none if it is.
+	Unready() []string
+}
+
+// NewHealthHandler returns the handler of the liveness probe on /healthz and
+// of the readiness probe on /readyz. The readiness probe fails with the reasons
+// of the checkers, one per line.
+func NewHealthHandler(checkers []HealthChecker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		reasons := make([]string, 0)
+		for _, checker := range checkers {
+			reasons = append(reasons, checker.Unready()...)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		if len(reasons) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(strings.Join(reasons, "\n") + "\n"))
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	return mux
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testChecker []string
+
+func (c testChecker) Unready() []string {
+	return c
+}
+
+func TestHealthHandler(t *testing.T) {
+	tests := []struct {
+		path     string
+		checkers []HealthChecker
+		status   int
+		body     string
+	}{
+		{"/healthz", []HealthChecker{testChecker{"root chain is syncing"}}, http.StatusOK, "ok\n"},
+		{"/readyz", []HealthChecker{testChecker{}, testChecker(nil)}, http.StatusOK, "ok\n"},
+		{"/readyz", []HealthChecker{testChecker{"root chain is syncing"}, testChecker{"missed heartbeat of slave S0"}},
+			http.StatusServiceUnavailable, "root chain is syncing\nmissed heartbeat of slave S0\n"},
+	}
+	for i, tt := range tests {
+		server := httptest.NewServer(NewHealthHandler(tt.checkers))
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		server.Close()
+		if resp.StatusCode != tt.status || string(body) != tt.body {
+			t.Errorf("test %d: %s returned %d %q, want %d %q", i, tt.path, resp.StatusCode, body, tt.status, tt.body)
+		}
+	}
+}
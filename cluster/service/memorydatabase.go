@@ -24,6 +24,10 @@ func (db *QkcMemoryDB) Put(key []byte, value []byte) error {
 	return db.MemDatabase.Put(key, value)
 }
 
+func (db *QkcMemoryDB) IsReadOnly() bool {
+	return db.isReadOnly
+}
+
 func (db *QkcMemoryDB) SetIsReadOnly(isReadOnly bool) {
 	db.isReadOnly = isReadOnly
 }
@@ -4,13 +4,16 @@ package service
 import (
 	"fmt"
 	qkcrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/internal/prometheus"
 	"github.com/QuarkChain/goquarkchain/p2p"
 	"github.com/QuarkChain/goquarkchain/rpc"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/prometheus/prometheus/util/flock"
 	"google.golang.org/grpc"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -37,6 +40,8 @@ type Node struct {
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
 	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
 
+	rateLimiter *rpc.RateLimiter // Limits of the calls to the public HTTP and websocket endpoints
+
 	httpWhitelist []string     // public HTTP RPC modules to allow through this endpoint
 	httpListener  net.Listener // public HTTP RPC listener socket to server API requests
 	httpHandler   *rpc.Server  // public HTTP RPC request handler to process the API requests
@@ -44,13 +49,17 @@ type Node struct {
 	httpPrivListener net.Listener // private HTTP RPC listener socket to server API requests
 	httpPrivHandler  *rpc.Server  // private HTTP RPC request handler to process the API requests
 
-	isMaster     bool         // node module, true master type full functions start, false slave type just start part functions.
-	grpcListener net.Listener // GRPC listener socket to server API requests
-	grpcHandler  *grpc.Server // GRPC request handler to process the API requests
+	isMaster     bool                 // node module, true master type full functions start, false slave type just start part functions.
+	grpcListener net.Listener         // GRPC listener socket to server API requests
+	grpcHandler  *grpc.Server         // GRPC request handler to process the API requests
+	grpcInProc   *qkcrpc.InProcServer // GRPC services served to the same process only
 
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
 
+	metricsListener net.Listener // Prometheus metrics listener socket
+	healthListener  net.Listener // Liveness and readiness probes listener socket
+
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
@@ -224,6 +233,7 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 	for _, srv := range services {
 		apis = append(apis, srv.APIs()...)
 	}
+	n.rateLimiter = rpc.NewRateLimiter(n.config.RateLimit)
 	if err := n.startGRPC(apis, n.config.GRPCModules); err != nil {
 		n.stopRPC()
 		return err
@@ -242,12 +252,19 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 			n.stopRPC()
 			return err
 		}
-	} else {
-		// start ws service
-		if err := n.startWS(apis, n.config.WSModules, n.config.WSOrigins); err != nil {
-			n.stopWS()
-			return err
-		}
+	}
+	// start ws service
+	if err := n.startWS(apis, n.config.WSModules, n.config.WSOrigins); err != nil {
+		n.stopRPC()
+		return err
+	}
+	if err := n.startMetrics(); err != nil {
+		n.stopRPC()
+		return err
+	}
+	if err := n.startHealth(services); err != nil {
+		n.stopRPC()
+		return err
 	}
 	// All API endpoints started successfully
 	n.rpcAPIs = apis
@@ -257,6 +274,8 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 func (n *Node) stopRPC() {
 	n.stopGRPC()
 	n.stopWS()
+	n.stopMetrics()
+	n.stopHealth()
 	if n.IsMaster() {
 		n.stopIPC()
 		n.stopHTTP()
@@ -311,6 +330,15 @@ func (n *Node) startGRPC(apis []rpc.API, modules []string) error {
 	}
 
 	apis = n.apiFilter(apis, false, modules)
+	if n.config.GRPCInProcess {
+		srv, err := qkcrpc.StartInProcServer(n.config.GRPCEndpoint, apis)
+		if err != nil {
+			return err
+		}
+		n.grpcInProc = srv
+		n.log.Info("grpc in-process endpoint opened", "url", n.config.GRPCEndpoint)
+		return nil
+	}
 	listener, handler, err := qkcrpc.StartGRPCServer(n.config.GRPCEndpoint, apis)
 	if err != nil {
 		return err
@@ -322,6 +350,12 @@ func (n *Node) startGRPC(apis []rpc.API, modules []string) error {
 }
 
 func (n *Node) stopGRPC() {
+	if n.grpcInProc != nil {
+		n.grpcInProc.Stop()
+		n.grpcInProc = nil
+
+		n.log.Info("grpc in-process endpoint closed", "url", n.config.GRPCEndpoint)
+	}
 	if n.grpcListener != nil {
 		n.grpcListener.Close()
 		n.grpcListener = nil
@@ -334,6 +368,60 @@ func (n *Node) stopGRPC() {
 	}
 }
 
+// startMetrics starts serving the metrics registry to Prometheus.
+func (n *Node) startMetrics() error {
+	if n.config.MetricsEndpoint == "" {
+		return nil
+	}
+	listener, err := prometheus.StartEndpoint(n.config.MetricsEndpoint, metrics.DefaultRegistry)
+	if err != nil {
+		return err
+	}
+	n.metricsListener = listener
+	n.log.Info("Metrics endpoint opened", "url", fmt.Sprintf("http://%s/metrics", n.config.MetricsEndpoint))
+	return nil
+}
+
+func (n *Node) stopMetrics() {
+	if n.metricsListener != nil {
+		n.metricsListener.Close()
+		n.metricsListener = nil
+
+		n.log.Info("Metrics endpoint closed", "url", fmt.Sprintf("http://%s/metrics", n.config.MetricsEndpoint))
+	}
+}
+
+// startHealth starts serving the probes of the services implementing
+// HealthChecker.
+func (n *Node) startHealth(services map[reflect.Type]Service) error {
+	if n.config.HealthEndpoint == "" {
+		return nil
+	}
+	checkers := make([]HealthChecker, 0)
+	for _, service := range services {
+		if checker, ok := service.(HealthChecker); ok {
+			checkers = append(checkers, checker)
+		}
+	}
+	listener, err := net.Listen("tcp", n.config.HealthEndpoint)
+	if err != nil {
+		return err
+	}
+	go http.Serve(listener, NewHealthHandler(checkers))
+	n.healthListener = listener
+	n.log.Info("Health endpoint opened", "url", "http://"+n.config.HealthEndpoint)
+	return nil
+}
+
+func (n *Node) stopHealth() {
+	if n.healthListener != nil {
+		n.healthListener.Close()
+		n.healthListener = nil
+
+		n.log.Info("Health endpoint closed", "url", "http://"+n.config.HealthEndpoint)
+	}
+}
+
 // startWS initializes and starts the websocket RPC endpoint.
 func (n *Node) startWS(apis []rpc.API, modules []string, wsOrigins []string) error {
 	// Short circuit if the WS endpoint isn't being exposed
@@ -341,7 +429,7 @@ func (n *Node) startWS(apis []rpc.API, modules []string, wsOrigins []string) err
 		return nil
 	}
 	publicApis := n.apiFilter(apis, true, modules)
-	listener, handler, err := rpc.StartWSEndpoint(n.config.WSEndpoint, publicApis, modules, wsOrigins, false)
+	listener, handler, err := rpc.StartWSEndpoint(n.config.WSEndpoint, publicApis, modules, wsOrigins, false, n.rateLimiter)
 	if err != nil {
 		return err
 	}
@@ -377,7 +465,7 @@ func (n *Node) startHTTP(apis []rpc.API, modules []string, timeouts rpc.HTTPTime
 		publicApis = n.apiFilter(apis, true, modules)
 		eptParams  []string
 	)
-	listener, handler, err := rpc.StartHTTPEndpoint(n.config.HTTPEndpoint, publicApis, modules, eptParams, eptParams, timeouts)
+	listener, handler, err := rpc.StartHTTPEndpoint(n.config.HTTPEndpoint, rpc.EndpointHTTP, publicApis, modules, eptParams, eptParams, timeouts, n.rateLimiter, nil)
 	if err != nil {
 		return err
 	}
@@ -411,8 +499,15 @@ func (n *Node) startPrivHTTP(apis []rpc.API, modules []string, timeouts rpc.HTTP
 		privateApis = n.apiFilter(apis, false, modules)
 		eptParams   []string
 	)
+	auth, err := rpc.NewAuthenticator(n.config.HTTPPrivAuth)
+	if err != nil {
+		return err
+	}
+	if auth == nil && !isLoopback(n.config.HTTPPrivEndpoint) {
+		n.log.Warn("private HTTP endpoint is reachable from other hosts without credentials", "url", fmt.Sprintf("http://%s", n.config.HTTPPrivEndpoint))
+	}
 
-	listener, handler, err := rpc.StartHTTPEndpoint(n.config.HTTPPrivEndpoint, privateApis, modules, eptParams, eptParams, timeouts)
+	listener, handler, err := rpc.StartHTTPEndpoint(n.config.HTTPPrivEndpoint, rpc.EndpointPrivHTTP, privateApis, modules, eptParams, eptParams, timeouts, nil, auth)
 	if err != nil {
 		return err
 	}
@@ -422,6 +517,19 @@ func (n *Node) startPrivHTTP(apis []rpc.API, modules []string, timeouts rpc.HTTP
 	return nil
 }
 
+// isLoopback reports whether the host of endpoint only accepts local connections.
+func isLoopback(endpoint string) bool {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func (n *Node) stopPrivHTTP() {
 	if n.httpPrivListener != nil {
 		n.httpPrivListener.Close()
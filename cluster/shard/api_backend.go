@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/monitoring"
 	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	qsync "github.com/QuarkChain/goquarkchain/cluster/sync"
 	qcom "github.com/QuarkChain/goquarkchain/common"
@@ -168,10 +169,19 @@ func (s *ShardBackend) GetHeaderByNumber(height qrpc.BlockNumber) (*types.MinorB
 func (s *ShardBackend) HandleNewTip(rBHeader *types.RootBlockHeader, mBHeader *types.MinorBlockHeader, peerID string) error {
 	s.wg.Add(1)
 	defer s.wg.Done()
+	if s.MinorBlockChain.IsFastSyncing() {
+		// the master adds the blocks confirmed by the root chain until the state is downloaded
+		return nil
+	}
 	if s.MinorBlockChain.GetRootBlockByHash(mBHeader.PrevRootBlockHash) == nil {
 		log.Debug(s.logInfo, "preRootBlockHash do not have height ,no need to add task", mBHeader.Number, "preRootHash", mBHeader.PrevRootBlockHash.String())
 		return nil
 	}
+	if err := s.MinorBlockChain.Validator().ValidateSeal(mBHeader, false); err != nil {
+		log.Warn(s.logInfo+" HandleNewTip ValidateSeal", "peer", peerID, "err", err)
+		return err
+	}
+	s.updatePeerTip(peerID, mBHeader.Number)
 	if s.MinorBlockChain.CurrentBlock().Number() >= mBHeader.Number {
 		log.Debug(s.logInfo, "no need t sync curr height", s.MinorBlockChain.CurrentBlock().Number(), "tipHeight", mBHeader.Number)
 		return nil
@@ -179,6 +189,7 @@ func (s *ShardBackend) HandleNewTip(rBHeader *types.RootBlockHeader, mBHeader *t
 	if s.mBPool.getBlockInPool(mBHeader.Hash()) {
 		return nil
 	}
+	monitoring.Default().BlockSeen(mBHeader.Hash(), peerID)
 	peer := &peer{cm: s.conn, peerID: peerID}
 	err := s.synchronizer.AddTask(qsync.NewMinorChainTask(peer, mBHeader))
 	if err != nil {
@@ -219,6 +230,12 @@ func (s *ShardBackend) NewMinorBlock(peerId string, block *types.MinorBlock) (er
 	if s.mBPool.getBlockInPool(mHash) {
 		return
 	}
+	if peerId != "" {
+		monitoring.Default().BlockSeen(mHash, peerId)
+	}
+	if s.MinorBlockChain.IsFastSyncing() {
+		return
+	}
 	if s.MinorBlockChain.HasBlock(block.Hash()) {
 		log.Debug("add minor block, Known minor block", "branch", block.Branch(), "height", block.Number())
 		return
@@ -252,6 +269,7 @@ func (s *ShardBackend) NewMinorBlock(peerId string, block *types.MinorBlock) (er
 		log.Warn(s.logInfo+" ValidateBlock", "err", err)
 		return nil // next time to handle
 	}
+	s.updatePeerTip(peerId, block.NumberU64())
 
 	s.mBPool.setBlockInPool(block.Hash())
 	go func() {
@@ -293,6 +311,7 @@ func (s *ShardBackend) AddMinorBlock(block *types.MinorBlock) error {
 	// only remove from pool if the block successfully added to state,
 	// this may cache failed blocks but prevents them being broadcasted more than needed
 	s.mBPool.delBlockInPool(block.Hash())
+	monitoring.Default().BlockImported(monitoring.ShardChain(s.branch.Value), block.Header())
 
 	if xshardLst[0] == nil {
 		log.Info(s.logInfo+" add minor block has been added...", "branch", s.branch.Value, "height", block.Number())
@@ -377,6 +396,7 @@ func (s *ShardBackend) AddBlockListForSync(blockLst []*types.MinorBlock) error {
 			return nil
 		}
 		s.mBPool.delBlockInPool(block.Hash())
+		monitoring.Default().BlockImported(monitoring.ShardChain(s.branch.Value), block.Header())
 		prevRootHeight := s.MinorBlockChain.GetRootBlockByHash(block.PrevRootBlockHash())
 		blockHashToXShardList[blockHash] = &XshardListTuple{XshardTxList: xshardLst[0], PrevRootHeight: prevRootHeight.Number()}
 		uncommittedBlockHeaderList = append(uncommittedBlockHeaderList, block.Header())
@@ -492,6 +512,9 @@ func (s *ShardBackend) GetDefaultCoinbaseAddress() account.Address {
 
 // miner api
 func (s *ShardBackend) CreateBlockToMine(addr *account.Address) (types.IBlock, *big.Int, uint64, error) {
+	if s.MinorBlockChain.IsFastSyncing() {
+		return nil, nil, 0, errors.New("shard is fast syncing")
+	}
 	coinbaseAddress := s.Config.CoinbaseAddress
 	if addr != nil {
 		coinbaseAddress = *addr
@@ -519,16 +542,44 @@ func (s *ShardBackend) CreateBlockToMine(addr *account.Address) (types.IBlock, *
 	return minorBlock, diff, 1, nil
 }
 
-func (s *ShardBackend) CheckMinorBlock(header *types.MinorBlockHeader) error {
-	block := s.MinorBlockChain.GetBlock(header.Hash())
-	if qcom.IsNil(block) {
-		return fmt.Errorf("block %v cannot be found", header.Hash())
+// CheckMinorBlocksInRoot checks the database of the shard for the minor blocks
+// of the shard a root block confirms, and for the deposits made to the shard by
+// the ones of its neighbors. The check stops at the first inconsistency found,
+// which is returned in the result. The blocks whose state cannot be verified as
+// the state of their parent is pruned are counted apart.
+func (s *ShardBackend) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) (*rpc.ShardDBCheck, error) {
+	result := &rpc.ShardDBCheck{FullShardId: s.branch.Value}
+	var err error
+	for _, header := range rootBlock.MinorBlockHeaders() {
+		if header.Branch != s.branch {
+			continue
+		}
+		var rerun bool
+		rerun, err = s.MinorBlockChain.CheckBlockDB(header)
+		if err == core.ErrDBCheckStatePruned {
+			result.AddUnverified(header.Number)
+			err = nil
+			continue
+		}
+		if err != nil {
+			break
+		}
+		result.CheckedBlocks++
+		if rerun {
+			result.RerunBlocks++
+		}
 	}
-	if header.Number == 0 {
-		return nil
+	if err == nil {
+		err = s.MinorBlockChain.CheckCrossShardTxListsDB(rootBlock)
+	}
+	if checkErr, ok := err.(*core.DBCheckError); ok {
+		log.Error(s.logInfo+" Database inconsistency", "check", checkErr.Check, "number", checkErr.Number,
+			"hash", checkErr.Hash, "err", checkErr.Err)
+		result.Inconsistency = &rpc.DBInconsistency{Check: checkErr.Check, Height: checkErr.Number,
+			Hash: checkErr.Hash, Error: checkErr.Err.Error()}
+		return result, nil
 	}
-	_, _, err := s.MinorBlockChain.InsertChainForDeposits([]types.IBlock{block}, true)
-	return err
+	return result, err
 }
 
 func (s *ShardBackend) GetRootChainStakes(address account.Address, lastMinor common.Hash) (*big.Int,
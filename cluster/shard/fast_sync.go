@@ -0,0 +1,244 @@
+package shard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/monitoring"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/p2p"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// StateNodeBatchSize is the number of state nodes requested from a peer at once.
+	StateNodeBatchSize = 384
+	// CrossShardTxListBatchSize is the number of cross-shard tx lists requested
+	// from a peer at once.
+	CrossShardTxListBatchSize = 100
+
+	stateNodeResponseSize = 2 * 1024 * 1024
+	stateSyncPeriod       = 2 * time.Minute
+)
+
+// AddBlockListForFastSync adds the blocks confirmed by a root block without
+// executing them, and sends their headers to the master. The cross-shard tx
+// lists of the blocks are unknown until they are executed, so the neighbor
+// shards download them from the peers when they finish their own fast sync.
+func (s *ShardBackend) AddBlockListForFastSync(blockLst []*types.MinorBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	headerList := make([]*types.MinorBlockHeader, 0, len(blockLst))
+	for _, block := range blockLst {
+		if block.Branch().Value != s.branch.Value {
+			continue
+		}
+		if err := s.MinorBlockChain.AddBlockWithoutState(block); err != nil {
+			log.Error(s.logInfo+" Failed to add minor block without state", "number", block.NumberU64(), "err", err)
+			return err
+		}
+		s.mBPool.delBlockInPool(block.Hash())
+		monitoring.Default().BlockImported(monitoring.ShardChain(s.branch.Value), block.Header())
+		headerList = append(headerList, block.Header())
+	}
+	if len(headerList) == 0 {
+		return nil
+	}
+	req := &rpc.AddMinorBlockHeaderListRequest{
+		MinorBlockHeaderList: headerList,
+	}
+	return s.conn.SendMinorBlockHeaderListToMaster(req)
+}
+
+// SyncState downloads the state of the fast sync head from the peer, and then
+// the cross-shard tx lists the head has still to process. It gives up after a
+// while to let the caller check the peer, and returns true once the shard has
+// them all and executes the blocks it adds again.
+func (s *ShardBackend) SyncState(peerID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	if !s.MinorBlockChain.IsFastSyncing() {
+		return true, nil
+	}
+	if s.stateSync == nil {
+		sched, err := s.MinorBlockChain.NewFastSyncState()
+		if err != nil {
+			return false, err
+		}
+		s.stateSync, s.stateSyncQueue = sched, nil
+	}
+	deadline := time.Now().Add(stateSyncPeriod)
+	for s.stateSync.Pending() > 0 {
+		if time.Now().After(deadline) {
+			log.Info(s.logInfo+" Syncing state", "peer", peerID, "pending", s.stateSync.Pending(),
+				"total", rawdb.ReadFastTrieProgress(s.chainDb))
+			return false, nil
+		}
+		if err := s.syncStateNodes(peerID); err != nil {
+			// restart from the root, the nodes already written are skipped
+			s.stateSync, s.stateSyncQueue = nil, nil
+			return false, err
+		}
+	}
+
+	if err := s.syncCrossShardTxLists(peerID, s.MinorBlockChain.FastSyncMissingDeposits()); err != nil {
+		return false, err
+	}
+	if err := s.MinorBlockChain.FinishFastSync(); err != nil {
+		return false, err
+	}
+	s.stateSync, s.stateSyncQueue = nil, nil
+	return true, nil
+}
+
+// SyncDroppedDeposits downloads again from the peer the cross-shard tx lists
+// downloaded during fast sync which were dropped after a block processing them
+// failed to validate. It is called before adding the blocks synced from the
+// peer, whether the node fast syncs or not, as it may have been restarted
+// without fast sync since.
+func (s *ShardBackend) SyncDroppedDeposits(peerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	return s.syncCrossShardTxLists(peerID, s.MinorBlockChain.FastSyncDroppedDeposits())
+}
+
+// StateSyncProgress returns the number of state entries downloaded during fast
+// sync.
+func (s *ShardBackend) StateSyncProgress() uint64 {
+	return rawdb.ReadFastTrieProgress(s.chainDb)
+}
+
+// syncCrossShardTxLists downloads the cross-shard tx lists made by the given
+// minor blocks of the neighbor shards from the peer. They cannot be checked
+// against the blocks, which the shard did not execute, so they are verified by
+// executing the blocks processing them.
+func (s *ShardBackend) syncCrossShardTxLists(peerID string, missing []common.Hash) error {
+	for len(missing) > 0 {
+		hashList := missing
+		if len(hashList) > CrossShardTxListBatchSize {
+			hashList = hashList[:CrossShardTxListBatchSize]
+		}
+		missing = missing[len(hashList):]
+		xShardTxLists, err := s.conn.GetCrossShardTxLists(hashList, peerID, s.branch.Value)
+		if err != nil {
+			return err
+		}
+		if len(xShardTxLists) != len(hashList) {
+			return fmt.Errorf("peer %s returned %d of %d cross-shard tx lists", peerID, len(xShardTxLists), len(hashList))
+		}
+		for i, xShardTxList := range xShardTxLists {
+			if xShardTxList.MinorBlockHash != hashList[i] {
+				return fmt.Errorf("peer %s returned cross-shard tx list of %x instead of %x", peerID,
+					xShardTxList.MinorBlockHash, hashList[i])
+			}
+			s.MinorBlockChain.AddFastSyncCrossShardTxList(xShardTxList.MinorBlockHash,
+				types.CrossShardTransactionDepositList{TXList: xShardTxList.TxList})
+		}
+	}
+	return nil
+}
+
+// syncStateNodes requests a batch of the missing state nodes from the peer and
+// writes the ones it returns. The ones it does not return are requested again
+// with the next batch.
+func (s *ShardBackend) syncStateNodes(peerID string) error {
+	queue := s.stateSyncQueue
+	if len(queue) < StateNodeBatchSize {
+		queue = append(queue, s.stateSync.Missing(StateNodeBatchSize-len(queue))...)
+	}
+	s.stateSyncQueue = queue
+	nodes, err := s.conn.GetMinorStateNodes(queue, peerID, s.branch.Value)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("peer %s has none of %d state nodes", peerID, len(queue))
+	}
+
+	results := make([]trie.SyncResult, 0, len(nodes))
+	delivered := make(map[common.Hash]struct{}, len(nodes))
+	for _, node := range nodes {
+		hash := crypto.Keccak256Hash(node)
+		if _, ok := delivered[hash]; ok {
+			continue
+		}
+		delivered[hash] = struct{}{}
+		results = append(results, trie.SyncResult{Hash: hash, Data: node})
+	}
+	if _, index, err := s.stateSync.Process(results); err != nil {
+		return fmt.Errorf("state node %x from peer %s: %v", results[index].Hash, peerID, err)
+	}
+	batch := s.chainDb.NewBatch()
+	written, err := s.stateSync.Commit(batch)
+	if err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	progress := rawdb.ReadFastTrieProgress(s.chainDb) + uint64(written)
+	rawdb.WriteFastTrieProgress(s.chainDb, progress)
+
+	remaining := make([]common.Hash, 0, len(queue)-len(delivered))
+	for _, hash := range queue {
+		if _, ok := delivered[hash]; !ok {
+			remaining = append(remaining, hash)
+		}
+	}
+	s.stateSyncQueue = remaining
+	log.Debug(s.logInfo+" Imported state entries", "count", len(results), "total", progress, "pending", s.stateSync.Pending())
+	return nil
+}
+
+// GetStateNodeList returns the state trie nodes and contract codes of the given
+// hashes for a peer fast syncing. The ones the shard does not have are skipped,
+// and the list is cut when it gets too large.
+func (s *ShardBackend) GetStateNodeList(hashList []common.Hash) [][]byte {
+	if len(hashList) > 2*StateNodeBatchSize {
+		hashList = hashList[:2*StateNodeBatchSize]
+	}
+	nodes, size := make([][]byte, 0, len(hashList)), 0
+	for _, hash := range hashList {
+		data, err := s.MinorBlockChain.TrieNode(hash)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		nodes = append(nodes, data)
+		if size += len(data); size >= stateNodeResponseSize {
+			break
+		}
+	}
+	return nodes
+}
+
+// GetCrossShardTxList returns the cross-shard tx lists made to this shard by the
+// given minor blocks of the neighbor shards for a peer fast syncing, stopping at
+// the first one the shard does not have.
+func (s *ShardBackend) GetCrossShardTxList(mHashList []common.Hash) []*p2p.CrossShardTxList {
+	if len(mHashList) > 2*CrossShardTxListBatchSize {
+		mHashList = mHashList[:2*CrossShardTxListBatchSize]
+	}
+	xShardTxLists := make([]*p2p.CrossShardTxList, 0, len(mHashList))
+	for _, hash := range mHashList {
+		list := s.MinorBlockChain.ReadCrossShardTxList(hash)
+		if list == nil {
+			break
+		}
+		xShardTxLists = append(xShardTxLists, &p2p.CrossShardTxList{MinorBlockHash: hash, TxList: list.TXList})
+	}
+	return xShardTxLists
+}
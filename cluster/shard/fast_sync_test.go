@@ -0,0 +1,103 @@
+package shard
+
+import (
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/consensus"
+	"github.com/QuarkChain/goquarkchain/core"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/core/vm"
+	"github.com/QuarkChain/goquarkchain/p2p"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConn serves the cross-shard tx lists of a peer.
+type fakeConn struct {
+	ConnManager
+	xShardTxLists map[common.Hash][]*types.CrossShardTransactionDeposit
+	requested     []common.Hash
+}
+
+func (c *fakeConn) GetCrossShardTxLists(mHashList []common.Hash, peerId string, branch uint32) ([]*p2p.CrossShardTxList, error) {
+	c.requested = append(c.requested, mHashList...)
+	xShardTxLists := make([]*p2p.CrossShardTxList, 0, len(mHashList))
+	for _, hash := range mHashList {
+		xShardTxLists = append(xShardTxLists, &p2p.CrossShardTxList{MinorBlockHash: hash, TxList: c.xShardTxLists[hash]})
+	}
+	return xShardTxLists, nil
+}
+
+func newTestMinorBlockChain(t *testing.T, cfg *config.ClusterConfig, fullShardId uint32) (*core.MinorBlockChain, *types.RootBlock) {
+	db := ethdb.NewMemDatabase()
+	genesis := core.NewGenesis(cfg.Quarkchain)
+	rootBlock := genesis.MustCommitRootBlock(db)
+	genesis.MustCommitMinorBlock(db, rootBlock, fullShardId)
+	chain, err := core.NewMinorBlockChain(db, nil, params.TestChainConfig, cfg, new(consensus.FakeEngine), vm.Config{}, nil, fullShardId)
+	assert.NoError(t, err)
+	_, err = chain.InitGenesisState(rootBlock)
+	assert.NoError(t, err)
+	return chain, rootBlock
+}
+
+func TestSyncDroppedDeposits(t *testing.T) {
+	cfg := config.NewClusterConfig()
+	cfg.Quarkchain.Update(1, 2, 10, 1)
+	cfg.Quarkchain.SkipRootCoinbaseCheck = true
+	cfg.Quarkchain.SkipMinorDifficultyCheck = true
+	cfg.Quarkchain.SkipRootDifficultyCheck = true
+	fullShardId := cfg.Quarkchain.GetGenesisShardIds()[0]
+	peer, rootBlock := newTestMinorBlockChain(t, cfg, fullShardId)
+	defer peer.Stop()
+	chain, _ := newTestMinorBlockChain(t, cfg, fullShardId)
+	defer chain.Stop()
+
+	// the neighbor shard makes deposits to the shard, the list was downloaded
+	// during fast sync
+	firstRootBlock := rootBlock.Header().CreateBlockToAppend(nil, nil, nil, nil, nil).Finalize(nil, nil, common.Hash{})
+	neighbor := &types.MinorBlockHeader{Branch: account.Branch{Value: fullShardId + 1}, PrevRootBlockHash: firstRootBlock.Hash()}
+	nextRootBlock := firstRootBlock.Header().CreateBlockToAppend(nil, nil, nil, nil, nil)
+	nextRootBlock.AddMinorBlockHeader(neighbor)
+	nextRootBlock = nextRootBlock.Finalize(nil, nil, common.Hash{})
+	for _, bc := range []*core.MinorBlockChain{peer, chain} {
+		_, err := bc.AddRootBlock(firstRootBlock)
+		assert.NoError(t, err)
+		bc.AddCrossShardTxListByMinorBlockHash(neighbor.Hash(), types.CrossShardTransactionDepositList{})
+		_, err = bc.AddRootBlock(nextRootBlock)
+		assert.NoError(t, err)
+	}
+	chain.AddFastSyncCrossShardTxList(neighbor.Hash(), types.CrossShardTransactionDepositList{})
+
+	// a block processing it fails to execute, so the list is dropped
+	block, err := peer.CreateBlockToMine(nil, nil, nil, nil, nil)
+	assert.NoError(t, err)
+	block.Finalize(nil, common.Hash{}, nil, nil, block.CoinbaseAmount(), block.Meta().XShardTxCursorInfo)
+	_, err = chain.InsertChain([]types.IBlock{block}, false)
+	assert.Error(t, err)
+	assert.Nil(t, chain.ReadCrossShardTxList(neighbor.Hash()))
+
+	// and downloaded again before the blocks synced are added
+	conn := &fakeConn{xShardTxLists: make(map[common.Hash][]*types.CrossShardTransactionDeposit)}
+	shrd := &ShardBackend{branch: account.Branch{Value: fullShardId}, conn: conn, MinorBlockChain: chain}
+	assert.NoError(t, shrd.SyncDroppedDeposits("peer"))
+	assert.Equal(t, []common.Hash{neighbor.Hash()}, conn.requested)
+	assert.NotNil(t, chain.ReadCrossShardTxList(neighbor.Hash()))
+	assert.Equal(t, 0, len(chain.FastSyncDroppedDeposits()))
+
+	block, err = peer.CreateBlockToMine(nil, nil, nil, nil, nil)
+	assert.NoError(t, err)
+	block, _, err = peer.FinalizeAndAddBlock(block)
+	assert.NoError(t, err)
+	_, err = chain.InsertChain([]types.IBlock{block}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash(), chain.CurrentBlock().Hash())
+
+	// nothing is downloaded once the list is verified
+	conn.requested = nil
+	assert.NoError(t, shrd.SyncDroppedDeposits("peer"))
+	assert.Equal(t, 0, len(conn.requested))
+}
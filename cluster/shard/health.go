@@ -0,0 +1,52 @@
+package shard
+
+import (
+	"fmt"
+)
+
+// updatePeerTip records a validated tip announced by the peer. The latest
+// tip replaces the former one, so a peer switching to a shorter fork lowers it.
+func (s *ShardBackend) updatePeerTip(peerID string, height uint64) {
+	if peerID == "" {
+		return
+	}
+	s.peerTipsMu.Lock()
+	defer s.peerTipsMu.Unlock()
+	s.peerTips[peerID] = height
+}
+
+// RemovePeerTip forgets the tip of a disconnected peer.
+func (s *ShardBackend) RemovePeerTip(peerID string) {
+	s.peerTipsMu.Lock()
+	defer s.peerTipsMu.Unlock()
+	delete(s.peerTips, peerID)
+}
+
+func (s *ShardBackend) highestPeerTip() uint64 {
+	s.peerTipsMu.RLock()
+	defer s.peerTipsMu.RUnlock()
+	var tip uint64
+	for _, height := range s.peerTips {
+		if height > tip {
+			tip = height
+		}
+	}
+	return tip
+}
+
+// Unready returns why the shard is not ready to serve: it is syncing or its
+// tip lags the tip announced by the peers by more than maxTipLag blocks.
+func (s *ShardBackend) Unready(maxTipLag uint64) []string {
+	reasons := make([]string, 0)
+	if s.synchronizer.IsSyncing() {
+		reasons = append(reasons, fmt.Sprintf("shard %d is syncing", s.branch.Value))
+	}
+	if s.MinorBlockChain.IsFastSyncing() {
+		reasons = append(reasons, fmt.Sprintf("shard %d is fast syncing", s.branch.Value))
+	}
+	tip, peerTip := s.MinorBlockChain.CurrentBlock().NumberU64(), s.highestPeerTip()
+	if peerTip > tip+maxTipLag {
+		reasons = append(reasons, fmt.Sprintf("shard %d tip %d lags the tip %d of the peers", s.branch.Value, tip, peerTip))
+	}
+	return reasons
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/QuarkChain/goquarkchain/account"
 	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/p2p"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -23,4 +24,6 @@ type ConnManager interface {
 	BroadcastMinorBlock(peerId string, minorBlock *types.MinorBlock) error
 	GetMinorBlocks(mHeaderList []common.Hash, peerId string, branch uint32) ([]*types.MinorBlock, error)
 	GetMinorBlockHeaderList(gReq *rpc.GetMinorBlockHeaderListWithSkipRequest) ([]*types.MinorBlockHeader, error)
+	GetMinorStateNodes(hashList []common.Hash, peerId string, branch uint32) ([][]byte, error)
+	GetCrossShardTxLists(mHashList []common.Hash, peerId string, branch uint32) ([]*p2p.CrossShardTxList, error)
 }
@@ -0,0 +1,37 @@
+package shard
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func (s *ShardBackend) metricName(name string) string {
+	return fmt.Sprintf("shard/%d/%s", s.branch.Value, name)
+}
+
+// registerMetrics reports the tip, the transaction pool and the sync state of
+// the shard to the metrics registry.
+func (s *ShardBackend) registerMetrics() {
+	metrics.NewRegisteredFunctionalGauge(s.metricName("height"), nil, func() int64 {
+		return int64(s.MinorBlockChain.CurrentBlock().NumberU64())
+	})
+	metrics.NewRegisteredFunctionalGauge(s.metricName("txpool/pending"), nil, func() int64 {
+		return int64(s.MinorBlockChain.GetTxPoolContent(nil, true).Pending)
+	})
+	metrics.NewRegisteredFunctionalGauge(s.metricName("txpool/queued"), nil, func() int64 {
+		return int64(s.MinorBlockChain.GetTxPoolContent(nil, true).Queued)
+	})
+	metrics.NewRegisteredFunctionalGauge(s.metricName("syncing"), nil, func() int64 {
+		if s.synchronizer.IsSyncing() {
+			return 1
+		}
+		return 0
+	})
+}
+
+func (s *ShardBackend) unregisterMetrics() {
+	for _, name := range []string{"height", "txpool/pending", "txpool/queued", "syncing"} {
+		metrics.DefaultRegistry.Unregister(s.metricName(name))
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 type BlockCommitCode int
@@ -61,8 +62,14 @@ type ShardBackend struct {
 	eventMux     *event.TypeMux
 	synchronizer synchronizer.Synchronizer
 	logInfo      string
+	peerTipsMu   sync.RWMutex
+	peerTips     map[string]uint64 // validated tip announced by each connected peer
 
 	posw consensus.PoSWCalculator
+
+	// state download of the fast sync, see SyncState
+	stateSync      *trie.Sync
+	stateSyncQueue []common.Hash
 }
 
 func New(ctx *service.ServiceContext, rBlock *types.RootBlock, conn ConnManager,
@@ -82,6 +89,7 @@ func New(ctx *service.ServiceContext, rBlock *types.RootBlock, conn ConnManager,
 			eventMux:          ctx.EventMux,
 			logInfo:           fmt.Sprintf("shard:%x", fullshardId),
 			running:           true,
+			peerTips:          make(map[string]uint64),
 		}
 		err error
 	)
@@ -118,6 +126,7 @@ func New(ctx *service.ServiceContext, rBlock *types.RootBlock, conn ConnManager,
 	shard.posw = consensus.CreatePoSWCalculator(shard.MinorBlockChain, shard.Config.PoswConfig)
 
 	shard.miner = miner.New(ctx, shard, shard.engine)
+	shard.registerMetrics()
 
 	return shard, nil
 }
@@ -133,9 +142,9 @@ func (s *ShardBackend) Stop() {
 		return
 	}
 	s.running = false
+	s.unregisterMetrics()
 	s.synchronizer.Close()
 	s.miner.Stop()
-	s.eventMux.Stop()
 	s.engine.Close()
 
 	s.wg.Wait()
@@ -1,6 +1,7 @@
 package slave
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -12,7 +13,9 @@ import (
 	"github.com/QuarkChain/goquarkchain/cluster/sync"
 	qcom "github.com/QuarkChain/goquarkchain/common"
 	"github.com/QuarkChain/goquarkchain/consensus"
+	"github.com/QuarkChain/goquarkchain/core"
 	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/core/vm"
 	"github.com/QuarkChain/goquarkchain/p2p"
 	qrpc "github.com/QuarkChain/goquarkchain/rpc"
 	"github.com/ethereum/go-ethereum/common"
@@ -25,7 +28,7 @@ func (s *SlaveBackend) GetUnconfirmedHeaderList() ([]*rpc.HeadersInfo, error) {
 	var (
 		headersInfoLst = make([]*rpc.HeadersInfo, 0)
 	)
-	for branch, shard := range s.shards {
+	for branch, shard := range s.getShards() {
 		if headers, err := shard.GetUnconfirmedHeaderList(); err == nil {
 			headersInfoLst = append(headersInfoLst, &rpc.HeadersInfo{
 				Branch:     branch,
@@ -40,7 +43,7 @@ func (s *SlaveBackend) GetUnconfirmedHeaderList() ([]*rpc.HeadersInfo, error) {
 
 func (s *SlaveBackend) AddRootBlock(block *types.RootBlock) (switched bool, err error) {
 	switched = false
-	for _, shard := range s.shards {
+	for _, shard := range s.getShards() {
 		if switched, err = shard.AddRootBlock(block); err != nil {
 			return false, err
 		}
@@ -55,7 +58,7 @@ func (s *SlaveBackend) CreateShards(rootBlock *types.RootBlock, forceInit bool)
 	var g errgroup.Group
 	for _, id := range fullShardList {
 		id := id
-		if shd, ok := s.shards[id]; ok {
+		if shd, ok := s.getShard(id); ok {
 			if forceInit {
 				if err := shd.InitFromRootBlock(rootBlock); err != nil {
 					return err
@@ -81,20 +84,30 @@ func (s *SlaveBackend) CreateShards(rootBlock *types.RootBlock, forceInit bool)
 		})
 	}
 	if err := g.Wait(); err != nil {
-		for _, slv := range s.shards {
+		s.lock.Lock()
+		shards := s.shards
+		s.shards = make(map[uint32]*shard.ShardBackend)
+		s.lock.Unlock()
+		for _, slv := range shards {
 			slv.Stop()
 		}
-		s.shards = make(map[uint32]*shard.ShardBackend)
 		return err
 	}
 	return nil
 }
 
-func (s *SlaveBackend) AddBlockListForSync(mHashList []common.Hash, peerId string, branch uint32) (*rpc.ShardStatus, error) {
-	shard, ok := s.shards[branch]
+func (s *SlaveBackend) AddBlockListForSync(mHashList []common.Hash, peerId string, branch uint32, fastSync bool) (*rpc.ShardStatus, error) {
+	shard, ok := s.getShard(branch)
 	if !ok {
 		return nil, ErrMsg("AddBlockListForSync")
 	}
+	if fastSync {
+		shard.MinorBlockChain.StartFastSync()
+	}
+	addBlockList := shard.AddBlockListForSync
+	if shard.MinorBlockChain.IsFastSyncing() {
+		addBlockList = shard.AddBlockListForFastSync
+	}
 
 	hashList := make([]common.Hash, 0, len(mHashList))
 	for _, hash := range mHashList {
@@ -102,6 +115,11 @@ func (s *SlaveBackend) AddBlockListForSync(mHashList []common.Hash, peerId strin
 			hashList = append(hashList, hash)
 		}
 	}
+	if len(hashList) > 0 && !shard.MinorBlockChain.IsFastSyncing() {
+		if err := shard.SyncDroppedDeposits(peerId); err != nil {
+			return nil, err
+		}
+	}
 
 	var (
 		BlockBatchSize = 100
@@ -123,7 +141,7 @@ func (s *SlaveBackend) AddBlockListForSync(mHashList []common.Hash, peerId strin
 		if len(bList) != hLen {
 			return nil, errors.New("Failed to add minor blocks for syncing root block: length of downloaded block list is incorrect")
 		}
-		if err := shard.AddBlockListForSync(bList); err != nil {
+		if err := addBlockList(bList); err != nil {
 			return nil, err
 		}
 		hashList = hashList[hLen:]
@@ -131,6 +149,27 @@ func (s *SlaveBackend) AddBlockListForSync(mHashList []common.Hash, peerId strin
 	return shard.MinorBlockChain.GetShardStats()
 }
 
+// SyncShardState downloads for a while the state of the last block a shard
+// added while fast syncing from the peer, and tells whether it is done.
+func (s *SlaveBackend) SyncShardState(branch uint32, peerId string) (*rpc.SyncShardStateResponse, error) {
+	shard, ok := s.getShard(branch)
+	if !ok {
+		return nil, ErrMsg("SyncShardState")
+	}
+	done, err := shard.SyncState(peerId)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.SyncShardStateResponse{Done: done, Progress: shard.StateSyncProgress()}, nil
+}
+
+// PeerDisconnected drops the tips the peer announced to the shards.
+func (s *SlaveBackend) PeerDisconnected(peerID string) {
+	for _, shrd := range s.getShards() {
+		shrd.RemovePeerTip(peerID)
+	}
+}
+
 func (s *SlaveBackend) AddTx(tx *types.Transaction) (err error) {
 	toShardSize, err := s.clstrCfg.Quarkchain.GetShardSizeByChainId(tx.EvmTx.ToChainID())
 	if err != nil {
@@ -146,7 +185,7 @@ func (s *SlaveBackend) AddTx(tx *types.Transaction) (err error) {
 	if err := tx.EvmTx.SetFromShardSize(fromShardSize); err != nil {
 		return err
 	}
-	if shard, ok := s.shards[tx.EvmTx.FromFullShardId()]; ok {
+	if shard, ok := s.getShard(tx.EvmTx.FromFullShardId()); ok {
 		return shard.MinorBlockChain.AddTx(tx)
 	}
 	return ErrMsg("AddTx")
@@ -157,7 +196,7 @@ func (s *SlaveBackend) AddTxList(peerID string, branch uint32, txs []*types.Tran
 		return nil
 	}
 
-	shard, ok := s.shards[branch]
+	shard, ok := s.getShard(branch)
 	if !ok {
 		return fmt.Errorf("fullShardID:%v not found", branch)
 	}
@@ -181,7 +220,7 @@ func (s *SlaveBackend) AddTxList(peerID string, branch uint32, txs []*types.Tran
 	return nil
 }
 
-func (s *SlaveBackend) ExecuteTx(tx *types.Transaction, address *account.Address, height *uint64) ([]byte, error) {
+func (s *SlaveBackend) ExecuteTx(tx *types.Transaction, address *account.Address, height *uint64, overrides []*rpc.StateOverride) ([]byte, error) {
 	fromShardSize, err := s.clstrCfg.Quarkchain.GetShardSizeByChainId(tx.EvmTx.FromChainID())
 	if err != nil {
 		return nil, err
@@ -189,8 +228,8 @@ func (s *SlaveBackend) ExecuteTx(tx *types.Transaction, address *account.Address
 	if err := tx.EvmTx.SetFromShardSize(fromShardSize); err != nil {
 		return nil, err
 	}
-	if shard, ok := s.shards[tx.EvmTx.FromFullShardId()]; ok {
-		return shard.MinorBlockChain.ExecuteTx(tx, address, height)
+	if shard, ok := s.getShard(tx.EvmTx.FromFullShardId()); ok {
+		return shard.MinorBlockChain.ExecuteTx(tx, address, height, overrides)
 	}
 	return nil, ErrMsg("ExecuteTx")
 }
@@ -201,7 +240,7 @@ func (s *SlaveBackend) GetAccountData(address *account.Address, height *uint64)
 		bt      []byte
 		err     error
 	)
-	for branch, shard := range s.shards {
+	for branch, shard := range s.getShards() {
 		data := rpc.AccountBranchData{
 			Branch: branch,
 		}
@@ -232,14 +271,14 @@ func (s *SlaveBackend) GetAccountData(address *account.Address, height *uint64)
 }
 
 func (s *SlaveBackend) GetMinorBlock(hash common.Hash, height *uint64, branch uint32) (*types.MinorBlock, error) {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		return shard.GetMinorBlock(hash, height)
 	}
 	return nil, ErrMsg("GetMinorBlock")
 }
 
 func (s *SlaveBackend) GetMinorBlockExtraInfo(block *types.MinorBlock, branch uint32) (*rpc.PoSWInfo, error) {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		extra, err := shard.MinorBlockChain.PoswInfo(block)
 		if err != nil {
 			return nil, err
@@ -250,7 +289,7 @@ func (s *SlaveBackend) GetMinorBlockExtraInfo(block *types.MinorBlock, branch ui
 }
 
 func (s *SlaveBackend) GetTransactionByHash(txHash common.Hash, branch uint32) (*types.MinorBlock, uint32, error) {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		minorBlock, idx := shard.MinorBlockChain.GetTransactionByHash(txHash)
 		return minorBlock, idx, nil
 	}
@@ -258,45 +297,82 @@ func (s *SlaveBackend) GetTransactionByHash(txHash common.Hash, branch uint32) (
 }
 
 func (s *SlaveBackend) GetTransactionReceipt(txHash common.Hash, branch uint32) (*types.MinorBlock, uint32, *types.Receipt, error) {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		block, index, receipts := shard.MinorBlockChain.GetTransactionReceipt(txHash)
 		return block, index, receipts, nil
 	}
 	return nil, 0, nil, ErrMsg("GetTransactionReceipt")
 }
 
+func (s *SlaveBackend) TraceTransaction(txHash common.Hash, branch uint32, config *rpc.TraceConfig) ([]byte, error) {
+	if shard, ok := s.getShard(branch); ok {
+		result, err := shard.MinorBlockChain.TraceTransaction(txHash, toTraceConfig(config))
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(result)
+	}
+	return nil, ErrMsg("TraceTransaction")
+}
+
+func (s *SlaveBackend) TraceMinorBlock(blockHash common.Hash, branch uint32, config *rpc.TraceConfig) ([]byte, error) {
+	if shard, ok := s.getShard(branch); ok {
+		results, err := shard.MinorBlockChain.TraceMinorBlock(blockHash, toTraceConfig(config))
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(results)
+	}
+	return nil, ErrMsg("TraceMinorBlock")
+}
+
+func toTraceConfig(config *rpc.TraceConfig) *core.TraceConfig {
+	if config == nil {
+		return nil
+	}
+	return &core.TraceConfig{
+		LogConfig: &vm.LogConfig{
+			DisableMemory:  config.DisableMemory,
+			DisableStack:   config.DisableStack,
+			DisableStorage: config.DisableStorage,
+			Limit:          int(config.Limit),
+		},
+		Tracer: &config.Tracer,
+	}
+}
+
 func (s *SlaveBackend) GetTransactionListByAddress(address *account.Address, transferTokenID *uint64, start []byte, limit uint32) ([]*rpc.TransactionDetail, []byte, error) {
 	branch, err := s.getBranch(address)
 	if err != nil {
 		return nil, nil, err
 	}
-	if shard, ok := s.shards[branch.Value]; ok {
+	if shard, ok := s.getShard(branch.Value); ok {
 		return shard.GetTransactionListByAddress(address, transferTokenID, start, limit)
 	}
 	return nil, nil, ErrMsg("GetTransactionListByAddress")
 }
 
 func (s *SlaveBackend) GetAllTx(branch account.Branch, start []byte, limit uint32) ([]*rpc.TransactionDetail, []byte, error) {
-	if shard, ok := s.shards[branch.Value]; ok {
+	if shard, ok := s.getShard(branch.Value); ok {
 		return shard.GetAllTx(start, limit)
 	}
 	return nil, nil, ErrMsg("GetAllTx")
 }
 
 func (s *SlaveBackend) GetLogs(args *qrpc.FilterQuery) ([]*types.Log, error) {
-	if shard, ok := s.shards[args.FullShardId]; ok {
+	if shard, ok := s.getShard(args.FullShardId); ok {
 		return shard.GetLogsByFilterQuery(args)
 	}
 	return nil, ErrMsg("GetLogs")
 }
 
-func (s *SlaveBackend) EstimateGas(tx *types.Transaction, address *account.Address) (uint32, error) {
+func (s *SlaveBackend) EstimateGas(tx *types.Transaction, address *account.Address, overrides []*rpc.StateOverride) (uint32, error) {
 	fullShardId, err := s.clstrCfg.Quarkchain.GetFullShardIdByFullShardKey(address.FullShardKey)
 	if err != nil {
 		return 0, err
 	}
-	if shrd, ok := s.shards[fullShardId]; ok {
-		return shrd.MinorBlockChain.EstimateGas(tx, *address)
+	if shrd, ok := s.getShard(fullShardId); ok {
+		return shrd.MinorBlockChain.EstimateGas(tx, *address, overrides)
 	}
 	return 0, ErrMsg("EstimateGas")
 }
@@ -306,7 +382,7 @@ func (s *SlaveBackend) GetStorageAt(address *account.Address, key common.Hash, h
 	if err != nil {
 		return common.Hash{}, err
 	}
-	if shard, ok := s.shards[branch.Value]; ok {
+	if shard, ok := s.getShard(branch.Value); ok {
 		hash, err := shard.MinorBlockChain.GetHashByHeight(height)
 		if err != nil {
 			return common.Hash{}, err
@@ -316,12 +392,65 @@ func (s *SlaveBackend) GetStorageAt(address *account.Address, key common.Hash, h
 	return common.Hash{}, ErrMsg("GetStorageAt")
 }
 
+func (s *SlaveBackend) GetProof(address *account.Address, storageKeys []common.Hash, height *uint64) (*rpc.AccountProof, error) {
+	branch, err := s.getBranch(address)
+	if err != nil {
+		return nil, err
+	}
+	if shard, ok := s.getShard(branch.Value); ok {
+		hash, err := shard.MinorBlockChain.GetHashByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		return shard.MinorBlockChain.GetProof(address.Recipient, storageKeys, &hash)
+	}
+	return nil, ErrMsg("GetProof")
+}
+
+func (s *SlaveBackend) GetPendingTransactionHashes(branch uint32) ([]common.Hash, error) {
+	if shard, ok := s.getShard(branch); ok {
+		return shard.MinorBlockChain.GetPendingTxHashes()
+	}
+	return nil, ErrMsg("GetPendingTransactionHashes")
+}
+
+func (s *SlaveBackend) GetXShardDepositStatus(txHash common.Hash, branch uint32, sourceBlockHash, rootBlockHash common.Hash) (*rpc.XShardDepositStatus, error) {
+	if shard, ok := s.getShard(branch); ok {
+		return shard.MinorBlockChain.GetXShardDepositStatus(txHash, sourceBlockHash, rootBlockHash)
+	}
+	return nil, ErrMsg("GetXShardDepositStatus")
+}
+
+func (s *SlaveBackend) GetFeeHistory(branch uint32, tokenID, blockCount uint64, percentiles []uint32) (*rpc.FeeHistory, error) {
+	if shard, ok := s.getShard(branch); ok {
+		return shard.MinorBlockChain.FeeHistory(tokenID, blockCount, percentiles)
+	}
+	return nil, ErrMsg("GetFeeHistory")
+}
+
+// GetTxPoolContent returns the transaction pool of the shard of branch, or the
+// ones of all the shards of the slave if branch is 0.
+func (s *SlaveBackend) GetTxPoolContent(branch uint32, sender *account.Recipient, countOnly bool) ([]*rpc.ShardTxPool, error) {
+	if branch != 0 {
+		if shard, ok := s.getShard(branch); ok {
+			return []*rpc.ShardTxPool{shard.MinorBlockChain.GetTxPoolContent(sender, countOnly)}, nil
+		}
+		return nil, ErrMsg("GetTxPoolContent")
+	}
+	shards := s.getShards()
+	pools := make([]*rpc.ShardTxPool, 0, len(shards))
+	for _, shard := range shards {
+		pools = append(pools, shard.MinorBlockChain.GetTxPoolContent(sender, countOnly))
+	}
+	return pools, nil
+}
+
 func (s *SlaveBackend) GetCode(address *account.Address, height *uint64) ([]byte, error) {
 	branch, err := s.getBranch(address)
 	if err != nil {
 		return nil, err
 	}
-	if shard, ok := s.shards[branch.Value]; ok {
+	if shard, ok := s.getShard(branch.Value); ok {
 		hash, err := shard.MinorBlockChain.GetHashByHeight(height)
 		if err != nil {
 			return nil, err
@@ -332,7 +461,7 @@ func (s *SlaveBackend) GetCode(address *account.Address, height *uint64) ([]byte
 }
 
 func (s *SlaveBackend) GasPrice(branch uint32, tokenID uint64) (uint64, error) {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		price, err := shard.MinorBlockChain.GasPrice(tokenID)
 		if err != nil {
 			return 0, errors.New(fmt.Sprintf("Failed to get gas price, shard id : %d, err: %v", shard.Config.ShardID, err))
@@ -343,14 +472,14 @@ func (s *SlaveBackend) GasPrice(branch uint32, tokenID uint64) (uint64, error) {
 }
 
 func (s *SlaveBackend) GetWork(branch uint32, coinbaseAddr *account.Address) (*consensus.MiningWork, error) {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		return shard.GetWork(coinbaseAddr)
 	}
 	return nil, ErrMsg("GetWork")
 }
 
 func (s *SlaveBackend) SubmitWork(headerHash common.Hash, nonce uint64, mixHash common.Hash, branch uint32) error {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		return shard.SubmitWork(headerHash, nonce, mixHash)
 	}
 	return ErrMsg("SubmitWork")
@@ -358,15 +487,18 @@ func (s *SlaveBackend) SubmitWork(headerHash common.Hash, nonce uint64, mixHash
 
 func (s *SlaveBackend) AddCrossShardTxListByMinorBlockHash(minorHash common.Hash,
 	txList []*types.CrossShardTransactionDeposit, branch uint32) error {
-	if shard, ok := s.shards[branch]; ok {
+	if shard, ok := s.getShard(branch); ok {
 		shard.MinorBlockChain.AddCrossShardTxListByMinorBlockHash(minorHash, types.CrossShardTransactionDepositList{TXList: txList})
 		return nil
 	}
+	if s.keepMovedDeposits(&rpc.AddXshardTxListRequest{Branch: branch, MinorBlockHash: minorHash, TxList: txList}) {
+		return nil
+	}
 	return ErrMsg("AddCrossShardTxListByMinorBlockHash")
 }
 
 func (s *SlaveBackend) GetMinorBlockListByHashList(mHashList []common.Hash, branch uint32) ([]*types.MinorBlock, error) {
-	shrd, ok := s.shards[branch]
+	shrd, ok := s.getShard(branch)
 	if !ok {
 		return nil, ErrMsg("GetMinorBlockListByHashList")
 	}
@@ -388,8 +520,24 @@ func (s *SlaveBackend) GetMinorBlockListByHashList(mHashList []common.Hash, bran
 	return minorList, nil
 }
 
+func (s *SlaveBackend) GetMinorStateNodeList(hashList []common.Hash, branch uint32) ([][]byte, error) {
+	shrd, ok := s.getShard(branch)
+	if !ok {
+		return nil, ErrMsg("GetMinorStateNodeList")
+	}
+	return shrd.GetStateNodeList(hashList), nil
+}
+
+func (s *SlaveBackend) GetCrossShardTxList(mHashList []common.Hash, branch uint32) ([]*p2p.CrossShardTxList, error) {
+	shrd, ok := s.getShard(branch)
+	if !ok {
+		return nil, ErrMsg("GetCrossShardTxList")
+	}
+	return shrd.GetCrossShardTxList(mHashList), nil
+}
+
 func (s *SlaveBackend) getMinorBlockHeaders(req *p2p.GetMinorBlockHeaderListRequest) ([]*types.MinorBlockHeader, error) {
-	shard, ok := s.shards[req.Branch.Value]
+	shard, ok := s.getShard(req.Branch.Value)
 	if !ok {
 		return nil, ErrMsg("GetMinorBlockHeaderList")
 	}
@@ -415,7 +563,7 @@ func (s *SlaveBackend) getMinorBlockHeaders(req *p2p.GetMinorBlockHeaderListRequ
 }
 
 func (s *SlaveBackend) getMinorBlockHeadersWithSkip(gReq *p2p.GetMinorBlockHeaderListWithSkipRequest) ([]*types.MinorBlockHeader, error) {
-	shrd, ok := s.shards[gReq.Branch.Value]
+	shrd, ok := s.getShard(gReq.Branch.Value)
 	if !ok {
 		return nil, ErrMsg("GetMinorBlockHeaderList")
 	}
@@ -473,35 +621,41 @@ func (s *SlaveBackend) HandleNewTip(req *rpc.HandleNewTipRequest) error {
 	}
 
 	mBHeader := req.MinorBlockHeaderList[0]
-	if shard, ok := s.shards[mBHeader.Branch.Value]; ok {
+	if shard, ok := s.getShard(mBHeader.Branch.Value); ok {
 		return shard.HandleNewTip(req.RootBlockHeader, mBHeader, req.PeerID)
 	}
 	return ErrMsg("HandleNewTip")
 }
 
 func (s *SlaveBackend) NewMinorBlock(peerId string, block *types.MinorBlock) error {
-	if shard, ok := s.shards[block.Branch().Value]; ok {
+	if shard, ok := s.getShard(block.Branch().Value); ok {
 		return shard.NewMinorBlock(peerId, block)
 	}
 	return ErrMsg("NewMinorBlock")
 }
 
 func (s *SlaveBackend) GenTx(genTxs rpc.GenTxRequest) error {
-	for _, shard := range s.shards {
+	for _, shard := range s.getShards() {
 		if !shard.AccountForTPSReady() {
 			return errors.New("account for tps not ready")
 		}
 	}
-	for _, shrd := range s.shards {
+	for _, shrd := range s.getShards() {
 		sd := shrd
 		go sd.GenTx(genTxs)
 	}
 	return nil
 }
 
+// SetMining starts or stops mining the shards, the shards the slave is a
+// standby of are only mined once promoted, and none while the slave misses the
+// heartbeats of the master.
 func (s *SlaveBackend) SetMining(mining bool) {
-	for _, shrd := range s.shards {
-		shrd.SetMining(mining)
+	s.roleMu.Lock()
+	defer s.roleMu.Unlock()
+	s.mining = mining
+	for fullShardId, shrd := range s.getShards() {
+		shrd.SetMining(s.isMining(fullShardId))
 	}
 }
 
@@ -510,29 +664,43 @@ func (s *SlaveBackend) EventMux() *event.TypeMux {
 }
 
 func (s *SlaveBackend) GetShardFilter(fullShardId uint32) (filters.ShardFilter, error) {
-	if shrd, ok := s.shards[fullShardId]; ok {
+	if shrd, ok := s.getShard(fullShardId); ok {
 		return shrd, nil
 	}
 	return nil, fmt.Errorf("bad params of fullShardId: %d\n", fullShardId)
 }
 
-func (s *SlaveBackend) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) error {
+// CheckMinorBlocksInRoot checks the databases of the shards of the slave in
+// parallel for the minor blocks a root block confirms.
+func (s *SlaveBackend) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) (*rpc.CheckMinorBlocksInRootResponse, error) {
 	if rootBlock == nil {
-		return errors.New("CheckMinorBlocksInRoot failed: invalid root block")
+		return nil, errors.New("CheckMinorBlocksInRoot failed: invalid root block")
 	}
-	for _, header := range rootBlock.MinorBlockHeaders() {
-		if shard, ok := s.shards[header.Branch.Value]; ok {
-			if err := shard.CheckMinorBlock(header); err != nil {
-				return err
-			}
-		}
+	var (
+		g       errgroup.Group
+		shardM  = s.getShards()
+		shards  = make([]*shard.ShardBackend, 0, len(shardM))
+		results = make([]*rpc.ShardDBCheck, len(shardM))
+	)
+	for _, shrd := range shardM {
+		shards = append(shards, shrd)
+	}
+	for i, shrd := range shards {
+		i, shrd := i, shrd
+		g.Go(func() (err error) {
+			results[i], err = shrd.CheckMinorBlocksInRoot(rootBlock)
+			return err
+		})
 	}
-	return nil
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return &rpc.CheckMinorBlocksInRootResponse{ShardList: results}, nil
 }
 
 func (s *SlaveBackend) GetRootChainStakes(address account.Address, lastMinor common.Hash) (*big.Int,
 	*account.Recipient, error) {
-	for _, shrd := range s.shards {
+	for _, shrd := range s.getShards() {
 		if shrd.Config.ChainID == 0 && shrd.Config.ShardID == 0 {
 			return shrd.GetRootChainStakes(address, lastMinor)
 		}
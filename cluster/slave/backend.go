@@ -22,9 +22,19 @@ type SlaveBackend struct {
 	lock   sync.RWMutex
 	shards map[uint32]*shard.ShardBackend
 
+	roleMu  sync.Mutex
+	standby map[uint32]bool // shards the slave is a standby of, not promoted yet
+	mining  bool            // whether the master asked to mine, the shards it is a standby of excluded
+	fenced  bool            // whether the slave missed the heartbeats of the master, see fenceLoop
+
+	exportMu sync.Mutex
+	exports  map[uint32]*shardExport // shards moved or being moved to other slaves
+	imports  map[uint32]string       // shards being copied from other slaves, by source
+
 	ctx      *service.ServiceContext
 	eventMux *event.TypeMux
 	logInfo  string
+	exitCh   chan struct{}
 }
 
 func New(ctx *service.ServiceContext, clusterCfg *config.ClusterConfig, cfg *config.SlaveConfig) (*SlaveBackend, error) {
@@ -33,9 +43,13 @@ func New(ctx *service.ServiceContext, clusterCfg *config.ClusterConfig, cfg *con
 		clstrCfg:      clusterCfg,
 		fullShardList: make([]uint32, 0),
 		shards:        make(map[uint32]*shard.ShardBackend),
+		exports:       make(map[uint32]*shardExport),
+		imports:       make(map[uint32]string),
 		ctx:           ctx,
 		eventMux:      ctx.EventMux,
 		logInfo:       "SlaveBackend",
+		standby:       make(map[uint32]bool),
+		exitCh:        make(chan struct{}),
 	}
 	fullShardIds := slave.clstrCfg.Quarkchain.GetGenesisShardIds()
 	for _, id := range fullShardIds {
@@ -43,13 +57,20 @@ func New(ctx *service.ServiceContext, clusterCfg *config.ClusterConfig, cfg *con
 			continue
 		}
 		slave.fullShardList = append(slave.fullShardList, id)
+		if cfg.Standby {
+			slave.standby[id] = true
+		}
 	}
 
 	slave.connManager = NewToSlaveConnManager(slave.clstrCfg, slave)
 	return slave, nil
 }
 
+// GetFullShardList returns the shards run by the slave, the list is replaced
+// when shards move and must not be modified.
 func (s *SlaveBackend) GetFullShardList() []uint32 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	return s.fullShardList
 }
 
@@ -81,7 +102,28 @@ func (s *SlaveBackend) GetConfig() *config.SlaveConfig {
 }
 
 func (s *SlaveBackend) GetShard(fullShardId uint32) *shard.ShardBackend {
-	return s.shards[fullShardId]
+	shrd, _ := s.getShard(fullShardId)
+	return shrd
+}
+
+// getShard returns the shard if the slave runs it, shards can be moved to or
+// from the slave at any time so s.shards is only accessed under s.lock.
+func (s *SlaveBackend) getShard(fullShardId uint32) (*shard.ShardBackend, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	shrd, ok := s.shards[fullShardId]
+	return shrd, ok
+}
+
+// getShards returns a copy of the shards run by the slave by full shard id.
+func (s *SlaveBackend) getShards() map[uint32]*shard.ShardBackend {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	shards := make(map[uint32]*shard.ShardBackend, len(s.shards))
+	for fullShardId, shrd := range s.shards {
+		shards[fullShardId] = shrd
+	}
+	return shards
 }
 
 func (s *SlaveBackend) Protocols() (protos []p2p.Protocol) { return nil }
@@ -108,15 +150,20 @@ func (s *SlaveBackend) APIs() []rpc.API {
 }
 
 func (s *SlaveBackend) Stop() error {
+	close(s.exitCh)
 	s.eventMux.Stop()
-	for target := range s.shards {
-		s.shards[target].Stop()
-		delete(s.shards, target)
+	s.lock.Lock()
+	shards := s.shards
+	s.shards = make(map[uint32]*shard.ShardBackend)
+	s.lock.Unlock()
+	for _, shrd := range shards {
+		shrd.Stop()
 	}
 	s.connManager.Stop()
 	return nil
 }
 
 func (s *SlaveBackend) Init(srvr *p2p.Server) error {
+	go s.fenceLoop()
 	return nil
 }
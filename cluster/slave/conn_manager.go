@@ -70,7 +70,43 @@ func (s *ConnManager) SetConnectToMasterAndSlaves(cfgs []*config.SlaveConfig) {
 	}
 }
 
+// MoveShard sends the messages to a shard to the slave at target, which runs it
+// instead of the slave from.
+func (s *ConnManager) MoveShard(fullShardId uint32, from, target string) (*SlaveConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	to, ok := s.slavesConn[target]
+	if !ok {
+		return nil, fmt.Errorf("no connection to slave %s", target)
+	}
+	conns := make([]*SlaveConn, 0, len(s.fullShardIdToSlaves[fullShardId]))
+	for _, conn := range s.fullShardIdToSlaves[fullShardId] {
+		if conn.id == from {
+			conn.removeShard(fullShardId)
+			continue
+		}
+		if conn != to {
+			conns = append(conns, conn)
+		}
+	}
+	to.addShard(fullShardId)
+	s.fullShardIdToSlaves[fullShardId] = append(conns, to)
+	return to, nil
+}
+
+func (s *ConnManager) getSlaveConn(target string) (*SlaveConn, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn, ok := s.slavesConn[target]
+	return conn, ok
+}
+
+// GetConnectionsByFullShardId returns the slaves running the shard, the list is
+// replaced when the shard moves and must not be modified.
 func (s *ConnManager) GetConnectionsByFullShardId(id uint32) []*SlaveConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if conns, ok := s.fullShardIdToSlaves[id]; ok {
 		return conns
 	}
@@ -79,26 +115,22 @@ func (s *ConnManager) GetConnectionsByFullShardId(id uint32) []*SlaveConn {
 
 func (s *ConnManager) AddXshardTxList(fullShardId uint32, xshardReq *rpc.AddXshardTxListRequest) error {
 	var g errgroup.Group
-	if clients, ok := s.fullShardIdToSlaves[fullShardId]; ok {
-		for _, client := range clients {
-			cli := client
-			g.Go(func() error {
-				return cli.AddXshardTxList(xshardReq)
-			})
-		}
+	for _, client := range s.GetConnectionsByFullShardId(fullShardId) {
+		cli := client
+		g.Go(func() error {
+			return cli.AddXshardTxList(xshardReq)
+		})
 	}
 	return g.Wait()
 }
 
 func (s *ConnManager) BatchAddXshardTxList(fullShardId uint32, xshardReqs []*rpc.AddXshardTxListRequest) error {
 	var g errgroup.Group
-	if clients, ok := s.fullShardIdToSlaves[fullShardId]; ok {
-		for _, client := range clients {
-			cli := client
-			g.Go(func() error {
-				return cli.BatchAddXshardTxList(xshardReqs)
-			})
-		}
+	for _, client := range s.GetConnectionsByFullShardId(fullShardId) {
+		cli := client
+		g.Go(func() error {
+			return cli.BatchAddXshardTxList(xshardReqs)
+		})
 	}
 	return g.Wait()
 }
@@ -121,7 +153,7 @@ func (s *ConnManager) BroadcastXshardTxList(block *types.MinorBlock,
 			}
 			continue
 		}
-		if shard, ok := s.slave.shards[branch.Value]; ok {
+		if shard, ok := s.slave.getShard(branch.Value); ok {
 			shard.MinorBlockChain.AddCrossShardTxListByMinorBlockHash(hash, types.CrossShardTransactionDepositList{TXList: request.TxList})
 		}
 		err := s.AddXshardTxList(branch.GetFullShardID(), request)
@@ -160,7 +192,7 @@ func (s *ConnManager) BatchBroadcastXshardTxList(
 	}
 
 	for branch, request := range brchToAddXsdTxLstReqLst {
-		if shard, ok := s.slave.shards[branch.Value]; ok {
+		if shard, ok := s.slave.getShard(branch.Value); ok {
 			for _, req := range request {
 				shard.MinorBlockChain.AddCrossShardTxListByMinorBlockHash(req.MinorBlockHash, types.CrossShardTransactionDepositList{TXList: req.TxList})
 			}
@@ -0,0 +1,27 @@
+package slave
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+)
+
+// heartbeatTimeout is how long the slave may go without a heartbeat from the
+// master before it is no longer ready.
+const heartbeatTimeout = 3 * config.HeartbeatInterval
+
+// Unready returns why the slave is not ready to serve: the master stopped
+// sending heartbeats, or one of its shards is syncing or lags the peers.
+func (s *SlaveBackend) Unready() []string {
+	reasons := make([]string, 0)
+	if elapsed := time.Since(s.ctx.GetTimestamp()); elapsed > heartbeatTimeout {
+		reasons = append(reasons, fmt.Sprintf("missed heartbeat of the master for %v", elapsed.Round(time.Second)))
+	}
+	for _, id := range s.GetFullShardList() {
+		if shard, ok := s.getShard(id); ok {
+			reasons = append(reasons, shard.Unready(s.clstrCfg.Readiness.MaxShardTipLag)...)
+		}
+	}
+	return reasons
+}
@@ -19,6 +19,11 @@ func (s *ConnManager) SendMinorBlockHeaderToMaster(request *rpc.AddMinorBlockHea
 	if s.masterClient.target == "" {
 		return errors.New("master endpoint is empty")
 	}
+	// the headers of a standby are sent by the active slave of the shard
+	if s.slave.IsStandby(request.MinorBlockHeader.Branch.Value) {
+		return nil
+	}
+	request.SlaveID = s.slave.config.ID
 
 	data, err := serialize.SerializeToBytes(request)
 	if err != nil {
@@ -39,6 +44,10 @@ func (s *ConnManager) SendMinorBlockHeaderToMaster(request *rpc.AddMinorBlockHea
 }
 
 func (s *ConnManager) SendMinorBlockHeaderListToMaster(request *rpc.AddMinorBlockHeaderListRequest) error {
+	if len(request.MinorBlockHeaderList) > 0 && s.slave.IsStandby(request.MinorBlockHeaderList[0].Branch.Value) {
+		return nil
+	}
+	request.SlaveID = s.slave.config.ID
 	data, err := serialize.SerializeToBytes(request)
 	if err != nil {
 		return err
@@ -50,7 +59,12 @@ func (s *ConnManager) SendMinorBlockHeaderListToMaster(request *rpc.AddMinorBloc
 	return err
 }
 
+// BroadcastNewTip sends the tip of a shard to the peers through the master,
+// the tips of a standby of the shard are left to the active slave of the shard.
 func (s *ConnManager) BroadcastNewTip(mHeaderLst []*types.MinorBlockHeader, rHeader *types.RootBlockHeader, branch uint32) error {
+	if s.slave.IsStandby(branch) {
+		return nil
+	}
 	gReq := rpc.BroadcastNewTip{MinorBlockHeaderList: mHeaderLst, RootBlockHeader: rHeader, Branch: branch}
 	data, err := serialize.SerializeToBytes(gReq)
 	if err != nil {
@@ -61,6 +75,9 @@ func (s *ConnManager) BroadcastNewTip(mHeaderLst []*types.MinorBlockHeader, rHea
 }
 
 func (s *ConnManager) BroadcastTransactions(peerId string, branch uint32, txs []*types.Transaction) error {
+	if s.slave.IsStandby(branch) {
+		return nil
+	}
 	raw, err := serialize.SerializeToBytes(&p2p.NewTransactionList{TransactionList: txs})
 	if err != nil {
 		return err
@@ -78,6 +95,9 @@ func (s *ConnManager) BroadcastMinorBlock(peerId string, minorBlock *types.Minor
 	if minorBlock == nil {
 		return errors.New("block is nil or branch mismatch")
 	}
+	if s.slave.IsStandby(minorBlock.Branch().Value) {
+		return nil
+	}
 	var (
 		gReq = rpc.P2PRedirectRequest{PeerID: peerId, Branch: minorBlock.Branch().Value}
 		err  error
@@ -122,6 +142,62 @@ func (s *ConnManager) GetMinorBlocks(mHeaderList []common.Hash, peerId string, b
 	return gRep.MinorBlockList, nil
 }
 
+func (s *ConnManager) GetMinorStateNodes(hashList []common.Hash, peerId string, branch uint32) ([][]byte, error) {
+	var (
+		gReq = rpc.P2PRedirectRequest{PeerID: peerId, Branch: branch}
+		gRep p2p.GetMinorStateNodeListResponse
+		err  error
+	)
+	gReq.Data, err = serialize.SerializeToBytes(p2p.GetMinorStateNodeListRequest{NodeHashList: hashList})
+	if err != nil {
+		return nil, err
+	}
+	data, err := serialize.SerializeToBytes(gReq)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.masterClient.client.Call(s.masterClient.target, &rpc.Request{Op: rpc.OpGetMinorStateNodeList, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serialize.DeserializeFromBytes(res.Data, &gRep); err != nil {
+		return nil, err
+	}
+	nodes := make([][]byte, 0, len(gRep.NodeList))
+	for _, node := range gRep.NodeList {
+		nodes = append(nodes, node.Data)
+	}
+	return nodes, nil
+}
+
+func (s *ConnManager) GetCrossShardTxLists(mHashList []common.Hash, peerId string, branch uint32) ([]*p2p.CrossShardTxList, error) {
+	var (
+		gReq = rpc.P2PRedirectRequest{PeerID: peerId, Branch: branch}
+		gRep p2p.GetCrossShardTxListResponse
+		err  error
+	)
+	gReq.Data, err = serialize.SerializeToBytes(p2p.GetCrossShardTxListRequest{MinorBlockHashList: mHashList})
+	if err != nil {
+		return nil, err
+	}
+	data, err := serialize.SerializeToBytes(gReq)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.masterClient.client.Call(s.masterClient.target, &rpc.Request{Op: rpc.OpGetCrossShardTxList, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serialize.DeserializeFromBytes(res.Data, &gRep); err != nil {
+		return nil, err
+	}
+	return gRep.CrossShardTxList, nil
+}
+
 func (s *ConnManager) GetMinorBlockHeaderList(gReq *rpc.GetMinorBlockHeaderListWithSkipRequest) ([]*types.MinorBlockHeader, error) {
 	var (
 		gRep p2p.GetMinorBlockHeaderListResponse
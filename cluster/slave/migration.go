@@ -0,0 +1,420 @@
+package slave
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/cluster/shard"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// shardDataChunkSize is the size of the chunks of a shard database sent to
+	// the slave the shard moves to.
+	shardDataChunkSize = 1 << 20
+	// shardImportRoundSize is how many bytes of a shard database a round of the
+	// copy copies at most, so that a round is done within the rpc timeout.
+	shardImportRoundSize = 256 << 20
+)
+
+// shardExport is a shard whose database is copied by the slave it moves to. The
+// shard runs during the first rounds of the copy and is stopped for the last
+// one. The cross-shard deposits to the stopped shard are kept until the move
+// completes, and forwarded to the other slave from then on.
+type shardExport struct {
+	dir      string
+	stopped  bool
+	deposits []*rpc.AddXshardTxListRequest
+	movedTo  *SlaveConn
+}
+
+func (s *SlaveBackend) shardDir(fullShardId uint32) (string, error) {
+	dir := s.ctx.ResolvePath(fmt.Sprintf("shard-%d/db", fullShardId))
+	if dir == "" {
+		return "", errors.New("shard databases are kept in memory")
+	}
+	return dir, nil
+}
+
+// ExportShard serves the database of a shard to the slave it moves to while the
+// shard keeps running, or with stop stops the shard for the last round of the
+// copy.
+func (s *SlaveBackend) ExportShard(fullShardId uint32, stop bool) error {
+	dir, err := s.shardDir(fullShardId)
+	if err != nil {
+		return err
+	}
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+	shrd, ok := s.getShard(fullShardId)
+	if !ok {
+		return fmt.Errorf("shard %x is not run by slave %s", fullShardId, s.config.ID)
+	}
+	export, ok := s.exports[fullShardId]
+	if !ok {
+		export = &shardExport{dir: dir}
+		s.exports[fullShardId] = export
+	}
+	if !stop {
+		log.Info("Exporting shard", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId))
+		return nil
+	}
+	export.stopped = true
+	s.removeShard(fullShardId)
+	shrd.Stop()
+	log.Info("Stopped shard to migrate it", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId))
+	return nil
+}
+
+// CancelExport stops serving the database of a shard which failed to be copied,
+// and runs the shard again with the deposits received meanwhile if it was
+// stopped.
+func (s *SlaveBackend) CancelExport(fullShardId uint32, rootTip *types.RootBlock) error {
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+	export, ok := s.exports[fullShardId]
+	if !ok || export.movedTo != nil {
+		return fmt.Errorf("shard %x is not being exported", fullShardId)
+	}
+	if !export.stopped {
+		delete(s.exports, fullShardId)
+		log.Info("Stopped exporting shard", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId))
+		return nil
+	}
+	shrd, err := s.openShard(fullShardId, rootTip)
+	if err != nil {
+		return err
+	}
+	delete(s.exports, fullShardId)
+	for _, req := range export.deposits {
+		shrd.MinorBlockChain.AddCrossShardTxListByMinorBlockHash(req.MinorBlockHash, types.CrossShardTransactionDepositList{TXList: req.TxList})
+	}
+	log.Info("Resumed shard after a failed migration", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId))
+	return nil
+}
+
+// GetShardData returns the files of the database of a shard being exported, or
+// a chunk of one of them.
+func (s *SlaveBackend) GetShardData(req *rpc.GetShardDataRequest) (*rpc.GetShardDataResponse, error) {
+	s.exportMu.Lock()
+	export, ok := s.exports[req.Branch]
+	s.exportMu.Unlock()
+	if !ok || export.movedTo != nil {
+		return nil, fmt.Errorf("shard %x is not being exported", req.Branch)
+	}
+
+	rsp := new(rpc.GetShardDataResponse)
+	if req.File == "" {
+		infos, err := ioutil.ReadDir(export.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if info.Mode().IsRegular() {
+				rsp.Files = append(rsp.Files, &rpc.ShardDataFile{Name: info.Name(), Size: uint64(info.Size())})
+			}
+		}
+		return rsp, nil
+	}
+	if !isShardDataFile(req.File) {
+		return nil, fmt.Errorf("invalid shard data file %q", req.File)
+	}
+	f, err := os.Open(filepath.Join(export.dir, req.File))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := make([]byte, shardDataChunkSize)
+	n, err := f.ReadAt(data, int64(req.Offset))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	rsp.Data = data[:n]
+	return rsp, nil
+}
+
+// ImportShard copies a round of the database of a shard from the slave at the
+// source. The first rounds copy the database while the shard runs on the
+// source, the final one completes it once the shard is stopped there and runs
+// the shard from the root tip.
+func (s *SlaveBackend) ImportShard(req *rpc.ImportShardRequest) (*rpc.ImportShardResponse, error) {
+	dir, err := s.shardDir(req.Branch)
+	if err != nil {
+		return nil, err
+	}
+	// the database is only moved in place once fully copied
+	tmp := dir + ".import"
+	if req.Cancel {
+		s.exportMu.Lock()
+		delete(s.imports, req.Branch)
+		s.exportMu.Unlock()
+		return new(rpc.ImportShardResponse), os.RemoveAll(tmp)
+	}
+	if s.clstrCfg.Clean {
+		return nil, errors.New("shards cannot be imported by a slave cleaning its databases")
+	}
+	if _, ok := s.getShard(req.Branch); ok {
+		return nil, fmt.Errorf("shard %x is already run by slave %s", req.Branch, s.config.ID)
+	}
+	conn, ok := s.connManager.getSlaveConn(req.Source)
+	if !ok {
+		return nil, fmt.Errorf("no connection to slave %s", req.Source)
+	}
+
+	// what an earlier import copied is dropped
+	s.exportMu.Lock()
+	source, ok := s.imports[req.Branch]
+	s.imports[req.Branch] = req.Source
+	s.exportMu.Unlock()
+	if !ok || source != req.Source {
+		if err := os.RemoveAll(tmp); err != nil {
+			return nil, err
+		}
+	}
+	rsp, err := syncShardData(conn, req.Branch, tmp, req.Final)
+	if err != nil || !req.Final {
+		return rsp, err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+	if _, err := s.openShard(req.Branch, req.RootTip); err != nil {
+		return nil, err
+	}
+	s.exportMu.Lock()
+	delete(s.imports, req.Branch)
+	// the shard may come back from a slave it was moved to
+	delete(s.exports, req.Branch)
+	s.exportMu.Unlock()
+	log.Info("Imported shard", "slave", s.config.ID, "branch", fmt.Sprintf("%x", req.Branch), "source", req.Source)
+	return rsp, nil
+}
+
+// ShardMoved sends the messages to a shard to the slave it moved to. The slave
+// it moved from forwards it the deposits it kept and removes its database.
+func (s *SlaveBackend) ShardMoved(req *rpc.ShardMovedRequest) error {
+	to, err := s.connManager.MoveShard(req.Branch, req.From, req.Target)
+	if err != nil {
+		return err
+	}
+	if req.From == s.config.ID || req.To == s.config.ID {
+		s.saveFullShardList()
+	}
+	if req.From != s.config.ID {
+		return nil
+	}
+
+	s.exportMu.Lock()
+	export, ok := s.exports[req.Branch]
+	if !ok || export.movedTo != nil {
+		s.exportMu.Unlock()
+		return nil
+	}
+	export.movedTo = to
+	deposits := export.deposits
+	export.deposits = nil
+	s.exportMu.Unlock()
+
+	if len(deposits) > 0 {
+		if err := to.BatchAddXshardTxList(deposits); err != nil {
+			log.Error("Failed to forward deposits to a moved shard", "branch", fmt.Sprintf("%x", req.Branch), "slave", req.To, "err", err)
+		}
+	}
+	if err := os.RemoveAll(export.dir); err != nil {
+		return err
+	}
+	log.Info("Retired shard moved to another slave", "slave", s.config.ID, "branch", fmt.Sprintf("%x", req.Branch), "to", req.To)
+	return nil
+}
+
+// saveFullShardList writes the shards of the slave to the cluster config file,
+// so that the slave runs the shards moved to it once restarted.
+func (s *SlaveBackend) saveFullShardList() {
+	if s.clstrCfg.ConfigFile == "" {
+		return
+	}
+	s.lock.RLock()
+	lists := map[string][]uint32{s.config.ID: s.config.FullShardList}
+	s.lock.RUnlock()
+	if err := config.UpdateFullShardLists(s.clstrCfg.ConfigFile, lists); err != nil {
+		log.Error("Failed to save the shards of the slave", "slave", s.config.ID, "file", s.clstrCfg.ConfigFile, "err", err)
+	}
+}
+
+// keepMovedDeposits keeps the deposits to a shard stopped to be moved to
+// another slave, or forwards them to it once moved. It returns false if the
+// shard still runs on the slave.
+func (s *SlaveBackend) keepMovedDeposits(req *rpc.AddXshardTxListRequest) bool {
+	s.exportMu.Lock()
+	export, ok := s.exports[req.Branch]
+	if !ok || !export.stopped {
+		s.exportMu.Unlock()
+		return false
+	}
+	to := export.movedTo
+	if to == nil {
+		export.deposits = append(export.deposits, req)
+	}
+	s.exportMu.Unlock()
+
+	if to != nil {
+		if err := to.AddXshardTxList(req); err != nil {
+			log.Error("Failed to forward deposits to a moved shard", "branch", fmt.Sprintf("%x", req.Branch), "err", err)
+		}
+	}
+	return true
+}
+
+// openShard runs a shard from its database on disk.
+func (s *SlaveBackend) openShard(fullShardId uint32, rootTip *types.RootBlock) (*shard.ShardBackend, error) {
+	shrd, err := shard.New(s.ctx, rootTip, s.connManager, s.clstrCfg, fullShardId)
+	if err != nil {
+		return nil, err
+	}
+	if err := shrd.InitFromRootBlock(rootTip); err != nil {
+		shrd.Stop()
+		return nil, err
+	}
+	// new shards do not mine, and the miner cannot be stopped before it starts
+	s.roleMu.Lock()
+	delete(s.standby, fullShardId)
+	if s.isMining(fullShardId) {
+		shrd.SetMining(true)
+	}
+	s.roleMu.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.shards[fullShardId] = shrd
+	s.fullShardList = withShard(s.fullShardList, fullShardId, true)
+	s.config.FullShardList = withShard(s.config.FullShardList, fullShardId, true)
+	return shrd, nil
+}
+
+func (s *SlaveBackend) removeShard(fullShardId uint32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.shards, fullShardId)
+	s.fullShardList = withShard(s.fullShardList, fullShardId, false)
+	s.config.FullShardList = withShard(s.config.FullShardList, fullShardId, false)
+}
+
+func withShard(fullShardList []uint32, fullShardId uint32, with bool) []uint32 {
+	list := make([]uint32, 0, len(fullShardList)+1)
+	for _, id := range fullShardList {
+		if id != fullShardId {
+			list = append(list, id)
+		}
+	}
+	if with {
+		list = append(list, fullShardId)
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+	return list
+}
+
+func isShardDataFile(name string) bool {
+	return name != "." && name != ".." && filepath.Base(name) == name
+}
+
+// isShardTableFile tells whether a file of a shard database is a table, which
+// is never modified once written.
+func isShardTableFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".ldb" || ext == ".sst"
+}
+
+// syncShardData copies to dir the files of the database of a shard which
+// changed on the slave exporting it. While the shard runs there, only the
+// tables are copied, at most shardImportRoundSize bytes of them, and those
+// removed meanwhile by a compaction are skipped. The final copy is done once
+// the shard stopped, it copies the other files too and removes the files no
+// longer in the database.
+func syncShardData(conn *SlaveConn, fullShardId uint32, dir string, final bool) (*rpc.ImportShardResponse, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	list, err := conn.GetShardData(&rpc.GetShardDataRequest{Branch: fullShardId})
+	if err != nil {
+		return nil, err
+	}
+	rsp := new(rpc.ImportShardResponse)
+	files := make(map[string]bool, len(list.Files))
+	for _, file := range list.Files {
+		if !isShardDataFile(file.Name) {
+			return nil, fmt.Errorf("invalid shard data file %q", file.Name)
+		}
+		files[file.Name] = true
+		rsp.Total += file.Size
+		path := filepath.Join(dir, file.Name)
+		if isShardTableFile(file.Name) {
+			if info, err := os.Stat(path); err == nil && uint64(info.Size()) == file.Size {
+				continue
+			}
+		} else if !final {
+			continue
+		}
+		if !final && rsp.Copied >= shardImportRoundSize {
+			rsp.Pending += file.Size
+			continue
+		}
+		if err := copyShardDataFile(conn, fullShardId, file, path); err != nil {
+			if final {
+				return nil, err
+			}
+			log.Debug("Skipped shard data file", "branch", fmt.Sprintf("%x", fullShardId), "file", file.Name, "err", err)
+			os.Remove(path)
+			continue
+		}
+		rsp.Copied += file.Size
+	}
+	if !final {
+		return rsp, nil
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if !files[info.Name()] {
+			if err := os.RemoveAll(filepath.Join(dir, info.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rsp, nil
+}
+
+func copyShardDataFile(conn *SlaveConn, fullShardId uint32, file *rpc.ShardDataFile, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for offset := uint64(0); offset < file.Size; {
+		rsp, err := conn.GetShardData(&rpc.GetShardDataRequest{Branch: fullShardId, File: file.Name, Offset: offset})
+		if err != nil {
+			return err
+		}
+		if len(rsp.Data) == 0 {
+			return fmt.Errorf("shard data file %s truncated at %d", file.Name, offset)
+		}
+		if _, err := f.Write(rsp.Data); err != nil {
+			return err
+		}
+		offset += uint64(len(rsp.Data))
+	}
+	return f.Sync()
+}
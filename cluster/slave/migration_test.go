@@ -0,0 +1,75 @@
+package slave
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	qrpc "github.com/QuarkChain/goquarkchain/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncShardData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shard-migration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		src   = filepath.Join(dir, "src")
+		dst   = filepath.Join(dir, "dst")
+		files = map[string][]byte{
+			"000001.log": bytes.Repeat([]byte{1}, 2*shardDataChunkSize+10),
+			"000002.ldb": bytes.Repeat([]byte{2}, shardDataChunkSize+10),
+			"CURRENT":    []byte("MANIFEST-000000\n"),
+			"LOCK":       {},
+		}
+		source = &SlaveBackend{exports: map[uint32]*shardExport{1: {dir: src}}}
+	)
+	assert.NoError(t, os.MkdirAll(src, 0700))
+	for name, data := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(src, name), data, 0600))
+	}
+	// left by an earlier copy and no longer in the database
+	assert.NoError(t, os.MkdirAll(dst, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dst, "000000.ldb"), []byte{0}, 0600))
+
+	srv, err := rpc.StartInProcServer("127.0.0.1:38999", []qrpc.API{{Namespace: "grpc", Service: NewServerSideOp(source)}})
+	assert.NoError(t, err)
+	defer srv.Stop()
+	conn := NewToSlaveConn("127.0.0.1:38999", "S0", []uint32{1})
+
+	// only the tables are copied while the shard runs
+	rsp, err := syncShardData(conn, 1, dst, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(files["000002.ldb"])), rsp.Copied)
+	assert.Equal(t, uint64(0), rsp.Pending)
+	_, err = os.Stat(filepath.Join(dst, "CURRENT"))
+	assert.True(t, os.IsNotExist(err))
+	rsp, err = syncShardData(conn, 1, dst, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), rsp.Copied)
+
+	rsp, err = syncShardData(conn, 1, dst, true)
+	assert.NoError(t, err)
+	assert.Equal(t, rsp.Total-uint64(len(files["000002.ldb"])), rsp.Copied)
+	for name, data := range files {
+		copied, err := ioutil.ReadFile(filepath.Join(dst, name))
+		assert.NoError(t, err)
+		assert.Equal(t, data, copied)
+	}
+	_, err = os.Stat(filepath.Join(dst, "000000.ldb"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = conn.GetShardData(&rpc.GetShardDataRequest{Branch: 1, File: "../src/CURRENT"})
+	assert.Error(t, err)
+	_, err = syncShardData(conn, 2, dst, true)
+	assert.Error(t, err)
+
+	// the data of the shard is no longer served once moved
+	source.exports[1].movedTo = conn
+	_, err = syncShardData(conn, 1, dst, true)
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package slave
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	"github.com/QuarkChain/goquarkchain/serialize"
@@ -12,7 +14,8 @@ import (
 type SlaveConn struct {
 	target        string
 	id            string
-	chainMaskList []uint32
+	mu            sync.RWMutex
+	chainMaskList []uint32 // replaced when shards move, under mu
 	client        rpc.Client
 }
 
@@ -27,7 +30,7 @@ func NewToSlaveConn(target, id string, chainMaskList []uint32) *SlaveConn {
 
 func (s *SlaveConn) SendPing() bool {
 	var (
-		gReq = rpc.Ping{Id: []byte(s.id), FullShardList: s.chainMaskList}
+		gReq = rpc.Ping{Id: []byte(s.id), FullShardList: s.getChainMaskList()}
 		gRes rpc.Pong
 		err  error
 	)
@@ -52,7 +55,7 @@ func (s *SlaveConn) SendPing() bool {
 	}
 
 	if !s.EqualChainMask(gRes.FullShardList) {
-		log.Error("Chain_mask_list doesn't match", "target list", s.chainMaskList, "actual list", gRes.FullShardList)
+		log.Error("Chain_mask_list doesn't match", "target list", s.getChainMaskList(), "actual list", gRes.FullShardList)
 		return false
 	}
 
@@ -80,11 +83,36 @@ func (s *SlaveConn) BatchAddXshardTxList(xshardReqs []*rpc.AddXshardTxListReques
 	return err
 }
 
+// GetShardData returns the files of the database of a shard being exported by
+// the slave, or a chunk of one of them.
+func (s *SlaveConn) GetShardData(req *rpc.GetShardDataRequest) (*rpc.GetShardDataResponse, error) {
+	bytes, err := serialize.SerializeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Call(s.target, &rpc.Request{Op: rpc.OpGetShardData, Data: bytes})
+	if err != nil {
+		return nil, err
+	}
+	rsp := new(rpc.GetShardDataResponse)
+	if err = serialize.DeserializeFromBytes(res.Data, rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (s *SlaveConn) getChainMaskList() []uint32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chainMaskList
+}
+
 func (s *SlaveConn) EqualChainMask(chainMask []uint32) bool {
-	if len(chainMask) != len(s.chainMaskList) {
+	chainMaskList := s.getChainMaskList()
+	if len(chainMask) != len(chainMaskList) {
 		return false
 	}
-	for i, id := range s.chainMaskList {
+	for i, id := range chainMaskList {
 		if chainMask[i] != id {
 			return false
 		}
@@ -92,8 +120,35 @@ func (s *SlaveConn) EqualChainMask(chainMask []uint32) bool {
 	return true
 }
 
-func (s *SlaveConn) HasShard(fullshardId uint32) bool {
+func (s *SlaveConn) addShard(fullShardId uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hasShard(s.chainMaskList, fullShardId) {
+		return
+	}
+	chainMaskList := append(append([]uint32{}, s.chainMaskList...), fullShardId)
+	sort.Slice(chainMaskList, func(i, j int) bool { return chainMaskList[i] < chainMaskList[j] })
+	s.chainMaskList = chainMaskList
+}
+
+func (s *SlaveConn) removeShard(fullShardId uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chainMaskList := make([]uint32, 0, len(s.chainMaskList))
 	for _, id := range s.chainMaskList {
+		if id != fullShardId {
+			chainMaskList = append(chainMaskList, id)
+		}
+	}
+	s.chainMaskList = chainMaskList
+}
+
+func (s *SlaveConn) HasShard(fullshardId uint32) bool {
+	return hasShard(s.getChainMaskList(), fullshardId)
+}
+
+func hasShard(chainMaskList []uint32, fullshardId uint32) bool {
+	for _, id := range chainMaskList {
 		if id == fullshardId {
 			return true
 		}
@@ -31,9 +31,20 @@ func NewServerSideOp(slave *SlaveBackend) *SlaveServerSideOp {
 
 func (s *SlaveServerSideOp) HeartBeat(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
 	s.slave.ctx.SetTimestamp(time.Now())
-	if len(s.slave.shards) == 0 {
+	if len(s.slave.getShards()) == 0 {
 		return nil, errors.New("shards uninitialized")
 	}
+	// the master tells the slave which shards it is the active slave of with
+	// the heartbeats
+	if len(req.Data) > 0 {
+		var fullShardIds []uint32
+		if err := serialize.DeserializeFromBytes(req.Data, &fullShardIds); err != nil {
+			return nil, err
+		}
+		s.slave.setActiveShards(fullShardIds)
+	}
+	// with its roles up to date, the slave mines its active shards again
+	s.slave.setFenced(false)
 	return &rpc.Response{}, nil
 }
 
@@ -236,7 +247,7 @@ func (s *SlaveServerSideOp) ExecuteTransaction(ctx context.Context, req *rpc.Req
 	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
 		return nil, err
 	}
-	if gRes.Result, err = s.slave.ExecuteTx(gReq.Tx, gReq.FromAddress, gReq.BlockHeight); err != nil {
+	if gRes.Result, err = s.slave.ExecuteTx(gReq.Tx, gReq.FromAddress, gReq.BlockHeight, gReq.StateOverrides); err != nil {
 		return nil, err
 	}
 
@@ -343,7 +354,7 @@ func (s *SlaveServerSideOp) EstimateGas(ctx context.Context, req *rpc.Request) (
 		return nil, err
 	}
 
-	if gRes.Result, err = s.slave.EstimateGas(gReq.Tx, gReq.FromAddress); err != nil {
+	if gRes.Result, err = s.slave.EstimateGas(gReq.Tx, gReq.FromAddress, gReq.StateOverrides); err != nil {
 		return nil, err
 	}
 
@@ -518,6 +529,244 @@ func (s *SlaveServerSideOp) GetRootChainStakes(ctx context.Context, req *rpc.Req
 	return response, nil
 }
 
+func (s *SlaveServerSideOp) TraceTransaction(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.TraceTransactionRequest
+		gRes     rpc.TraceResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes.Result, err = s.slave.TraceTransaction(gReq.TxHash, gReq.Branch, gReq.Config); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) TraceMinorBlock(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.TraceMinorBlockRequest
+		gRes     rpc.TraceResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes.Result, err = s.slave.TraceMinorBlock(gReq.MinorBlockHash, gReq.Branch, gReq.Config); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetProof(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.GetProofRequest
+		gRes     *rpc.AccountProof
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes, err = s.slave.GetProof(gReq.Address, gReq.StorageKeys, gReq.BlockHeight); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetPendingTransactionHashes(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.GetPendingTransactionHashesRequest
+		gRes     rpc.GetPendingTransactionHashesResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes.TxHashes, err = s.slave.GetPendingTransactionHashes(gReq.Branch); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetXShardDepositStatus(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.GetXShardDepositStatusRequest
+		gRes     *rpc.XShardDepositStatus
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes, err = s.slave.GetXShardDepositStatus(gReq.TxHash, gReq.Branch, gReq.MinorBlockHash, gReq.RootBlockHash); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetFeeHistory(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.GetFeeHistoryRequest
+		gRes     *rpc.FeeHistory
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes, err = s.slave.GetFeeHistory(gReq.Branch, gReq.TokenID, gReq.BlockCount, gReq.Percentiles); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetTxPoolContent(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.GetTxPoolContentRequest
+		gRes     rpc.GetTxPoolContentResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes.Shards, err = s.slave.GetTxPoolContent(gReq.Branch, gReq.Sender, gReq.CountOnly); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) ExportShard(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.ExportShardRequest
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gReq.Cancel {
+		err = s.slave.CancelExport(gReq.Branch, gReq.RootTip)
+	} else {
+		err = s.slave.ExportShard(gReq.Branch, gReq.Stop)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) ImportShard(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.ImportShardRequest
+		gRes     *rpc.ImportShardResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes, err = s.slave.ImportShard(&gReq); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) ShardMoved(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.ShardMovedRequest
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if err = s.slave.ShardMoved(&gReq); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetShardData(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.GetShardDataRequest
+		gRes     *rpc.GetShardDataResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes, err = s.slave.GetShardData(&gReq); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) SyncShardState(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.SyncShardStateRequest
+		gRes     *rpc.SyncShardStateResponse
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if gRes, err = s.slave.SyncShardState(gReq.Branch, gReq.PeerId); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) PeerDisconnected(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		peerID   string
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &peerID); err != nil {
+		return nil, err
+	}
+	s.slave.PeerDisconnected(peerID)
+	return response, nil
+}
+
 // check if the blocks are vailed.
 func (s *SlaveServerSideOp) AddMinorBlockListForSync(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
 	var (
@@ -532,7 +781,7 @@ func (s *SlaveServerSideOp) AddMinorBlockListForSync(ctx context.Context, req *r
 	if len(gReq.MinorBlockHashList) == 0 {
 		return response, nil
 	}
-	if gRes.ShardStatus, err = s.slave.AddBlockListForSync(gReq.MinorBlockHashList, gReq.PeerId, gReq.Branch); err != nil {
+	if gRes.ShardStatus, err = s.slave.AddBlockListForSync(gReq.MinorBlockHashList, gReq.PeerId, gReq.Branch, gReq.FastSync); err != nil {
 		return nil, err
 	}
 	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
@@ -695,7 +944,7 @@ func (s *SlaveServerSideOp) AddTransactions(ctx context.Context, req *rpc.Reques
 
 	var (
 		txList       = make(map[uint32][]*types.Transaction)
-		fullShardIds = s.slave.fullShardList
+		fullShardIds = s.slave.GetFullShardList()
 	)
 
 	for _, tx := range txs.TransactionList {
@@ -751,6 +1000,57 @@ func (s *SlaveServerSideOp) HandleNewMinorBlock(ctx context.Context, req *rpc.Re
 	return &rpc.Response{}, nil
 }
 
+func (s *SlaveServerSideOp) GetMinorStateNodeList(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.P2PRedirectRequest
+		gRes     p2p.GetMinorStateNodeListResponse
+		hashList p2p.GetMinorStateNodeListRequest
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if err = serialize.DeserializeFromBytes(gReq.Data, &hashList); err != nil {
+		return nil, err
+	}
+	nodeList, err := s.slave.GetMinorStateNodeList(hashList.NodeHashList, gReq.Branch)
+	if err != nil {
+		return nil, err
+	}
+	gRes.NodeList = make([]*p2p.StateNode, 0, len(nodeList))
+	for _, node := range nodeList {
+		gRes.NodeList = append(gRes.NodeList, &p2p.StateNode{Data: node})
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *SlaveServerSideOp) GetCrossShardTxList(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
+	var (
+		gReq     rpc.P2PRedirectRequest
+		gRes     p2p.GetCrossShardTxListResponse
+		hashList p2p.GetCrossShardTxListRequest
+		response = &rpc.Response{RpcId: req.RpcId}
+		err      error
+	)
+	if err = serialize.DeserializeFromBytes(req.Data, &gReq); err != nil {
+		return nil, err
+	}
+	if err = serialize.DeserializeFromBytes(gReq.Data, &hashList); err != nil {
+		return nil, err
+	}
+	if gRes.CrossShardTxList, err = s.slave.GetCrossShardTxList(hashList.MinorBlockHashList, gReq.Branch); err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (s *SlaveServerSideOp) SetMining(ctx context.Context, req *rpc.Request) (*rpc.Response, error) {
 	var (
 		mining   bool
@@ -773,5 +1073,12 @@ func (s *SlaveServerSideOp) CheckMinorBlocksInRoot(ctx context.Context, req *rpc
 	if err = serialize.DeserializeFromBytes(req.Data, &rootBlock); err != nil {
 		return nil, err
 	}
-	return response, s.slave.CheckMinorBlocksInRoot(&rootBlock)
+	gRes, err := s.slave.CheckMinorBlocksInRoot(&rootBlock)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data, err = serialize.SerializeToBytes(gRes); err != nil {
+		return nil, err
+	}
+	return response, nil
 }
@@ -0,0 +1,40 @@
+package slave
+
+import (
+	"fmt"
+
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// OpenShardForRestore stops a shard which has no block but its genesis yet and
+// opens its database, for a state snapshot to be written into it. The caller
+// closes the database and runs the shard again with RunRestoredShard.
+func (s *SlaveBackend) OpenShardForRestore(fullShardId uint32) (ethdb.Database, error) {
+	if _, err := s.shardDir(fullShardId); err != nil {
+		return nil, err
+	}
+	shrd, ok := s.getShard(fullShardId)
+	if !ok {
+		return nil, fmt.Errorf("shard %x is not run by slave %s", fullShardId, s.config.ID)
+	}
+	if number := shrd.MinorBlockChain.CurrentBlock().NumberU64(); number != 0 {
+		return nil, fmt.Errorf("shard %x of slave %s has %d blocks already", fullShardId, s.config.ID, number)
+	}
+	s.removeShard(fullShardId)
+	shrd.Stop()
+	return s.ctx.OpenDatabase(fmt.Sprintf("shard-%d/db", fullShardId), false, false)
+}
+
+// RunRestoredShard runs again a shard whose database was restored from a state
+// snapshot, from the root block the snapshot was taken at.
+func (s *SlaveBackend) RunRestoredShard(fullShardId uint32, rootTip *types.RootBlock) error {
+	shrd, err := s.openShard(fullShardId, rootTip)
+	if err != nil {
+		return err
+	}
+	log.Info("Restored shard", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId),
+		"number", shrd.MinorBlockChain.CurrentBlock().NumberU64(), "rootNumber", rootTip.NumberU64())
+	return nil
+}
@@ -0,0 +1,81 @@
+package slave
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// IsStandby reports whether the slave is a standby of the shard not promoted
+// yet, which imports the blocks of the shard without mining or broadcasting them.
+func (s *SlaveBackend) IsStandby(fullShardId uint32) bool {
+	s.roleMu.Lock()
+	defer s.roleMu.Unlock()
+	return s.standby[fullShardId]
+}
+
+// setActiveShards applies the roles given by the master: the slave is a
+// standby of its shards but the given ones, a promoted standby starts mining
+// the shard if the master asked the slaves to.
+func (s *SlaveBackend) setActiveShards(fullShardIds []uint32) {
+	active := make(map[uint32]bool, len(fullShardIds))
+	for _, fullShardId := range fullShardIds {
+		active[fullShardId] = true
+	}
+	s.roleMu.Lock()
+	defer s.roleMu.Unlock()
+	for fullShardId, shrd := range s.getShards() {
+		standby := !active[fullShardId]
+		if s.standby[fullShardId] == standby {
+			continue
+		}
+		if standby {
+			s.standby[fullShardId] = true
+			log.Warn("Slave is now a standby of the shard", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId))
+		} else {
+			delete(s.standby, fullShardId)
+			log.Warn("Standby slave promoted by the master", "slave", s.config.ID, "branch", fmt.Sprintf("%x", fullShardId), "mining", s.mining)
+		}
+		shrd.SetMining(s.isMining(fullShardId))
+	}
+}
+
+// isMining returns whether the slave mines the shard, the caller holds roleMu.
+func (s *SlaveBackend) isMining(fullShardId uint32) bool {
+	return s.mining && !s.standby[fullShardId] && !s.fenced
+}
+
+// fenceLoop stops mining the shards while the slave misses the heartbeats of
+// the master, which fails the slave over to the standby slaves meanwhile, so
+// that a shard is not mined by two slaves. Mining resumes with the heartbeats.
+func (s *SlaveBackend) fenceLoop() {
+	ticker := time.NewTicker(config.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.setFenced(time.Since(s.ctx.GetTimestamp()) > heartbeatTimeout)
+		case <-s.exitCh:
+			return
+		}
+	}
+}
+
+func (s *SlaveBackend) setFenced(fenced bool) {
+	s.roleMu.Lock()
+	defer s.roleMu.Unlock()
+	if s.fenced == fenced {
+		return
+	}
+	s.fenced = fenced
+	if fenced {
+		log.Warn("Slave missed the heartbeats of the master, mining stopped", "slave", s.config.ID)
+	} else {
+		log.Warn("Slave receives the heartbeats of the master again", "slave", s.config.ID, "mining", s.mining)
+	}
+	for fullShardId, shrd := range s.getShards() {
+		shrd.SetMining(s.isMining(fullShardId))
+	}
+}
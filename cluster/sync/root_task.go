@@ -20,23 +20,39 @@ type rootSyncerPeer interface {
 	PeerID() string
 }
 
+// FastSyncPivotDistance is the number of root blocks below the tip of the peer
+// from which the shards execute the minor blocks again when fast syncing. The
+// peers still keep the states of the shards at that height.
+const FastSyncPivotDistance = 16
+
+// MaxStateSyncIdlePeriods is the number of calls downloading the state of a
+// shard without any progress after which the peer is given up.
+const MaxStateSyncIdlePeriods = 3
+
 // All of the sync tasks to are to catch up with the root chain from peers.
 type rootChainTask struct {
 	task
-	header     *types.RootBlockHeader
-	peer       rootSyncerPeer
-	stats      *BlockSychronizerStats
-	statusChan chan *rpc.ShardStatus
-	slaveConns rpc.ConnManager
+	header      *types.RootBlockHeader
+	peer        rootSyncerPeer
+	stats       *BlockSychronizerStats
+	statusChan  chan *rpc.ShardStatus
+	slaveConns  rpc.ConnManager
+	fastSync    bool
+	stateSynced bool
+	trusted     []common.Hash // fast synced blocks whose PoSW stakes are trusted
 }
 
-// NewRootChainTask returns a sync task for root chain.
+// NewRootChainTask returns a sync task for root chain. If fastSync is set, the
+// shards add the minor blocks confirmed by the root blocks far below the tip of
+// the peer without executing them, and download their states from the peer
+// before executing the next ones.
 func NewRootChainTask(
 	p rootSyncerPeer,
 	header *types.RootBlockHeader,
 	stats *BlockSychronizerStats,
 	statusChan chan *rpc.ShardStatus,
 	slaveConns rpc.ConnManager,
+	fastSync bool,
 ) Task {
 	rTask := &rootChainTask{
 		peer:       p,
@@ -44,6 +60,7 @@ func NewRootChainTask(
 		stats:      stats,
 		statusChan: statusChan,
 		slaveConns: slaveConns,
+		fastSync:   fastSync,
 	}
 	rTask.task = task{
 		name:             "root",
@@ -119,7 +136,18 @@ func NewRootChainTask(
 		syncBlock: func(bc blockchain, block types.IBlock) error {
 			rb := block.(*types.RootBlock)
 			rbc := bc.(rootblockchain)
-			return rTask.syncMinorBlocks(rbc, rb)
+			if !rTask.fastSync {
+				return rTask.syncMinorBlocks(rbc, rb, false)
+			}
+			fast := rb.Number()+FastSyncPivotDistance <= rTask.header.Number
+			if fast {
+				// the PoSW stakes of the root chain are in the states of the shards
+				rTask.trusted = append(rTask.trusted, rb.Hash())
+				rbc.SetPoSWStakesTrusted([]common.Hash{rb.Hash()}, true)
+			} else if err := rTask.syncShardStates(); err != nil {
+				return err
+			}
+			return rTask.syncMinorBlocks(rbc, rb, fast)
 		},
 		needSkip: func(b blockchain) bool {
 			if rTask.header.GetTotalDifficulty().Cmp(b.CurrentHeader().GetTotalDifficulty()) <= 0 {
@@ -131,6 +159,18 @@ func NewRootChainTask(
 	return rTask
 }
 
+// Run syncs the root chain with the peer, the PoSW stakes of the blocks fast
+// synced are no longer trusted once it returns, whether they were added or not.
+func (r *rootChainTask) Run(bc blockchain) error {
+	defer func() {
+		if len(r.trusted) > 0 {
+			bc.(rootblockchain).SetPoSWStakesTrusted(r.trusted, false)
+			r.trusted = nil
+		}
+	}()
+	return r.task.Run(bc)
+}
+
 func (r *rootChainTask) Priority() *big.Int {
 	return r.header.GetTotalDifficulty()
 }
@@ -236,6 +276,7 @@ func (r *rootChainTask) findAncestor(bc blockchain) (*types.RootBlockHeader, err
 func (r *rootChainTask) syncMinorBlocks(
 	rbc rootblockchain,
 	rootBlock *types.RootBlock,
+	fastSync bool,
 ) error {
 	downloadMap := make(map[uint32][]common.Hash)
 	for _, header := range rootBlock.MinorBlockHeaders() {
@@ -252,7 +293,7 @@ func (r *rootChainTask) syncMinorBlocks(
 		}
 		// TODO Support to multiple connections
 		g.Go(func() error {
-			status, err := conns[0].AddBlockListForSync(&rpc.AddBlockListForSyncRequest{Branch: b, PeerId: r.PeerID(), MinorBlockHashList: hashList})
+			status, err := conns[0].AddBlockListForSync(&rpc.AddBlockListForSyncRequest{Branch: b, PeerId: r.PeerID(), MinorBlockHashList: hashList, FastSync: fastSync})
 			if err == nil {
 				r.statusChan <- status
 			}
@@ -271,3 +312,48 @@ func (r *rootChainTask) syncMinorBlocks(
 	//}
 	return nil
 }
+
+// syncShardStates downloads from the peer the states of the shards which added
+// minor blocks without executing them, so that they execute the next ones. It
+// gives up, for another peer to be tried, once a shard downloaded nothing for
+// MaxStateSyncIdlePeriods calls.
+func (r *rootChainTask) syncShardStates() error {
+	if r.stateSynced {
+		return nil
+	}
+	branches := make(map[uint32]struct{})
+	for _, conn := range r.slaveConns.GetSlaveConns() {
+		for _, branch := range conn.GetFullShardList() {
+			branches[branch] = struct{}{}
+		}
+	}
+
+	var g errgroup.Group
+	for branch := range branches {
+		b := branch
+		conns := r.slaveConns.GetSlaveConnsById(b)
+		if len(conns) == 0 {
+			return fmt.Errorf("shard connection for branch %d is missing", b)
+		}
+		g.Go(func() error {
+			var progress uint64
+			for idle := 0; idle < MaxStateSyncIdlePeriods; {
+				res, err := conns[0].SyncShardState(&rpc.SyncShardStateRequest{Branch: b, PeerId: r.PeerID()})
+				if err != nil || res.Done {
+					return err
+				}
+				if res.Progress > progress {
+					progress, idle = res.Progress, 0
+				} else {
+					idle++
+				}
+			}
+			return fmt.Errorf("no progress syncing the state of shard %d from peer %s", b, r.PeerID())
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	r.stateSynced = true
+	return nil
+}
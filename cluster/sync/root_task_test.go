@@ -169,6 +169,10 @@ func (bc *mockblockchain) IsMinorBlockValidated(hash common.Hash) bool {
 	return bc.rbc.IsMinorBlockValidated(hash)
 }
 
+func (bc *mockblockchain) SetPoSWStakesTrusted(hashes []common.Hash, trusted bool) {
+	bc.rbc.SetPoSWStakesTrusted(hashes, trusted)
+}
+
 type mockvalidator struct {
 	err error
 }
@@ -218,7 +222,7 @@ func TestRootChainTask(t *testing.T) {
 	retRBlocks, retRHeaders := makeRootChains(rbc.GetBlockByNumber(0).(*types.RootBlock), 20, false)
 
 	// No error if already have the target block.
-	var rt = NewRootChainTask(p, bc.CurrentHeader().(*types.RootBlockHeader), &BlockSychronizerStats{}, nil, nil)
+	var rt = NewRootChainTask(p, bc.CurrentHeader().(*types.RootBlockHeader), &BlockSychronizerStats{}, nil, nil, false)
 	rTask := rt.(*rootChainTask)
 	assert.NoError(t, rt.Run(bc))
 
@@ -340,9 +344,9 @@ func TestSyncMinorBlocks(t *testing.T) {
 			conn.(*mock_master.MockISlaveConn).EXPECT().AddBlockListForSync(gomock.Any()).DoAndReturn(AddBlockListForSyncFunc).Times(1)
 		}
 
-		var rt = NewRootChainTask(&mockpeer{name: "chunfeng"}, nil, nil, statusChan, shardConns)
+		var rt = NewRootChainTask(&mockpeer{name: "chunfeng"}, nil, nil, statusChan, shardConns, false)
 		rTask := rt.(*rootChainTask)
-		err := rTask.syncMinorBlocks(bc.(rootblockchain), block)
+		err := rTask.syncMinorBlocks(bc.(rootblockchain), block, false)
 		assert.NoError(t, err)
 
 		select {
@@ -360,6 +364,34 @@ func TestSyncMinorBlocks(t *testing.T) {
 	}
 }
 
+func TestSyncShardStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shardConns := newFakeConnManager(1, ctrl)
+	conn := shardConns.GetSlaveConns()[0].(*mock_master.MockISlaveConn)
+	conn.EXPECT().GetShardMaskList().Return([]uint32{1}).AnyTimes()
+	newTask := func() *rootChainTask {
+		return NewRootChainTask(&mockpeer{name: "chunfeng"}, nil, nil, nil, shardConns, true).(*rootChainTask)
+	}
+
+	// the state is downloaded as long as the shard makes progress
+	gomock.InOrder(
+		conn.EXPECT().SyncShardState(gomock.Any()).Return(&rpc.SyncShardStateResponse{Progress: 10}, nil),
+		conn.EXPECT().SyncShardState(gomock.Any()).Return(&rpc.SyncShardStateResponse{Progress: 10}, nil).Times(MaxStateSyncIdlePeriods-1),
+		conn.EXPECT().SyncShardState(gomock.Any()).Return(&rpc.SyncShardStateResponse{Progress: 20}, nil),
+		conn.EXPECT().SyncShardState(gomock.Any()).Return(&rpc.SyncShardStateResponse{Done: true, Progress: 20}, nil),
+	)
+	rTask := newTask()
+	assert.NoError(t, rTask.syncShardStates())
+	assert.True(t, rTask.stateSynced)
+
+	// and the peer is given up once it stops sending any
+	conn.EXPECT().SyncShardState(gomock.Any()).Return(&rpc.SyncShardStateResponse{}, nil).Times(MaxStateSyncIdlePeriods)
+	rTask = newTask()
+	assert.Error(t, rTask.syncShardStates())
+	assert.False(t, rTask.stateSynced)
+}
+
 type fakeConnManager struct {
 	conns []rpc.ISlaveConn
 }
@@ -23,6 +23,7 @@ type rootblockchain interface {
 	blockchain
 	AddValidatedMinorBlockHeader(common.Hash, *types.TokenBalances)
 	IsMinorBlockValidated(common.Hash) bool
+	SetPoSWStakesTrusted([]common.Hash, bool)
 }
 
 // Synchronizer will sync blocks for the master server when receiving new root blocks from peers.
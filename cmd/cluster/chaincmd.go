@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/QuarkChain/goquarkchain/cluster/master"
+	"github.com/QuarkChain/goquarkchain/cluster/service"
+	"github.com/QuarkChain/goquarkchain/cluster/slave"
+	"github.com/QuarkChain/goquarkchain/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	chainFlags = []cli.Flag{
+		ClusterConfigFlag,
+		utils.DataDirFlag,
+		utils.DbPathRootFlag,
+		utils.GenesisDirFlag,
+		utils.NetworkIdFlag,
+		utils.CacheFlag,
+	}
+
+	importCommand = cli.Command{
+		Action:    utils.MigrateFlags(importChain),
+		Name:      "import",
+		Usage:     "Import the root and shard chains from an archive",
+		ArgsUsage: "<filename>",
+		Flags:     chainFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import command replays an archive written by the export command on the
+master and the slaves of the cluster config, which are run in this process.
+The minor blocks confirmed by each root block are added to their shards
+before it. The archive is read gzip-compressed if the file ends with .gz.`,
+	}
+	exportCommand = cli.Command{
+		Action:    utils.MigrateFlags(exportChain),
+		Name:      "export",
+		Usage:     "Export the root and shard chains into an archive",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Flags:     chainFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The export command writes the root blocks of the cluster, each one followed
+by the minor blocks it confirms with their cross-shard deposits, into a
+versioned archive. The master and the slaves of the cluster config are run
+in this process and must not be running elsewhere. Optional first and last
+root block numbers can be given to export a part of the chain. The archive
+is gzip-compressed if the file ends with .gz.`,
+	}
+	snapshotCommand = cli.Command{
+		Action:    utils.MigrateFlags(snapshotChain),
+		Name:      "snapshot",
+		Usage:     "Write a state snapshot of the cluster",
+		ArgsUsage: "<filename> [<rootBlockNum>]",
+		Flags:     chainFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The snapshot command writes the root blocks of the cluster up to the given
+root block, the tip by default, then for each shard the state trie at the
+last minor block this root block confirms, with the recent blocks and their
+receipts, and the cross-shard deposits not processed yet. The master and the
+slaves of the cluster config are run in this process and must not be running
+elsewhere. The snapshot is gzip-compressed if the file ends with .gz.`,
+	}
+	bootstrapCommand = cli.Command{
+		Action:    utils.MigrateFlags(bootstrapChain),
+		Name:      "bootstrap",
+		Usage:     "Bootstrap a new cluster from a state snapshot",
+		ArgsUsage: "<filename>",
+		Flags:     chainFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The bootstrap command restores a state snapshot written by the snapshot
+command into a cluster with no block but its genesis ones, instead of syncing
+the chain from the start. The shards are checked against the minor block
+headers confirmed by the root blocks of the snapshot. The master and the
+slaves of the cluster config are run in this process, and the cluster is
+started as usual afterwards.`,
+	}
+)
+
+func importChain(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	mstr, slaves, stop := startChainCluster(ctx)
+	defer stop()
+
+	// the databases are closed before an error is reported
+	if err := utils.ImportChain(mstr, slaves, ctx.Args().First()); err != nil {
+		return fmt.Errorf("Import error: %v", err)
+	}
+	return nil
+}
+
+func exportChain(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 && len(ctx.Args()) != 3 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	mstr, slaves, stop := startChainCluster(ctx)
+	defer stop()
+
+	first, last := uint64(0), mstr.CurrentBlock().NumberU64()
+	if len(ctx.Args()) == 3 {
+		var ferr, lerr error
+		first, ferr = strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		last, lerr = strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+		if ferr != nil || lerr != nil {
+			utils.Fatalf("Export error in parsing parameters: block number not an integer")
+		}
+	}
+	if err := utils.ExportChain(mstr, slaves, ctx.Args().First(), first, last); err != nil {
+		return fmt.Errorf("Export error: %v", err)
+	}
+	return nil
+}
+
+func snapshotChain(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 && len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	mstr, slaves, stop := startChainCluster(ctx)
+	defer stop()
+
+	number := mstr.CurrentBlock().NumberU64()
+	if len(ctx.Args()) == 2 {
+		var err error
+		if number, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			utils.Fatalf("Snapshot error in parsing parameters: block number not an integer")
+		}
+	}
+	if err := utils.SnapshotChain(mstr, slaves, ctx.Args().First(), number); err != nil {
+		return fmt.Errorf("Snapshot error: %v", err)
+	}
+	return nil
+}
+
+func bootstrapChain(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	mstr, slaves, stop := startChainCluster(ctx)
+	defer stop()
+
+	if err := utils.BootstrapChain(mstr, slaves, ctx.Args().First()); err != nil {
+		return fmt.Errorf("Bootstrap error: %v", err)
+	}
+	return nil
+}
+
+// startChainCluster runs the master and the slaves of the cluster config in
+// this process, as --single_process does, but without the public RPC, the p2p
+// network nor mining. The databases are never cleaned, whatever the config.
+// The returned function stops them.
+func startChainCluster(ctx *cli.Context) (*master.QKCMasterBackend, []*slave.SlaveBackend, func()) {
+	ctx.GlobalSet(utils.SingleProcessFlag.Name, "true")
+	ctx.GlobalSet(utils.RPCDisabledFlag.Name, "true")
+	ctx.GlobalSet(utils.CleanFlag.Name, "false")
+
+	node, cfg := makeFullNode(ctx, clientIdentifier)
+	var (
+		stacks = make([]*service.Node, 0, len(cfg.Cluster.SlaveList))
+		slaves = make([]*slave.SlaveBackend, 0, len(cfg.Cluster.SlaveList))
+	)
+	for _, slv := range cfg.Cluster.SlaveList {
+		stack, _ := makeFullNode(ctx, slv.ID)
+		if err := stack.Start(); err != nil {
+			utils.Fatalf("Error starting slave: %v", err)
+		}
+		var backend *slave.SlaveBackend
+		if err := stack.Service(&backend); err != nil {
+			utils.Fatalf("slave service not running %v", err)
+		}
+		stacks = append(stacks, stack)
+		slaves = append(slaves, backend)
+	}
+	if err := node.Start(); err != nil {
+		utils.Fatalf("Error starting master: %v", err)
+	}
+	var mstr *master.QKCMasterBackend
+	if err := node.Service(&mstr); err != nil {
+		utils.Fatalf("master service not running %v", err)
+	}
+
+	stop := func() {
+		if err := node.Stop(); err != nil {
+			log.Error("Failed to stop master", "err", err)
+		}
+		for _, stack := range stacks {
+			if err := stack.Stop(); err != nil {
+				log.Error("Failed to stop slave", "err", err)
+			}
+		}
+	}
+	return mstr, slaves, stop
+}
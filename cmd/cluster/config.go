@@ -11,10 +11,13 @@ import (
 	"unicode"
 
 	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/monitoring"
+	qkcrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
 	"github.com/QuarkChain/goquarkchain/cluster/service"
 	"github.com/QuarkChain/goquarkchain/cmd/utils"
 	"github.com/QuarkChain/goquarkchain/core/vm"
 	"github.com/QuarkChain/goquarkchain/params"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/naoina/toml"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -64,7 +67,7 @@ func defaultNodeConfig() service.Config {
 	return cfg
 }
 
-func makeConfigNode(ctx *cli.Context) (*service.Node, qkcConfig) {
+func makeConfigNode(ctx *cli.Context, serviceName string) (*service.Node, qkcConfig) {
 	// Load defaults.
 	cfg := qkcConfig{
 		Cluster: *config.NewClusterConfig(),
@@ -76,21 +79,38 @@ func makeConfigNode(ctx *cli.Context) (*service.Node, qkcConfig) {
 		if err := loadConfig(file, &cfg.Cluster); err != nil {
 			utils.Fatalf("%v", err)
 		}
+		cfg.Cluster.ConfigFile = file
 		if err := cfg.Cluster.BackWardChainMaskList(); err != nil {
 			utils.Fatalf("%v", err)
 		}
+		if cfg.Cluster.GasPriceOracle != nil {
+			if err := cfg.Cluster.GasPriceOracle.Validate(); err != nil {
+				utils.Fatalf("%v", err)
+			}
+		}
 	}
 
 	utils.SetClusterConfig(ctx, &cfg.Cluster)
 
-	ServiceName := ctx.GlobalString(utils.ServiceFlag.Name)
-	if ServiceName != clientIdentifier {
-		slv, err := cfg.Cluster.GetSlaveConfig(ServiceName)
+	// load the credentials of the gRPC links before the endpoint is set below
+	ownTLS := cfg.Cluster.Master.TLS
+	if serviceName != clientIdentifier {
+		if slv, err := cfg.Cluster.GetSlaveConfig(serviceName); err == nil {
+			ownTLS = slv.TLS
+		}
+	}
+	grpcTLS, err := qkcrpc.NewTLS(ownTLS, &cfg.Cluster)
+	if err != nil {
+		utils.Fatalf("Failed to load gRPC TLS config: %v", err)
+	}
+	qkcrpc.SetTLS(grpcTLS)
+	if serviceName != clientIdentifier {
+		slv, err := cfg.Cluster.GetSlaveConfig(serviceName)
 		if err != nil {
 			utils.Fatalf("service type error: %v", err)
 		}
 		// set slave name and grpc endpoint
-		cfg.Service.Name = ServiceName
+		cfg.Service.Name = serviceName
 		cfg.Cluster.Quarkchain.GRPCHost = slv.IP
 		cfg.Cluster.Quarkchain.GRPCPort = slv.Port
 
@@ -111,12 +131,31 @@ func makeConfigNode(ctx *cli.Context) (*service.Node, qkcConfig) {
 		if err := config.UpdateGenesisAlloc(&cfg.Cluster); err != nil {
 			utils.Fatalf("Update genesis alloc err: %v", err)
 		}
+	} else if ctx.GlobalBool(utils.WSEnableFlag.Name) {
+		// set master websocket endpoint
+		ip, port := cfg.Cluster.JSONRPCHOST, cfg.Cluster.WSJSONRPCPort
+		if ctx.GlobalIsSet(utils.WSRPCHostFlag.Name) {
+			ip = ctx.GlobalString(utils.WSRPCHostFlag.Name)
+		}
+		if ctx.GlobalIsSet(utils.WSRPCPortFlag.Name) {
+			port = uint16(ctx.GlobalInt(utils.WSRPCPortFlag.Name))
+		}
+		cfg.Service.WSEndpoint = fmt.Sprintf("%s:%d", ip, port)
+	}
+	// set metrics and health endpoints, the slaves listen next to the master
+	if ctx.GlobalBool(utils.MetricsEnabledFlag.Name) {
+		port := servicePort(ctx.GlobalInt(utils.MetricsPortFlag.Name), serviceName)
+		cfg.Service.MetricsEndpoint = fmt.Sprintf("%s:%d", ctx.GlobalString(utils.MetricsHostFlag.Name), port)
+	}
+	if ctx.GlobalBool(utils.HealthEnableFlag.Name) {
+		port := servicePort(ctx.GlobalInt(utils.HealthPortFlag.Name), serviceName)
+		cfg.Service.HealthEndpoint = fmt.Sprintf("%s:%d", ctx.GlobalString(utils.HealthHostFlag.Name), port)
 	}
 	// Load default cluster config.
 	utils.SetNodeConfig(ctx, &cfg.Service, &cfg.Cluster)
 
 	stack, err := service.New(&cfg.Service)
-	stack.SetIsMaster(ServiceName == clientIdentifier)
+	stack.SetIsMaster(serviceName == clientIdentifier)
 	if err != nil {
 		utils.Fatalf("Failed to create the protocol stack: %v", err)
 	}
@@ -147,8 +186,27 @@ func makeConfigNode(ctx *cli.Context) (*service.Node, qkcConfig) {
 	return stack, cfg
 }
 
-func makeFullNode(ctx *cli.Context) *service.Node {
-	stack, cfg := makeConfigNode(ctx)
+// servicePort returns the port of the service given the port of the master,
+// slave Si listens on the port plus 1+i.
+func servicePort(port int, serviceName string) int {
+	if serviceName == clientIdentifier {
+		return port
+	}
+	sufPort, _ := strconv.Atoi(serviceName[1:])
+	return port + 1 + sufPort
+}
+
+func makeFullNode(ctx *cli.Context, serviceName string) (*service.Node, qkcConfig) {
+	stack, cfg := makeConfigNode(ctx, serviceName)
+
+	// post the mined blocks, the block propagation and the error logs to kafka,
+	// a single process cluster posts them as its master
+	if monitoring.Default() == nil {
+		if sink := monitoring.New(cfg.Cluster.Monitoring, cfg.Service.Name); sink != nil {
+			monitoring.SetDefault(sink)
+			log.Root().SetHandler(log.MultiHandler(log.Root().GetHandler(), sink.ErrorHandler()))
+		}
+	}
 
 	if !stack.IsMaster() {
 		for _, slv := range cfg.Cluster.SlaveList {
@@ -161,5 +219,5 @@ func makeFullNode(ctx *cli.Context) *service.Node {
 		utils.RegisterMasterService(stack, &cfg.Cluster)
 	}
 
-	return stack
+	return stack, cfg
 }
@@ -8,14 +8,17 @@ import (
 	godebug "runtime/debug"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/QuarkChain/goquarkchain/cluster/master"
+	"github.com/QuarkChain/goquarkchain/cluster/monitoring"
 	"github.com/QuarkChain/goquarkchain/cluster/service"
 	"github.com/QuarkChain/goquarkchain/cluster/slave"
 	"github.com/QuarkChain/goquarkchain/cmd/utils"
 	"github.com/QuarkChain/goquarkchain/internal/debug"
 	"github.com/elastic/gosigar"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -31,6 +34,7 @@ var (
 	usageFlags = []cli.Flag{
 		ClusterConfigFlag,
 		utils.ServiceFlag,
+		utils.SingleProcessFlag,
 		utils.DataDirFlag,
 		utils.LogLevelFlag,
 		utils.CleanFlag,
@@ -45,6 +49,8 @@ var (
 		utils.CheckDBRBlockFromFlag,
 		utils.CheckDBRBlockToFlag,
 		utils.CheckDBRBlockBatchFlag,
+		utils.CheckDBReportFlag,
+		utils.CheckDBRegenerateRangeFlag,
 
 		utils.EnableTransactionHistoryFlag,
 		utils.MaxPeersFlag,
@@ -52,6 +58,7 @@ var (
 		utils.UpnpFlag,
 		utils.PrivkeyFlag,
 		utils.GCModeFlag,
+		utils.FastSyncFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -60,6 +67,7 @@ var (
 		utils.RPCPortFlag,
 		utils.PrivateRPCListenAddrFlag,
 		utils.PrivateRPCPortFlag,
+		utils.PrivateRPCJWTSecretFlag,
 		utils.IPCEnableFlag,
 		utils.IPCPathFlag,
 		utils.GRPCAddrFlag,
@@ -67,6 +75,12 @@ var (
 		utils.WSEnableFlag,
 		utils.WSRPCHostFlag,
 		utils.WSRPCPortFlag,
+		utils.MetricsEnabledFlag,
+		utils.MetricsHostFlag,
+		utils.MetricsPortFlag,
+		utils.HealthEnableFlag,
+		utils.HealthHostFlag,
+		utils.HealthPortFlag,
 	}
 )
 
@@ -74,7 +88,12 @@ func init() {
 	// Initialize the CLI app and start Geth
 	app.Action = cluster
 	app.HideVersion = true // we have a command to print the version
-	app.Commands = []cli.Command{}
+	app.Commands = []cli.Command{
+		importCommand,
+		exportCommand,
+		snapshotCommand,
+		bootstrapCommand,
+	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
 	app.Flags = append(app.Flags, debug.Flags...)
@@ -127,9 +146,39 @@ func cluster(ctx *cli.Context) error {
 	if args := ctx.Args(); len(args) > 0 {
 		return fmt.Errorf("invalid command: %q", args[0])
 	}
-	node := makeFullNode(ctx)
+	if ctx.GlobalBool(utils.SingleProcessFlag.Name) {
+		return singleProcessCluster(ctx)
+	}
+	node, _ := makeFullNode(ctx, ctx.GlobalString(utils.ServiceFlag.Name))
+	startService(ctx, node)
+	node.Wait()
+	monitoring.Default().Stop()
+	return nil
+}
+
+// singleProcessCluster runs the master and all the slaves of the cluster config
+// in this process, which call each other in memory instead of over gRPC. The
+// slaves are started before the master connects to them and stopped after it.
+func singleProcessCluster(ctx *cli.Context) error {
+	node, cfg := makeFullNode(ctx, clientIdentifier)
+	slaves := make([]*service.Node, 0, len(cfg.Cluster.SlaveList))
+	for _, slv := range cfg.Cluster.SlaveList {
+		stack, _ := makeFullNode(ctx, slv.ID)
+		slaves = append(slaves, stack)
+	}
+	for _, stack := range slaves {
+		if err := stack.Start(); err != nil {
+			utils.Fatalf("Error starting slave: %v", err)
+		}
+	}
 	startService(ctx, node)
 	node.Wait()
+	for _, stack := range slaves {
+		if err := stack.Stop(); err != nil {
+			log.Error("Failed to stop slave", "err", err)
+		}
+	}
+	monitoring.Default().Stop()
 	return nil
 }
 
@@ -138,6 +187,9 @@ func cluster(ctx *cli.Context) error {
 // miner.
 func startService(ctx *cli.Context, stack *service.Node) {
 	debug.Memsize.Add("service", stack)
+	if metrics.Enabled {
+		go metrics.CollectProcessMetrics(3 * time.Second)
+	}
 
 	// Start up the node itself
 	utils.StartService(stack)
@@ -148,7 +200,16 @@ func startService(ctx *cli.Context, stack *service.Node) {
 			utils.Fatalf("master service not running %v", err)
 		}
 		if master.GetClusterConfig().CheckDB {
-			master.CheckDB()
+			report, err := master.CheckDB()
+			if err != nil {
+				utils.Fatalf("Failed to check db: %v", err)
+			}
+			if err := report.Write(master.GetClusterConfig().CheckDBReport); err != nil {
+				utils.Fatalf("Failed to write db check report: %v", err)
+			}
+			if !report.Consistent() {
+				os.Exit(1)
+			}
 			os.Exit(0)
 			return
 		}
@@ -48,6 +48,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "QUARKCHAIN",
 		Flags: []cli.Flag{
 			utils.ServiceFlag,
+			utils.SingleProcessFlag,
 			utils.DataDirFlag,
 			ClusterConfigFlag,
 			utils.LogLevelFlag,
@@ -64,7 +65,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.CheckDBRBlockFromFlag,
 			utils.CheckDBRBlockToFlag,
 			utils.CheckDBRBlockBatchFlag,
+			utils.CheckDBReportFlag,
+			utils.CheckDBRegenerateRangeFlag,
 			utils.GCModeFlag,
+			utils.FastSyncFlag,
 		},
 	},
 	{
@@ -91,6 +95,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.PrivateRPCListenAddrFlag,
 			utils.PrivateRPCPortFlag,
+			utils.PrivateRPCJWTSecretFlag,
 		},
 	},
 	{
@@ -101,6 +106,17 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSRPCPortFlag,
 		},
 	},
+	{
+		Name: "METRICS AND HEALTH",
+		Flags: []cli.Flag{
+			utils.MetricsEnabledFlag,
+			utils.MetricsHostFlag,
+			utils.MetricsPortFlag,
+			utils.HealthEnableFlag,
+			utils.HealthHostFlag,
+			utils.HealthPortFlag,
+		},
+	},
 	{
 		Name: "IPC API",
 		Flags: []cli.Flag{
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxArchiveRecordSize bounds the records read from an archive.
+const maxArchiveRecordSize = 1 << 30
+
+// archiveFormat identifies a kind of archive: its files start with the magic,
+// followed by the version, then by a header record.
+type archiveFormat struct {
+	name    string
+	magic   string
+	version uint32
+}
+
+var (
+	// chainArchive is the format of the archives written by ExportChain.
+	chainArchive = archiveFormat{name: "chain archive", magic: "QKCA", version: 1}
+	// snapshotArchive is the format of the state snapshots written by SnapshotChain.
+	snapshotArchive = archiveFormat{name: "state snapshot", magic: "QKCS", version: 2}
+)
+
+// archiveHeader is the first record of a chain archive.
+type archiveHeader struct {
+	GenesisHash common.Hash
+}
+
+// archiveRootBlock is a root block of a chain archive, followed by the minor
+// blocks it confirms.
+type archiveRootBlock struct {
+	Block       *types.RootBlock
+	MinorBlocks []*archiveMinorBlock `bytesizeofslicelen:"4"`
+}
+
+// archiveMinorBlock is a minor block of a chain archive with the cross-shard
+// deposits it made to the other shards.
+type archiveMinorBlock struct {
+	Block    *types.MinorBlock
+	Deposits []*rpc.AddXshardTxListRequest `bytesizeofslicelen:"4"`
+}
+
+// archiveWriter writes the records of an archive, gzip-compressed when the
+// file name ends with .gz.
+type archiveWriter struct {
+	file *os.File
+	gz   *gzip.Writer
+	w    *bufio.Writer
+}
+
+func newArchiveWriter(fn string, format archiveFormat, header interface{}) (*archiveWriter, error) {
+	file, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	a := &archiveWriter{file: file}
+	if strings.HasSuffix(fn, ".gz") {
+		a.gz = gzip.NewWriter(file)
+		a.w = bufio.NewWriter(a.gz)
+	} else {
+		a.w = bufio.NewWriter(file)
+	}
+
+	var version [4]byte
+	binary.BigEndian.PutUint32(version[:], format.version)
+	if _, err := a.w.Write(append([]byte(format.magic), version[:]...)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := a.writeRecord(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *archiveWriter) writeRecord(val interface{}) error {
+	data, err := serialize.SerializeToBytes(val)
+	if err != nil {
+		return err
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := a.w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = a.w.Write(data)
+	return err
+}
+
+// Close flushes the archive and closes its file.
+func (a *archiveWriter) Close() error {
+	err := a.w.Flush()
+	if a.gz != nil {
+		if gerr := a.gz.Close(); err == nil {
+			err = gerr
+		}
+	}
+	if ferr := a.file.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// archiveReader reads the records of an archive written by archiveWriter.
+type archiveReader struct {
+	file *os.File
+	gz   *gzip.Reader
+	r    *bufio.Reader
+}
+
+// newArchiveReader opens an archive of the given format and reads its header.
+func newArchiveReader(fn string, format archiveFormat, header interface{}) (*archiveReader, error) {
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	a := &archiveReader{file: file}
+	if strings.HasSuffix(fn, ".gz") {
+		if a.gz, err = gzip.NewReader(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+		a.r = bufio.NewReader(a.gz)
+	} else {
+		a.r = bufio.NewReader(file)
+	}
+
+	prefix := make([]byte, len(format.magic)+4)
+	if _, err := io.ReadFull(a.r, prefix); err != nil || string(prefix[:len(format.magic)]) != format.magic {
+		a.Close()
+		return nil, fmt.Errorf("%s is not a %s", fn, format.name)
+	}
+	if version := binary.BigEndian.Uint32(prefix[len(format.magic):]); version != format.version {
+		a.Close()
+		return nil, fmt.Errorf("unsupported %s version %d, expected %d", format.name, version, format.version)
+	}
+	if err := a.readRecord(header); err != nil {
+		a.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// readRecord reads the next record of the archive into val, it returns io.EOF
+// at the end of the archive.
+func (a *archiveReader) readRecord(val interface{}) error {
+	var size [4]byte
+	if _, err := io.ReadFull(a.r, size[:]); err != nil {
+		return err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxArchiveRecordSize {
+		return fmt.Errorf("archive record too large: %d bytes", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(a.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return serialize.DeserializeFromBytes(data, val)
+}
+
+// Close closes the file of the archive.
+func (a *archiveReader) Close() error {
+	if a.gz != nil {
+		a.gz.Close()
+	}
+	return a.file.Close()
+}
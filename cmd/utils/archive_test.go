@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain-archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mBlock := types.NewMinorBlockWithHeader(&types.MinorBlockHeader{Number: 3, Branch: account.Branch{Value: 1}}, &types.MinorBlockMeta{})
+	record := &archiveRootBlock{
+		Block: types.NewRootBlockWithHeader(&types.RootBlockHeader{Number: 2}),
+		MinorBlocks: []*archiveMinorBlock{{
+			Block: mBlock,
+			Deposits: []*rpc.AddXshardTxListRequest{{
+				Branch:         65537,
+				MinorBlockHash: mBlock.Hash(),
+				TxList:         []*types.CrossShardTransactionDeposit{},
+			}},
+		}},
+	}
+	genesisHash := common.HexToHash("0x01")
+
+	for _, name := range []string{"chain.qkc", "chain.qkc.gz"} {
+		fn := filepath.Join(dir, name)
+		w, err := newArchiveWriter(fn, chainArchive, &archiveHeader{GenesisHash: genesisHash})
+		assert.NoError(t, err)
+		assert.NoError(t, w.writeRecord(record))
+		assert.NoError(t, w.writeRecord(record))
+		assert.NoError(t, w.Close())
+
+		header := new(archiveHeader)
+		r, err := newArchiveReader(fn, chainArchive, header)
+		assert.NoError(t, err)
+		assert.Equal(t, genesisHash, header.GenesisHash)
+		for i := 0; i < 2; i++ {
+			read := new(archiveRootBlock)
+			assert.NoError(t, r.readRecord(read))
+			assert.Equal(t, record.Block.Hash(), read.Block.Hash())
+			assert.Equal(t, 1, len(read.MinorBlocks))
+			assert.Equal(t, mBlock.Hash(), read.MinorBlocks[0].Block.Hash())
+			assert.Equal(t, record.MinorBlocks[0].Deposits, read.MinorBlocks[0].Deposits)
+		}
+		assert.Equal(t, io.EOF, r.readRecord(new(archiveRootBlock)))
+		assert.NoError(t, r.Close())
+	}
+
+	// archives of another version are rejected
+	fn := filepath.Join(dir, "chain.qkc")
+	data, err := ioutil.ReadFile(fn)
+	assert.NoError(t, err)
+	data[len(chainArchive.magic)+3]++
+	assert.NoError(t, ioutil.WriteFile(fn, data, 0600))
+	_, err = newArchiveReader(fn, chainArchive, new(archiveHeader))
+	assert.Error(t, err)
+
+	// and so are the archives of another format
+	_, err = newArchiveReader(filepath.Join(dir, "chain.qkc.gz"), snapshotArchive, new(archiveHeader))
+	assert.Error(t, err)
+}
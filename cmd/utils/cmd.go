@@ -2,17 +2,34 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/QuarkChain/goquarkchain/cluster/master"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	"github.com/QuarkChain/goquarkchain/cluster/service"
+	"github.com/QuarkChain/goquarkchain/cluster/shard"
+	"github.com/QuarkChain/goquarkchain/cluster/slave"
+	"github.com/QuarkChain/goquarkchain/core/types"
 	"github.com/QuarkChain/goquarkchain/internal/debug"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"io"
 	"os"
 	"os/signal"
 	"runtime"
+	"sort"
 	"syscall"
+	"time"
 )
 
+// importBatchSize is how many minor blocks of a shard are added at once.
+const importBatchSize = 100
+
+// statsReportLimit is the time after which the progress of an import or an
+// export is logged.
+const statsReportLimit = 8 * time.Second
+
 // Fatalf formats a message to standard error and exits the program.
 // The message is also printed to standard output if standard error
 // is redirected to a different file.
@@ -53,3 +70,229 @@ func StartService(stack *service.Node) {
 		debug.LoudPanic("boom")
 	}()
 }
+
+// ExportChain writes the root blocks from first to last of the cluster to the
+// archive fn, each one followed by the minor blocks it confirms and their
+// cross-shard deposits. The slaves must run all the shards of the cluster.
+func ExportChain(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, fn string, first, last uint64) error {
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	log.Info("Exporting blockchain", "file", fn, "first", first, "last", last)
+	genesisNumber := uint64(0)
+	genesis, _, err := mstr.GetRootBlockByNumber(&genesisNumber, false)
+	if err != nil {
+		return err
+	}
+	w, err := newArchiveWriter(fn, chainArchive, &archiveHeader{GenesisHash: genesis.Hash()})
+	if err != nil {
+		return err
+	}
+
+	var (
+		shards          = clusterShards(slaves)
+		start, reported = time.Now(), time.Now()
+	)
+	for nr := first; nr <= last; nr++ {
+		nr := nr
+		rBlock, _, err := mstr.GetRootBlockByNumber(&nr, false)
+		if err != nil {
+			w.Close()
+			return fmt.Errorf("export failed on #%d: %v", nr, err)
+		}
+		record := &archiveRootBlock{Block: rBlock, MinorBlocks: make([]*archiveMinorBlock, 0, len(rBlock.MinorBlockHeaders()))}
+		for _, header := range rBlock.MinorBlockHeaders() {
+			mBlock, err := exportMinorBlock(shards, header)
+			if err != nil {
+				w.Close()
+				return err
+			}
+			record.MinorBlocks = append(record.MinorBlocks, mBlock)
+		}
+		if err := w.writeRecord(record); err != nil {
+			w.Close()
+			return err
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Exporting blocks", "exported", nr-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain", "file", fn, "blocks", last-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func exportMinorBlock(shards map[uint32][]*shard.ShardBackend, header *types.MinorBlockHeader) (*archiveMinorBlock, error) {
+	var (
+		hash   = header.Hash()
+		branch = header.Branch.Value
+	)
+	if len(shards[branch]) == 0 {
+		return nil, fmt.Errorf("export failed: shard %x is not run by the slaves", branch)
+	}
+	block := shards[branch][0].MinorBlockChain.GetMinorBlock(hash)
+	if block == nil {
+		return nil, fmt.Errorf("export failed: minor block %x of shard %x not found", hash, branch)
+	}
+	record := &archiveMinorBlock{Block: block, Deposits: make([]*rpc.AddXshardTxListRequest, 0)}
+	for _, fullShardId := range sortedShardIds(shards) {
+		if fullShardId == branch {
+			continue
+		}
+		if list := shards[fullShardId][0].MinorBlockChain.ReadCrossShardTxList(hash); list != nil {
+			record.Deposits = append(record.Deposits, &rpc.AddXshardTxListRequest{Branch: fullShardId, MinorBlockHash: hash, TxList: list.TXList})
+		}
+	}
+	return record, nil
+}
+
+// ImportChain replays the archive fn written by ExportChain on the cluster.
+// The minor blocks confirmed by each root block are added to their shards
+// before it, as the root block is only valid once they are. The cross-shard
+// deposits they make are checked against the ones of the archive.
+func ImportChain(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, fn string) error {
+	log.Info("Importing blockchain", "file", fn)
+	header := new(archiveHeader)
+	r, err := newArchiveReader(fn, chainArchive, header)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	genesisNumber := uint64(0)
+	genesis, _, err := mstr.GetRootBlockByNumber(&genesisNumber, false)
+	if err != nil {
+		return err
+	}
+	if header.GenesisHash != genesis.Hash() {
+		return fmt.Errorf("archive of another chain, genesis %x, expected %x", header.GenesisHash, genesis.Hash())
+	}
+
+	var (
+		start, reported   = time.Now(), time.Now()
+		imported, skipped = 0, 0
+	)
+	for {
+		record := new(archiveRootBlock)
+		if err := r.readRecord(record); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("import failed after %d blocks: %v", imported+skipped, err)
+		}
+		if _, _, err := mstr.GetRootBlockByHash(record.Block.Hash(), false); err == nil {
+			skipped++
+			continue
+		}
+		if err := importRootBlock(mstr, slaves, record); err != nil {
+			return fmt.Errorf("import failed on #%d: %v", record.Block.NumberU64(), err)
+		}
+		imported++
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Importing blocks", "imported", imported, "skipped", skipped, "number", record.Block.NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	log.Info("Imported blockchain", "file", fn, "blocks", imported, "skipped", skipped, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func importRootBlock(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, record *archiveRootBlock) error {
+	// the minor blocks are grouped by shard in the root block
+	shards := clusterShards(slaves)
+	for i := 0; i < len(record.MinorBlocks); {
+		branch := record.MinorBlocks[i].Block.Branch().Value
+		blocks := make([]*types.MinorBlock, 0)
+		for ; i < len(record.MinorBlocks) && record.MinorBlocks[i].Block.Branch().Value == branch; i++ {
+			blocks = append(blocks, record.MinorBlocks[i].Block)
+		}
+		if len(shards[branch]) == 0 {
+			return fmt.Errorf("shard %x is not run by the slaves", branch)
+		}
+		// the standby slaves of the shard are kept in sync
+		for _, shrd := range shards[branch] {
+			if err := importMinorBlocks(shrd, blocks); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mBlock := range record.MinorBlocks {
+		if err := checkDeposits(shards, mBlock); err != nil {
+			return err
+		}
+	}
+	return mstr.AddRootBlock(record.Block)
+}
+
+func importMinorBlocks(shrd *shard.ShardBackend, blocks []*types.MinorBlock) error {
+	missing := make([]*types.MinorBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if !shrd.MinorBlockChain.HasBlock(block.Hash()) {
+			missing = append(missing, block)
+		}
+	}
+	for len(missing) > 0 {
+		n := len(missing)
+		if n > importBatchSize {
+			n = importBatchSize
+		}
+		if err := shrd.AddBlockListForSync(missing[:n]); err != nil {
+			return err
+		}
+		missing = missing[n:]
+	}
+	return nil
+}
+
+// checkDeposits compares the cross-shard deposits made by an imported minor
+// block to the ones of the archive.
+func checkDeposits(shards map[uint32][]*shard.ShardBackend, mBlock *archiveMinorBlock) error {
+	for _, deposits := range mBlock.Deposits {
+		// the shard is not created yet, the deposits were not kept by the
+		// exporting cluster either
+		if len(shards[deposits.Branch]) == 0 {
+			continue
+		}
+		list := shards[deposits.Branch][0].MinorBlockChain.ReadCrossShardTxList(deposits.MinorBlockHash)
+		if list == nil {
+			return fmt.Errorf("no cross-shard deposits of minor block %x to shard %x", deposits.MinorBlockHash, deposits.Branch)
+		}
+		expected, err := serialize.SerializeToBytes(&types.CrossShardTransactionDepositList{TXList: deposits.TxList})
+		if err != nil {
+			return err
+		}
+		actual, err := serialize.SerializeToBytes(list)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, actual) {
+			return fmt.Errorf("cross-shard deposits of minor block %x to shard %x differ from the archive", deposits.MinorBlockHash, deposits.Branch)
+		}
+	}
+	return nil
+}
+
+// clusterShards returns the shards run by the slaves by full shard id, a shard
+// is run by several slaves when it has standby ones.
+func clusterShards(slaves []*slave.SlaveBackend) map[uint32][]*shard.ShardBackend {
+	shards := make(map[uint32][]*shard.ShardBackend)
+	for _, slv := range slaves {
+		for _, fullShardId := range slv.GetFullShardList() {
+			if shrd := slv.GetShard(fullShardId); shrd != nil {
+				shards[fullShardId] = append(shards[fullShardId], shrd)
+			}
+		}
+	}
+	return shards
+}
+
+func sortedShardIds(shards map[uint32][]*shard.ShardBackend) []uint32 {
+	ids := make([]uint32, 0, len(shards))
+	for id := range shards {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/QuarkChain/goquarkchain/cluster/slave"
 	"github.com/QuarkChain/goquarkchain/p2p"
 	"github.com/QuarkChain/goquarkchain/params"
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"gopkg.in/urfave/cli.v1"
@@ -131,6 +133,14 @@ var (
 		Usage: "service type,if has eight slaves,fill like(S0,S2,...S7)",
 		Value: "master",
 	}
+	SingleProcessFlag = cli.BoolFlag{
+		Name:  "single_process",
+		Usage: "run the master and all the slaves of the cluster config in one process, calling each other in memory",
+	}
+	FastSyncFlag = cli.BoolFlag{
+		Name:  "fast_sync",
+		Usage: "download the states of the shards near the tip of the peers instead of executing all the minor blocks",
+	}
 	CheckDBFlag = cli.BoolFlag{
 		Name:  "check_db",
 		Usage: "if true, will perform integrity check on db only",
@@ -150,6 +160,15 @@ var (
 		Usage: "the batch size of root block check at the same time",
 		Value: 0,
 	}
+	CheckDBReportFlag = cli.StringFlag{
+		Name:  "check_db_report",
+		Usage: "json file the report of the db check is written to, the standard output if empty",
+	}
+	CheckDBRegenerateRangeFlag = cli.IntFlag{
+		Name:  "check_db_regenerate_range",
+		Usage: "max number of minor blocks run again to regenerate a pruned state during the db check, 0 not to regenerate it",
+		Value: 0,
+	}
 
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
@@ -180,6 +199,10 @@ var (
 		Name:  "json_rpc_private_port",
 		Usage: "public HTTP-RPC server listening port",
 	}
+	PrivateRPCJWTSecretFlag = cli.StringFlag{
+		Name:  "json_rpc_private_jwt_secret",
+		Usage: "File holding the hex encoded HS256 secret of the JWTs required by the private HTTP-RPC server",
+	}
 
 	GRPCAddrFlag = cli.StringFlag{
 		Name:  "grpc_host",
@@ -227,7 +250,7 @@ var (
 	}
 	WSRPCHostFlag = cli.StringFlag{
 		Name:  "ws_host",
-		Usage: "websocket rpc host work for slave service",
+		Usage: "websocket rpc host work for master and slave service",
 		Value: config.DefaultHost,
 	}
 	WSRPCPortFlag = cli.IntFlag{
@@ -236,6 +259,37 @@ var (
 		Value: int(config.DefaultWSPort),
 	}
 
+	// Metrics settings, the name of MetricsEnabledFlag is the one go-ethereum
+	// looks for to enable the collection of the metrics
+	MetricsEnabledFlag = cli.BoolFlag{
+		Name:  metrics.MetricsEnabledFlag,
+		Usage: "Enable metrics collection and the Prometheus metrics endpoint",
+	}
+	MetricsHostFlag = cli.StringFlag{
+		Name:  "metrics_host",
+		Usage: "Prometheus metrics endpoint listening interface",
+		Value: config.DefaultHost,
+	}
+	MetricsPortFlag = cli.IntFlag{
+		Name:  "metrics_port",
+		Usage: "Prometheus metrics endpoint port of the master, slave Si listens on the port plus 1+i",
+		Value: int(config.DefaultMetricsPort),
+	}
+	HealthEnableFlag = cli.BoolFlag{
+		Name:  "health",
+		Usage: "Enable the /healthz and /readyz HTTP endpoints",
+	}
+	HealthHostFlag = cli.StringFlag{
+		Name:  "health_host",
+		Usage: "Health endpoint listening interface",
+		Value: config.DefaultHost,
+	}
+	HealthPortFlag = cli.IntFlag{
+		Name:  "health_port",
+		Usage: "Health endpoint port of the master, slave Si listens on the port plus 1+i",
+		Value: int(config.DefaultHealthPort),
+	}
+
 	GCModeFlag = cli.StringFlag{
 		Name:  "gcmode",
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
@@ -303,6 +357,7 @@ func setHTTP(ctx *cli.Context, cfg *service.Config, clstrCfg *config.ClusterConf
 		}
 		cfg.HTTPEndpoint = fmt.Sprintf("%s:%d", host, port)
 	}
+	cfg.RateLimit = clstrCfg.RateLimit
 	privPort := clstrCfg.PrivateJSONRPCPort
 	if ctx.GlobalIsSet(PrivateRPCPortFlag.Name) {
 		privPort = uint16(ctx.GlobalInt(PrivateRPCPortFlag.Name))
@@ -312,6 +367,7 @@ func setHTTP(ctx *cli.Context, cfg *service.Config, clstrCfg *config.ClusterConf
 		privateHost = ctx.GlobalString(PrivateRPCListenAddrFlag.Name)
 	}
 	cfg.HTTPPrivEndpoint = fmt.Sprintf("%s:%d", privateHost, privPort)
+	cfg.HTTPPrivAuth = clstrCfg.PrivateJSONRPCAuth
 }
 
 func setGRPC(ctx *cli.Context, cfg *service.Config, clstrCfg *config.ClusterConfig) {
@@ -322,6 +378,7 @@ func setGRPC(ctx *cli.Context, cfg *service.Config, clstrCfg *config.ClusterConf
 		clstrCfg.Quarkchain.GRPCHost = ctx.GlobalString(GRPCAddrFlag.Name)
 	}
 	cfg.GRPCEndpoint = fmt.Sprintf("%s:%d", clstrCfg.Quarkchain.GRPCHost, clstrCfg.Quarkchain.GRPCPort)
+	cfg.GRPCInProcess = ctx.GlobalBool(SingleProcessFlag.Name)
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -414,6 +471,14 @@ func SetClusterConfig(ctx *cli.Context, cfg *config.ClusterConfig) {
 		cfg.PrivateJSONRPCPort = uint16(ctx.GlobalInt(PrivateRPCPortFlag.Name))
 	}
 
+	if ctx.GlobalIsSet(PrivateRPCJWTSecretFlag.Name) {
+		// the auth config is null in a config file leaving the endpoint open
+		if cfg.PrivateJSONRPCAuth == nil {
+			cfg.PrivateJSONRPCAuth = rpcconfig.NewAuthConfig()
+		}
+		cfg.PrivateJSONRPCAuth.JWTSecretFile = ctx.GlobalString(PrivateRPCJWTSecretFlag.Name)
+	}
+
 	if ctx.GlobalBool(StartSimulatedMiningFlag.Name) {
 		cfg.StartSimulatedMining = true
 	}
@@ -445,6 +510,9 @@ func SetClusterConfig(ctx *cli.Context, cfg *config.ClusterConfig) {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+	if ctx.GlobalBool(FastSyncFlag.Name) {
+		cfg.FastSync = true
+	}
 }
 
 // SetNodeConfig applies node-related command line flags to the config.
@@ -468,6 +536,12 @@ func setCheckDBConfig(ctx *cli.Context, clstrCfg *config.ClusterConfig) {
 	if ctx.GlobalIsSet(CheckDBRBlockBatchFlag.Name) {
 		clstrCfg.CheckDBRBlockBatch = ctx.GlobalInt(CheckDBRBlockBatchFlag.Name)
 	}
+	if ctx.GlobalIsSet(CheckDBReportFlag.Name) {
+		clstrCfg.CheckDBReport = ctx.GlobalString(CheckDBReportFlag.Name)
+	}
+	if ctx.GlobalIsSet(CheckDBRegenerateRangeFlag.Name) {
+		clstrCfg.CheckDBRegenerateRange = ctx.GlobalInt(CheckDBRegenerateRangeFlag.Name)
+	}
 }
 
 func setDataDir(ctx *cli.Context, cfg *service.Config, clstrCfg *config.ClusterConfig) {
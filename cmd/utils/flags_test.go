@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func TestSetClusterConfigNullAuth(t *testing.T) {
+	cfg := config.NewClusterConfig()
+	assert.NoError(t, json.Unmarshal([]byte(`{"PRIVATE_JSON_RPC_AUTH": null}`), cfg))
+	assert.Nil(t, cfg.PrivateJSONRPCAuth)
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range []cli.Flag{PrivateRPCJWTSecretFlag, GCModeFlag} {
+		f.Apply(set)
+	}
+	assert.NoError(t, set.Parse([]string{"--" + PrivateRPCJWTSecretFlag.Name, "jwt.hex"}))
+	SetClusterConfig(cli.NewContext(nil, set, nil), cfg)
+	assert.Equal(t, "jwt.hex", cfg.PrivateJSONRPCAuth.JWTSecretFile)
+}
@@ -0,0 +1,411 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/cluster/master"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/cluster/slave"
+	"github.com/QuarkChain/goquarkchain/core"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// snapshotNodeBatchSize is how many state nodes are written in a record.
+const snapshotNodeBatchSize = 1024
+
+// snapshotHeader is the first record of a state snapshot, it is followed by the
+// root blocks from 1 to the one the snapshot is taken at, then by the shards.
+type snapshotHeader struct {
+	GenesisHash common.Hash
+	RootNumber  uint64
+	RootHash    common.Hash
+}
+
+// snapshotShard starts the records of a shard in a state snapshot: its last
+// blocks up to the tip confirmed by the root block, a snapshotDeposits, then
+// its state nodes in snapshotNodes up to an empty one.
+type snapshotShard struct {
+	Branch     uint32
+	Tip        common.Hash
+	BlockCount uint32
+}
+
+// snapshotDeposits are the cross-shard deposits to a shard which the tip has
+// not processed yet, with the minor blocks of the neighbor shards making them
+// to check them against.
+type snapshotDeposits struct {
+	Deposits []*rpc.AddXshardTxListRequest `bytesizeofslicelen:"4"`
+	Blocks   []*types.MinorBlock           `bytesizeofslicelen:"4"`
+}
+
+type snapshotNodes struct {
+	Nodes []*core.SnapshotNode `bytesizeofslicelen:"4"`
+}
+
+// SnapshotChain writes a state snapshot of the cluster at the root block of
+// the given number to fn: the root blocks up to it and, for each shard, the
+// state at the last minor block it confirms with the blocks and cross-shard
+// deposits the shard needs to run from there. The slaves must run all the
+// shards of the cluster.
+func SnapshotChain(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, fn string, number uint64) error {
+	rootBlock, _, err := mstr.GetRootBlockByNumber(&number, false)
+	if err != nil {
+		return err
+	}
+	genesisNumber := uint64(0)
+	genesis, _, err := mstr.GetRootBlockByNumber(&genesisNumber, false)
+	if err != nil {
+		return err
+	}
+	log.Info("Writing state snapshot", "file", fn, "rootNumber", number)
+	w, err := newArchiveWriter(fn, snapshotArchive, &snapshotHeader{
+		GenesisHash: genesis.Hash(),
+		RootNumber:  number,
+		RootHash:    rootBlock.Hash(),
+	})
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := writeSnapshot(mstr, slaves, w, rootBlock); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	log.Info("Wrote state snapshot", "file", fn, "rootNumber", number, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func writeSnapshot(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, w *archiveWriter, rootBlock *types.RootBlock) error {
+	for nr := uint64(1); nr <= rootBlock.NumberU64(); nr++ {
+		nr := nr
+		rBlock, _, err := mstr.GetRootBlockByNumber(&nr, false)
+		if err != nil {
+			return fmt.Errorf("snapshot failed on #%d: %v", nr, err)
+		}
+		if err := w.writeRecord(rBlock); err != nil {
+			return err
+		}
+	}
+
+	shards := clusterShards(slaves)
+	for _, fullShardId := range sortedShardIds(shards) {
+		chain := shards[fullShardId][0].MinorBlockChain
+		tipHash := chain.ReadLastConfirmedMinorBlockHeaderAtRootBlock(rootBlock.Hash())
+		tip := chain.GetMinorBlock(tipHash)
+		if tip == nil {
+			return fmt.Errorf("snapshot failed: no block of shard %x confirmed by root block %d", fullShardId, rootBlock.NumberU64())
+		}
+		first, hashes := chain.SnapshotWindowStart(tip, rootBlock), make([]common.Hash, 0)
+		for block := tip; ; block = chain.GetMinorBlock(block.ParentHash()) {
+			if block == nil {
+				return fmt.Errorf("snapshot failed: missing block of shard %x", fullShardId)
+			}
+			hashes = append([]common.Hash{block.Hash()}, hashes...)
+			if block.NumberU64() <= first {
+				break
+			}
+		}
+
+		if err := w.writeRecord(&snapshotShard{Branch: fullShardId, Tip: tipHash, BlockCount: uint32(len(hashes))}); err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			block, err := chain.GetSnapshotBlock(hash)
+			if err != nil {
+				return fmt.Errorf("snapshot failed on shard %x: %v", fullShardId, err)
+			}
+			if err := w.writeRecord(block); err != nil {
+				return err
+			}
+		}
+		deposits := &snapshotDeposits{Deposits: chain.GetSnapshotDeposits(tip, rootBlock)}
+		for _, req := range deposits.Deposits {
+			var block *types.MinorBlock
+			if neighbor, ok := shards[req.Branch]; ok {
+				block = neighbor[0].MinorBlockChain.GetMinorBlock(req.MinorBlockHash)
+			}
+			if block == nil {
+				return fmt.Errorf("snapshot failed: missing block %x of shard %x making deposits", req.MinorBlockHash, req.Branch)
+			}
+			deposits.Blocks = append(deposits.Blocks, block)
+		}
+		if err := w.writeRecord(deposits); err != nil {
+			return err
+		}
+
+		count, batch := 0, &snapshotNodes{Nodes: make([]*core.SnapshotNode, 0, snapshotNodeBatchSize)}
+		err := chain.SnapshotState(tip, func(hash common.Hash, blob []byte) error {
+			batch.Nodes = append(batch.Nodes, &core.SnapshotNode{Hash: hash, Blob: blob})
+			count++
+			if len(batch.Nodes) < snapshotNodeBatchSize {
+				return nil
+			}
+			err := w.writeRecord(batch)
+			batch.Nodes = batch.Nodes[:0]
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("snapshot failed on the state of shard %x: %v", fullShardId, err)
+		}
+		if len(batch.Nodes) > 0 {
+			if err := w.writeRecord(batch); err != nil {
+				return err
+			}
+		}
+		if err := w.writeRecord(&snapshotNodes{Nodes: make([]*core.SnapshotNode, 0)}); err != nil {
+			return err
+		}
+		log.Info("Wrote shard state", "branch", fmt.Sprintf("%x", fullShardId), "number", tip.NumberU64(), "blocks", len(hashes), "nodes", count)
+	}
+	return nil
+}
+
+// BootstrapChain restores the state snapshot fn written by SnapshotChain into a
+// cluster which has no block but its genesis ones. The root blocks are added
+// to the master, then each shard is checked against the minor block headers
+// they confirm, its deposits against the transactions of the neighbor blocks
+// making them, and written into the databases of the slaves running it, which
+// run it again from the root block of the snapshot. The databases are left to
+// be removed if the bootstrap fails.
+func BootstrapChain(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, fn string) error {
+	log.Info("Bootstrapping from state snapshot", "file", fn)
+	header := new(snapshotHeader)
+	r, err := newArchiveReader(fn, snapshotArchive, header)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	genesisNumber := uint64(0)
+	genesis, _, err := mstr.GetRootBlockByNumber(&genesisNumber, false)
+	if err != nil {
+		return err
+	}
+	if header.GenesisHash != genesis.Hash() {
+		return fmt.Errorf("snapshot of another chain, genesis %x, expected %x", header.GenesisHash, genesis.Hash())
+	}
+	if number := mstr.CurrentBlock().NumberU64(); number != 0 {
+		return fmt.Errorf("the cluster has %d root blocks already, snapshots are restored into new clusters", number)
+	}
+
+	start := time.Now()
+	rootBlock, err := restoreRootBlocks(mstr, slaves, r, header)
+	if err != nil {
+		return err
+	}
+
+	restored := make(map[uint32]bool)
+	for {
+		record := new(snapshotShard)
+		if err := r.readRecord(record); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if restored[record.Branch] {
+			return fmt.Errorf("shard %x is twice in the snapshot", record.Branch)
+		}
+		if err := restoreShard(mstr, slaves, r, record, rootBlock); err != nil {
+			return fmt.Errorf("bootstrap failed on shard %x: %v", record.Branch, err)
+		}
+		restored[record.Branch] = true
+	}
+	for fullShardId := range clusterShards(slaves) {
+		if !restored[fullShardId] {
+			return fmt.Errorf("shard %x is not in the snapshot", fullShardId)
+		}
+	}
+	log.Info("Bootstrapped from state snapshot", "file", fn, "rootNumber", rootBlock.NumberU64(), "shards", len(restored), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func restoreRootBlocks(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, r *archiveReader, header *snapshotHeader) (*types.RootBlock, error) {
+	var (
+		rootBlock = mstr.CurrentBlock()
+		batch     = make([]*types.RootBlock, 0, importBatchSize)
+		reported  = time.Now()
+		shards    = clusterShards(slaves)
+	)
+	// the minor block headers are checked by the shards they belong to
+	verifyHeader := func(mHeader *types.MinorBlockHeader) error {
+		shrds, ok := shards[mHeader.Branch.GetFullShardID()]
+		if !ok {
+			return fmt.Errorf("shard %x is not run by the slaves", mHeader.Branch.GetFullShardID())
+		}
+		return shrds[0].MinorBlockChain.VerifySnapshotHeader(mHeader)
+	}
+	for nr := uint64(1); nr <= header.RootNumber; nr++ {
+		rBlock := new(types.RootBlock)
+		if err := r.readRecord(rBlock); err != nil {
+			return nil, fmt.Errorf("bootstrap failed on #%d: %v", nr, err)
+		}
+		if rBlock.NumberU64() != nr || rBlock.ParentHash() != rootBlock.Hash() {
+			return nil, fmt.Errorf("bootstrap failed on #%d: root block does not extend #%d", nr, rootBlock.NumberU64())
+		}
+		rootBlock = rBlock
+		if batch = append(batch, rBlock); len(batch) == importBatchSize || nr == header.RootNumber {
+			if err := mstr.AddSnapshotRootBlocks(batch, verifyHeader); err != nil {
+				return nil, fmt.Errorf("bootstrap failed on #%d: %v", batch[0].NumberU64(), err)
+			}
+			batch = batch[:0]
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Adding root blocks", "number", nr, "last", header.RootNumber)
+			reported = time.Now()
+		}
+	}
+	if rootBlock.Hash() != header.RootHash {
+		return nil, fmt.Errorf("bootstrap failed: root block %d is %x, expected %x", header.RootNumber, rootBlock.Hash(), header.RootHash)
+	}
+	return rootBlock, nil
+}
+
+// restoreShard writes a shard of the snapshot into the databases of the slaves
+// running it, and runs it again from rootBlock.
+func restoreShard(mstr *master.QKCMasterBackend, slaves []*slave.SlaveBackend, r *archiveReader, record *snapshotShard, rootBlock *types.RootBlock) error {
+	shardSlaves := make([]*slave.SlaveBackend, 0)
+	for _, slv := range slaves {
+		if slv.GetShard(record.Branch) != nil {
+			shardSlaves = append(shardSlaves, slv)
+		}
+	}
+	if len(shardSlaves) == 0 {
+		return fmt.Errorf("shard %x is not run by the slaves", record.Branch)
+	}
+
+	dbs := make([]ethdb.Database, 0, len(shardSlaves))
+	closeDBs := func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+	}
+	for _, slv := range shardSlaves {
+		db, err := slv.OpenShardForRestore(record.Branch)
+		if err != nil {
+			closeDBs()
+			return err
+		}
+		dbs = append(dbs, db)
+	}
+	err := writeShardSnapshot(mstr, r, record, rootBlock, dbs)
+	closeDBs()
+	if err != nil {
+		return err
+	}
+	for _, slv := range shardSlaves {
+		if err := slv.RunRestoredShard(record.Branch, rootBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeShardSnapshot(mstr *master.QKCMasterBackend, r *archiveReader, record *snapshotShard, rootBlock *types.RootBlock, dbs []ethdb.Database) error {
+	// the blocks up to the tip must be linked
+	var tip *types.MinorBlock
+	for i := uint32(0); i < record.BlockCount; i++ {
+		block := new(core.SnapshotBlock)
+		if err := r.readRecord(block); err != nil {
+			return err
+		}
+		if block.Block.Branch().Value != record.Branch {
+			return fmt.Errorf("block %x of shard %x", block.Block.Hash(), block.Block.Branch().Value)
+		}
+		if tip != nil && (block.Block.ParentHash() != tip.Hash() || block.Block.NumberU64() != tip.NumberU64()+1) {
+			return fmt.Errorf("block %d does not extend block %d", block.Block.NumberU64(), tip.NumberU64())
+		}
+		for _, db := range dbs {
+			if err := core.WriteSnapshotBlock(db, block); err != nil {
+				return err
+			}
+		}
+		tip = block.Block
+	}
+	if tip == nil || tip.Hash() != record.Tip {
+		return fmt.Errorf("blocks do not end with the tip %x", record.Tip)
+	}
+
+	// and the tip must be the last block of the shard the root blocks confirm,
+	// the deposits are checked against the headers confirmed since the cursor
+	// of the tip
+	var (
+		confirmed common.Hash
+		cursor    = tip.Meta().XShardTxCursorInfo.RootBlockHeight
+		headers   = make(map[common.Hash]bool)
+	)
+	for nr := uint64(1); nr <= rootBlock.NumberU64(); nr++ {
+		nr := nr
+		rBlock, _, err := mstr.GetRootBlockByNumber(&nr, false)
+		if err != nil {
+			return err
+		}
+		for _, header := range rBlock.MinorBlockHeaders() {
+			if header.Branch.Value == record.Branch {
+				confirmed = header.Hash()
+			} else if nr >= cursor {
+				headers[header.Hash()] = true
+			}
+		}
+		for _, db := range dbs {
+			core.WriteSnapshotRootBlock(db, rBlock, confirmed)
+		}
+	}
+	if confirmed != tip.Hash() {
+		return fmt.Errorf("tip %x is not the last block confirmed by root block %d, %x is", tip.Hash(), rootBlock.NumberU64(), confirmed)
+	}
+
+	deposits := new(snapshotDeposits)
+	if err := r.readRecord(deposits); err != nil {
+		return err
+	}
+	if len(deposits.Blocks) != len(deposits.Deposits) {
+		return fmt.Errorf("%d blocks for the deposits of %d blocks", len(deposits.Blocks), len(deposits.Deposits))
+	}
+	for i, req := range deposits.Deposits {
+		if !headers[req.MinorBlockHash] {
+			return fmt.Errorf("deposits of minor block %x which is not confirmed since the cursor of the tip", req.MinorBlockHash)
+		}
+		block := deposits.Blocks[i]
+		if block.Hash() != req.MinorBlockHash || block.Branch().Value != req.Branch {
+			return fmt.Errorf("block %x for the deposits of minor block %x", block.Hash(), req.MinorBlockHash)
+		}
+		if err := core.VerifySnapshotDeposits(mstr.GetClusterConfig().Quarkchain, block, record.Branch, req.TxList); err != nil {
+			return fmt.Errorf("deposits of minor block %x: %v", req.MinorBlockHash, err)
+		}
+	}
+	for _, db := range dbs {
+		core.WriteSnapshotDeposits(db, deposits.Deposits)
+	}
+
+	count := 0
+	for {
+		batch := new(snapshotNodes)
+		if err := r.readRecord(batch); err != nil {
+			return err
+		}
+		if len(batch.Nodes) == 0 {
+			break
+		}
+		for _, db := range dbs {
+			if err := core.WriteSnapshotNodes(db, batch.Nodes); err != nil {
+				return err
+			}
+		}
+		count += len(batch.Nodes)
+	}
+	for _, db := range dbs {
+		if err := core.WriteSnapshotHead(db, tip); err != nil {
+			return err
+		}
+	}
+	log.Info("Restored shard state", "branch", fmt.Sprintf("%x", record.Branch), "number", tip.NumberU64(), "blocks", record.BlockCount, "nodes", count)
+	return nil
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// unindexedBackend hides the bloom bits sections of a shard from the filters.
+type unindexedBackend struct {
+	*MinorBlockChain
+}
+
+func (b unindexedBackend) BloomStatus() (uint64, uint64) {
+	size, _ := b.MinorBlockChain.BloomStatus()
+	return size, 0
+}
+
+func waitBloomSections(t *testing.T, shardState *MinorBlockChain, sections uint64) {
+	for i := 0; i < 100; i++ {
+		if _, indexed := shardState.BloomStatus(); indexed >= sections {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("bloom bits sections not indexed in time, want %d", sections)
+}
+
+func TestBloomIndexedLogs(t *testing.T) {
+	defer func(size, confirms uint64) { bloomBitsBlocks, bloomConfirms = size, confirms }(bloomBitsBlocks, bloomConfirms)
+	bloomBitsBlocks, bloomConfirms = 8, 1
+
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2, err := account.CreatRandomAccountWithFullShardKey(0)
+	assert.NoError(t, err)
+	fakeMoney := uint64(10000000)
+	shardState := createDefaultShardState(setUp(&acc1, &fakeMoney, nil), nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	// the blocks are timestamped one second apart from the genesis to mine more
+	// of them than the time allowed in the future
+	rootTime := shardState.rootTip.Time() + 1
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(&rootTime, nil, nil, nil, nil).Finalize(nil, nil, common.Hash{})
+	_, err = shardState.AddRootBlock(rootBlock)
+	assert.NoError(t, err)
+
+	// every third block creates a contract emitting an event
+	contracts := make([]common.Address, 0)
+	for i := 1; i <= 25; i++ {
+		if i%3 == 0 {
+			tx, err := CreateContract(shardState, id1.GetKey(), acc1, acc1.FullShardKey, ContractCreationWithEventByteCode)
+			assert.NoError(t, err)
+			assert.NoError(t, shardState.AddTx(tx))
+		}
+		createTime := shardState.CurrentBlock().Time() + 1
+		block, err := shardState.CreateBlockToMine(&createTime, &acc2, nil, nil, nil)
+		assert.NoError(t, err)
+		_, receipts, err := shardState.FinalizeAndAddBlock(block)
+		assert.NoError(t, err)
+		if i%3 == 0 {
+			assert.Equal(t, 1, len(receipts[0].Logs))
+			contracts = append(contracts, receipts[0].ContractAddress)
+		}
+	}
+	waitBloomSections(t, shardState, 3)
+
+	topic := shardState.GetLogs(shardState.GetBlockByNumber(3).Hash())[0][0].Topics[0]
+	queries := []struct {
+		begin, end uint64
+		addresses  []common.Address
+		topics     [][]common.Hash
+		expected   int
+	}{
+		{0, 25, nil, nil, 8},
+		{0, 25, nil, [][]common.Hash{{topic}}, 8},
+		{4, 20, nil, [][]common.Hash{{topic}}, 5},
+		{0, 25, contracts[2:4], nil, 2},
+		{0, 25, []common.Address{acc1.Recipient}, nil, 0},
+		{0, 25, nil, [][]common.Hash{{common.HexToHash("2324242424")}}, 0},
+	}
+	for i, query := range queries {
+		indexed, err := NewRangeFilter(shardState, query.begin, query.end, query.addresses, query.topics).Logs()
+		assert.NoError(t, err)
+		unindexed, err := NewRangeFilter(unindexedBackend{shardState}, query.begin, query.end, query.addresses, query.topics).Logs()
+		assert.NoError(t, err)
+		assert.Equal(t, query.expected, len(indexed), "query %d", i)
+		assert.Equal(t, unindexed, indexed, "query %d", i)
+	}
+
+	// the sections after a reorg are indexed again
+	shardState.bloomIndexer.newHead(12, true)
+	sections, _, _ := shardState.bloomIndexer.Sections()
+	assert.Equal(t, uint64(1), sections)
+	createTime := shardState.CurrentBlock().Time() + 1
+	block, err := shardState.CreateBlockToMine(&createTime, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	_, _, err = shardState.FinalizeAndAddBlock(block)
+	assert.NoError(t, err)
+	waitBloomSections(t, shardState, 3)
+	logs, err := NewRangeFilter(shardState, 0, 26, nil, [][]common.Hash{{topic}}).Logs()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(logs))
+	for _, log := range logs {
+		assert.NotEqual(t, types.Log{}, *log)
+	}
+}
+
+func TestBloomIndexerCheckDB(t *testing.T) {
+	env := setUp(nil, nil, nil)
+	env.clusterConfig.CheckDB = true
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	// the bloom bits would be dropped, so the indexer is not started
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&shardState.bloomIndexer.active))
+}
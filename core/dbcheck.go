@@ -0,0 +1,185 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The kinds of data checked by the database integrity check of a shard.
+const (
+	DBCheckCanonical    = "canonical"
+	DBCheckBody         = "body"
+	DBCheckState        = "state"
+	DBCheckTxHistory    = "tx_history"
+	DBCheckXShardTxList = "xshard_tx_list"
+)
+
+var errTxHistoryMissing = errors.New("tx history index entry missing")
+
+// ErrDBCheckStatePruned is returned by CheckBlockDB for a block whose parent
+// state is pruned and not regenerated within CheckDBRegenerateRange blocks, the
+// block is checked but its state is not verified.
+var ErrDBCheckStatePruned = errors.New("state of the parent block is pruned")
+
+// DBCheckError is returned by the database integrity check of a shard, with the
+// kind of data found inconsistent and the minor block it belongs to.
+type DBCheckError struct {
+	Check  string
+	Number uint64
+	Hash   common.Hash
+	Err    error
+}
+
+func (e *DBCheckError) Error() string {
+	return fmt.Sprintf("%s check of minor block %d [%x] failed: %v", e.Check, e.Number, e.Hash, e.Err)
+}
+
+func newDBCheckError(check string, header *types.MinorBlockHeader, err error) *DBCheckError {
+	return &DBCheckError{Check: check, Number: header.Number, Hash: header.Hash(), Err: err}
+}
+
+// CheckBlockDB checks the minor block of a header confirmed by a root block
+// against the database: the block is canonical, its body matches the header,
+// and its tx history index entries are there. The block is also run again on
+// the state of its parent to check its state and receipt roots and the deposits
+// it received, the state is regenerated from the nearest state stored if it is
+// pruned. It returns whether the block was run again, and a *DBCheckError for
+// the first inconsistency found, or ErrDBCheckStatePruned if the state is out
+// of the range of CheckDBRegenerateRange.
+func (m *MinorBlockChain) CheckBlockDB(header *types.MinorBlockHeader) (bool, error) {
+	if header.Branch != m.branch {
+		return false, ErrBranch
+	}
+	if hash := rawdb.ReadCanonicalHash(m.db, rawdb.ChainTypeMinor, header.Number); hash != header.Hash() {
+		return false, newDBCheckError(DBCheckCanonical, header, fmt.Errorf("canonical block is %x", hash))
+	}
+	block := m.GetMinorBlock(header.Hash())
+	if block == nil {
+		return false, newDBCheckError(DBCheckBody, header, ErrMinorBlockIsNil)
+	}
+	if header.Number == 0 {
+		return false, nil
+	}
+	if err := m.checkBlockBody(block); err != nil {
+		return false, newDBCheckError(DBCheckBody, header, err)
+	}
+	if err := m.checkTxHistoryIndex(block); err != nil {
+		return false, newDBCheckError(DBCheckTxHistory, header, err)
+	}
+
+	parent := m.GetMinorBlock(block.ParentHash())
+	if parent == nil {
+		return false, newDBCheckError(DBCheckBody, header, ErrPreBlockNotFound)
+	}
+	if !m.HasState(parent.Meta().Root) {
+		if m.clusterConfig.CheckDBRegenerateRange <= 0 {
+			return false, ErrDBCheckStatePruned
+		}
+		err := m.regenerateState(parent, uint64(m.clusterConfig.CheckDBRegenerateRange))
+		if err == errStateOutOfRange {
+			return false, ErrDBCheckStatePruned
+		} else if err != nil {
+			return false, newDBCheckError(DBCheckState, header, err)
+		}
+	}
+	evmState, receipts, _, usedGas, xShardReceiveTxList, err := m.runBlock(block)
+	if err != nil {
+		return true, newDBCheckError(DBCheckState, header, err)
+	}
+	if err := m.Validator().ValidateState(block, parent, evmState, receipts, usedGas); err != nil {
+		return true, newDBCheckError(DBCheckState, header, err)
+	}
+	// the deposits received are only kept with the tx history
+	if m.clusterConfig.EnableTransactionHistory {
+		confirmed := rawdb.ReadConfirmedCrossShardTxList(m.db, block.Hash())
+		if confirmed == nil {
+			return true, newDBCheckError(DBCheckXShardTxList, header, errors.New("received deposits missing"))
+		}
+		if !sameDeposits(confirmed.TXList, xShardReceiveTxList) {
+			return true, newDBCheckError(DBCheckXShardTxList, header, fmt.Errorf("received %d deposits instead of %d",
+				len(confirmed.TXList), len(xShardReceiveTxList)))
+		}
+	}
+	return true, nil
+}
+
+// CheckCrossShardTxListsDB checks that the database has the lists of deposits
+// made to the shard by the minor blocks of the neighbor shards a root block
+// confirms. It returns a *DBCheckError for the first list missing. Only the
+// existence of the lists is checked, their deposits are not recomputed as the
+// neighbor blocks are kept by other shards.
+func (m *MinorBlockChain) CheckCrossShardTxListsDB(rBlock *types.RootBlock) error {
+	if rBlock.Number() <= uint32(m.clusterConfig.Quarkchain.GetGenesisRootHeight(m.branch.Value)) {
+		return nil
+	}
+	for _, header := range rBlock.MinorBlockHeaders() {
+		if header.Branch == m.branch || !m.hasCrossShardTxList(header) {
+			continue
+		}
+		if m.ReadCrossShardTxList(header.Hash()) == nil {
+			return newDBCheckError(DBCheckXShardTxList, header,
+				fmt.Errorf("deposits confirmed by root block %d missing", rBlock.Number()))
+		}
+	}
+	return nil
+}
+
+func (m *MinorBlockChain) checkBlockBody(block *types.MinorBlock) error {
+	if block.Branch() != m.branch {
+		return ErrBranch
+	}
+	if block.MetaHash() != block.Meta().Hash() {
+		return ErrMetaHash
+	}
+	if types.CalculateMerkleRoot(block.Transactions()) != block.Meta().TxHash {
+		return ErrTxHash
+	}
+	return nil
+}
+
+func (m *MinorBlockChain) checkTxHistoryIndex(block *types.MinorBlock) error {
+	if !m.clusterConfig.EnableTransactionHistory {
+		return nil
+	}
+	has := func(key []byte) error {
+		if ok, _ := m.db.Has(key); !ok {
+			return fmt.Errorf("%v: %x", errTxHistoryMissing, key)
+		}
+		return nil
+	}
+	for index, tx := range block.Transactions() {
+		if err := m.updateTxHistoryIndex(tx, block.NumberU64(), index, has); err != nil {
+			return err
+		}
+	}
+	if rawdb.ReadConfirmedCrossShardTxList(m.db, block.Hash()) == nil {
+		return nil
+	}
+	return m.updateTxHistoryIndexFromBlock(block, has)
+}
+
+func sameDeposits(a, b []*types.CrossShardTransactionDeposit) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, err := serialize.SerializeToBytes(a[i])
+		if err != nil {
+			return false
+		}
+		y, err := serialize.SerializeToBytes(b[i])
+		if err != nil {
+			return false
+		}
+		if !bytes.Equal(x, y) {
+			return false
+		}
+	}
+	return true
+}
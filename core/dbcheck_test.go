@@ -0,0 +1,80 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBlockDB(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2, err := account.CreatRandomAccountWithFullShardKey(0)
+	assert.NoError(t, err)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	env.clusterConfig.EnableTransactionHistory = true
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	rootBlock, blocks, err := createContractBlocks(shardState, id1, &acc2, 3)
+	assert.NoError(t, err)
+
+	rerun, err := shardState.CheckBlockDB(shardState.GetBlockByNumber(0).IHeader().(*types.MinorBlockHeader))
+	assert.NoError(t, err)
+	assert.False(t, rerun)
+	for _, block := range blocks {
+		rerun, err := shardState.CheckBlockDB(block.Header())
+		assert.NoError(t, err)
+		assert.True(t, rerun)
+	}
+	assert.NoError(t, shardState.CheckCrossShardTxListsDB(rootBlock))
+
+	// the states of the parents of a block are pruned, they are regenerated
+	// only within range
+	assert.NoError(t, env.db.Delete(blocks[0].Meta().Root.Bytes()))
+	assert.NoError(t, env.db.Delete(blocks[1].Meta().Root.Bytes()))
+	prunedState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer prunedState.Stop()
+	assert.False(t, prunedState.HasState(blocks[1].Meta().Root))
+	_, err = prunedState.CheckBlockDB(blocks[2].Header())
+	assert.Equal(t, ErrDBCheckStatePruned, err)
+	prunedState.clusterConfig.CheckDBRegenerateRange = 1
+	_, err = prunedState.CheckBlockDB(blocks[2].Header())
+	assert.Equal(t, ErrDBCheckStatePruned, err)
+	prunedState.clusterConfig.CheckDBRegenerateRange = 2
+	rerun, err = prunedState.CheckBlockDB(blocks[2].Header())
+	assert.NoError(t, err)
+	assert.True(t, rerun)
+	assert.True(t, prunedState.HasState(blocks[1].Meta().Root))
+
+	checkError := func(block *types.MinorBlock, check string) {
+		_, err := shardState.CheckBlockDB(block.Header())
+		checkErr, ok := err.(*DBCheckError)
+		if assert.True(t, ok, "unexpected error %v", err) {
+			assert.Equal(t, check, checkErr.Check)
+			assert.Equal(t, block.NumberU64(), checkErr.Number)
+			assert.Equal(t, block.Hash(), checkErr.Hash)
+		}
+	}
+	// the deposits received by a block are not the ones it processes again
+	deposit := &types.CrossShardTransactionDeposit{CrossShardTransactionDepositV0: types.CrossShardTransactionDepositV0{
+		TxHash: common.HexToHash("0x01"), From: acc2, To: acc1, Value: &serialize.Uint256{Value: big.NewInt(0)}, IsFromRootChain: true,
+		GasPrice: &serialize.Uint256{Value: big.NewInt(1)}, GasRemained: &serialize.Uint256{Value: big.NewInt(0)}}}
+	rawdb.WriteConfirmedCrossShardTxList(shardState.db, blocks[0].Hash(),
+		&types.CrossShardTransactionDepositList{TXList: []*types.CrossShardTransactionDeposit{deposit}})
+	checkError(blocks[0], DBCheckXShardTxList)
+	// the tx history of the sender of a block is missing
+	assert.NoError(t, shardState.db.Delete(encodeAddressTxKey(acc1.Recipient, blocks[1].NumberU64(), 0, false)))
+	checkError(blocks[1], DBCheckTxHistory)
+	// the canonical chain does not have a block
+	rawdb.WriteCanonicalHash(shardState.db, rawdb.ChainTypeMinor, blocks[1].Hash(), blocks[2].NumberU64())
+	checkError(blocks[2], DBCheckCanonical)
+}
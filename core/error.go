@@ -56,6 +56,7 @@ var (
 	ErrCoinbaseAmount            = errors.New("wrong coinbase amount")
 	ErrXShardList                = errors.New("xShardReceivedGasUsed not match")
 	ErrNetWorkID                 = errors.New("network id not match")
+	ErrEthTxNotEnabled           = errors.New("ethereum transaction is not enabled yet")
 	ErrNotNeighbor               = errors.New("is not a neighbor")
 	ErrNotSameRootChain          = errors.New("is not same root chain")
 	ErrPoswOnRootChainIsNotFound = errors.New("PoSW-on-root-chain contract is not found")
@@ -0,0 +1,270 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/state"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// ErrNotFastSyncing is returned when adding a block without state to a shard
+// that is not fast syncing.
+var ErrNotFastSyncing = errors.New("shard is not fast syncing")
+
+// CurrentFastBlock retrieves the head of the blocks added without executing them
+// while fast syncing, or nil if the shard is not fast syncing.
+func (m *MinorBlockChain) CurrentFastBlock() *types.MinorBlock {
+	block, _ := m.currentFastBlock.Load().(*types.MinorBlock)
+	return block
+}
+
+// IsFastSyncing returns whether the shard adds blocks without executing them.
+func (m *MinorBlockChain) IsFastSyncing() bool {
+	return m.CurrentFastBlock() != nil
+}
+
+// StartFastSync makes the shard add the blocks without executing them until
+// the state of the last one is downloaded. It returns false, and the shard
+// keeps executing blocks, if it has already executed a block.
+func (m *MinorBlockChain) StartFastSync() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.IsFastSyncing() {
+		return true
+	}
+	if m.CurrentBlock().NumberU64() != 0 {
+		return false
+	}
+	rawdb.WriteHeadFastBlockHash(m.db, m.CurrentBlock().Hash())
+	m.currentFastBlock.Store(m.CurrentBlock())
+	log.Info(m.logInfo+" Start fast sync", "genesis", m.CurrentBlock().Hash())
+	return true
+}
+
+// AddBlockWithoutState checks a block extending the fast sync head and adds it
+// as the new head without executing it, unless it is already on the fast sync
+// chain. Its state root is only checked when
+// the state of a later block is downloaded.
+func (m *MinorBlockChain) AddBlockWithoutState(block *types.MinorBlock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	head := m.CurrentFastBlock()
+	if head == nil {
+		return ErrNotFastSyncing
+	}
+	if block.Branch().Value != m.branch.Value {
+		return ErrBranch
+	}
+	// a block added before a restart is already on the fast sync chain
+	if block.NumberU64() <= head.NumberU64() && rawdb.ReadCanonicalHash(m.db, rawdb.ChainTypeMinor, block.NumberU64()) == block.Hash() {
+		return nil
+	}
+	if block.ParentHash() != head.Hash() || block.NumberU64() != head.NumberU64()+1 {
+		return fmt.Errorf("block %d [%x] does not extend the fast sync head %d [%x]",
+			block.NumberU64(), block.Hash(), head.NumberU64(), head.Hash())
+	}
+	if block.Time() <= head.Time() {
+		return ErrTime
+	}
+	if block.MetaHash() != block.Meta().Hash() {
+		return ErrMetaHash
+	}
+	if types.CalculateMerkleRoot(block.Transactions()) != block.Meta().TxHash {
+		return ErrTxHash
+	}
+	if m.GetRootBlockByHash(block.PrevRootBlockHash()) == nil {
+		return ErrRootBlockIsNil
+	}
+	// the stakes of the PoSW are in the state, so the seal is checked against
+	// the difficulty lowered by the PoSW
+	if !m.clusterConfig.Quarkchain.DisablePowCheck {
+		if err := m.validator.ValidateSeal(block.Header(), false); err != nil {
+			return err
+		}
+	}
+
+	batch := m.db.NewBatch()
+	rawdb.WriteMinorBlock(batch, block)
+	rawdb.WriteBlockContentLookupEntriesWithCrossShardHashList(batch, block, nil)
+	rawdb.WriteCanonicalHash(batch, rawdb.ChainTypeMinor, block.Hash(), block.NumberU64())
+	rawdb.WriteCommitMinorBlock(batch, block.Hash())
+	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if err := m.putTotalTxCount(block); err != nil {
+		return err
+	}
+	m.blockCache.Add(block.Hash(), block)
+	m.currentFastBlock.Store(block)
+	return nil
+}
+
+// NewFastSyncState returns the scheduler downloading the state of the fast
+// sync head, which skips the parts of it already in the database.
+func (m *MinorBlockChain) NewFastSyncState() (*trie.Sync, error) {
+	head := m.CurrentFastBlock()
+	if head == nil {
+		return nil, ErrNotFastSyncing
+	}
+	return state.NewStateSync(head.Meta().Root, m.db), nil
+}
+
+// FastSyncMissingDeposits returns the hashes of the minor blocks of neighbor
+// shards that made cross-shard deposits the fast sync head has not processed
+// all of yet, and whose deposits are not in the database.
+func (m *MinorBlockChain) FastSyncMissingDeposits() []common.Hash {
+	head := m.CurrentFastBlock()
+	if head == nil {
+		return nil
+	}
+	cursor := head.Meta().XShardTxCursorInfo
+	hashes := make([]common.Hash, 0)
+	for rBlock := m.GetRootTip(); rBlock != nil && rBlock.NumberU64() >= cursor.RootBlockHeight; rBlock = m.GetRootBlockByHash(rBlock.ParentHash()) {
+		for _, header := range rBlock.MinorBlockHeaders() {
+			if header.Branch == m.branch || !m.hasCrossShardTxList(header) {
+				continue
+			}
+			if m.ReadCrossShardTxList(header.Hash()) == nil {
+				hashes = append(hashes, header.Hash())
+			}
+		}
+		if rBlock.NumberU64() == 0 {
+			break
+		}
+	}
+	return hashes
+}
+
+// AddFastSyncCrossShardTxList adds a cross-shard tx list the fast sync head has
+// still to process, downloaded from a peer. The peer is trusted until a block
+// processing the list is executed: the list is dropped if one processing it
+// fails to validate, see FastSyncDroppedDeposits.
+func (m *MinorBlockChain) AddFastSyncCrossShardTxList(hash common.Hash, txList types.CrossShardTransactionDepositList) {
+	m.fastSyncDeposits.Lock()
+	defer m.fastSyncDeposits.Unlock()
+	rootHeight, hashes := rawdb.ReadFastSyncDeposits(m.db)
+	if tip := m.GetRootTip().NumberU64(); tip > rootHeight {
+		rootHeight = tip
+	}
+	known := false
+	for _, h := range hashes {
+		if h == hash {
+			known = true
+			break
+		}
+	}
+	if !known {
+		hashes = append(hashes, hash)
+	}
+	rawdb.WriteCrossShardTxList(m.db, hash, &txList)
+	m.crossShardTxListCache.Remove(hash)
+	rawdb.WriteFastSyncDeposits(m.db, rootHeight, hashes)
+}
+
+// FastSyncDroppedDeposits returns the hashes of the minor blocks whose
+// cross-shard tx lists downloaded during fast sync were dropped after a block
+// processing them failed to validate, to download them again.
+func (m *MinorBlockChain) FastSyncDroppedDeposits() []common.Hash {
+	m.fastSyncDeposits.Lock()
+	defer m.fastSyncDeposits.Unlock()
+	_, hashes := rawdb.ReadFastSyncDeposits(m.db)
+	dropped := make([]common.Hash, 0)
+	for _, hash := range hashes {
+		if m.ReadCrossShardTxList(hash) == nil {
+			dropped = append(dropped, hash)
+		}
+	}
+	return dropped
+}
+
+// dropFastSyncDeposits drops the cross-shard tx lists downloaded during fast
+// sync and not verified yet which a block failing to execute may have
+// processed, as one of them may be wrong: the ones confirmed by the root blocks
+// from the cursor of its parent to its previous root block.
+func (m *MinorBlockChain) dropFastSyncDeposits(block *types.MinorBlock) {
+	m.fastSyncDeposits.Lock()
+	defer m.fastSyncDeposits.Unlock()
+	_, hashes := rawdb.ReadFastSyncDeposits(m.db)
+	if len(hashes) == 0 {
+		return
+	}
+	parent := m.GetMinorBlock(block.ParentHash())
+	if parent == nil {
+		return
+	}
+	processed := make(map[common.Hash]struct{})
+	cursor := parent.Meta().XShardTxCursorInfo
+	for rBlock := m.GetRootBlockByHash(block.PrevRootBlockHash()); rBlock != nil && rBlock.NumberU64() >= cursor.RootBlockHeight; rBlock = m.GetRootBlockByHash(rBlock.ParentHash()) {
+		for _, header := range rBlock.MinorBlockHeaders() {
+			processed[header.Hash()] = struct{}{}
+		}
+		if rBlock.NumberU64() == 0 {
+			break
+		}
+	}
+	dropped := 0
+	for _, hash := range hashes {
+		if _, ok := processed[hash]; !ok {
+			continue
+		}
+		if m.ReadCrossShardTxList(hash) != nil {
+			rawdb.DeleteCrossShardTxList(m.db, hash)
+			m.crossShardTxListCache.Remove(hash)
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		log.Warn(m.logInfo+" Dropped cross-shard tx lists downloaded during fast sync", "block", block.NumberU64(),
+			"hash", block.Hash(), "count", dropped)
+	}
+}
+
+// verifyFastSyncDeposits stops tracking the cross-shard tx lists downloaded
+// during fast sync once a validated block processed them all, which it did if
+// its cursor is past the root blocks confirming them.
+func (m *MinorBlockChain) verifyFastSyncDeposits(block *types.MinorBlock) {
+	m.fastSyncDeposits.Lock()
+	defer m.fastSyncDeposits.Unlock()
+	rootHeight, hashes := rawdb.ReadFastSyncDeposits(m.db)
+	if len(hashes) > 0 && block.Meta().XShardTxCursorInfo.RootBlockHeight > rootHeight {
+		rawdb.DeleteFastSyncDeposits(m.db)
+		log.Info(m.logInfo+" Verified cross-shard tx lists downloaded during fast sync", "block", block.NumberU64(), "count", len(hashes))
+	}
+}
+
+// FinishFastSync makes the fast sync head, whose state and pending deposits
+// are downloaded, the head block, and the shard executes the blocks it adds
+// from now on.
+func (m *MinorBlockChain) FinishFastSync() error {
+	head := m.CurrentFastBlock()
+	if head == nil {
+		return ErrNotFastSyncing
+	}
+	if !m.HasState(head.Meta().Root) {
+		return fmt.Errorf("missing state of fast sync head %d [%x]", head.NumberU64(), head.Hash())
+	}
+	if missing := m.FastSyncMissingDeposits(); len(missing) != 0 {
+		return fmt.Errorf("missing %d cross-shard tx lists of fast sync head %d [%x]", len(missing), head.NumberU64(), head.Hash())
+	}
+	evmState, err := m.StateAt(head.Meta().Root)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.insert(head)
+	m.currentEvmState = evmState
+	rawdb.WriteHeadFastBlockHash(m.db, common.Hash{})
+	m.currentFastBlock.Store((*types.MinorBlock)(nil))
+	m.mu.Unlock()
+
+	m.chainHeadFeed.Send(MinorChainHeadEvent{Block: head})
+	log.Info(m.logInfo+" Fast sync done", "number", head.NumberU64(), "hash", head.Hash())
+	return nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFastSync(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2, err := account.CreatRandomAccountWithFullShardKey(0)
+	assert.NoError(t, err)
+	fakeMoney := uint64(10000000)
+	shardState := createDefaultShardState(setUp(&acc1, &fakeMoney, nil), nil, nil, nil, nil)
+	defer shardState.Stop()
+	fastState := createDefaultShardState(setUp(&acc1, &fakeMoney, nil), nil, nil, nil, nil)
+	defer fastState.Stop()
+
+	rootBlock, blocks, err := createContractBlocks(shardState, id1, &acc2, 3)
+	assert.NoError(t, err)
+
+	// the blocks are added without state, once only and in order
+	assert.Equal(t, ErrNotFastSyncing, fastState.AddBlockWithoutState(blocks[0]))
+	assert.True(t, fastState.StartFastSync())
+	_, err = fastState.AddRootBlock(rootBlock)
+	assert.NoError(t, err)
+	assert.Error(t, fastState.AddBlockWithoutState(blocks[1]))
+	assert.NoError(t, fastState.AddBlockWithoutState(blocks[0]))
+	assert.NoError(t, fastState.AddBlockWithoutState(blocks[1]))
+	assert.NoError(t, fastState.AddBlockWithoutState(blocks[0]))
+	assert.Equal(t, uint64(0), fastState.CurrentBlock().NumberU64())
+	assert.Equal(t, blocks[1].Hash(), fastState.CurrentFastBlock().Hash())
+	assert.True(t, fastState.HasBlock(blocks[1].Hash()))
+	assert.Error(t, fastState.FinishFastSync())
+
+	// the state of the head is downloaded from the full chain
+	sched, err := fastState.NewFastSyncState()
+	assert.NoError(t, err)
+	for sched.Pending() > 0 {
+		results := make([]trie.SyncResult, 0)
+		for _, hash := range sched.Missing(0) {
+			data, err := shardState.TrieNode(hash)
+			assert.NoError(t, err)
+			results = append(results, trie.SyncResult{Hash: hash, Data: data})
+		}
+		_, _, err := sched.Process(results)
+		assert.NoError(t, err)
+		_, err = sched.Commit(fastState.db)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, fastState.FinishFastSync())
+	assert.False(t, fastState.IsFastSyncing())
+	assert.Equal(t, blocks[1].Hash(), fastState.CurrentBlock().Hash())
+	balance, err := fastState.GetBalance(acc1.Recipient, nil)
+	assert.NoError(t, err)
+	hash := blocks[1].Hash()
+	expected, err := shardState.GetBalance(acc1.Recipient, &hash)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, expected.GetTokenBalance(shardState.GetGenesisToken()).Cmp(balance.GetTokenBalance(fastState.GetGenesisToken())))
+	assert.NotEqual(t, 0, balance.GetTokenBalance(fastState.GetGenesisToken()).Cmp(new(big.Int)))
+
+	// the cross-shard tx lists downloaded are kept when a block not processing
+	// them fails to execute
+	neighbor := &types.MinorBlockHeader{Branch: account.Branch{Value: fastState.branch.Value + 1}, PrevRootBlockHash: rootBlock.Hash()}
+	nextRootBlock := rootBlock.Header().CreateBlockToAppend(nil, nil, nil, nil, nil)
+	nextRootBlock.AddMinorBlockHeader(neighbor)
+	nextRootBlock = nextRootBlock.Finalize(nil, nil, common.Hash{})
+	shardState.AddCrossShardTxListByMinorBlockHash(neighbor.Hash(), types.CrossShardTransactionDepositList{})
+	fastState.AddCrossShardTxListByMinorBlockHash(neighbor.Hash(), types.CrossShardTransactionDepositList{})
+	_, err = fastState.AddRootBlock(nextRootBlock)
+	assert.NoError(t, err)
+	fastState.AddFastSyncCrossShardTxList(neighbor.Hash(), types.CrossShardTransactionDepositList{})
+	fastState.dropFastSyncDeposits(blocks[2])
+	assert.Equal(t, 0, len(fastState.FastSyncDroppedDeposits()))
+
+	// and the next blocks are executed
+	_, err = fastState.InsertChain([]types.IBlock{blocks[2]}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[2].Hash(), fastState.CurrentBlock().Hash())
+	assert.Equal(t, blocks[2].Meta().Root, fastState.currentEvmState.IntermediateRoot(true))
+	_, hashes := rawdb.ReadFastSyncDeposits(fastState.db)
+	assert.Equal(t, []common.Hash{neighbor.Hash()}, hashes)
+
+	// they are dropped when a block processing them fails to execute, to be
+	// downloaded again
+	_, err = shardState.AddRootBlock(nextRootBlock)
+	assert.NoError(t, err)
+	badBlock, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	badBlock.Finalize(nil, common.Hash{}, nil, nil, badBlock.CoinbaseAmount(), badBlock.Meta().XShardTxCursorInfo)
+	_, err = fastState.InsertChain([]types.IBlock{badBlock}, false)
+	assert.Error(t, err)
+	assert.Nil(t, fastState.ReadCrossShardTxList(neighbor.Hash()))
+	assert.Equal(t, []common.Hash{neighbor.Hash()}, fastState.FastSyncDroppedDeposits())
+	fastState.AddFastSyncCrossShardTxList(neighbor.Hash(), types.CrossShardTransactionDepositList{})
+	assert.Equal(t, 0, len(fastState.FastSyncDroppedDeposits()))
+
+	// the lists are verified once a block processed the root blocks confirming them
+	block, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	block, _, err = shardState.FinalizeAndAddBlock(block)
+	assert.NoError(t, err)
+	_, err = fastState.InsertChain([]types.IBlock{block}, false)
+	assert.NoError(t, err)
+	_, hashes = rawdb.ReadFastSyncDeposits(fastState.db)
+	assert.Equal(t, 0, len(hashes))
+}
@@ -17,11 +17,13 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"math/big"
 
 	"github.com/QuarkChain/goquarkchain/core/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 )
 
 type Backend interface {
@@ -29,6 +31,9 @@ type Backend interface {
 	GetReceiptsByHash(hash common.Hash) types.Receipts
 	GetLogs(hash common.Hash) [][]*types.Log
 	CurrentBlock() *types.MinorBlock
+
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
 // Filter can be used to retrieve and filter logs.
@@ -40,6 +45,8 @@ type Filter struct {
 
 	block      common.Hash // Block hash if filtering a single block
 	begin, end uint64      // Range interval if filtering multiple blocks
+
+	matcher *bloombits.Matcher
 }
 
 // NewRangeFilter creates a new filter which uses a bloom filter on blocks to
@@ -64,9 +71,12 @@ func NewRangeFilter(backend Backend, begin, end uint64, addresses []common.Addre
 		filters = append(filters, filter)
 	}
 
+	size, _ := backend.BloomStatus()
+
 	// Create a generic filter and convert it into a range filter
 	filter := newFilter(backend, addresses, topics)
 
+	filter.matcher = bloombits.NewMatcher(size, filters)
 	filter.begin = begin
 	filter.end = end
 
@@ -91,13 +101,70 @@ func (f *Filter) Logs() ([]*types.Log, error) {
 		logs []*types.Log
 		err  error
 	)
-
+	size, sections := f.backend.BloomStatus()
+	if indexed := sections * size; indexed > f.begin {
+		if indexed > f.end {
+			logs, err = f.indexedLogs(context.Background(), f.end)
+		} else {
+			logs, err = f.indexedLogs(context.Background(), indexed-1)
+		}
+		if err != nil {
+			return logs, err
+		}
+	}
 	rest, err := f.unindexedLogs(f.end)
 	logs = append(logs, rest...)
 	return logs, err
 }
 
-// indexedLogs returns the logs matching the filter criteria based on raw block
+// indexedLogs returns the logs matching the filter criteria based on the bloom
+// bits indexed locally.
+func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	// Create a matcher session and request servicing from the backend
+	matches := make(chan uint64, 64)
+
+	session, err := f.matcher.Start(ctx, f.begin, end, matches)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	f.backend.ServiceFilter(ctx, session)
+
+	// Iterate over the matches until exhausted or context closed
+	var logs []*types.Log
+
+	for {
+		select {
+		case number, ok := <-matches:
+			// Abort if all matches have been fulfilled
+			if !ok {
+				err := session.Error()
+				if err == nil {
+					f.begin = end + 1
+				}
+				return logs, err
+			}
+			f.begin = number + 1
+
+			// Retrieve the suggested block and pull any truly matching logs
+			block, ok := f.backend.GetBlockByNumber(number).(*types.MinorBlock)
+			if !ok {
+				return logs, errors.New("no such block")
+			}
+			found, err := f.checkMatches(block.Header())
+			if err != nil {
+				return logs, err
+			}
+			logs = append(logs, found...)
+
+		case <-ctx.Done():
+			return logs, ctx.Err()
+		}
+	}
+}
+
+// unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(end uint64) ([]*types.Log, error) {
 	var logs []*types.Log
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/state"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const maxFeeHistoryBlocks = 1024
+
+// gasPriceConverter converts gas prices of a token into the default chain token
+// with the state the transactions of a block were executed on, which gives the
+// price they paid.
+type gasPriceConverter struct {
+	m       *MinorBlockChain
+	tokenID uint64
+	block   common.Hash
+	state   *state.StateDB
+	err     error
+	prices  map[uint64]uint64
+}
+
+func (m *MinorBlockChain) newGasPriceConverter(tokenID uint64) *gasPriceConverter {
+	return &gasPriceConverter{m: m, tokenID: tokenID}
+}
+
+func (c *gasPriceConverter) convert(block *types.MinorBlock, price uint64) (uint64, error) {
+	if c.tokenID == c.m.clusterConfig.Quarkchain.GetDefaultChainTokenID() {
+		return price, nil
+	}
+	if c.block != block.Hash() {
+		c.block, c.state, c.err, c.prices = block.Hash(), nil, nil, make(map[uint64]uint64)
+		if parent := c.m.GetMinorBlock(block.ParentHash()); parent == nil {
+			c.err = fmt.Errorf("no parent of block %d [%x]", block.NumberU64(), block.Hash())
+		} else if c.state, c.err = c.m.StateAt(parent.Root()); c.err == nil {
+			c.m.setEvmStateWithBlock(c.state, block)
+		}
+	}
+	if c.err != nil {
+		return 0, c.err
+	}
+	if converted, ok := c.prices[price]; ok {
+		return converted, nil
+	}
+	converted, err := ConvertToDefaultChainTokenGasPrice(c.state, c.m.ChainConfig(), c.tokenID, new(big.Int).SetUint64(price))
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert gas price of token %d: %v", c.tokenID, err)
+	}
+	c.prices[price] = converted.Uint64()
+	return converted.Uint64(), nil
+}
+
+// blockGasPrices returns the gas prices paid in the token of the converter by
+// the transactions of the block, leaving out the prices below the ignore price
+// of the oracle once converted to the default chain token. The prices which
+// cannot be converted, as the state of the block is pruned for instance, are
+// kept.
+func (m *MinorBlockChain) blockGasPrices(block *types.MinorBlock, converter *gasPriceConverter) []uint64 {
+	prices := make([]uint64, 0)
+	for _, tx := range block.GetTransactions() {
+		if tx.EvmTx.GasTokenID() != converter.tokenID {
+			continue
+		}
+		price := tx.EvmTx.GasPrice().Uint64()
+		if m.gasPriceSuggestionOracle.IgnorePrice > 0 {
+			converted, err := converter.convert(block, price)
+			if err == nil && converted < m.gasPriceSuggestionOracle.IgnorePrice {
+				continue
+			}
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+// FeeHistory returns the percentiles of the gas prices paid in tokenID by the
+// last blockCount blocks, percentiles are in basis points in ascending order.
+// The prices in the default chain token are left to 0 for the blocks whose
+// prices cannot be converted.
+func (m *MinorBlockChain) FeeHistory(tokenID, blockCount uint64, percentiles []uint32) (*rpc.FeeHistory, error) {
+	for i, p := range percentiles {
+		if p > 10000 || (i > 0 && p < percentiles[i-1]) {
+			return nil, errors.New("percentiles should be in ascending order between 0 and 100")
+		}
+	}
+	tip := m.CurrentBlock().NumberU64()
+	if blockCount > maxFeeHistoryBlocks {
+		blockCount = maxFeeHistoryBlocks
+	}
+	if blockCount > tip+1 {
+		blockCount = tip + 1
+	}
+
+	converter := m.newGasPriceConverter(tokenID)
+	history := &rpc.FeeHistory{Blocks: make([]*rpc.FeeHistoryBlock, 0, blockCount)}
+	for height := tip + 1 - blockCount; height <= tip; height++ {
+		block, ok := m.GetBlockByNumber(height).(*types.MinorBlock)
+		if !ok || block == nil {
+			return nil, fmt.Errorf("no such block:height %d", height)
+		}
+		prices := m.blockGasPrices(block, converter)
+		sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+		entry := &rpc.FeeHistoryBlock{
+			Height:                     height,
+			GasUsed:                    block.GasUsed().Uint64(),
+			GasLimit:                   block.GasLimit().Uint64(),
+			GasPrices:                  make([]uint64, len(percentiles)),
+			DefaultChainTokenGasPrices: make([]uint64, len(percentiles)),
+		}
+		if len(prices) > 0 {
+			for i, p := range percentiles {
+				price := prices[(len(prices)-1)*int(p)/10000]
+				entry.GasPrices[i] = price
+				if converted, err := converter.convert(block, price); err == nil {
+					entry.DefaultChainTokenGasPrices[i] = converted
+				}
+			}
+		}
+		history.Blocks = append(history.Blocks, entry)
+	}
+	return history, nil
+}
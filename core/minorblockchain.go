@@ -39,14 +39,17 @@ import (
 	"github.com/QuarkChain/goquarkchain/core/types"
 	"github.com/QuarkChain/goquarkchain/core/vm"
 	qkcParams "github.com/QuarkChain/goquarkchain/params"
+	"github.com/QuarkChain/goquarkchain/qkcdb"
 	"github.com/QuarkChain/goquarkchain/serialize"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/common/prque"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	lru "github.com/hashicorp/golang-lru"
 )
@@ -68,6 +71,7 @@ type gasPriceSuggestionOracle struct {
 	cache       *lru.Cache
 	CheckBlocks uint64
 	Percentile  uint64
+	IgnorePrice uint64
 }
 
 type CoinbaseAmountAboutHeight struct {
@@ -106,12 +110,17 @@ type MinorBlockChain struct {
 	scope         event.SubscriptionScope
 	genesisBlock  *types.MinorBlock
 
+	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
+	bloomIndexer  *ChainIndexer                  // Bloom indexer operating during block imports
+
 	mu      sync.RWMutex // global mutex for locking chain operations
 	chainmu sync.RWMutex // blockchain insertion lock
 	procmu  sync.RWMutex // block processor lock
 
-	checkpoint   int          // checkpoint counts towards the new checkpoint
-	currentBlock atomic.Value // Current head of the block chain
+	checkpoint       int          // checkpoint counts towards the new checkpoint
+	currentBlock     atomic.Value // Current head of the block chain
+	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	fastSyncDeposits sync.Mutex   // guards the cross-shard tx lists downloaded during fast sync to verify
 
 	stateCache            state.Database // State database to reuse between imports (contains state cache)
 	receiptsCache         *lru.Cache     // Cache for the most recent receipts per block
@@ -153,6 +162,8 @@ type MinorBlockChain struct {
 	posw                     consensus.PoSWCalculator
 	gasLimit                 *big.Int
 	xShardGasLimit           *big.Int
+	insertTimer              metrics.Timer     // time taken to import a block
+	reorgDepth               metrics.Histogram // number of blocks dropped by the reorgs
 }
 
 // NewMinorBlockChain returns a fully initialised block chain using information
@@ -187,6 +198,13 @@ func NewMinorBlockChain(
 	rootBlockCache, _ := lru.New(maxRootBlockLimit)
 	lastConfimCache, _ := lru.New(maxLastConfirmLimit)
 	gasPriceCache, _ := lru.New(maxGasPriceCacheLimit)
+	oracleConfig := clusterConfig.GasPriceOracle
+	if oracleConfig == nil {
+		oracleConfig = config.NewGasPriceOracleConfig()
+	}
+	if err := oracleConfig.Validate(); err != nil {
+		return nil, err
+	}
 	bc := &MinorBlockChain{
 		ethChainConfig:           chainConfig,
 		clusterConfig:            clusterConfig,
@@ -195,6 +213,7 @@ func NewMinorBlockChain(
 		triegc:                   prque.New(nil),
 		stateCache:               state.NewDatabaseWithCache(db, cacheConfig.TrieCleanLimit),
 		quit:                     make(chan struct{}),
+		bloomRequests:            make(chan chan *bloombits.Retrieval),
 		shouldPreserve:           shouldPreserve,
 		receiptsCache:            receiptsCache,
 		blockCache:               blockCache,
@@ -214,10 +233,14 @@ func NewMinorBlockChain(
 		rewardCalc:               &qkcCommon.ConstMinorBlockRewardCalculator{},
 		gasPriceSuggestionOracle: &gasPriceSuggestionOracle{
 			cache:       gasPriceCache,
-			CheckBlocks: 5,
-			Percentile:  50,
+			CheckBlocks: oracleConfig.CheckBlocks,
+			Percentile:  oracleConfig.Percentile,
+			IgnorePrice: oracleConfig.IgnorePrice,
 		},
-		logInfo: fmt.Sprintf("shard:%x", fullShardID),
+		logInfo:     fmt.Sprintf("shard:%x", fullShardID),
+		insertTimer: metrics.GetOrRegisterTimer(fmt.Sprintf("chain/shard/%d/inserts", fullShardID), nil),
+		reorgDepth: metrics.GetOrRegisterHistogram(fmt.Sprintf("chain/shard/%d/reorg/depth", fullShardID), nil,
+			metrics.NewExpDecaySample(1028, 0.015)),
 	}
 	var err error
 	bc.gasLimit, err = bc.clusterConfig.Quarkchain.GasLimit(bc.branch.Value)
@@ -242,6 +265,12 @@ func NewMinorBlockChain(
 	}
 	bc.posw = consensus.CreatePoSWCalculator(bc, bc.shardConfig.PoswConfig)
 	bc.txPool = NewTxPool(DefaultTxPoolConfig, bc)
+	bc.bloomIndexer = NewBloomIndexer(db, bloomBitsBlocks, bloomConfirms, bc.logInfo+" bloombits")
+	// the bloom bits would be dropped by a read-only database
+	if !clusterConfig.CheckDB && !qkcdb.IsReadOnly(db) {
+		bc.bloomIndexer.Start(bc)
+	}
+	bc.startBloomHandlers(bloomBitsBlocks)
 	// Take ownership of this particular state
 	go bc.update()
 	return bc, nil
@@ -294,6 +323,14 @@ func (m *MinorBlockChain) loadLastState() error {
 	// Everything seems to be fine, set as the head block
 	m.currentBlock.Store(currentBlock)
 
+	// Restore the last known head fast block
+	m.currentFastBlock.Store((*types.MinorBlock)(nil))
+	if head := rawdb.ReadHeadFastBlockHash(m.db); head != (common.Hash{}) {
+		if block := m.GetMinorBlock(head); block != nil {
+			m.currentFastBlock.Store(block)
+			log.Info(m.logInfo+" Fast syncing", "number", block.NumberU64(), "hash", block.Hash())
+		}
+	}
 	return nil
 }
 
@@ -656,6 +693,9 @@ func (m *MinorBlockChain) Stop() {
 	if !atomic.CompareAndSwapInt32(&m.running, 0, 1) {
 		return
 	}
+	if err := m.bloomIndexer.Close(); err != nil {
+		log.Error(m.logInfo+" Failed to close bloom indexer", "err", err)
+	}
 	// Unsubscribe all subscriptions registered from blockchain
 	m.scope.Close()
 	close(m.quit)
@@ -1138,11 +1178,17 @@ func (m *MinorBlockChain) insertChain(chain []types.IBlock, verifySeals bool, is
 		state, receipts, logs, usedGas, xShardReceiveTxList, err := m.runBlock(mBlock)
 		if err != nil {
 			m.reportBlock(block, receipts, err)
+			if !isCheckDB {
+				m.dropFastSyncDeposits(mBlock)
+			}
 			return it.index, events, coalescedLogs, xShardList, err
 		}
 		// Validate the state using the default validator
 		if err := m.Validator().ValidateState(block, parent, state, receipts, usedGas); err != nil {
 			m.reportBlock(block, receipts, err)
+			if !isCheckDB {
+				m.dropFastSyncDeposits(mBlock)
+			}
 			return it.index, events, coalescedLogs, xShardList, err
 		}
 
@@ -1150,6 +1196,7 @@ func (m *MinorBlockChain) insertChain(chain []types.IBlock, verifySeals bool, is
 			xShardList = append(xShardList, state.GetXShardList())
 			return 0, events, coalescedLogs, xShardList, nil
 		}
+		m.verifyFastSyncDeposits(mBlock)
 		updateTip, err := m.updateTip(state, mBlock)
 		if err != nil {
 			return it.index, events, coalescedLogs, xShardList, err
@@ -1159,6 +1206,7 @@ func (m *MinorBlockChain) insertChain(chain []types.IBlock, verifySeals bool, is
 		if err != nil {
 			return it.index, events, coalescedLogs, xShardList, err
 		}
+		m.insertTimer.UpdateSince(start)
 		switch status {
 		case CanonStatTy:
 			log.Debug(m.logInfo+" Inserted new block", "number", mBlock.NumberU64(), "hash", mBlock.Hash().TerminalString(),
@@ -1402,6 +1450,7 @@ func (m *MinorBlockChain) reorg(oldBlock, newBlock types.IBlock) error {
 		} else {
 			log.Warn("ChainRevert genesis", "drop", len(oldChain), "dropfrom", oldChain[0].Hash(), "add", len(newChain), "addfrom", newChain[0].Hash())
 		}
+		m.reorgDepth.Update(int64(len(oldChain)))
 
 	} else {
 		// we support reorg block from same chain,because we should delete and add tx index
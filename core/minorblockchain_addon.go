@@ -258,6 +258,9 @@ func (m *MinorBlockChain) validateTx(tx *types.Transaction, evmState *state.Stat
 			return nil, errors.New("smart contract tx is not allowed before evm is enabled")
 		}
 	}
+	if err := checkEthTransaction(m.clusterConfig.Quarkchain, tx, m.CurrentBlock().NumberU64()+1); err != nil {
+		return nil, err
+	}
 	var sender account.Recipient
 	if fromAddress == nil {
 		sender, err = tx.Sender(types.NewEIP155Signer(m.clusterConfig.Quarkchain.NetworkID))
@@ -372,6 +375,18 @@ func (m *MinorBlockChain) isNeighbor(remoteBranch account.Branch, rootHeight *ui
 	return account.IsNeighbor(m.branch, remoteBranch, uint32(shardSize))
 }
 
+// hasCrossShardTxList returns whether the shard receives a cross-shard tx list
+// from the minor block of header, which is of another shard.
+func (m *MinorBlockChain) hasCrossShardTxList(header *types.MinorBlockHeader) bool {
+	// prev_root_header can be None when the shard is not created at root height 0
+	prevRootBlock := m.GetRootBlockByHash(header.PrevRootBlockHash)
+	if prevRootBlock == nil || prevRootBlock.Number() == uint32(m.clusterConfig.Quarkchain.GetGenesisRootHeight(m.branch.Value)) {
+		return false
+	}
+	t := prevRootBlock.Number()
+	return m.isNeighbor(header.Branch, &t)
+}
+
 func (m *MinorBlockChain) putRootBlock(rBlock *types.RootBlock, minorHeader *types.MinorBlockHeader) {
 	log.Debug(m.logInfo+" putRootBlock", "rNumber", rBlock.Number(), "lenMinor", len(rBlock.MinorBlockHeaders()))
 	rBlockHash := rBlock.Hash()
@@ -441,6 +456,10 @@ func (m *MinorBlockChain) InitFromRootBlock(rBlock *types.RootBlock) error {
 		log.Error(m.logInfo, "confirmedHeaderTip", confirmedHeaderTip, "rBlock.Hash", rBlock.Hash().String())
 		headerTip = m.GetBlockByNumber(0).(*types.MinorBlock)
 	}
+	if m.IsFastSyncing() {
+		// the blocks added by the fast sync are not executed
+		headerTip = m.CurrentBlock()
+	}
 
 	m.rootTip = rBlock
 	m.confirmedHeaderTip = confirmedHeaderTip
@@ -638,8 +657,48 @@ func (m *MinorBlockChain) GetStorageAt(recipient account.Recipient, key common.H
 	return evmState.GetState(recipient, key), nil
 }
 
+// applyStateOverrides replaces the state of the overridden accounts in evmState,
+// which must be a throwaway copy as the overrides are never meant to be committed.
+func applyStateOverrides(evmState *state.StateDB, overrides []*rpc.StateOverride) error {
+	seen := make(map[account.Recipient]struct{}, len(overrides))
+	for _, override := range overrides {
+		if override == nil {
+			continue
+		}
+		if _, ok := seen[override.Address]; ok {
+			return fmt.Errorf("duplicated state override for %x", override.Address)
+		}
+		seen[override.Address] = struct{}{}
+		for _, balance := range override.Balances {
+			if balance == nil || balance.Balance == nil {
+				return fmt.Errorf("empty balance override for %x", override.Address)
+			}
+			evmState.SetBalance(override.Address, balance.Balance, balance.TokenID)
+		}
+		if override.Nonce != nil {
+			evmState.SetNonce(override.Address, *override.Nonce)
+		}
+		if override.OverrideCode {
+			evmState.SetCode(override.Address, override.Code)
+		}
+		if override.ReplaceStorage {
+			storage := make(map[common.Hash]common.Hash, len(override.Storage))
+			for _, entry := range override.Storage {
+				storage[entry.Key] = entry.Value
+			}
+			evmState.SetStorage(override.Address, storage)
+		} else {
+			for _, entry := range override.Storage {
+				evmState.SetState(override.Address, entry.Key, entry.Value)
+			}
+		}
+	}
+	return nil
+}
+
 // ExecuteTx execute tx
-func (m *MinorBlockChain) ExecuteTx(tx *types.Transaction, fromAddress *account.Address, height *uint64) ([]byte, error) {
+func (m *MinorBlockChain) ExecuteTx(tx *types.Transaction, fromAddress *account.Address, height *uint64,
+	overrides []*rpc.StateOverride) ([]byte, error) {
 	if height == nil {
 		temp := m.CurrentBlock().NumberU64()
 		height = &temp
@@ -656,6 +715,9 @@ func (m *MinorBlockChain) ExecuteTx(tx *types.Transaction, fromAddress *account.
 		return nil, err
 	}
 	state := evmState.Copy()
+	if err := applyStateOverrides(state, overrides); err != nil {
+		return nil, err
+	}
 	state.SetGasUsed(new(big.Int).SetUint64(0))
 	var gas uint64
 	if tx.EvmTx.Gas() != 0 {
@@ -961,11 +1023,12 @@ func (m *MinorBlockChain) AddRootBlock(rBlock *types.RootBlock) (bool, error) {
 			shardHeaders = append(shardHeaders, mHeader)
 			continue
 		}
-		prevRootBlock := m.GetRootBlockByHash(mHeader.PrevRootBlockHash)
-
-		// prev_root_header can be None when the shard is not created at root height 0
-		t := prevRootBlock.Number()
-		if prevRootBlock == nil || prevRootBlock.Number() == uint32(m.clusterConfig.Quarkchain.GetGenesisRootHeight(m.branch.Value)) || !m.isNeighbor(mHeader.Branch, &t) {
+		// the deposits of the blocks before the fast sync head are downloaded
+		// along with its state
+		if m.IsFastSyncing() {
+			continue
+		}
+		if !m.hasCrossShardTxList(mHeader) {
 			if data := m.ReadCrossShardTxList(h); data != nil {
 				errXshardListAlreadyHave := errors.New("already have")
 				log.Error(m.logInfo, "addrootBlock err-1", errXshardListAlreadyHave)
@@ -1031,6 +1094,10 @@ func (m *MinorBlockChain) AddRootBlock(rBlock *types.RootBlock) (bool, error) {
 	}
 
 	m.mu.Unlock()
+	// the head stays at the last block executed until the fast sync completes
+	if m.IsFastSyncing() {
+		return false, nil
+	}
 	origHeaderTip := m.CurrentBlock()
 	if shardHeader != nil {
 		origBlock := m.GetBlockByNumber(shardHeader.Number)
@@ -1093,6 +1160,9 @@ func (m *MinorBlockChain) GetTransactionByHash(hash common.Hash) (*types.MinorBl
 	_, mHash, txIndex := rawdb.ReadTransaction(m.db, hash)
 	if mHash == qkcCommon.EmptyHash { //TODO need? for test???
 		tx := m.txPool.all.Get(hash)
+		if tx == nil {
+			tx = m.txPool.all.GetByEthHash(hash)
+		}
 		if tx == nil {
 			return nil, 0
 		}
@@ -1109,6 +1179,10 @@ func (m *MinorBlockChain) GetTransactionReceipt(hash common.Hash) (*types.MinorB
 	if block == nil {
 		return nil, 0, nil
 	}
+	// the receipts know the transactions found by Ethereum hash by their hash
+	if int(index) < len(block.Transactions()) {
+		hash = block.Transactions()[index].Hash()
+	}
 	receipts := m.GetReceiptsByHash(block.Hash())
 	for _, receipt := range receipts {
 		if receipt.TxHash == hash {
@@ -1164,12 +1238,43 @@ func (m *MinorBlockChain) GetShardStats() (*rpc.ShardStatus, error) {
 	}, nil
 }
 
+// GetTxPoolContent returns the number of pending and queued transactions in
+// the pool along with the transactions of sender, or of every sender if nil.
+func (m *MinorBlockChain) GetTxPoolContent(sender *account.Recipient, countOnly bool) *rpc.ShardTxPool {
+	pending, queued := m.txPool.Stats()
+	content := &rpc.ShardTxPool{
+		Branch:   m.branch.Value,
+		Pending:  uint32(pending),
+		Queued:   uint32(queued),
+		Accounts: make([]*rpc.TxPoolAccount, 0),
+	}
+	if !countOnly {
+		content.Accounts = m.txPool.Accounts(sender)
+	}
+	return content
+}
+
 func (m *MinorBlockChain) GetPendingCount() int {
 	return m.txPool.PendingCount()
 }
 
+// GetPendingTxHashes returns the hashes of all the processable transactions in tx pool.
+func (m *MinorBlockChain) GetPendingTxHashes() ([]common.Hash, error) {
+	pending, err := m.txPool.Pending()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, 0)
+	for _, txs := range pending {
+		for _, tx := range txs {
+			hashes = append(hashes, tx.Hash())
+		}
+	}
+	return hashes, nil
+}
+
 // EstimateGas estimate gas for this tx
-func (m *MinorBlockChain) EstimateGas(tx *types.Transaction, fromAddress account.Address) (uint32, error) {
+func (m *MinorBlockChain) EstimateGas(tx *types.Transaction, fromAddress account.Address, overrides []*rpc.StateOverride) (uint32, error) {
 	// no need to locks
 	if tx.EvmTx.Gas() > math.MaxUint32 {
 		return 0, errors.New("gas > maxInt31")
@@ -1190,6 +1295,12 @@ func (m *MinorBlockChain) EstimateGas(tx *types.Transaction, fromAddress account
 	if err != nil {
 		return 0, err
 	}
+	if len(overrides) != 0 {
+		currentState = currentState.Copy()
+		if err := applyStateOverrides(currentState, overrides); err != nil {
+			return 0, err
+		}
+	}
 	if currentState.GetGasLimit().Uint64() > math.MaxInt32 {
 		return 0, errors.New("gasLimit > MaxInt32")
 	}
@@ -1252,19 +1363,14 @@ func (m *MinorBlockChain) GasPrice(tokenID uint64) (uint64, error) {
 		startHeight = 3
 	}
 	prices := make([]uint64, 0)
+	converter := m.newGasPriceConverter(tokenID)
 	for index := startHeight; index < int64(currHeight+1); index++ {
 		block, ok := m.GetBlockByNumber(uint64(index)).(*types.MinorBlock)
 		if !ok {
 			log.Error(m.logInfo, "failed to get block", index)
 			return 0, errors.New("failed to get block")
 		}
-		tempPreBlockPrices := make([]uint64, 0)
-		for _, tx := range block.GetTransactions() {
-			if tx.EvmTx.GasTokenID() == tokenID {
-				tempPreBlockPrices = append(tempPreBlockPrices, tx.EvmTx.GasPrice().Uint64())
-			}
-		}
-		prices = append(prices, tempPreBlockPrices...)
+		prices = append(prices, m.blockGasPrices(block, converter)...)
 	}
 	if len(prices) == 0 {
 		return m.clusterConfig.Quarkchain.MinTXPoolGasPrice.Uint64(), nil
@@ -1674,6 +1780,9 @@ func (m *MinorBlockChain) putTxHistoryIndex(tx *types.Transaction, height uint64
 
 func (m *MinorBlockChain) removeTxHistoryIndex(db rawdb.DatabaseDeleter, tx *types.Transaction, height uint64, index int) error {
 	rawdb.DeleteBlockContentLookupEntry(db, tx.Hash())
+	if tx.EvmTx.Version() == types.EthTxVersion {
+		rawdb.DeleteBlockContentLookupEntry(db, tx.EvmTx.EthHash())
+	}
 	return m.updateTxHistoryIndex(tx, height, index, m.deleteTxIndexDB)
 }
 
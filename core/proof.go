@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GetProof returns the state of the account in the minor block of the given
+// hash, with the proofs of the account, its token balances and the requested
+// storage slots against the state root of the block.
+func (m *MinorBlockChain) GetProof(recipient account.Recipient, storageKeys []common.Hash, hash *common.Hash) (*rpc.AccountProof, error) {
+	if hash == nil {
+		t := m.CurrentBlock().Hash()
+		hash = &t
+	}
+	mBlock := m.GetMinorBlock(*hash)
+	if mBlock == nil {
+		return nil, fmt.Errorf("no such block:hash %v", (*hash).String())
+	}
+	root := mBlock.GetMetaData().Root
+	evmState, err := m.StateAt(root)
+	if err != nil {
+		return nil, err
+	}
+
+	accountRLP, err := evmState.GetAccountRLP(recipient)
+	if err != nil {
+		return nil, err
+	}
+	accountProof, err := evmState.GetProof(recipient)
+	if err != nil {
+		return nil, err
+	}
+	result := &rpc.AccountProof{
+		MinorBlockHash:   mBlock.Hash(),
+		MinorBlockHeight: mBlock.NumberU64(),
+		StateRoot:        root,
+		AccountRLP:       accountRLP,
+		AccountProof:     accountProof,
+		Balances:         make([]*rpc.TokenBalanceProof, 0),
+		StorageProofs:    make([]*rpc.StorageProof, 0, len(storageKeys)),
+	}
+	exist := len(accountRLP) != 0
+	if exist {
+		result.Nonce = evmState.GetNonce(recipient)
+		result.CodeHash = evmState.GetCodeHash(recipient)
+		result.StorageRoot = evmState.GetStorageRoot(recipient)
+
+		tokenTrieRoot, balanceProofs, err := evmState.GetTokenBalanceProofs(recipient)
+		if err != nil {
+			return nil, err
+		}
+		result.TokenTrieRoot = tokenTrieRoot
+		if balanceProofs != nil {
+			for _, p := range balanceProofs {
+				result.Balances = append(result.Balances, &rpc.TokenBalanceProof{TokenID: p.TokenID, Balance: p.Balance, Proof: p.Proof})
+			}
+		} else {
+			balances := evmState.GetBalances(recipient).GetBalanceMap()
+			for tokenID, balance := range balances {
+				if balance.Sign() > 0 {
+					result.Balances = append(result.Balances, &rpc.TokenBalanceProof{TokenID: tokenID, Balance: balance, Proof: rpc.MerkleProof{}})
+				}
+			}
+			sort.Slice(result.Balances, func(i, j int) bool {
+				return result.Balances[i].TokenID < result.Balances[j].TokenID
+			})
+		}
+	}
+
+	for _, key := range storageKeys {
+		storageProof := &rpc.StorageProof{Key: key, Proof: rpc.MerkleProof{}}
+		if exist {
+			storageProof.Value = evmState.GetState(recipient, key)
+			proof, err := evmState.GetStorageProof(recipient, key)
+			if err != nil {
+				return nil, err
+			}
+			storageProof.Proof = proof
+		}
+		result.StorageProofs = append(result.StorageProofs, storageProof)
+	}
+	return result, nil
+}
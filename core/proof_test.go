@@ -0,0 +1,118 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyProof(t *testing.T, root common.Hash, key []byte, proof rpc.MerkleProof) []byte {
+	proofDB := ethdb.NewMemDatabase()
+	for _, node := range proof {
+		assert.NoError(t, proofDB.Put(crypto.Keccak256(node), node))
+	}
+	value, _, err := trie.VerifyProof(root, crypto.Keccak256(key), proofDB)
+	assert.NoError(t, err)
+	return value
+}
+
+func TestGetProof(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2, err := account.CreatRandomAccountWithFullShardKey(0)
+	assert.NoError(t, err)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil).Finalize(nil, nil, common.Hash{})
+	_, err = shardState.AddRootBlock(rootBlock)
+	checkErr(err)
+
+	tx0, err := CreateContract(shardState, id1.GetKey(), acc1, acc1.FullShardKey, ContractWithStorage2)
+	assert.NoError(t, err)
+	assert.NoError(t, shardState.AddTx(tx0))
+	b1, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	_, _, err = shardState.FinalizeAndAddBlock(b1)
+	assert.NoError(t, err)
+	_, _, contractReceipt := shardState.GetTransactionReceipt(tx0.Hash())
+	contractAddress := account.NewAddress(contractReceipt.ContractAddress, contractReceipt.ContractFullShardKey)
+
+	data, err := hex.DecodeString("c2e171d7")
+	value, gasPrice, gas := big.NewInt(0), uint64(1), uint64(50000)
+	tx1 := createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, contractAddress,
+		value, &gas, &gasPrice, nil, data, nil, nil)
+	assert.NoError(t, shardState.AddTx(tx1))
+	b2, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	b2, _, err = shardState.FinalizeAndAddBlock(b2)
+	assert.NoError(t, err)
+
+	// the account of the sender
+	proof, err := shardState.GetProof(acc1.Recipient, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, b2.Hash(), proof.MinorBlockHash)
+	assert.Equal(t, b2.GetMetaData().Root, proof.StateRoot)
+	assert.Equal(t, proof.AccountRLP, verifyProof(t, proof.StateRoot, acc1.Recipient.Bytes(), proof.AccountProof))
+	assert.Equal(t, uint64(2), proof.Nonce)
+	assert.Equal(t, common.Hash{}, proof.TokenTrieRoot)
+	assert.Equal(t, 1, len(proof.Balances))
+	assert.Equal(t, shardState.GetGenesisToken(), proof.Balances[0].TokenID)
+	balances, err := shardState.GetBalance(acc1.Recipient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, balances.GetTokenBalance(shardState.GetGenesisToken()), proof.Balances[0].Balance)
+
+	// the storage of the contract
+	storageKeyBytes, err := hex.DecodeString(ZFill64(hex.EncodeToString(acc1.Recipient[:])) + ZFill64("1"))
+	assert.NoError(t, err)
+	keys := []common.Hash{{}, crypto.Keccak256Hash(storageKeyBytes), common.BigToHash(big.NewInt(100))}
+	proof, err = shardState.GetProof(contractAddress.Recipient, keys, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, proof.AccountRLP, verifyProof(t, proof.StateRoot, contractAddress.Recipient.Bytes(), proof.AccountProof))
+	assert.Equal(t, 3, len(proof.StorageProofs))
+	nonEmpty := 0
+	for i, storageProof := range proof.StorageProofs {
+		assert.Equal(t, keys[i], storageProof.Key)
+		stored, err := shardState.GetStorageAt(contractAddress.Recipient, keys[i], nil)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, storageProof.Value)
+		leaf := verifyProof(t, proof.StorageRoot, keys[i].Bytes(), storageProof.Proof)
+		if storageProof.Value == (common.Hash{}) {
+			assert.Nil(t, leaf)
+			continue
+		}
+		nonEmpty++
+		var content []byte
+		assert.NoError(t, rlp.DecodeBytes(leaf, &content))
+		assert.Equal(t, storageProof.Value, common.BytesToHash(content))
+	}
+	assert.Equal(t, 1, nonEmpty)
+
+	bytes, err := serialize.SerializeToBytes(proof)
+	assert.NoError(t, err)
+	decoded := new(rpc.AccountProof)
+	assert.NoError(t, serialize.DeserializeFromBytes(bytes, decoded))
+	assert.Equal(t, proof.AccountProof, decoded.AccountProof)
+	assert.Equal(t, proof.StorageProofs[0].Proof, decoded.StorageProofs[0].Proof)
+
+	// an account which does not exist
+	proof, err = shardState.GetProof(common.Address{1}, keys[:1], &b1.Header().ParentHash)
+	assert.NoError(t, err)
+	assert.Nil(t, verifyProof(t, proof.StateRoot, common.Address{1}.Bytes(), proof.AccountProof))
+	assert.Equal(t, 0, len(proof.AccountRLP))
+	assert.Equal(t, 0, len(proof.Balances))
+	assert.Equal(t, 0, len(proof.StorageProofs[0].Proof))
+}
@@ -23,6 +23,11 @@ type HashList struct {
 	HList []common.Hash `bytesizeofslicelen:"4"`
 }
 
+type fastSyncDeposits struct {
+	RootHeight uint64
+	Hashes     []common.Hash `bytesizeofslicelen:"4"`
+}
+
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
 func ReadCanonicalHash(db DatabaseReader, chainType ChainType, number uint64) common.Hash {
 	data, _ := db.Get(headerHashKey(chainType, number))
@@ -122,6 +127,42 @@ func WriteFastTrieProgress(db DatabaseWriter, count uint64) {
 	}
 }
 
+// ReadFastSyncDeposits retrieves the hashes of the minor blocks whose cross-shard
+// tx lists were downloaded during fast sync and are not verified yet, and the
+// height of the last root block confirming one of them.
+func ReadFastSyncDeposits(db DatabaseReader) (uint64, []common.Hash) {
+	data, _ := db.Get(fastSyncDepositsKey)
+	if len(data) == 0 {
+		return 0, nil
+	}
+	var deposits fastSyncDeposits
+	if err := serialize.DeserializeFromBytes(data, &deposits); err != nil {
+		log.Error("Invalid fast sync deposits", "err", err)
+		return 0, nil
+	}
+	return deposits.RootHeight, deposits.Hashes
+}
+
+// WriteFastSyncDeposits stores the hashes of the minor blocks whose cross-shard
+// tx lists were downloaded during fast sync and are not verified yet.
+func WriteFastSyncDeposits(db DatabaseWriter, rootHeight uint64, hashes []common.Hash) {
+	data, err := serialize.SerializeToBytes(&fastSyncDeposits{RootHeight: rootHeight, Hashes: hashes})
+	if err != nil {
+		log.Crit("Failed to serialize fast sync deposits", "err", err)
+	}
+	if err := db.Put(fastSyncDepositsKey, data); err != nil {
+		log.Crit("Failed to store fast sync deposits", "err", err)
+	}
+}
+
+// DeleteFastSyncDeposits removes the cross-shard tx lists downloaded during fast
+// sync from the ones to verify.
+func DeleteFastSyncDeposits(db DatabaseDeleter) {
+	if err := db.Delete(fastSyncDepositsKey); err != nil {
+		log.Crit("Failed to delete fast sync deposits", "err", err)
+	}
+}
+
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db DatabaseReader, hash common.Hash) bool {
 	if has, err := db.Has(headerKey(hash)); !has || err != nil {
@@ -546,6 +587,12 @@ func ReadCrossShardTxList(db DatabaseReader, hash common.Hash) *types.CrossShard
 	return list
 }
 
+func DeleteCrossShardTxList(db DatabaseDeleter, hash common.Hash) {
+	if err := db.Delete(makeXShardTxList(hash)); err != nil {
+		log.Crit("Failed to delete cross shard tx list", "err", err)
+	}
+}
+
 func WriteLastConfirmedMinorBlockHeaderAtRootBlock(db DatabaseWriter, rHash common.Hash, mHash common.Hash) {
 	if err := db.Put(makeRLastMHash(rHash), mHash.Bytes()); err != nil {
 		log.Crit("failed to store last confirmed  minot block at root block")
@@ -40,6 +40,12 @@ func WriteBlockContentLookupEntriesWithCrossShardHashList(db DatabaseWriter, blo
 		if err := db.Put(lookupKey(item.Hash()), data); err != nil {
 			log.Crit("Failed to store content lookup entry", "err", err)
 		}
+		// Ethereum tooling looks transactions up by their Ethereum hash
+		if tx, ok := item.(*types.Transaction); ok && tx.EvmTx != nil && tx.EvmTx.Version() == types.EthTxVersion {
+			if err := db.Put(lookupKey(tx.EvmTx.EthHash()), data); err != nil {
+				log.Crit("Failed to store content lookup entry", "err", err)
+			}
+		}
 	}
 	if hList == nil || len(hList.HList) == 0 {
 		return
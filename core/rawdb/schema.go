@@ -25,6 +25,9 @@ var (
 	// fastTrieProgressKey tracks the number of trie entries imported during fast sync.
 	fastTrieProgressKey = []byte("TrieSync")
 
+	// fastSyncDepositsKey tracks the cross-shard tx lists downloaded during fast sync not verified yet.
+	fastSyncDepositsKey = []byte("FastSyncDeposits")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix        = []byte("h")   // headerPrefix + hash -> header
 	latestMHeaderPrefix = []byte("lmh") //latestMHeaderPrefix + hash -> latest minor header list
@@ -30,11 +30,15 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	lru "github.com/hashicorp/golang-lru"
 )
 
 var (
 	ErrNoGenesis = errors.New("Genesis not found in chain")
+
+	rootBlockInsertTimer = metrics.NewRegisteredTimer("chain/root/inserts", nil)
+	rootBlockReorgDepth  = metrics.NewRegisteredHistogram("chain/root/reorg/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
 )
 
 const (
@@ -110,6 +114,8 @@ type RootBlockChain struct {
 	countMinorBlocks    bool
 	addBlockAndBroad    func(block *types.RootBlock) error
 	isCheckDB           bool
+	poswTrustedMu       sync.RWMutex
+	poswTrusted         map[common.Hash]bool // blocks whose miners are assumed to have the PoSW stakes
 	posw                consensus.PoSWCalculator
 	rootChainStakesFunc func(address account.Address, lastMinor common.Hash) (*big.Int, *account.Recipient, error)
 }
@@ -133,6 +139,7 @@ func NewRootBlockChain(db ethdb.Database, chainConfig *config.QuarkChainConfig,
 		engine:                   engine,
 		validatedMinorBlockCache: validatedMinorBlockHashCache,
 		isCheckDB:                false,
+		poswTrusted:              make(map[common.Hash]bool),
 	}
 	bc.SetValidator(NewRootBlockValidator(chainConfig, bc, engine))
 	bc.posw = posw.NewPoSW(bc, chainConfig.Root.PoSWConfig)
@@ -163,6 +170,29 @@ func (bc *RootBlockChain) SetIsCheckDB(isCheckDB bool) {
 	bc.isCheckDB = isCheckDB
 }
 
+// SetPoSWStakesTrusted makes the chain assume that the miners of the blocks with
+// the given hashes have the stakes PoSW requires, their seal is then checked
+// against the lowered difficulty only, or stops assuming it. It is set when
+// adding the root blocks of a state snapshot or fast synced, whose shards do not
+// keep the states the stakes are read from.
+func (bc *RootBlockChain) SetPoSWStakesTrusted(hashes []common.Hash, trusted bool) {
+	bc.poswTrustedMu.Lock()
+	defer bc.poswTrustedMu.Unlock()
+	for _, hash := range hashes {
+		if trusted {
+			bc.poswTrusted[hash] = true
+		} else {
+			delete(bc.poswTrusted, hash)
+		}
+	}
+}
+
+func (bc *RootBlockChain) isPoSWStakesTrusted(hash common.Hash) bool {
+	bc.poswTrustedMu.RLock()
+	defer bc.poswTrustedMu.RUnlock()
+	return bc.poswTrusted[hash]
+}
+
 func (bc *RootBlockChain) IsCheckDB() bool {
 	return bc.isCheckDB
 }
@@ -645,6 +675,7 @@ func (bc *RootBlockChain) insertChain(chain []types.IBlock, verifySeals bool) (i
 		if err != nil {
 			return it.index, events, err
 		}
+		rootBlockInsertTimer.UpdateSince(start)
 		switch status {
 		case CanonStatTy:
 			log.Debug("Inserted new block", "number", block.NumberU64(), "hash", block.Hash(),
@@ -849,6 +880,7 @@ func (bc *RootBlockChain) reorg(oldBlock, newBlock types.IBlock) error {
 		}
 		logFn("Chain split detected", "number", commonBlock.NumberU64(), "hash", commonBlock.Hash(),
 			"drop", len(oldChain), "dropfrom", oldChain[0].Hash(), "add", len(newChain), "addfrom", newChain[0].Hash())
+		rootBlockReorgDepth.Update(int64(len(oldChain)))
 	} else {
 		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.NumberU64(), "oldhash", oldBlock.Hash(), "newnum", newBlock.NumberU64(), "newhash", newBlock.Hash())
 	}
@@ -1105,6 +1137,9 @@ func (bc *RootBlockChain) GetAdjustedDifficulty(header types.IHeader) (*big.Int,
 		return guardianAdjustedDiff, 1, nil
 	}
 	if bc.posw.IsPoSWEnabled(header.GetTime(), header.NumberU64()) {
+		if bc.isPoSWStakesTrusted(header.Hash()) {
+			return header.GetDifficulty(), bc.Config().Root.PoSWConfig.DiffDivider, nil
+		}
 		poswAdjusted, err := bc.getPoSWAdjustedDiff(header)
 		if err != nil {
 			log.Debug("PoSW not applied", "reason", err, "coinbase", header.GetCoinbase().ToHex())
@@ -15,6 +15,7 @@ import (
 	"bou.ke/monkey"
 	"github.com/QuarkChain/goquarkchain/account"
 	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	qkcCommon "github.com/QuarkChain/goquarkchain/common"
 	"github.com/QuarkChain/goquarkchain/consensus"
 	"github.com/QuarkChain/goquarkchain/core/rawdb"
@@ -24,9 +25,11 @@ import (
 	"github.com/QuarkChain/goquarkchain/params"
 	"github.com/QuarkChain/goquarkchain/serialize"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	ethParams "github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -226,6 +229,56 @@ func TestGasPrice(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, gasPrice, uint64(0))
 
+	// qi converts to twice the price in the default chain token
+	monkey.Patch(GetGasUtilityInfo, func(a vm.StateDB, b *ethParams.ChainConfig, c uint64, gasPrice *big.Int) (uint8, *big.Int, error) {
+		return 100, new(big.Int).Mul(gasPrice, big.NewInt(2)), nil
+	})
+	history, err := shardState.FeeHistory(qiToken, 2, []uint32{0, 10000})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history.Blocks))
+	assert.Equal(t, uint64(4), history.Blocks[0].Height)
+	assert.Equal(t, []uint64{41, 41}, history.Blocks[0].GasPrices)
+	assert.Equal(t, []uint64{82, 82}, history.Blocks[0].DefaultChainTokenGasPrices)
+	assert.Equal(t, []uint64{40, 40}, history.Blocks[1].GasPrices)
+	assert.Equal(t, []uint64{80, 80}, history.Blocks[1].DefaultChainTokenGasPrices)
+
+	// prices below the ignore price in the default chain token are not sampled
+	shardState.gasPriceSuggestionOracle.IgnorePrice = 81
+	history, err = shardState.FeeHistory(qiToken, 100, []uint32{5000})
+	assert.NoError(t, err)
+	assert.Equal(t, currentNumber+1, len(history.Blocks))
+	assert.Equal(t, []uint64{41}, history.Blocks[4].GasPrices)
+	assert.Equal(t, []uint64{0}, history.Blocks[5].GasPrices)
+	shardState.gasPriceSuggestionOracle.cache.Purge()
+	shardState.gasPriceSuggestionOracle.Percentile = 0
+	gasPrice, err = shardState.GasPrice(qiToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(41), gasPrice)
+
+	// the prices are converted with the state of each block
+	monkey.Patch(GetGasUtilityInfo, func(a vm.StateDB, b *ethParams.ChainConfig, c uint64, gasPrice *big.Int) (uint8, *big.Int, error) {
+		return 100, new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(a.GetBlockNumber())), nil
+	})
+	history, err = shardState.FeeHistory(qiToken, 2, []uint32{0})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{41 * 4}, history.Blocks[0].DefaultChainTokenGasPrices)
+	assert.Equal(t, []uint64{40 * 5}, history.Blocks[1].DefaultChainTokenGasPrices)
+
+	// and the ones which cannot be converted are sampled all the same
+	monkey.Patch(GetGasUtilityInfo, func(a vm.StateDB, b *ethParams.ChainConfig, c uint64, gasPrice *big.Int) (uint8, *big.Int, error) {
+		return 0, nil, errors.New("no gas utility")
+	})
+	history, err = shardState.FeeHistory(qiToken, 2, []uint32{0})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{40}, history.Blocks[1].GasPrices)
+	assert.Equal(t, []uint64{0}, history.Blocks[1].DefaultChainTokenGasPrices)
+	shardState.gasPriceSuggestionOracle.cache.Purge()
+	gasPrice, err = shardState.GasPrice(qiToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(40), gasPrice)
+
+	_, err = shardState.FeeHistory(qiToken, 2, []uint32{5000, 1000})
+	assert.Error(t, err)
 }
 
 func TestEstimateGas(t *testing.T) {
@@ -247,13 +300,13 @@ func TestEstimateGas(t *testing.T) {
 		return createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, acc2, new(big.Int).SetUint64(123456), nil, nil, nil, data, nil, nil)
 	}
 	tx := txGen([]byte{})
-	estimate, err := shardState.EstimateGas(tx, acc1)
+	estimate, err := shardState.EstimateGas(tx, acc1, nil)
 	checkErr(err)
 
 	assert.Equal(t, estimate, uint32(21000))
 
 	newTx := txGen([]byte("12123478123412348125936583475758"))
-	estimate, err = shardState.EstimateGas(newTx, acc1)
+	estimate, err = shardState.EstimateGas(newTx, acc1, nil)
 	checkErr(err)
 	assert.Equal(t, estimate, uint32(23176))
 }
@@ -279,10 +332,83 @@ func TestExecuteTx(t *testing.T) {
 
 	// adding this line to make sure `execute_tx` would reset `gas_used`
 	currentEvmState.SetGasUsed(currentEvmState.GetGasLimit())
-	_, err = shardState.ExecuteTx(tx, &acc1, nil)
+	_, err = shardState.ExecuteTx(tx, &acc1, nil, nil)
 	checkErr(err)
 }
 
+func TestExecuteTxWithStateOverrides(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	checkErr(err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	id2, err := account.CreatRandomIdentity()
+	checkErr(err)
+	acc2 := account.CreatAddressFromIdentity(id2, 0)
+	contract, err := account.CreatRandomAccountWithFullShardKey(0)
+	checkErr(err)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil).Finalize(nil, nil, common.Hash{})
+	_, err = shardState.AddRootBlock(rootBlock)
+	checkErr(err)
+
+	// returns sload(0)
+	code := common.FromHex("60005460005260206000f3")
+	slot, value := common.Hash{}, common.BigToHash(big.NewInt(42))
+	genesisToken := shardState.GetGenesisToken()
+	nonce := uint64(7)
+	overrides := []*rpc.StateOverride{
+		{
+			Address:  acc2.Recipient,
+			Balances: []*types.TokenBalancePair{{TokenID: genesisToken, Balance: big.NewInt(1000000)}},
+			Nonce:    &nonce,
+		},
+		{
+			Address:      contract.Recipient,
+			OverrideCode: true,
+			Code:         code,
+			Storage:      []*rpc.StorageEntry{{Key: slot, Value: value}},
+		},
+	}
+
+	evmState, err := shardState.State()
+	checkErr(err)
+	evmState = evmState.Copy()
+	assert.NoError(t, applyStateOverrides(evmState, overrides))
+	copied := evmState.Copy()
+	assert.Equal(t, big.NewInt(1000000), copied.GetBalance(acc2.Recipient, genesisToken))
+	assert.Equal(t, nonce, copied.GetNonce(acc2.Recipient))
+	assert.Equal(t, code, copied.GetCode(contract.Recipient))
+	assert.Equal(t, value, copied.GetState(contract.Recipient, slot))
+	assert.Error(t, applyStateOverrides(evmState.Copy(), append(overrides, &rpc.StateOverride{Address: acc2.Recipient})))
+
+	// acc2 has no balance to pay for gas without the overrides
+	gas := uint64(50000)
+	tx := createTransferTransaction(shardState, id2.GetKey().Bytes(), acc2, contract, new(big.Int), &gas, nil, nil, nil, nil, nil)
+	_, err = shardState.ExecuteTx(tx, &acc2, nil, nil)
+	assert.Error(t, err)
+	ret, err := shardState.ExecuteTx(tx, &acc2, nil, overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, value.Bytes(), ret)
+
+	// the whole storage is replaced
+	overrides[1].ReplaceStorage = true
+	overrides[1].Storage = nil
+	ret, err = shardState.ExecuteTx(tx, &acc2, nil, overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}.Bytes(), ret)
+
+	_, err = shardState.EstimateGas(tx, acc2, overrides)
+	assert.NoError(t, err)
+
+	// the chain state is left untouched
+	evmState, err = shardState.State()
+	checkErr(err)
+	assert.Equal(t, 0, evmState.GetBalance(acc2.Recipient, genesisToken).Sign())
+	assert.Equal(t, 0, len(evmState.GetCode(contract.Recipient)))
+}
+
 func TestAddTxIncorrectFromShardID(t *testing.T) {
 	id1, err := account.CreatRandomIdentity()
 	checkErr(err)
@@ -297,7 +423,7 @@ func TestAddTxIncorrectFromShardID(t *testing.T) {
 	tx := createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, acc2, new(big.Int).SetUint64(12345), nil, nil, nil, nil, nil, nil)
 	err = shardState.AddTx(tx)
 	assert.Error(t, err)
-	_, err = shardState.ExecuteTx(tx, &acc1, nil)
+	_, err = shardState.ExecuteTx(tx, &acc1, nil, nil)
 	assert.Error(t, err)
 }
 
@@ -3637,3 +3763,62 @@ func TestXshardGasLimitFromMultipleShards(t *testing.T) {
 	tb = shardState0.currentEvmState.GetBalance(acc1.Recipient, shardState0.GetGenesisToken())
 	assert.Equal(t, tb, big.NewInt(10000000+1000000+12345+888888+111111))
 }
+
+func TestEthTransactionFork(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	checkErr(err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2, err := account.CreatRandomAccountWithFullShardKey(0)
+	checkErr(err)
+	acc3, err := account.CreatRandomAccountWithFullShardKey(0)
+	checkErr(err)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	fullShardId, err := env.clusterConfig.Quarkchain.GetFullShardIdByFullShardKey(0)
+	checkErr(err)
+	env.clusterConfig.Quarkchain.GetShardConfigByFullShardID(fullShardId).EnableEthTxHeight = 2
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	networkId := env.clusterConfig.Quarkchain.NetworkID
+	key, err := crypto.ToECDSA(id1.GetKey().Bytes())
+	checkErr(err)
+	ethTx := ethTypes.NewTransaction(0, common.Address(acc2.Recipient), big.NewInt(12345), 21000, new(big.Int), nil)
+	ethTx, err = ethTypes.SignTx(ethTx, ethTypes.NewEIP155Signer(new(big.Int).SetUint64(types.EthChainID(networkId, 0))), key)
+	checkErr(err)
+	encoded, err := rlp.EncodeToBytes(ethTx)
+	checkErr(err)
+	evmTx, err := types.NewEvmTransactionFromEth(encoded, networkId, 0)
+	checkErr(err)
+	tx := &types.Transaction{TxType: types.EvmTx, EvmTx: evmTx}
+
+	// neither the pool nor the blocks take the transaction below the fork height
+	assert.Equal(t, ErrEthTxNotEnabled, shardState.AddTx(tx))
+	b1, err := shardState.CreateBlockToMine(nil, &acc3, nil, nil, nil)
+	checkErr(err)
+	b1.AddTx(tx)
+	_, _, err = shardState.FinalizeAndAddBlock(b1)
+	assert.Equal(t, ErrEthTxNotEnabled, err)
+	b1, err = shardState.CreateBlockToMine(nil, &acc3, nil, nil, nil)
+	checkErr(err)
+	_, _, err = shardState.FinalizeAndAddBlock(b1)
+	checkErr(err)
+
+	assert.NoError(t, shardState.AddTx(tx))
+	// the pending transaction is known by its Ethereum hash
+	block, _ := shardState.GetTransactionByHash(ethTx.Hash())
+	assert.NotNil(t, block)
+	assert.Equal(t, tx.Hash(), block.Transactions()[0].Hash())
+	b2, err := shardState.CreateBlockToMine(nil, &acc3, nil, nil, nil)
+	checkErr(err)
+	assert.Equal(t, 1, len(b2.Transactions()))
+	_, _, err = shardState.FinalizeAndAddBlock(b2)
+	checkErr(err)
+
+	// the transaction is known by its Ethereum hash as well
+	block, index, receipt := shardState.GetTransactionReceipt(ethTx.Hash())
+	assert.Equal(t, b2.Hash(), block.Hash())
+	assert.Equal(t, uint32(0), index)
+	assert.Equal(t, tx.Hash(), receipt.TxHash)
+	assert.Equal(t, uint64(1), receipt.Status)
+}
@@ -0,0 +1,449 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	qkcCommon "github.com/QuarkChain/goquarkchain/common"
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/state"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/core/vm"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	emptyStateRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	emptyCodeHash  = crypto.Keccak256(nil)
+
+	errStateOutOfRange = errors.New("no state stored within range")
+)
+
+// SnapshotBlock is a minor block of a state snapshot, with what a shard keeps
+// about it besides the block itself.
+type SnapshotBlock struct {
+	Block *types.MinorBlock
+	// Receipts are RLP encoded in their storage form.
+	Receipts            []byte `bytesizeofslicelen:"4"`
+	TotalTxCount        uint32
+	XShardDepositHashes []common.Hash `bytesizeofslicelen:"4"`
+}
+
+// SnapshotNode is a node of a state trie, or a contract code, of a snapshot.
+type SnapshotNode struct {
+	Hash common.Hash
+	Blob []byte `bytesizeofslicelen:"4"`
+}
+
+// SnapshotWindowStart returns the number of the first block a snapshot of the
+// shard at tip, confirmed by rootBlock, keeps: the shard looks up the blocks
+// the state tries it garbage collects belong to, and the PoSW window.
+func (m *MinorBlockChain) SnapshotWindowStart(tip *types.MinorBlock, rootBlock *types.RootBlock) uint64 {
+	keep := uint64(triesInMemory)
+	if m.shardConfig.PoswConfig != nil && m.shardConfig.PoswConfig.WindowSize > keep {
+		keep = m.shardConfig.PoswConfig.WindowSize
+	}
+	start := uint64(1)
+	if tip.NumberU64() > keep {
+		start = tip.NumberU64() - keep
+	}
+	if rootBlock.NumberU64() > triesInRootBlock {
+		preRootBlock := m.GetRootBlockByHeight(rootBlock.Hash(), rootBlock.NumberU64()-triesInRootBlock)
+		if preRootBlock != nil {
+			if block := m.GetMinorBlock(m.ReadLastConfirmedMinorBlockHeaderAtRootBlock(preRootBlock.Hash())); block != nil && block.NumberU64() < start {
+				start = block.NumberU64()
+			}
+		}
+	}
+	if start == 0 {
+		start = 1
+	}
+	return start
+}
+
+// GetSnapshotBlock returns the block of the given hash for a state snapshot.
+func (m *MinorBlockChain) GetSnapshotBlock(hash common.Hash) (*SnapshotBlock, error) {
+	block := m.GetMinorBlock(hash)
+	if block == nil {
+		return nil, ErrMinorBlockIsNil
+	}
+	storageReceipts := make([]*types.ReceiptForStorage, 0)
+	for _, receipt := range rawdb.ReadReceipts(m.db, hash) {
+		storageReceipts = append(storageReceipts, (*types.ReceiptForStorage)(receipt))
+	}
+	receipts, err := rlp.EncodeToBytes(storageReceipts)
+	if err != nil {
+		return nil, err
+	}
+	totalTx := rawdb.ReadTotalTx(m.db, hash)
+	if totalTx == nil {
+		return nil, fmt.Errorf("missing total tx count of block %x", hash)
+	}
+	hashList := m.getXShardDepositHashList(hash)
+	if hashList == nil {
+		return nil, fmt.Errorf("missing cross-shard deposits of block %x", hash)
+	}
+	return &SnapshotBlock{
+		Block:               block,
+		Receipts:            receipts,
+		TotalTxCount:        *totalTx,
+		XShardDepositHashes: hashList.HList,
+	}, nil
+}
+
+// GetSnapshotDeposits returns the cross-shard deposits made to the shard by the
+// minor blocks confirmed by rootBlock and its ancestors that block has not
+// processed all the deposits of yet, according to its cursor.
+func (m *MinorBlockChain) GetSnapshotDeposits(block *types.MinorBlock, rootBlock *types.RootBlock) []*rpc.AddXshardTxListRequest {
+	cursor := block.Meta().XShardTxCursorInfo
+	deposits := make([]*rpc.AddXshardTxListRequest, 0)
+	for rBlock := rootBlock; rBlock != nil && rBlock.NumberU64() >= cursor.RootBlockHeight; rBlock = m.GetRootBlockByHash(rBlock.ParentHash()) {
+		for _, header := range rBlock.MinorBlockHeaders() {
+			if header.Branch == m.branch {
+				continue
+			}
+			if list := m.ReadCrossShardTxList(header.Hash()); list != nil {
+				deposits = append(deposits, &rpc.AddXshardTxListRequest{
+					Branch:         header.Branch.Value,
+					MinorBlockHash: header.Hash(),
+					TxList:         list.TXList,
+				})
+			}
+		}
+		if rBlock.NumberU64() == 0 {
+			break
+		}
+	}
+	return deposits
+}
+
+// SnapshotState calls fn with the hash and the blob of every node of the state
+// of block. The blocks after the last one whose state is stored are executed
+// again when needed, as only some recent states are written on exit.
+func (m *MinorBlockChain) SnapshotState(block *types.MinorBlock, fn func(hash common.Hash, blob []byte) error) error {
+	if err := m.regenerateState(block, 0); err != nil {
+		return err
+	}
+	triedb := m.stateCache.TrieDB()
+	return walkState(m.stateCache, block.Meta().Root, func(hash common.Hash) error {
+		blob, err := triedb.Node(hash)
+		if err != nil {
+			return err
+		}
+		return fn(hash, blob)
+	})
+}
+
+// regenerateState runs again the blocks after the last ancestor of block whose
+// state is stored, up to block. It returns errStateOutOfRange if more than
+// limit blocks need to run again, the limit is ignored if 0.
+func (m *MinorBlockChain) regenerateState(block *types.MinorBlock, limit uint64) error {
+	blocks := make([]*types.MinorBlock, 0)
+	for {
+		if _, err := m.StateAt(block.Meta().Root); err == nil {
+			break
+		}
+		if limit > 0 && uint64(len(blocks)) >= limit {
+			return errStateOutOfRange
+		}
+		blocks = append(blocks, block)
+		parent := m.GetMinorBlock(block.ParentHash())
+		if parent == nil {
+			return fmt.Errorf("missing state of block %d [%x]", block.NumberU64(), block.Hash())
+		}
+		block = parent
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		evmState, _, _, _, _, err := m.runBlock(blocks[i])
+		if err != nil {
+			return err
+		}
+		root, err := evmState.Commit(true)
+		if err != nil {
+			return err
+		}
+		if root != blocks[i].Meta().Root {
+			return fmt.Errorf("state root of block %d [%x] mismatch: %x, expected %x",
+				blocks[i].NumberU64(), blocks[i].Hash(), root, blocks[i].Meta().Root)
+		}
+	}
+	return nil
+}
+
+// walkState calls fn with the hash of every node of the state trie at root, of
+// the storage and token tries of its accounts and of their code. It fails on
+// the first node missing from db.
+func walkState(db state.Database, root common.Hash, fn func(hash common.Hash) error) error {
+	walk := func(it trie.NodeIterator) error {
+		for it.Next(true) {
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				if err := fn(hash); err != nil {
+					return err
+				}
+			}
+		}
+		return it.Error()
+	}
+
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return err
+	}
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if err := fn(hash); err != nil {
+				return err
+			}
+		}
+		if !it.Leaf() {
+			continue
+		}
+		addrHash := common.BytesToHash(it.LeafKey())
+		account := state.NewAccount(db.TrieDB())
+		if err := rlp.DecodeBytes(it.LeafBlob(), &account); err != nil {
+			return err
+		}
+		if account.Root != emptyStateRoot {
+			storage, err := db.OpenStorageTrie(addrHash, account.Root)
+			if err != nil {
+				return err
+			}
+			if err := walk(storage.NodeIterator(nil)); err != nil {
+				return err
+			}
+		}
+		if tokens := account.TokenBalances.NodeIterator(); tokens != nil {
+			if err := walk(tokens); err != nil {
+				return err
+			}
+		}
+		if !bytes.Equal(account.CodeHash, emptyCodeHash) {
+			codeHash := common.BytesToHash(account.CodeHash)
+			if _, err := db.ContractCode(addrHash, codeHash); err != nil {
+				return fmt.Errorf("code %x: %v", codeHash, err)
+			}
+			if err := fn(codeHash); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// WriteSnapshotRootBlock writes a root block of a state snapshot into the
+// database of a shard, with the hash of the last minor block of the shard it
+// confirms, or an empty hash if there is none.
+func WriteSnapshotRootBlock(db ethdb.Database, rBlock *types.RootBlock, confirmed common.Hash) {
+	rawdb.WriteLastConfirmedMinorBlockHeaderAtRootBlock(db, rBlock.Hash(), confirmed)
+	rawdb.WriteRootBlock(db, rBlock)
+}
+
+// WriteSnapshotBlock checks a block of a state snapshot against its receipts
+// and writes it into the database of its shard as a canonical block.
+func WriteSnapshotBlock(db ethdb.Database, sBlock *SnapshotBlock) error {
+	block := sBlock.Block
+	if block.MetaHash() != block.Meta().Hash() {
+		return ErrMetaHash
+	}
+	if types.CalculateMerkleRoot(block.Transactions()) != block.Meta().TxHash {
+		return ErrTxHash
+	}
+	storageReceipts := make([]*types.ReceiptForStorage, 0)
+	if err := rlp.DecodeBytes(sBlock.Receipts, &storageReceipts); err != nil {
+		return err
+	}
+	receipts := make(types.Receipts, len(storageReceipts))
+	for i, receipt := range storageReceipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	if hash := types.DeriveSha(receipts); hash != block.ReceiptHash() {
+		return fmt.Errorf("invalid receipt root hash of block %d (remote: %x local: %x)", block.NumberU64(), block.ReceiptHash(), hash)
+	}
+
+	hashList := &rawdb.HashList{HList: sBlock.XShardDepositHashes}
+	batch := db.NewBatch()
+	rawdb.WriteMinorBlock(batch, block)
+	rawdb.WriteReceipts(batch, block.Hash(), receipts)
+	rawdb.WriteTotalTx(batch, block.Hash(), sBlock.TotalTxCount)
+	rawdb.PutXShardDepositHashList(batch, block.Hash(), hashList)
+	rawdb.WriteBlockContentLookupEntriesWithCrossShardHashList(batch, block, hashList)
+	rawdb.WriteCanonicalHash(batch, rawdb.ChainTypeMinor, block.Hash(), block.NumberU64())
+	rawdb.WriteCommitMinorBlock(batch, block.Hash())
+	return batch.Write()
+}
+
+// VerifySnapshotHeader checks a minor block header of the shard confirmed by
+// the root blocks of a state snapshot with what does not need the state of the
+// shard: its seal, against the difficulty lowered as much as PoSW allows, and
+// its coinbase, made to the shard and of at least the block reward as the fees
+// of the block are not known.
+func (m *MinorBlockChain) VerifySnapshotHeader(header *types.MinorBlockHeader) error {
+	if header.Branch != m.branch {
+		return ErrBranch
+	}
+	if header.Number == 0 {
+		if genesis := m.GetBlockByNumber(0); qkcCommon.IsNil(genesis) || genesis.Hash() != header.Hash() {
+			return errors.New("genesis block mismatch")
+		}
+		return nil
+	}
+	if err := m.Validator().ValidateSeal(header, false); err != nil {
+		return err
+	}
+	if !m.branch.IsInBranch(header.Coinbase.FullShardKey) {
+		return ErrMinerFullShardKey
+	}
+	if header.CoinbaseAmount == nil {
+		return ErrCoinbaseAmount
+	}
+	amounts := header.CoinbaseAmount.GetBalanceMap()
+	for token, reward := range m.getCoinbaseAmount(header.Number).GetBalanceMap() {
+		if amount, ok := amounts[token]; reward.Sign() > 0 && (!ok || amount.Cmp(reward) < 0) {
+			return ErrCoinbaseAmount
+		}
+	}
+	return nil
+}
+
+// VerifySnapshotDeposits checks the deposits of a state snapshot made to the
+// shard fullShardId by a minor block of another shard against the transactions
+// of the block: each deposit must be made, in order, by a cross-shard
+// transaction of the block to the shard, with the value, gas and data it is
+// signed with. The gas price of a transaction paying gas with another token
+// than the genesis one is converted with the state of its shard, so its deposit
+// cannot be verified and is rejected.
+func VerifySnapshotDeposits(qkcCfg *config.QuarkChainConfig, block *types.MinorBlock, fullShardId uint32,
+	deposits []*types.CrossShardTransactionDeposit) error {
+	if block.MetaHash() != block.Meta().Hash() {
+		return ErrMetaHash
+	}
+	if types.CalculateMerkleRoot(block.Transactions()) != block.Meta().TxHash {
+		return ErrTxHash
+	}
+	txs, i := block.Transactions(), 0
+	for _, deposit := range deposits {
+		for i < len(txs) && txs[i].Hash() != deposit.TxHash {
+			i++
+		}
+		if i == len(txs) {
+			return fmt.Errorf("deposit of tx %x not made by block %x", deposit.TxHash, block.Hash())
+		}
+		expected, err := snapshotDeposit(qkcCfg, block, fullShardId, txs[i])
+		if err != nil {
+			return fmt.Errorf("deposit of tx %x: %v", deposit.TxHash, err)
+		}
+		if !sameDeposits([]*types.CrossShardTransactionDeposit{deposit}, []*types.CrossShardTransactionDeposit{expected}) {
+			return fmt.Errorf("deposit of tx %x does not match the tx", deposit.TxHash)
+		}
+		i++
+	}
+	return nil
+}
+
+// snapshotDeposit returns the deposit a cross-shard transaction of block makes
+// to the shard fullShardId, as made by StateTransition.AddCrossShardTxDeposit.
+func snapshotDeposit(qkcCfg *config.QuarkChainConfig, block *types.MinorBlock, fullShardId uint32,
+	tx *types.Transaction) (*types.CrossShardTransactionDeposit, error) {
+	if tx.TxType != types.EvmTx {
+		return nil, errors.New("not an evm tx")
+	}
+	evmTx := tx.EvmTx
+	toFullShardId, err := qkcCfg.GetFullShardIdByFullShardKey(evmTx.ToFullShardKey())
+	if err != nil {
+		return nil, err
+	}
+	if toFullShardId != fullShardId || toFullShardId == block.Branch().Value {
+		return nil, fmt.Errorf("not a cross-shard tx to shard %x", fullShardId)
+	}
+	genesisToken := qkcCfg.GetDefaultChainTokenID()
+	if evmTx.GasTokenID() != genesisToken {
+		return nil, fmt.Errorf("gas paid with token %d, the converted gas price cannot be verified", evmTx.GasTokenID())
+	}
+	from, err := tx.Sender(types.MakeSigner(evmTx.NetworkId()))
+	if err != nil {
+		return nil, err
+	}
+	intrinsicGas, err := IntrinsicGas(evmTx.Data(), evmTx.To() == nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if evmTx.Gas() < intrinsicGas {
+		return nil, ErrIntrinsicGas
+	}
+
+	deposit := &types.CrossShardTransactionDeposit{
+		CrossShardTransactionDepositV0: types.CrossShardTransactionDepositV0{
+			TxHash:          tx.Hash(),
+			From:            account.Address{Recipient: from, FullShardKey: evmTx.FromFullShardKey()},
+			To:              account.Address{FullShardKey: evmTx.ToFullShardKey()},
+			Value:           &serialize.Uint256{Value: evmTx.Value()},
+			GasPrice:        &serialize.Uint256{Value: evmTx.GasPrice()},
+			GasTokenID:      genesisToken,
+			TransferTokenID: evmTx.TransferTokenID(),
+			GasRemained:     &serialize.Uint256{Value: new(big.Int)},
+			MessageData:     evmTx.Data(),
+			CreateContract:  evmTx.To() == nil,
+		},
+		RefundRate: 100,
+	}
+	if evmTx.To() == nil {
+		// the nonce of the sender is incremented before the contract address
+		// is computed
+		fromFullShardKey := evmTx.FromFullShardKey()
+		deposit.To.Recipient = vm.CreateAddress(from, &fromFullShardKey, evmTx.Nonce()+1)
+		deposit.GasRemained.Value.SetUint64(evmTx.Gas() - intrinsicGas)
+	} else {
+		deposit.To.Recipient = *evmTx.To()
+		if block.Time() >= qkcCfg.EnableEvmTimeStamp {
+			deposit.GasRemained.Value.SetUint64(evmTx.Gas() - intrinsicGas)
+		}
+	}
+	return deposit, nil
+}
+
+// WriteSnapshotDeposits writes the cross-shard deposits of a state snapshot
+// into the database of the shard they are made to, see VerifySnapshotDeposits.
+func WriteSnapshotDeposits(db ethdb.Database, deposits []*rpc.AddXshardTxListRequest) {
+	for _, req := range deposits {
+		rawdb.WriteCrossShardTxList(db, req.MinorBlockHash, &types.CrossShardTransactionDepositList{TXList: req.TxList})
+	}
+}
+
+// WriteSnapshotNodes checks the nodes of a state snapshot against their hash
+// and writes them into the database of a shard.
+func WriteSnapshotNodes(db ethdb.Database, nodes []*SnapshotNode) error {
+	batch := db.NewBatch()
+	for _, node := range nodes {
+		if hash := crypto.Keccak256Hash(node.Blob); hash != node.Hash {
+			return fmt.Errorf("state node %x has hash %x", node.Hash, hash)
+		}
+		if err := batch.Put(node.Hash[:], node.Blob); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// WriteSnapshotHead checks that the state of the tip of a state snapshot is
+// complete in the database of its shard and makes it the head block.
+func WriteSnapshotHead(db ethdb.Database, tip *types.MinorBlock) error {
+	if err := walkState(state.NewDatabase(db), tip.Meta().Root, func(common.Hash) error { return nil }); err != nil {
+		return fmt.Errorf("incomplete state of block %d [%x]: %v", tip.NumberU64(), tip.Hash(), err)
+	}
+	if rawdb.ReadMinorBlock(db, tip.Hash()) == nil {
+		return ErrMinorBlockIsNil
+	}
+	rawdb.WriteHeadBlockHash(db, tip.Hash())
+	return nil
+}
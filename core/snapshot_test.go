@@ -0,0 +1,181 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/core/state"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotState(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2, err := account.CreatRandomAccountWithFullShardKey(0)
+	assert.NoError(t, err)
+	storageKeyBytes, err := hex.DecodeString(ZFill64(hex.EncodeToString(acc1.Recipient[:])) + ZFill64("1"))
+	assert.NoError(t, err)
+	storageKey := crypto.Keccak256Hash(storageKeyBytes)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil).Finalize(nil, nil, common.Hash{})
+	_, err = shardState.AddRootBlock(rootBlock)
+	assert.NoError(t, err)
+
+	// a contract with storage, then a call setting it
+	tx0, err := CreateContract(shardState, id1.GetKey(), acc1, acc1.FullShardKey, ContractWithStorage2)
+	assert.NoError(t, err)
+	assert.NoError(t, shardState.AddTx(tx0))
+	b1, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	b1, _, err = shardState.FinalizeAndAddBlock(b1)
+	assert.NoError(t, err)
+	_, _, receipt := shardState.GetTransactionReceipt(tx0.Hash())
+	assert.Equal(t, uint64(1), receipt.Status)
+	contract := account.NewAddress(receipt.ContractAddress, receipt.ContractFullShardKey)
+
+	data, err := hex.DecodeString("c2e171d7")
+	assert.NoError(t, err)
+	gas := uint64(50000)
+	tx1 := createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, contract, big.NewInt(0), &gas, nil, nil, data, nil, nil)
+	assert.NoError(t, shardState.AddTx(tx1))
+	b2, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	b2, _, err = shardState.FinalizeAndAddBlock(b2)
+	assert.NoError(t, err)
+	tip := shardState.CurrentBlock()
+	assert.Equal(t, b2.Hash(), tip.Hash())
+
+	nodes := make([]*SnapshotNode, 0)
+	assert.NoError(t, shardState.SnapshotState(tip, func(hash common.Hash, blob []byte) error {
+		nodes = append(nodes, &SnapshotNode{Hash: hash, Blob: blob})
+		return nil
+	}))
+
+	db := ethdb.NewMemDatabase()
+	var sBlock *SnapshotBlock
+	for _, hash := range []common.Hash{b1.Hash(), b2.Hash()} {
+		sBlock, err = shardState.GetSnapshotBlock(hash)
+		assert.NoError(t, err)
+		assert.NoError(t, WriteSnapshotBlock(db, sBlock))
+	}
+	assert.NoError(t, WriteSnapshotNodes(db, nodes))
+	assert.NoError(t, WriteSnapshotHead(db, tip))
+
+	restored, err := state.New(tip.Meta().Root, state.NewDatabase(db))
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToHash("162e"), restored.GetState(contract.Recipient, storageKey))
+	code, err := shardState.GetCode(contract.Recipient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, code, restored.GetCode(contract.Recipient))
+
+	// a missing node is detected
+	db = ethdb.NewMemDatabase()
+	assert.NoError(t, WriteSnapshotBlock(db, sBlock))
+	assert.NoError(t, WriteSnapshotNodes(db, nodes[1:]))
+	assert.Error(t, WriteSnapshotHead(db, tip))
+
+	// so are altered nodes and receipts
+	assert.Error(t, WriteSnapshotNodes(db, []*SnapshotNode{{Hash: nodes[0].Hash, Blob: append(nodes[0].Blob, 0)}}))
+	receipts, err := rlp.EncodeToBytes([]interface{}{})
+	assert.NoError(t, err)
+	assert.Error(t, WriteSnapshotBlock(db, &SnapshotBlock{Block: tip, Receipts: receipts}))
+}
+
+func TestVerifySnapshotDeposits(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2 := account.CreatAddressFromIdentity(id1, 1)
+	acc3, err := account.CreatRandomAccountWithFullShardKey(0)
+	assert.NoError(t, err)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+	qkcCfg := env.clusterConfig.Quarkchain
+	toFullShardId, err := qkcCfg.GetFullShardIdByFullShardKey(acc2.FullShardKey)
+	assert.NoError(t, err)
+
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil)
+	rootBlock.AddMinorBlockHeader(shardState.CurrentBlock().Header())
+	rootBlock = rootBlock.Finalize(nil, nil, common.Hash{})
+	_, err = shardState.AddRootBlock(rootBlock)
+	assert.NoError(t, err)
+	gas := uint64(21000 + 9000)
+	tx := createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, acc2, big.NewInt(888888), &gas, nil, nil, nil, nil, nil)
+	assert.NoError(t, shardState.AddTx(tx))
+	block, err := shardState.CreateBlockToMine(nil, &acc3, nil, nil, nil)
+	assert.NoError(t, err)
+	block, _, err = shardState.FinalizeAndAddBlock(block)
+	assert.NoError(t, err)
+	deposits := shardState.currentEvmState.GetXShardList()
+	assert.Equal(t, 1, len(deposits))
+
+	assert.NoError(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, deposits))
+	assert.NoError(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, nil))
+	// the deposits are made to another shard
+	assert.Error(t, VerifySnapshotDeposits(qkcCfg, block, block.Branch().Value, deposits))
+
+	// a tampered deposit is rejected
+	tampered := *deposits[0]
+	tampered.Value = &serialize.Uint256{Value: big.NewInt(999999999)}
+	assert.Error(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, []*types.CrossShardTransactionDeposit{&tampered}))
+	tampered = *deposits[0]
+	tampered.To = acc3
+	assert.Error(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, []*types.CrossShardTransactionDeposit{&tampered}))
+	tampered = *deposits[0]
+	tampered.GasRemained = &serialize.Uint256{Value: big.NewInt(1000000)}
+	assert.Error(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, []*types.CrossShardTransactionDeposit{&tampered}))
+	// as are deposits of a tx the block does not have
+	tampered = *deposits[0]
+	tampered.TxHash = common.HexToHash("0x01")
+	assert.Error(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, []*types.CrossShardTransactionDeposit{&tampered}))
+	assert.Error(t, VerifySnapshotDeposits(qkcCfg, block, toFullShardId, append(deposits, deposits[0])))
+}
+
+func TestVerifySnapshotHeader(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	block, err := shardState.CreateBlockToMine(nil, &acc1, nil, nil, nil)
+	assert.NoError(t, err)
+	block, _, err = shardState.FinalizeAndAddBlock(block)
+	assert.NoError(t, err)
+	genesis := shardState.GetBlockByNumber(0).IHeader().(*types.MinorBlockHeader)
+	assert.NoError(t, shardState.VerifySnapshotHeader(genesis))
+	assert.NoError(t, shardState.VerifySnapshotHeader(block.Header()))
+
+	// a header of another shard
+	header := types.CopyMinorBlockHeader(block.Header())
+	header.Branch = account.Branch{Value: header.Branch.Value + 1}
+	assert.Equal(t, ErrBranch, shardState.VerifySnapshotHeader(header))
+	// a genesis block of another chain
+	header = types.CopyMinorBlockHeader(genesis)
+	header.Time++
+	assert.Error(t, shardState.VerifySnapshotHeader(header))
+	// a coinbase made to another shard, or lower than the block reward
+	header = types.CopyMinorBlockHeader(block.Header())
+	header.Coinbase.FullShardKey++
+	assert.Equal(t, ErrMinerFullShardKey, shardState.VerifySnapshotHeader(header))
+	header = types.CopyMinorBlockHeader(block.Header())
+	header.CoinbaseAmount = types.NewEmptyTokenBalances()
+	assert.Equal(t, ErrCoinbaseAmount, shardState.VerifySnapshotHeader(header))
+}
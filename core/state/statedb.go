@@ -299,6 +299,31 @@ func (s *StateDB) GetProof(a common.Address) ([][]byte, error) {
 	return [][]byte(proof), err
 }
 
+// GetAccountRLP returns the RLP encoded account stored in the state trie, nil
+// is returned if the account does not exist.
+func (s *StateDB) GetAccountRLP(a common.Address) ([]byte, error) {
+	return s.trie.TryGet(a.Bytes())
+}
+
+// GetStorageRoot returns the root of the committed storage trie of the account.
+func (s *StateDB) GetStorageRoot(a common.Address) common.Hash {
+	stateObject := s.getStateObject(a)
+	if stateObject == nil {
+		return common.Hash{}
+	}
+	return stateObject.data.Root
+}
+
+// GetTokenBalanceProofs returns the root of the token trie of the account with
+// the proofs of all the balances stored in it, see TokenBalances.Prove.
+func (s *StateDB) GetTokenBalanceProofs(a common.Address) (common.Hash, []*types.TokenBalanceProof, error) {
+	stateObject := s.getStateObject(a)
+	if stateObject == nil {
+		return common.Hash{}, nil, nil
+	}
+	return stateObject.data.TokenBalances.Prove()
+}
+
 // GetProof returns the StorageProof for given key
 func (s *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byte, error) {
 	var proof proofList
@@ -391,6 +416,17 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetStorage replaces the entire storage for the specified account with given
+// storage. This function should only be used for debugging.
+func (s *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	stateObject := s.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.SetStorage(storage)
+		// keep the object in copies of this state
+		s.stateObjectsDirty[addr] = struct{}{}
+	}
+}
+
 // Suicide marks the given account as suicided.
 // This clears the account balance.
 //
@@ -25,15 +25,30 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 )
 
+// syncAccount is an Account as stored in the state trie. Its token balances are
+// kept serialized, as decoding them opens the token trie, which is not synced
+// yet when the account is.
+type syncAccount struct {
+	Nonce         uint64
+	TokenBalances []byte
+	Root          common.Hash
+	CodeHash      []byte
+	Rest          []rlp.RawValue `rlp:"tail"`
+}
+
 // NewStateSync create a new state trie download scheduler.
 func NewStateSync(root common.Hash, database ethdb.Database) *trie.Sync {
 	var syncer *trie.Sync
 	callback := func(leaf []byte, parent common.Hash) error {
-		obj := NewAccount(trie.NewDatabase(database))
+		var obj syncAccount
 		if err := rlp.Decode(bytes.NewReader(leaf), &obj); err != nil {
 			return err
 		}
 		syncer.AddSubTrie(obj.Root, 64, parent, nil)
+		// token balances stored in a trie are serialized as 0x01 and its root
+		if len(obj.TokenBalances) == 1+common.HashLength && obj.TokenBalances[0] == 1 {
+			syncer.AddSubTrie(common.BytesToHash(obj.TokenBalances[1:]), 64, parent, nil)
+		}
 		syncer.AddRawEntry(common.BytesToHash(obj.CodeHash), 64, parent)
 		return nil
 	}
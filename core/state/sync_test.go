@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/QuarkChain/goquarkchain/core/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -349,3 +350,54 @@ func TestIncompleteStateSync(t *testing.T) {
 		dstDb.Put(key, value)
 	}
 }
+
+// Tests that the token tries of the accounts holding more tokens than fit in
+// the account itself are synced along with the state trie.
+func TestTokenTrieStateSync(t *testing.T) {
+	// Create a state with accounts of many tokens
+	srcDb := NewDatabase(ethdb.NewMemDatabase())
+	state, _ := New(common.Hash{}, srcDb)
+	for i := byte(0); i < 4; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		for token := uint64(1); token <= 2*types.TokenTrieThreshold; token++ {
+			state.AddBalance(addr, big.NewInt(int64(i)*100+int64(token)), token)
+		}
+	}
+	srcRoot, _ := state.Commit(false)
+	srcDb.TrieDB().Commit(srcRoot, false)
+
+	dstDb := ethdb.NewMemDatabase()
+	sched := NewStateSync(srcRoot, dstDb)
+
+	queue := append([]common.Hash{}, sched.Missing(100)...)
+	for len(queue) > 0 {
+		results := make([]trie.SyncResult, len(queue))
+		for i, hash := range queue {
+			data, err := srcDb.TrieDB().Node(hash)
+			if err != nil {
+				t.Fatalf("failed to retrieve node data for %x", hash)
+			}
+			results[i] = trie.SyncResult{Hash: hash, Data: data}
+		}
+		if _, index, err := sched.Process(results); err != nil {
+			t.Fatalf("failed to process result #%d: %v", index, err)
+		}
+		if index, err := sched.Commit(dstDb); err != nil {
+			t.Fatalf("failed to commit data #%d: %v", index, err)
+		}
+		queue = append(queue[:0], sched.Missing(100)...)
+	}
+	dstState, err := New(srcRoot, NewDatabase(dstDb))
+	if err != nil {
+		t.Fatalf("failed to create state trie at %x: %v", srcRoot, err)
+	}
+	for i := byte(0); i < 4; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		for token := uint64(1); token <= 2*types.TokenTrieThreshold; token++ {
+			want := big.NewInt(int64(i)*100 + int64(token))
+			if balance := dstState.GetBalance(addr, token); balance.Cmp(want) != 0 {
+				t.Errorf("account %d token %d: balance mismatch: have %v, want %v", i, token, balance, want)
+			}
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"math/big"
 
 	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
 	qkcCmn "github.com/QuarkChain/goquarkchain/common"
 	"github.com/QuarkChain/goquarkchain/consensus"
 	"github.com/QuarkChain/goquarkchain/core/state"
@@ -188,8 +189,28 @@ func ValidateTransaction(state vm.StateDB, chainConfig *params.ChainConfig, tx *
 	return nil
 }
 
+// checkEthTransaction rejects the transactions signed in the Ethereum format
+// in the blocks below ENABLE_ETH_TX_HEIGHT of their chain.
+func checkEthTransaction(quarkChainConfig *config.QuarkChainConfig, tx *types.Transaction, height uint64) error {
+	if tx.EvmTx.Version() != types.EthTxVersion {
+		return nil
+	}
+	fullShardId, err := quarkChainConfig.GetFullShardIdByFullShardKey(tx.EvmTx.FromFullShardKey())
+	if err != nil {
+		return err
+	}
+	shardConfig := quarkChainConfig.GetShardConfigByFullShardID(fullShardId)
+	if shardConfig == nil || height < shardConfig.EnableEthTxHeight {
+		return ErrEthTxNotEnabled
+	}
+	return nil
+}
+
 // ApplyTransaction apply tx
 func ApplyTransaction(config *params.ChainConfig, bc ChainContext, gp *GasPool, statedb *state.StateDB, header types.IHeader, tx *types.Transaction, usedGas *uint64, cfg vm.Config) ([]byte, *types.Receipt, uint64, error) {
+	if err := checkEthTransaction(statedb.GetQuarkChainConfig(), tx, header.NumberU64()); err != nil {
+		return nil, nil, 0, err
+	}
 	statedbGensisToken := statedb.GetQuarkChainConfig().GetDefaultChainTokenID()
 	gasPrice, refundRate := tx.EvmTx.GasPrice(), uint8(100)
 	convertedGenesisTokenGasPrice := new(big.Int)
@@ -17,6 +17,7 @@ import (
 	"github.com/QuarkChain/goquarkchain/core/vm"
 	qParam "github.com/QuarkChain/goquarkchain/params"
 	"github.com/QuarkChain/goquarkchain/qkcdb"
+	ethCommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
@@ -382,3 +383,33 @@ func CreateContract(mBlockChain *MinorBlockChain, key account.Key, fromAddress a
 	}
 	return &types.Transaction{TxType: types.EvmTx, EvmTx: evmTx}, nil
 }
+
+// createContractBlocks adds a root block to the shard, then count blocks each
+// creating a contract with storage from the account of the identity, mined by
+// the coinbase.
+func createContractBlocks(shardState *MinorBlockChain, id account.Identity, coinbase *account.Address, count int) (*types.RootBlock, []*types.MinorBlock, error) {
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil).Finalize(nil, nil, ethCommon.Hash{})
+	if _, err := shardState.AddRootBlock(rootBlock); err != nil {
+		return nil, nil, err
+	}
+	acc := account.CreatAddressFromIdentity(id, 0)
+	blocks := make([]*types.MinorBlock, 0, count)
+	for i := 0; i < count; i++ {
+		tx, err := CreateContract(shardState, id.GetKey(), acc, acc.FullShardKey, ContractWithStorage2)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := shardState.AddTx(tx); err != nil {
+			return nil, nil, err
+		}
+		block, err := shardState.CreateBlockToMine(nil, coinbase, nil, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if block, _, err = shardState.FinalizeAndAddBlock(block); err != nil {
+			return nil, nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return rootBlock, blocks, nil
+}
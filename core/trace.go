@@ -0,0 +1,234 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	qkcCommon "github.com/QuarkChain/goquarkchain/common"
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/state"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/core/vm"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// StructLoggerName selects vm.StructLogger, which is also used if no tracer is given.
+	StructLoggerName = "structLogger"
+	// CallTracerName selects vm.CallTracer.
+	CallTracerName = "callTracer"
+)
+
+var (
+	ErrTxNotFound = errors.New("transaction not found")
+)
+
+// TraceConfig holds extra parameters to trace functions.
+type TraceConfig struct {
+	*vm.LogConfig
+	Tracer *string
+}
+
+// StructLogRes stores a structured log emitted by the EVM while replaying a
+// transaction in debug mode
+type StructLogRes struct {
+	Pc      uint64             `json:"pc"`
+	Op      string             `json:"op"`
+	Gas     uint64             `json:"gas"`
+	GasCost uint64             `json:"gasCost"`
+	Depth   int                `json:"depth"`
+	Error   string             `json:"error,omitempty"`
+	Stack   *[]string          `json:"stack,omitempty"`
+	Memory  *[]string          `json:"memory,omitempty"`
+	Storage *map[string]string `json:"storage,omitempty"`
+}
+
+// ExecutionResult groups all structured logs emitted by the EVM
+// while replaying a transaction in debug mode as well as transaction
+// execution status, the amount of gas used and the return value
+type ExecutionResult struct {
+	Gas         uint64         `json:"gas"`
+	Failed      bool           `json:"failed"`
+	ReturnValue string         `json:"returnValue"`
+	StructLogs  []StructLogRes `json:"structLogs"`
+}
+
+// TxTraceResult is the result of a single transaction trace of a minor block.
+type TxTraceResult struct {
+	TxHash common.Hash `json:"txHash"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// FormatLogs formats EVM returned structured logs for json output
+func FormatLogs(logs []vm.StructLog) []StructLogRes {
+	formatted := make([]StructLogRes, len(logs))
+	for index, trace := range logs {
+		formatted[index] = StructLogRes{
+			Pc:      trace.Pc,
+			Op:      trace.Op.String(),
+			Gas:     trace.Gas,
+			GasCost: trace.GasCost,
+			Depth:   trace.Depth,
+			Error:   trace.ErrorString(),
+		}
+		if trace.Stack != nil {
+			stack := make([]string, len(trace.Stack))
+			for i, stackValue := range trace.Stack {
+				stack[i] = fmt.Sprintf("%x", common.LeftPadBytes(stackValue.Bytes(), 32))
+			}
+			formatted[index].Stack = &stack
+		}
+		if trace.Memory != nil {
+			memory := make([]string, 0, (len(trace.Memory)+31)/32)
+			for i := 0; i+32 <= len(trace.Memory); i += 32 {
+				memory = append(memory, fmt.Sprintf("%x", trace.Memory[i:i+32]))
+			}
+			formatted[index].Memory = &memory
+		}
+		if trace.Storage != nil {
+			storage := make(map[string]string)
+			for i, storageValue := range trace.Storage {
+				storage[fmt.Sprintf("%x", i)] = fmt.Sprintf("%x", storageValue)
+			}
+			formatted[index].Storage = &storage
+		}
+	}
+	return formatted
+}
+
+// TraceTransaction re-executes the minor block which includes the given
+// transaction on top of its parent state and returns the trace of it.
+func (m *MinorBlockChain) TraceTransaction(hash common.Hash, config *TraceConfig) (interface{}, error) {
+	_, blockHash, _ := rawdb.ReadTransaction(m.db, hash)
+	if blockHash == qkcCommon.EmptyHash {
+		return nil, ErrTxNotFound
+	}
+	block := m.GetMinorBlock(blockHash)
+	if block == nil {
+		return nil, ErrMinorBlockIsNil
+	}
+	results, err := m.traceBlock(block, config, &hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrTxNotFound
+	}
+	if results[0].Error != "" {
+		return nil, errors.New(results[0].Error)
+	}
+	return results[0].Result, nil
+}
+
+// TraceMinorBlock re-executes all the transactions of the given minor block
+// on top of its parent state and returns the traces of them.
+func (m *MinorBlockChain) TraceMinorBlock(hash common.Hash, config *TraceConfig) ([]*TxTraceResult, error) {
+	block := m.GetMinorBlock(hash)
+	if block == nil {
+		return nil, ErrMinorBlockIsNil
+	}
+	return m.traceBlock(block, config, nil)
+}
+
+// traceBlock replays block the same way as runBlock does; only the tx matching
+// txHash is traced if it is not nil, and replaying stops right after it.
+func (m *MinorBlockChain) traceBlock(block *types.MinorBlock, config *TraceConfig, txHash *common.Hash) ([]*TxTraceResult, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	if _, err := newTracer(config); err != nil {
+		return nil, err
+	}
+	evmState, err := m.getEvmStateForNewBlock(block, true)
+	if err != nil {
+		return nil, err
+	}
+	_, txCursorInfo, _, err := m.RunCrossShardTxWithCursor(evmState, block)
+	if err != nil {
+		return nil, err
+	}
+	evmState.SetTxCursorInfo(txCursorInfo)
+
+	// same as StateProcessor.Process
+	evmState.SetQuarkChainConfig(m.clusterConfig.Quarkchain)
+	evmState.SetBlockCoinbase(block.Coinbase().Recipient)
+	evmState.SetGasLimit(block.GasLimit())
+	var (
+		results = make([]*TxTraceResult, 0, len(block.Transactions()))
+		usedGas = new(uint64)
+		header  = block.IHeader()
+		gp      = new(GasPool).AddGas(block.GasLimit().Uint64())
+		xGas    = block.GetXShardGasLimit().Uint64()
+	)
+	for i, tx := range block.Transactions() {
+		evmTx, err := m.validateTx(tx, evmState, nil, nil, &xGas)
+		if err != nil {
+			return nil, err
+		}
+		evmState.Prepare(tx.Hash(), block.Hash(), i)
+		if txHash != nil && tx.Hash() != *txHash {
+			if _, _, _, err := ApplyTransaction(m.ChainConfig(), m, gp, evmState, header, evmTx, usedGas, *m.GetVMConfig()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		result, err := m.traceTx(evmTx, evmState, header, gp, usedGas, config)
+		res := &TxTraceResult{TxHash: tx.Hash(), Result: result}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+		if txHash != nil {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (m *MinorBlockChain) traceTx(tx *types.Transaction, evmState *state.StateDB, header types.IHeader, gp *GasPool,
+	usedGas *uint64, config *TraceConfig) (interface{}, error) {
+	tracer, err := newTracer(config)
+	if err != nil {
+		return nil, err
+	}
+	vmConfig := *m.GetVMConfig()
+	vmConfig.Debug = true
+	vmConfig.Tracer = tracer
+
+	ret, receipt, gas, err := ApplyTransaction(m.ChainConfig(), m, gp, evmState, header, tx, usedGas, vmConfig)
+	if err != nil {
+		return nil, err
+	}
+	switch tracer := tracer.(type) {
+	case *vm.CallTracer:
+		return tracer.GetResult(), nil
+	case *vm.StructLogger:
+		return &ExecutionResult{
+			Gas:         gas,
+			Failed:      receipt.Status == types.ReceiptStatusFailed,
+			ReturnValue: fmt.Sprintf("%x", ret),
+			StructLogs:  FormatLogs(tracer.StructLogs()),
+		}, nil
+	}
+	return nil, errors.New("unknown tracer")
+}
+
+// newTracer creates the tracer selected by config, vm.StructLogger by default.
+func newTracer(config *TraceConfig) (vm.Tracer, error) {
+	if config == nil {
+		return vm.NewStructLogger(nil), nil
+	}
+	name := StructLoggerName
+	if config.Tracer != nil && *config.Tracer != "" {
+		name = *config.Tracer
+	}
+	switch name {
+	case StructLoggerName:
+		return vm.NewStructLogger(config.LogConfig), nil
+	case CallTracerName:
+		return vm.NewCallTracer(), nil
+	}
+	return nil, fmt.Errorf("unknown tracer %s", name)
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/core/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceTransaction(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	id2, err := account.CreatRandomIdentity()
+	assert.NoError(t, err)
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2 := account.CreatAddressFromIdentity(id2, 0)
+	fakeMoney := uint64(10000000)
+	env := setUp(&acc1, &fakeMoney, nil)
+	shardState := createDefaultShardState(env, nil, nil, nil, nil)
+	defer shardState.Stop()
+
+	rootBlock := shardState.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil).Finalize(nil, nil, common.Hash{})
+	_, err = shardState.AddRootBlock(rootBlock)
+	checkErr(err)
+
+	tx0, err := CreateContract(shardState, id1.GetKey(), acc1, acc1.FullShardKey, ContractWithStorage2)
+	assert.NoError(t, err)
+	assert.NoError(t, shardState.AddTx(tx0))
+	b1, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	b1, _, err = shardState.FinalizeAndAddBlock(b1)
+	assert.NoError(t, err)
+	_, _, contractReceipt := shardState.GetTransactionReceipt(tx0.Hash())
+	contractAddress := account.NewAddress(contractReceipt.ContractAddress, contractReceipt.ContractFullShardKey)
+
+	data, err := hex.DecodeString("c2e171d7")
+	value, gasPrice, gas := big.NewInt(0), uint64(1), uint64(50000)
+	tx1 := createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, contractAddress,
+		value, &gas, &gasPrice, nil, data, nil, nil)
+	assert.NoError(t, shardState.AddTx(tx1))
+	nonce := tx1.EvmTx.Nonce() + 1
+	tx2 := createTransferTransaction(shardState, id1.GetKey().Bytes(), acc1, acc2,
+		big.NewInt(100), nil, nil, &nonce, nil, nil, nil)
+	assert.NoError(t, shardState.AddTx(tx2))
+	b2, err := shardState.CreateBlockToMine(nil, &acc2, nil, nil, nil)
+	assert.NoError(t, err)
+	b2, receipts, err := shardState.FinalizeAndAddBlock(b2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(b2.Transactions()))
+
+	result, err := shardState.TraceTransaction(tx1.Hash(), &TraceConfig{LogConfig: &vm.LogConfig{DisableMemory: true}})
+	assert.NoError(t, err)
+	res := result.(*ExecutionResult)
+	assert.False(t, res.Failed)
+	assert.Equal(t, receipts[0].GasUsed, res.Gas)
+	assert.NotEqual(t, 0, len(res.StructLogs))
+	for _, l := range res.StructLogs {
+		assert.Nil(t, l.Memory)
+		assert.NotNil(t, l.Stack)
+	}
+	sstore := false
+	for _, l := range res.StructLogs {
+		if l.Op == "SSTORE" {
+			sstore = true
+			assert.NotNil(t, l.Storage)
+		}
+	}
+	assert.True(t, sstore)
+
+	results, err := shardState.TraceMinorBlock(b2.Hash(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, tx1.Hash(), results[0].TxHash)
+	assert.Equal(t, tx2.Hash(), results[1].TxHash)
+	assert.Equal(t, len(res.StructLogs), len(results[0].Result.(*ExecutionResult).StructLogs))
+	assert.Equal(t, 0, len(results[1].Result.(*ExecutionResult).StructLogs))
+
+	tracer := CallTracerName
+	result, err = shardState.TraceTransaction(tx1.Hash(), &TraceConfig{Tracer: &tracer})
+	assert.NoError(t, err)
+	frame := result.(*vm.CallFrame)
+	assert.Equal(t, "CALL", frame.Type)
+	assert.Equal(t, contractAddress.Recipient, frame.To)
+	assert.Equal(t, acc1.Recipient, frame.From)
+	assert.Equal(t, shardState.GetGenesisToken(), uint64(frame.TransferTokenID))
+	assert.Equal(t, "", frame.Error)
+
+	tracer = "unknown"
+	_, err = shardState.TraceMinorBlock(b2.Hash(), &TraceConfig{Tracer: &tracer})
+	assert.Error(t, err)
+
+	_, err = shardState.TraceTransaction(common.Hash{}, nil)
+	assert.Equal(t, ErrTxNotFound, err)
+}
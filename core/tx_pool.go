@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/QuarkChain/goquarkchain/account"
 	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/event"
@@ -376,6 +378,46 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// Accounts retrieves the pending and queued transactions of each sender along
+// with the nonce of the sender in the current state, or of the given sender only.
+func (pool *TxPool) Accounts(sender *common.Address) []*rpc.TxPoolAccount {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	addrs := make(map[common.Address]struct{})
+	for addr := range pool.pending {
+		addrs[addr] = struct{}{}
+	}
+	for addr := range pool.queue {
+		addrs[addr] = struct{}{}
+	}
+	accounts := make([]*rpc.TxPoolAccount, 0, len(addrs))
+	for addr := range addrs {
+		if sender != nil && addr != *sender {
+			continue
+		}
+		acc := &rpc.TxPoolAccount{
+			Address: addr,
+			Nonce:   pool.currentState.GetNonce(addr),
+			Pending: make([]*rpc.TxPoolTx, 0),
+			Queued:  make([]*rpc.TxPoolTx, 0),
+		}
+		if list := pool.pending[addr]; list != nil {
+			for _, tx := range list.Flatten() {
+				acc.Pending = append(acc.Pending, &rpc.TxPoolTx{Tx: tx, Seen: uint64(pool.all.Seen(tx.Hash()).Unix())})
+			}
+		}
+		if list := pool.queue[addr]; list != nil {
+			for _, tx := range list.Flatten() {
+				acc.Queued = append(acc.Queued, &rpc.TxPoolTx{Tx: tx, Seen: uint64(pool.all.Seen(tx.Hash()).Unix())})
+			}
+		}
+		accounts = append(accounts, acc)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i].Address[:], accounts[j].Address[:]) < 0 })
+	return accounts
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -1368,14 +1410,18 @@ func (as *accountSet) merge(other *accountSet) {
 // peeking into the pool in TxPool.Get without having to acquire the widely scoped
 // TxPool.mu mutex.
 type txLookup struct {
-	all  map[common.Hash]*types.Transaction
-	lock sync.RWMutex
+	all       map[common.Hash]*types.Transaction
+	seen      map[common.Hash]time.Time   // Time each transaction entered the pool
+	ethHashes map[common.Hash]common.Hash // Hash of the Ethereum signed transactions by their Ethereum hash
+	lock      sync.RWMutex
 }
 
 // newTxLookup returns a new txLookup structure.
 func newTxLookup() *txLookup {
 	return &txLookup{
-		all: make(map[common.Hash]*types.Transaction),
+		all:       make(map[common.Hash]*types.Transaction),
+		seen:      make(map[common.Hash]time.Time),
+		ethHashes: make(map[common.Hash]common.Hash),
 	}
 }
 
@@ -1399,6 +1445,19 @@ func (t *txLookup) Get(hash common.Hash) *types.Transaction {
 	return t.all[hash]
 }
 
+// GetByEthHash returns an Ethereum signed transaction by its Ethereum hash if
+// it exists in the lookup, or nil if not found.
+func (t *txLookup) GetByEthHash(ethHash common.Hash) *types.Transaction {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	hash, ok := t.ethHashes[ethHash]
+	if !ok {
+		return nil
+	}
+	return t.all[hash]
+}
+
 // Count returns the current number of items in the lookup.
 func (t *txLookup) Count() int {
 	t.lock.RLock()
@@ -1413,6 +1472,20 @@ func (t *txLookup) Add(tx *types.Transaction) {
 	defer t.lock.Unlock()
 
 	t.all[tx.Hash()] = tx
+	if _, ok := t.seen[tx.Hash()]; !ok {
+		t.seen[tx.Hash()] = time.Now()
+	}
+	if tx.EvmTx != nil && tx.EvmTx.Version() == types.EthTxVersion {
+		t.ethHashes[tx.EvmTx.EthHash()] = tx.Hash()
+	}
+}
+
+// Seen returns the time a transaction entered the lookup.
+func (t *txLookup) Seen(hash common.Hash) time.Time {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.seen[hash]
 }
 
 // Remove removes a transaction from the lookup.
@@ -1420,5 +1493,9 @@ func (t *txLookup) Remove(hash common.Hash) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	if tx, ok := t.all[hash]; ok && tx.EvmTx != nil && tx.EvmTx.Version() == types.EthTxVersion {
+		delete(t.ethHashes, tx.EvmTx.EthHash())
+	}
 	delete(t.all, hash)
+	delete(t.seen, hash)
 }
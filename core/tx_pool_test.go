@@ -747,6 +747,49 @@ func TestTransactionGapFilling(t *testing.T) {
 	}
 }
 
+// Tests that the pending and queued transactions of each sender are reported
+// with the time they entered the pool.
+func TestTransactionPoolAccounts(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+	other, _ := crypto.GenerateKey()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	otherAccount, _ := deriveSender(transaction(0, 0, other))
+	pool.currentState.AddBalance(account, big.NewInt(1000000), genesisTokenID)
+	pool.currentState.AddBalance(otherAccount, big.NewInt(1000000), genesisTokenID)
+
+	before := time.Now().Unix()
+	pool.AddRemotesSync([]*types.Transaction{
+		transaction(0, 100000, key),
+		transaction(2, 100000, key),
+		transaction(0, 100000, other),
+	})
+	if accounts := pool.Accounts(nil); len(accounts) != 2 {
+		t.Fatalf("accounts mismatched: have %d, want %d", len(accounts), 2)
+	}
+	accounts := pool.Accounts(&account)
+	if len(accounts) != 1 || accounts[0].Address != account || accounts[0].Nonce != 0 {
+		t.Fatalf("unexpected accounts %v", accounts)
+	}
+	if len(accounts[0].Pending) != 1 || accounts[0].Pending[0].Tx.EvmTx.Nonce() != 0 {
+		t.Fatalf("unexpected pending transactions %v", accounts[0].Pending)
+	}
+	if len(accounts[0].Queued) != 1 || accounts[0].Queued[0].Tx.EvmTx.Nonce() != 2 {
+		t.Fatalf("unexpected queued transactions %v", accounts[0].Queued)
+	}
+	if seen := int64(accounts[0].Queued[0].Seen); seen < before || seen > time.Now().Unix() {
+		t.Fatalf("unexpected time the transaction entered the pool: %d", seen)
+	}
+
+	pool.removeTx(accounts[0].Queued[0].Tx.Hash(), true)
+	if len(pool.all.seen) != pool.all.Count() {
+		t.Fatalf("seen times mismatched: have %d, want %d", len(pool.all.seen), pool.all.Count())
+	}
+}
+
 // Tests that if the transaction count belonging to a single account goes above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 func TestTransactionQueueAccountLimiting(t *testing.T) {
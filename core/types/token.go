@@ -27,6 +27,14 @@ type TokenBalancePair struct {
 	Balance *big.Int
 }
 
+// TokenBalanceProof is a token balance with its Merkle proof against the root
+// of the token trie.
+type TokenBalanceProof struct {
+	TokenID uint64
+	Balance *big.Int
+	Proof   [][]byte
+}
+
 type TokenBalances struct {
 	db        *trie.Database
 	tokenTrie *trie.SecureTrie
@@ -186,6 +194,49 @@ func (t *TokenBalances) GetTokenBalance(tokenID uint64) *big.Int {
 	return new(big.Int)
 }
 
+// Prove returns the root of the token trie with the proofs of all the balances
+// stored in it, only committed balances can be proved. An empty root and no
+// proofs are returned if the balances are stored in the account itself.
+func (t *TokenBalances) Prove() (common.Hash, []*TokenBalanceProof, error) {
+	if t.tokenTrie == nil {
+		return common.Hash{}, nil, nil
+	}
+	if len(t.balances) != 0 {
+		return common.Hash{}, nil, errors.New("token balances not committed")
+	}
+	proofs := make([]*TokenBalanceProof, 0)
+	it := trie.NewIterator(t.tokenTrie.NodeIterator(nil))
+	for it.Next() {
+		key := t.tokenTrie.GetKey(it.Key)
+		if len(key) != 32 {
+			return common.Hash{}, nil, fmt.Errorf("missing token id of key %x", it.Key)
+		}
+		balance := new(big.Int)
+		if err := rlp.DecodeBytes(it.Value, balance); err != nil {
+			return common.Hash{}, nil, err
+		}
+		proofs = append(proofs, &TokenBalanceProof{
+			TokenID: new(big.Int).SetBytes(key).Uint64(),
+			Balance: balance,
+			Proof:   it.Prove(),
+		})
+	}
+	if it.Err != nil {
+		return common.Hash{}, nil, it.Err
+	}
+	sort.Slice(proofs, func(i, j int) bool { return proofs[i].TokenID < proofs[j].TokenID })
+	return t.tokenTrie.Hash(), proofs, nil
+}
+
+// NodeIterator returns an iterator over the nodes of the token trie, or nil if
+// the balances are stored in the account itself.
+func (t *TokenBalances) NodeIterator() trie.NodeIterator {
+	if t.tokenTrie == nil {
+		return nil
+	}
+	return t.tokenTrie.NodeIterator(nil)
+}
+
 func (t *TokenBalances) GetBalanceMap() map[uint64]*big.Int {
 	data := t.Copy()
 	return data.balances
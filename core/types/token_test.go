@@ -14,6 +14,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestNewTokenBalanceMap(t *testing.T) {
@@ -153,3 +155,52 @@ func TestResetBalanceInTrieAndRevert(t *testing.T) {
 	//py handle revert in token_balances
 	//go handle revert in token_balances's caller
 }
+
+func TestTokenBalancesProve(t *testing.T) {
+	db := trie.NewDatabase(ethdb.NewMemDatabase())
+	b0, err := NewTokenBalances(nil, db)
+	assert.NoError(t, err)
+	b0.SetValue(big.NewInt(42), qCommon.TokenIDEncode("QKC"))
+	root, proofs, err := b0.Prove()
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}, root)
+	assert.Nil(t, proofs)
+
+	mapping := make(map[uint64]*big.Int, 0)
+	for index := 0; index < 17; index++ {
+		mapping[qCommon.TokenIDEncode("Q"+string(byte(65+index)))] = new(big.Int).SetUint64(uint64(index*1000 + 42))
+	}
+	b0.Add(mapping)
+	_, _, err = b0.Prove()
+	assert.NoError(t, err)
+	b0.Commit()
+	sData, err := b0.SerializeToBytes()
+	assert.NoError(t, err)
+
+	b1, err := NewTokenBalances(sData, db)
+	assert.NoError(t, err)
+	root, proofs, err = b1.Prove()
+	assert.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(sData[1:]), root)
+	assert.Equal(t, len(mapping)+1, len(proofs))
+	for i, proof := range proofs {
+		if i > 0 {
+			assert.True(t, proofs[i-1].TokenID < proof.TokenID)
+		}
+		assert.Equal(t, b1.GetTokenBalance(proof.TokenID), proof.Balance)
+
+		proofDB := ethdb.NewMemDatabase()
+		for _, node := range proof.Proof {
+			assert.NoError(t, proofDB.Put(crypto.Keccak256(node), node))
+		}
+		value, _, err := trie.VerifyProof(root, crypto.Keccak256(qCommon.EncodeToByte32(proof.TokenID)), proofDB)
+		assert.NoError(t, err)
+		balance := new(big.Int)
+		assert.NoError(t, rlp.DecodeBytes(value, balance))
+		assert.Equal(t, proof.Balance, balance)
+	}
+
+	b1.SetValue(big.NewInt(1), qCommon.TokenIDEncode("QKC"))
+	_, _, err = b1.Prove()
+	assert.Error(t, err)
+}
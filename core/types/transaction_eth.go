@@ -0,0 +1,116 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	qkcCommon "github.com/QuarkChain/goquarkchain/common"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EthTxVersion is the version of transactions signed in the Ethereum format,
+// the signature covers the EIP155 hash of the Ethereum transaction instead of
+// the QuarkChain specific fields, which are pinned by the chain id.
+const EthTxVersion = 2
+
+var (
+	// EthTxTokenID is the token Ethereum transactions transfer and pay gas with.
+	EthTxTokenID = qkcCommon.TokenIDEncode("QKC")
+
+	ErrEthTxChainID = errors.New("invalid chain id for ethereum transaction")
+)
+
+// ethTxdata is the legacy Ethereum transaction format.
+type ethTxdata struct {
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *account.Recipient `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	V, R, S      *big.Int
+}
+
+// EthChainID returns the EIP155 chain id Ethereum tooling signs transactions
+// of the shard of fullShardKey with.
+func EthChainID(networkId, fullShardKey uint32) uint64 {
+	return uint64(networkId)<<32 | uint64(fullShardKey)
+}
+
+// NewEvmTransactionFromEth converts an RLP encoded EIP155 signed Ethereum
+// transaction into a transaction that stays in the shard of fullShardKey.
+func NewEvmTransactionFromEth(encodedTx []byte, networkId, fullShardKey uint32) (*EvmTransaction, error) {
+	var data ethTxdata
+	if err := rlp.DecodeBytes(encodedTx, &data); err != nil {
+		return nil, err
+	}
+	// transactions without chain id could be replayed on any shard
+	if data.V.BitLen() > 64 || data.V.Uint64() < 35 {
+		return nil, ErrEthTxChainID
+	}
+	chainID := (data.V.Uint64() - 35) / 2
+	if chainID != EthChainID(networkId, fullShardKey) {
+		return nil, ErrEthTxChainID
+	}
+	tx := newEvmTransaction(data.AccountNonce, data.Recipient, data.Amount, data.GasLimit, data.Price,
+		fullShardKey, fullShardKey, networkId, EthTxVersion, data.Payload, EthTxTokenID, EthTxTokenID)
+	tx.data.V = new(big.Int).SetUint64(data.V.Uint64() - 35 - chainID*2 + 27)
+	tx.data.R, tx.data.S = data.R, data.S
+	return tx, nil
+}
+
+// EthChainID returns the chain id of an Ethereum transaction.
+func (tx *EvmTransaction) EthChainID() uint64 {
+	return EthChainID(tx.data.NetworkId, tx.FromFullShardKey())
+}
+
+// EthSignatureValues returns the signature values of the transaction with the
+// chain id added to V for Ethereum transactions.
+func (tx *EvmTransaction) EthSignatureValues() (v, r, s *big.Int) {
+	v, r, s = tx.RawSignatureValues()
+	if tx.data.Version == EthTxVersion {
+		v = new(big.Int).SetUint64(v.Uint64() - 27 + 35 + tx.EthChainID()*2)
+	}
+	return v, r, s
+}
+
+// EthHash returns the hash Ethereum tooling knows an Ethereum transaction by,
+// the hash of its RLP encoding as sent to eth_sendRawTransaction.
+func (tx *EvmTransaction) EthHash() common.Hash {
+	v, r, s := tx.EthSignatureValues()
+	return rlpHash(&ethTxdata{
+		AccountNonce: tx.data.AccountNonce,
+		Price:        tx.data.Price,
+		GasLimit:     tx.data.GasLimit,
+		Recipient:    tx.data.Recipient,
+		Amount:       tx.data.Amount,
+		Payload:      tx.data.Payload,
+		V:            v,
+		R:            r,
+		S:            s,
+	})
+}
+
+// ethHash returns the EIP155 hash signed by the sender of an Ethereum
+// transaction. The fields the hash does not cover are checked against the
+// values the transaction was converted with.
+func (tx *EvmTransaction) ethHash() (common.Hash, error) {
+	if tx.FromFullShardKey() != tx.ToFullShardKey() {
+		return common.Hash{}, errors.New("ethereum transaction must not be cross-shard")
+	}
+	if tx.data.GasTokenID != EthTxTokenID || tx.data.TransferTokenID != EthTxTokenID {
+		return common.Hash{}, fmt.Errorf("ethereum transaction must use token %d", EthTxTokenID)
+	}
+	return rlpHash([]interface{}{
+		tx.data.AccountNonce,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+		tx.EthChainID(), uint(0), uint(0),
+	}), nil
+}
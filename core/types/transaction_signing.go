@@ -107,6 +107,12 @@ func (s EIP155Signer) Sender(tx *EvmTransaction) (account.Recipient, error) {
 			return account.Recipient{}, err
 		}
 		return recoverPlain(hashTyped, tx.data.R, tx.data.S, tx.data.V, true)
+	} else if tx.data.Version == EthTxVersion {
+		hashEth, err := tx.ethHash()
+		if err != nil {
+			return account.Recipient{}, err
+		}
+		return recoverPlain(hashEth, tx.data.R, tx.data.S, tx.data.V, true)
 	} else {
 		return account.Recipient{}, fmt.Errorf("Version %d is not suppot", tx.data.Version)
 	}
@@ -20,7 +20,11 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestEIP155Signing(t *testing.T) {
@@ -41,3 +45,55 @@ func TestEIP155Signing(t *testing.T) {
 		t.Errorf("exected from and address to be equal. Got %x want %x", from, recipient)
 	}
 }
+
+func TestEthTxSigning(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	recipient := publicKey2Recipient(&key.PublicKey)
+	fullShardKey := uint32(0x00010002)
+
+	chainID := new(big.Int).SetUint64(EthChainID(3, fullShardKey))
+	ethTx := ethTypes.NewTransaction(5, common.Address{1}, big.NewInt(100), 21000, big.NewInt(2), []byte{0xaa})
+	ethTx, err := ethTypes.SignTx(ethTx, ethTypes.NewEIP155Signer(chainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := rlp.EncodeToBytes(ethTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewEvmTransactionFromEth(encoded, 3, fullShardKey+1); err != ErrEthTxChainID {
+		t.Fatalf("expected chain id error, got %v", err)
+	}
+	tx, err := NewEvmTransactionFromEth(encoded, 3, fullShardKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Version() != EthTxVersion || tx.FromFullShardKey() != fullShardKey || tx.ToFullShardKey() != fullShardKey {
+		t.Fatalf("unexpected transaction %v", tx)
+	}
+	if tx.Nonce() != 5 || *tx.To() != (account.Recipient{1}) || tx.Value().Int64() != 100 || tx.Gas() != 21000 {
+		t.Fatalf("unexpected transaction %v", tx)
+	}
+	from, err := Sender(NewEIP155Signer(3), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != recipient {
+		t.Errorf("exected from and address to be equal. Got %x want %x", from, recipient)
+	}
+	if tx.EthHash() != ethTx.Hash() {
+		t.Errorf("ethereum hash mismatch. Got %x want %x", tx.EthHash(), ethTx.Hash())
+	}
+	v, r, s := tx.EthSignatureValues()
+	ethV, ethR, ethS := ethTx.RawSignatureValues()
+	if v.Cmp(ethV) != 0 || r.Cmp(ethR) != 0 || s.Cmp(ethS) != 0 {
+		t.Errorf("signature values mismatch")
+	}
+
+	// the token is not covered by the Ethereum signature
+	tx.data.GasTokenID = 1
+	if _, err := NewEIP155Signer(3).Sender(tx); err == nil {
+		t.Errorf("expected error for ethereum transaction with other token")
+	}
+}
@@ -0,0 +1,171 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	transferMntAddress = common.HexToAddress(transferMntAddr)
+	mintMNTAddress     = common.HexToAddress(MintMNTAddr)
+
+	// revertSelector is the selector of Error(string), used by solidity to encode revert reasons
+	revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+)
+
+// NativeTokenTransfer is a native token movement made through the transferMnt
+// or mintMNT precompiled contracts.
+type NativeTokenTransfer struct {
+	Type    string          `json:"type"`
+	From    *common.Address `json:"from,omitempty"`
+	To      common.Address  `json:"to"`
+	TokenID hexutil.Uint64  `json:"tokenId"`
+	Value   *hexutil.Big    `json:"value"`
+}
+
+// CallFrame is a single call frame of the call tree recorded by CallTracer.
+type CallFrame struct {
+	Type            string               `json:"type"`
+	From            common.Address       `json:"from"`
+	To              common.Address       `json:"to"`
+	Value           *hexutil.Big         `json:"value,omitempty"`
+	TransferTokenID hexutil.Uint64       `json:"transferTokenId"`
+	Gas             hexutil.Uint64       `json:"gas"`
+	GasUsed         hexutil.Uint64       `json:"gasUsed"`
+	Input           hexutil.Bytes        `json:"input"`
+	Output          hexutil.Bytes        `json:"output,omitempty"`
+	Error           string               `json:"error,omitempty"`
+	RevertReason    string               `json:"revertReason,omitempty"`
+	NativeTransfer  *NativeTokenTransfer `json:"nativeTransfer,omitempty"`
+	Calls           []*CallFrame         `json:"calls,omitempty"`
+}
+
+// CallTracer is an EVM tracer and implements Tracer.
+//
+// CallTracer records the tree of the call frames made during the execution of
+// a transaction, as well as the native token movements done by precompiled
+// contracts.
+type CallTracer struct {
+	root  *CallFrame
+	stack []*CallFrame
+}
+
+// NewCallTracer returns a new call tracer
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// CaptureStart implements the Tracer interface, frames are recorded by CaptureEnter.
+func (t *CallTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements the Tracer interface.
+func (t *CallTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureFault implements the Tracer interface.
+func (t *CallTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface, frames are closed by CaptureExit.
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// CaptureEnter opens a new call frame as a child of the current one.
+func (t *CallTracer) CaptureEnter(env *EVM, typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	frame := &CallFrame{
+		Type:            typ.String(),
+		From:            from,
+		To:              to,
+		TransferTokenID: hexutil.Uint64(env.TransferTokenID),
+		Gas:             hexutil.Uint64(gas),
+		Input:           common.CopyBytes(input),
+	}
+	if value != nil {
+		frame.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	if len(t.stack) == 0 {
+		t.root = frame
+	} else {
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = append(parent.Calls, frame)
+	}
+	t.stack = append(t.stack, frame)
+	return nil
+}
+
+// CaptureExit closes the current call frame.
+func (t *CallTracer) CaptureExit(env *EVM, output []byte, gasUsed uint64, err error) error {
+	if len(t.stack) == 0 {
+		return errors.New("call tracer: exit without enter")
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	frame.GasUsed = hexutil.Uint64(gasUsed)
+	frame.Output = common.CopyBytes(output)
+	if err != nil {
+		frame.Error = err.Error()
+		if err == errExecutionReverted {
+			frame.RevertReason = unpackRevertReason(output)
+		}
+		return nil
+	}
+	frame.NativeTransfer = nativeTokenTransfer(frame)
+	return nil
+}
+
+// GetResult returns the outermost call frame of the traced transaction.
+func (t *CallTracer) GetResult() *CallFrame {
+	return t.root
+}
+
+// nativeTokenTransfer decodes the token movement of a successful call to
+// transferMnt(to, tokenID, value) or mintMNT(minter, tokenID, value).
+func nativeTokenTransfer(frame *CallFrame) *NativeTokenTransfer {
+	if frame.Type != CALL.String() || (frame.To != transferMntAddress && frame.To != mintMNTAddress) {
+		return nil
+	}
+	input := []byte(frame.Input)
+	transfer := &NativeTokenTransfer{
+		To:      common.BytesToAddress(getData(input, 0, 32)),
+		TokenID: hexutil.Uint64(new(big.Int).SetBytes(getData(input, 32, 32)).Uint64()),
+		Value:   (*hexutil.Big)(new(big.Int).SetBytes(getData(input, 64, 32))),
+	}
+	if frame.To == mintMNTAddress {
+		transfer.Type = "mintMNT"
+	} else {
+		from := frame.From
+		transfer.Type = "transferMnt"
+		transfer.From = &from
+	}
+	return transfer
+}
+
+// unpackRevertReason returns the message of a revert made by solidity's
+// revert(string) or require(bool, string), or empty string if there is none.
+func unpackRevertReason(output []byte) string {
+	if len(output) < 4+64 || !bytes.Equal(output[:4], revertSelector) {
+		return ""
+	}
+	data := output[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return ""
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[offset.Uint64():start])
+	if !length.IsUint64() || start+length.Uint64() > uint64(len(data)) {
+		return ""
+	}
+	return string(data[start : start+length.Uint64()])
+}
@@ -0,0 +1,52 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestCallTracerFrames(t *testing.T) {
+	var (
+		env    = NewEVM(Context{}, &dummyStatedb{}, params.TestChainConfig, Config{})
+		tracer = NewCallTracer()
+		from   = common.HexToAddress("0x01")
+		to     = common.HexToAddress("0x02")
+		minter = common.HexToAddress("0x03")
+	)
+	input := append(common.LeftPadBytes(minter.Bytes(), 32), common.LeftPadBytes([]byte{0x12, 0x34}, 32)...)
+	input = append(input, common.LeftPadBytes(big.NewInt(100).Bytes(), 32)...)
+
+	tracer.CaptureEnter(env, CALL, from, to, nil, 1000, big.NewInt(1))
+	tracer.CaptureEnter(env, CALL, to, mintMNTAddress, input, 500, big.NewInt(0))
+	tracer.CaptureExit(env, mintMNTSuccess, 9000, nil)
+	tracer.CaptureEnter(env, STATICCALL, to, from, nil, 100, nil)
+	// abi encoded Error("fail")
+	revert := common.Hex2Bytes("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6661696c00000000000000000000000000000000000000000000000000000000")
+	tracer.CaptureExit(env, revert, 10, errExecutionReverted)
+	tracer.CaptureExit(env, nil, 900, nil)
+
+	root := tracer.GetResult()
+	if root == nil || root.Type != "CALL" || root.From != from || root.To != to || uint64(root.GasUsed) != 900 {
+		t.Fatalf("unexpected root frame %+v", root)
+	}
+	if len(root.Calls) != 2 {
+		t.Fatalf("expected 2 sub calls, got %d", len(root.Calls))
+	}
+	mint := root.Calls[0].NativeTransfer
+	if mint == nil || mint.Type != "mintMNT" || mint.To != minter || uint64(mint.TokenID) != 0x1234 || mint.Value.ToInt().Int64() != 100 {
+		t.Fatalf("unexpected native transfer %+v", mint)
+	}
+	static := root.Calls[1]
+	if static.Value != nil || static.Error != errExecutionReverted.Error() || static.RevertReason != "fail" {
+		t.Fatalf("unexpected reverted frame %+v", static)
+	}
+	if err := tracer.CaptureExit(env, nil, 0, nil); err == nil {
+		t.Fatal("expected error when exiting without enter")
+	}
+}
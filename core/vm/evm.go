@@ -198,6 +198,12 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, CALL, caller.Address(), addr, input, gas, value)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err)
+		}()
+	}
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -276,6 +282,12 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, CALLCODE, caller.Address(), addr, input, gas, value)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err)
+		}()
+	}
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -318,6 +330,12 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, DELEGATECALL, caller.Address(), addr, input, gas, nil)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err)
+		}()
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -353,6 +371,12 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, STATICCALL, caller.Address(), addr, input, gas, nil)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err)
+		}()
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -515,6 +539,12 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	} else {
 		contractAddr = CreateAddress(caller.Address(), evm.Context.ToFullShardKey, evm.StateDB.GetNonce(caller.Address()))
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, CREATE, caller.Address(), contractAddr, code, gas, value)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err)
+		}()
+	}
 	return evm.create(caller, &codeAndHash{code: code}, gas, value, contractAddr)
 }
 
@@ -525,6 +555,12 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(evm, CREATE2, caller.Address(), contractAddr, code, gas, endowment)
+		defer func() {
+			evm.vmConfig.Tracer.CaptureExit(evm, ret, gas-leftOverGas, err)
+		}()
+	}
 	return evm.create(caller, codeAndHash, gas, endowment, contractAddr)
 }
 
@@ -94,7 +94,8 @@ func (s *StructLog) ErrorString() string {
 
 // Tracer is used to collect execution traces from an EVM transaction
 // execution. CaptureState is called for each step of the VM with the
-// current VM state.
+// current VM state. CaptureEnter and CaptureExit are called around every
+// call frame, including the outermost one.
 // Note that reference types are actual VM data structures; make copies
 // if you need to retain them beyond the current call.
 type Tracer interface {
@@ -102,6 +103,8 @@ type Tracer interface {
 	CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
 	CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
+	CaptureEnter(env *EVM, typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error
+	CaptureExit(env *EVM, output []byte, gasUsed uint64, err error) error
 }
 
 // StructLogger is an EVM state logger and implements Tracer.
@@ -203,6 +206,16 @@ func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration
 	return nil
 }
 
+// CaptureEnter implements the Tracer interface, StructLogger does not track call frames.
+func (l *StructLogger) CaptureEnter(env *EVM, typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureExit implements the Tracer interface, StructLogger does not track call frames.
+func (l *StructLogger) CaptureExit(env *EVM, output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
 // StructLogs returns the captured log entries.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
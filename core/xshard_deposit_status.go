@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/rawdb"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// cmpXShardTxCursor compares the positions of two cursors in the cross-shard
+// transactions of a shard, following the order the cursor goes through them.
+func cmpXShardTxCursor(a, b *types.XShardTxCursorInfo) int {
+	switch {
+	case a.RootBlockHeight != b.RootBlockHeight:
+		if a.RootBlockHeight < b.RootBlockHeight {
+			return -1
+		}
+		return 1
+	case a.MinorBlockIndex != b.MinorBlockIndex:
+		if a.MinorBlockIndex < b.MinorBlockIndex {
+			return -1
+		}
+		return 1
+	case a.XShardDepositIndex != b.XShardDepositIndex:
+		if a.XShardDepositIndex < b.XShardDepositIndex {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// GetXShardDepositStatus returns the status of the deposit of the cross-shard
+// transaction sent by the minor block of sourceBlockHash and confirmed by the
+// root block of rootBlockHash. The cursor of each minor block points at the
+// last deposit it applied, so the deposit is applied by the first minor block
+// whose cursor reaches the position of the deposit.
+func (m *MinorBlockChain) GetXShardDepositStatus(txHash, sourceBlockHash, rootBlockHash common.Hash) (*rpc.XShardDepositStatus, error) {
+	tip := m.CurrentBlock()
+	status := &rpc.XShardDepositStatus{TipCursor: *tip.Meta().XShardTxCursorInfo}
+	rBlock := m.GetRootBlockByHash(rootBlockHash)
+	if rBlock == nil {
+		return status, nil
+	}
+	status.RootBlockReceived = true
+
+	mBlockIndex := -1
+	for i, header := range rBlock.MinorBlockHeaders() {
+		if header.Hash() == sourceBlockHash {
+			mBlockIndex = i + 1
+			break
+		}
+	}
+	if mBlockIndex < 0 {
+		return nil, fmt.Errorf("minor block %x is not confirmed by root block %x", sourceBlockHash, rootBlockHash)
+	}
+	xTxList := m.ReadCrossShardTxList(sourceBlockHash)
+	if xTxList == nil {
+		return nil, fmt.Errorf("no cross-shard transactions of minor block %x", sourceBlockHash)
+	}
+	depositIndex := -1
+	for i, tx := range xTxList.TXList {
+		if tx.TxHash == txHash {
+			depositIndex = i
+			break
+		}
+	}
+	if depositIndex < 0 {
+		return nil, fmt.Errorf("no deposit of tx %x in minor block %x", txHash, sourceBlockHash)
+	}
+	status.DepositCursor = types.XShardTxCursorInfo{
+		RootBlockHeight:    rBlock.NumberU64(),
+		MinorBlockIndex:    uint64(mBlockIndex),
+		XShardDepositIndex: uint64(depositIndex),
+	}
+	if cmpXShardTxCursor(&status.TipCursor, &status.DepositCursor) < 0 {
+		return status, nil
+	}
+
+	low, high := uint64(0), tip.NumberU64()
+	for low < high {
+		mid := (low + high) / 2
+		block, ok := m.GetBlockByNumber(mid).(*types.MinorBlock)
+		if !ok || block == nil {
+			return nil, fmt.Errorf("no such block:height %d", mid)
+		}
+		if cmpXShardTxCursor(block.Meta().XShardTxCursorInfo, &status.DepositCursor) >= 0 {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	block, ok := m.GetBlockByNumber(low).(*types.MinorBlock)
+	if !ok || block == nil {
+		return nil, fmt.Errorf("no such block:height %d", low)
+	}
+	// the deposits applied by the block are only kept with transaction history
+	if confirmed := rawdb.ReadConfirmedCrossShardTxList(m.db, block.Hash()); confirmed != nil {
+		found := false
+		for _, tx := range confirmed.TXList {
+			if tx.TxHash == txHash {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("deposit of tx %x is not applied by minor block %x", txHash, block.Hash())
+		}
+	}
+	status.Applied = true
+	status.MinorBlockHash = block.Hash()
+	status.MinorBlockHeight = block.NumberU64()
+	return status, nil
+}
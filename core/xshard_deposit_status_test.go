@@ -0,0 +1,122 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/params"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetXShardDepositStatus(t *testing.T) {
+	id1, err := account.CreatRandomIdentity()
+	checkErr(err)
+
+	acc1 := account.CreatAddressFromIdentity(id1, 0)
+	acc2 := account.CreatAddressFromIdentity(id1, 16)
+	acc3, err := account.CreatRandomAccountWithFullShardKey(0)
+	newGenesisMinorQuarkash := uint64(10000000)
+	fakeShardSize := uint32(64)
+	env := setUp(&acc1, &newGenesisMinorQuarkash, &fakeShardSize)
+	env1 := setUp(&acc1, &newGenesisMinorQuarkash, &fakeShardSize)
+	// keep the deposits applied by each block of the destination shard
+	env.clusterConfig.EnableTransactionHistory = true
+	fakeID := uint32(0)
+	shardState0 := createDefaultShardState(env, &fakeID, nil, nil, nil)
+	defer shardState0.Stop()
+	fakeID = uint32(16)
+	shardState1 := createDefaultShardState(env1, &fakeID, nil, nil, nil)
+	defer shardState1.Stop()
+	rootBlock := shardState0.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil)
+	rootBlock.AddMinorBlockHeader(shardState0.CurrentBlock().Header())
+	rootBlock.AddMinorBlockHeader(shardState1.CurrentBlock().Header())
+	rootBlock.Finalize(nil, nil, common.Hash{})
+	_, err = shardState0.AddRootBlock(rootBlock)
+	checkErr(err)
+	_, err = shardState1.AddRootBlock(rootBlock)
+	checkErr(err)
+
+	b0, err := shardState0.CreateBlockToMine(nil, nil, nil, nil, nil)
+	checkErr(err)
+	b0, _, err = shardState0.FinalizeAndAddBlock(b0)
+	checkErr(err)
+	b1 := shardState1.CurrentBlock().CreateBlockToAppend(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	b1Header := b1.Header()
+	b1Header.PrevRootBlockHash = rootBlock.Hash()
+	b1 = types.NewMinorBlock(b1Header, b1.Meta(), b1.Transactions(), nil, nil)
+	fakeGas := uint64(30000)
+	fakeGasPrice := uint64(2)
+	value := new(big.Int).SetUint64(888888)
+	tx := createTransferTransaction(shardState1, id1.GetKey().Bytes(), acc2, acc1, value, &fakeGas, &fakeGasPrice, nil, nil, nil, nil)
+	b1.AddTx(tx)
+	crossShardGas := new(serialize.Uint256)
+	intrinsic := uint64(21000) + params.GtxxShardCost.Uint64()
+	crossShardGas.Value = new(big.Int).SetUint64(tx.EvmTx.Gas() - intrinsic)
+	txList := types.CrossShardTransactionDepositList{}
+	txList.TXList = append(txList.TXList, &types.CrossShardTransactionDeposit{
+		CrossShardTransactionDepositV0: types.CrossShardTransactionDepositV0{
+			TxHash:          tx.Hash(),
+			From:            acc2,
+			To:              acc1,
+			Value:           &serialize.Uint256{Value: value},
+			GasPrice:        &serialize.Uint256{Value: new(big.Int).SetUint64(fakeGasPrice)},
+			GasRemained:     crossShardGas,
+			TransferTokenID: tx.EvmTx.TransferTokenID(),
+			GasTokenID:      tx.EvmTx.GasTokenID(),
+		},
+		RefundRate: 100,
+	})
+	shardState0.AddCrossShardTxListByMinorBlockHash(b1.Hash(), txList)
+	rootBlock = shardState0.rootTip.Header().CreateBlockToAppend(nil, nil, nil, nil, nil)
+	rootBlock.AddMinorBlockHeader(b0.Header())
+	rootBlock.AddMinorBlockHeader(b1.Header())
+	rootBlock.Finalize(nil, nil, common.Hash{})
+
+	// the destination shard has not received the root block yet
+	status, err := shardState0.GetXShardDepositStatus(tx.Hash(), b1.Hash(), rootBlock.Hash())
+	assert.NoError(t, err)
+	assert.False(t, status.RootBlockReceived)
+	assert.False(t, status.Applied)
+	assert.Equal(t, *b0.Meta().XShardTxCursorInfo, status.TipCursor)
+
+	// queued in the destination shard
+	_, err = shardState0.AddRootBlock(rootBlock)
+	checkErr(err)
+	status, err = shardState0.GetXShardDepositStatus(tx.Hash(), b1.Hash(), rootBlock.Hash())
+	assert.NoError(t, err)
+	assert.True(t, status.RootBlockReceived)
+	assert.False(t, status.Applied)
+	assert.Equal(t, types.XShardTxCursorInfo{RootBlockHeight: rootBlock.NumberU64(), MinorBlockIndex: 2, XShardDepositIndex: 0}, status.DepositCursor)
+
+	_, err = shardState0.GetXShardDepositStatus(common.Hash{1}, b1.Hash(), rootBlock.Hash())
+	assert.Error(t, err)
+	_, err = shardState0.GetXShardDepositStatus(tx.Hash(), b0.Hash(), rootBlock.Hash())
+	assert.Error(t, err)
+
+	// applied by the next block of the destination shard
+	b2, err := shardState0.CreateBlockToMine(nil, &acc3, nil, nil, nil)
+	checkErr(err)
+	b2, _, err = shardState0.FinalizeAndAddBlock(b2)
+	checkErr(err)
+	b3, err := shardState0.CreateBlockToMine(nil, &acc3, nil, nil, nil)
+	checkErr(err)
+	_, _, err = shardState0.FinalizeAndAddBlock(b3)
+	checkErr(err)
+	status, err = shardState0.GetXShardDepositStatus(tx.Hash(), b1.Hash(), rootBlock.Hash())
+	assert.NoError(t, err)
+	assert.True(t, status.Applied)
+	assert.Equal(t, b2.Hash(), status.MinorBlockHash)
+	assert.Equal(t, b2.NumberU64(), status.MinorBlockHeight)
+	assert.True(t, cmpXShardTxCursor(&status.TipCursor, &status.DepositCursor) > 0)
+
+	bytes, err := serialize.SerializeToBytes(status)
+	assert.NoError(t, err)
+	decoded := new(rpc.XShardDepositStatus)
+	assert.NoError(t, serialize.DeserializeFromBytes(bytes, decoded))
+	assert.Equal(t, status, decoded)
+}
@@ -115,6 +115,36 @@ func RootBlockEncoder(rootBlock *types.RootBlock, extraInfo *rpc.PoSWInfo) (map[
 	return fields, nil
 }
 
+func RootBlockHeaderEncoder(header *types.RootBlockHeader) (map[string]interface{}, error) {
+	minerData, err := serialize.SerializeToBytes(header.Coinbase)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":             header.Hash(),
+		"height":         hexutil.Uint64(header.Number),
+		"hash":           header.Hash(),
+		"sealHash":       header.SealHash(),
+		"hashPrevBlock":  header.ParentHash,
+		"idPrevBlock":    header.ParentHash,
+		"nonce":          hexutil.Uint64(header.Nonce),
+		"hashMerkleRoot": header.MinorHeaderHash,
+		"miner":          DataEncoder(minerData),
+		"coinbase":       BalancesEncoder(header.CoinbaseAmount),
+		"difficulty":     (*hexutil.Big)(header.Difficulty),
+		"timestamp":      hexutil.Uint64(header.Time),
+		"signature":      DataEncoder(header.Signature[:]),
+	}, nil
+}
+
+func XShardTxCursorEncoder(cursor *types.XShardTxCursorInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"rootBlockHeight":    hexutil.Uint64(cursor.RootBlockHeight),
+		"minorBlockIndex":    hexutil.Uint64(cursor.MinorBlockIndex),
+		"xShardDepositIndex": hexutil.Uint64(cursor.XShardDepositIndex),
+	}
+}
+
 func MinorBlockHeaderEncoder(header *types.MinorBlockHeader) (map[string]interface{}, error) {
 	minerData, err := serialize.SerializeToBytes(header.Coinbase)
 	if err != nil {
@@ -325,3 +355,49 @@ func ReceiptEncoder(block *types.MinorBlock, i int, receipt *types.Receipt) (map
 	}
 	return field, nil
 }
+
+func proofEncoder(proof rpc.MerkleProof) []hexutil.Bytes {
+	nodes := make([]hexutil.Bytes, 0, len(proof))
+	for _, node := range proof {
+		nodes = append(nodes, hexutil.Bytes(node))
+	}
+	return nodes
+}
+
+func AccountProofEncoder(address *account.Address, proof *rpc.AccountProof) map[string]interface{} {
+	balances := make([]map[string]interface{}, 0, len(proof.Balances))
+	for _, b := range proof.Balances {
+		balance := map[string]interface{}{
+			"tokenId": hexutil.Uint64(b.TokenID),
+			"balance": (*hexutil.Big)(b.Balance),
+			"proof":   proofEncoder(b.Proof),
+		}
+		if tokenStr, err := common.TokenIdDecode(b.TokenID); err == nil {
+			balance["tokenStr"] = tokenStr
+		}
+		balances = append(balances, balance)
+	}
+	storageProofs := make([]map[string]interface{}, 0, len(proof.StorageProofs))
+	for _, s := range proof.StorageProofs {
+		storageProofs = append(storageProofs, map[string]interface{}{
+			"key":   s.Key,
+			"value": s.Value,
+			"proof": proofEncoder(s.Proof),
+		})
+	}
+	return map[string]interface{}{
+		"address":       DataEncoder(address.ToBytes()),
+		"blockId":       IDEncoder(proof.MinorBlockHash.Bytes(), address.FullShardKey),
+		"blockHash":     proof.MinorBlockHash,
+		"blockHeight":   hexutil.Uint64(proof.MinorBlockHeight),
+		"stateRoot":     proof.StateRoot,
+		"accountRlp":    hexutil.Bytes(proof.AccountRLP),
+		"accountProof":  proofEncoder(proof.AccountProof),
+		"nonce":         hexutil.Uint64(proof.Nonce),
+		"codeHash":      proof.CodeHash,
+		"storageHash":   proof.StorageRoot,
+		"tokenTrieRoot": proof.TokenTrieRoot,
+		"balances":      balances,
+		"storageProof":  storageProofs,
+	}
+}
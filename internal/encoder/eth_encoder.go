@@ -0,0 +1,153 @@
+package encoder
+
+import (
+	"errors"
+
+	"github.com/QuarkChain/goquarkchain/common"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/serialize"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// IsPendingBlock reports whether the block is the empty block the slaves return
+// for a transaction still in the pool or an unknown one, the genesis block is
+// the only other block at height 0 and it has no transactions.
+func IsPendingBlock(block *types.MinorBlock) bool {
+	return block == nil || block.NumberU64() == 0
+}
+
+// ethTxHash returns the hash Ethereum tooling knows the transaction by.
+func ethTxHash(tx *types.Transaction) ethCommon.Hash {
+	if tx.EvmTx.Version() == types.EthTxVersion {
+		return tx.EvmTx.EthHash()
+	}
+	return tx.Hash()
+}
+
+func EthBlockEncoder(block *types.MinorBlock, fullTx bool) (map[string]interface{}, error) {
+	serData, err := serialize.SerializeToBytes(block)
+	if err != nil {
+		return nil, err
+	}
+	header := block.Header()
+	meta := block.Meta()
+	field := map[string]interface{}{
+		"number":           hexutil.Uint64(header.Number),
+		"hash":             header.Hash(),
+		"parentHash":       header.ParentHash,
+		"nonce":            hexutil.Bytes(common.Uint64ToBytes(header.Nonce)),
+		"mixHash":          header.MixDigest,
+		"sha3Uncles":       ethTypes.EmptyUncleHash,
+		"logsBloom":        header.Bloom,
+		"transactionsRoot": meta.TxHash,
+		"stateRoot":        meta.Root,
+		"receiptsRoot":     meta.ReceiptHash,
+		"miner":            ethCommon.Address(header.Coinbase.Recipient),
+		"difficulty":       (*hexutil.Big)(header.Difficulty),
+		"totalDifficulty":  (*hexutil.Big)(header.Difficulty),
+		"extraData":        hexutil.Bytes(header.Extra),
+		"size":             hexutil.Uint64(len(serData)),
+		"gasLimit":         (*hexutil.Big)(header.GasLimit.Value),
+		"gasUsed":          (*hexutil.Big)(meta.GasUsed.Value),
+		"timestamp":        hexutil.Uint64(header.Time),
+		"uncles":           []ethCommon.Hash{},
+	}
+	if fullTx {
+		txs := make([]map[string]interface{}, 0, len(block.Transactions()))
+		for i := range block.Transactions() {
+			tx, err := EthTxEncoder(block, i)
+			if err != nil {
+				return nil, err
+			}
+			txs = append(txs, tx)
+		}
+		field["transactions"] = txs
+	} else {
+		txHashes := make([]ethCommon.Hash, 0, len(block.Transactions()))
+		for _, tx := range block.Transactions() {
+			txHashes = append(txHashes, ethTxHash(tx))
+		}
+		field["transactions"] = txHashes
+	}
+	return field, nil
+}
+
+func EthTxEncoder(block *types.MinorBlock, i int) (map[string]interface{}, error) {
+	tx := block.Transactions()[i]
+	evmtx := tx.EvmTx
+	sender, err := types.Sender(types.MakeSigner(evmtx.NetworkId()), evmtx)
+	if err != nil {
+		return nil, err
+	}
+	var to *ethCommon.Address
+	if evmtx.To() != nil {
+		addr := ethCommon.Address(*evmtx.To())
+		to = &addr
+	}
+	v, r, s := evmtx.EthSignatureValues()
+	field := map[string]interface{}{
+		"hash":             ethTxHash(tx),
+		"nonce":            hexutil.Uint64(evmtx.Nonce()),
+		"blockHash":        nil,
+		"blockNumber":      nil,
+		"transactionIndex": nil,
+		"from":             ethCommon.Address(sender),
+		"to":               to,
+		"value":            (*hexutil.Big)(evmtx.Value()),
+		"gasPrice":         (*hexutil.Big)(evmtx.GasPrice()),
+		"gas":              hexutil.Uint64(evmtx.Gas()),
+		"input":            hexutil.Bytes(evmtx.Data()),
+		"v":                (*hexutil.Big)(v),
+		"r":                (*hexutil.Big)(r),
+		"s":                (*hexutil.Big)(s),
+	}
+	if !IsPendingBlock(block) {
+		field["blockHash"] = block.Hash()
+		field["blockNumber"] = hexutil.Uint64(block.NumberU64())
+		field["transactionIndex"] = hexutil.Uint64(i)
+	}
+	return field, nil
+}
+
+func EthReceiptEncoder(block *types.MinorBlock, i int, receipt *types.Receipt) (map[string]interface{}, error) {
+	if block == nil || len(block.Transactions()) <= i {
+		return nil, errors.New("block is nil")
+	}
+	if receipt == nil {
+		return nil, errors.New("receipt is nil")
+	}
+	tx, err := EthTxEncoder(block, i)
+	if err != nil {
+		return nil, err
+	}
+	field := map[string]interface{}{
+		"transactionHash":   tx["hash"],
+		"transactionIndex":  hexutil.Uint64(i),
+		"blockHash":         block.Hash(),
+		"blockNumber":       hexutil.Uint64(block.NumberU64()),
+		"from":              tx["from"],
+		"to":                tx["to"],
+		"cumulativeGasUsed": hexutil.Uint64(receipt.CumulativeGasUsed),
+		"gasUsed":           hexutil.Uint64(receipt.GasUsed - receipt.GetPrevGasUsed()),
+		"contractAddress":   nil,
+		"logs":              ethLogListEncoder(receipt.Logs, tx["hash"]),
+		"logsBloom":         receipt.Bloom,
+		"status":            hexutil.Uint64(receipt.Status),
+	}
+	if receipt.ContractAddress.Big().Uint64() != 0 {
+		field["contractAddress"] = ethCommon.Address(receipt.ContractAddress)
+	}
+	return field, nil
+}
+
+// ethLogListEncoder encodes the logs of a receipt with the hash Ethereum
+// tooling knows their transaction by.
+func ethLogListEncoder(logs []*types.Log, txHash interface{}) []map[string]interface{} {
+	fields := LogListEncoder(logs, false)
+	for _, field := range fields {
+		field["transactionHash"] = txHash
+	}
+	return fields
+}
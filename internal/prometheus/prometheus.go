@@ -0,0 +1,104 @@
+// Package prometheus exposes the metrics registry in the Prometheus text format.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Quantiles are the quantiles reported for the timers and histograms.
+var Quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Handler returns a handler rendering the metrics of reg, the names of the
+// metrics have their "/" and other characters Prometheus does not accept
+// replaced by "_". Timers are reported in seconds.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := make([]string, 0)
+		values := make(map[string]interface{})
+		reg.Each(func(name string, i interface{}) {
+			names = append(names, name)
+			values[name] = i
+		})
+		sort.Strings(names)
+
+		var buf bytes.Buffer
+		for _, name := range names {
+			writeMetric(&buf, metricName(name), values[name])
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(buf.Bytes())
+	})
+}
+
+// StartEndpoint serves the metrics of reg on /metrics of endpoint until the
+// returned listener is closed.
+func StartEndpoint(endpoint string, reg metrics.Registry) (net.Listener, error) {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(reg))
+	go func() {
+		if err := http.Serve(listener, mux); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+			log.Error("Failure in running metrics server", "err", err)
+		}
+	}()
+	return listener, nil
+}
+
+func metricName(name string) string {
+	mangled := []byte(name)
+	for i, c := range mangled {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':' || (c >= '0' && c <= '9' && i > 0)) {
+			mangled[i] = '_'
+		}
+	}
+	return string(mangled)
+}
+
+func writeMetric(buf *bytes.Buffer, name string, i interface{}) {
+	switch m := i.(type) {
+	case metrics.Counter:
+		writeValue(buf, name, "counter", float64(m.Count()))
+	case metrics.Gauge:
+		writeValue(buf, name, "gauge", float64(m.Value()))
+	case metrics.GaugeFloat64:
+		writeValue(buf, name, "gauge", m.Value())
+	case metrics.Meter:
+		writeValue(buf, name, "counter", float64(m.Count()))
+	case metrics.Timer:
+		t := m.Snapshot()
+		writeSummary(buf, name, t.Percentiles(Quantiles), float64(t.Sum()), t.Count(), 1e-9)
+	case metrics.Histogram:
+		h := m.Snapshot()
+		writeSummary(buf, name, h.Percentiles(Quantiles), float64(h.Sum()), h.Count(), 1)
+	}
+}
+
+func writeValue(buf *bytes.Buffer, name, kind string, value float64) {
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, kind)
+	fmt.Fprintf(buf, "%s %s\n", name, formatFloat(value))
+}
+
+func writeSummary(buf *bytes.Buffer, name string, quantiles []float64, sum float64, count int64, scale float64) {
+	fmt.Fprintf(buf, "# TYPE %s summary\n", name)
+	for i, q := range Quantiles {
+		fmt.Fprintf(buf, "%s{quantile=\"%s\"} %s\n", name, formatFloat(q), formatFloat(quantiles[i]*scale))
+	}
+	fmt.Fprintf(buf, "%s_sum %s\n", name, formatFloat(sum*scale))
+	fmt.Fprintf(buf, "%s_count %d\n", name, count)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
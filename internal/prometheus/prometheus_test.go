@@ -0,0 +1,53 @@
+package prometheus
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	reg := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("grpc/AddRootBlock/errors", reg).Inc(3)
+	metrics.NewRegisteredGauge("shard/1/height", reg).Update(42)
+	timer := metrics.NewRegisteredTimer("chain/root/inserts", reg)
+	timer.Update(2 * time.Second)
+	timer.Update(4 * time.Second)
+
+	server := httptest.NewServer(Handler(reg))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"# TYPE grpc_AddRootBlock_errors counter\ngrpc_AddRootBlock_errors 3\n",
+		"# TYPE shard_1_height gauge\nshard_1_height 42\n",
+		"# TYPE chain_root_inserts summary\n",
+		"chain_root_inserts{quantile=\"0.5\"} 3\n",
+		"chain_root_inserts_sum 6\nchain_root_inserts_count 2\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+	if strings.Index(string(body), "chain_root") > strings.Index(string(body), "shard_1") {
+		t.Errorf("metrics are not sorted:\n%s", body)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/QuarkChain/goquarkchain/account"
 	qrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
@@ -23,7 +26,7 @@ type CommonAPI struct {
 	b Backend
 }
 
-func (c *CommonAPI) callOrEstimateGas(args *CallArgs, height *uint64, isCall bool) (hexutil.Bytes, error) {
+func (c *CommonAPI) callOrEstimateGas(args *CallArgs, height *uint64, isCall bool, overrides *StateOverrides) (hexutil.Bytes, error) {
 	if args.To == nil {
 		return nil, errors.New("missing to")
 	}
@@ -32,18 +35,22 @@ func (c *CommonAPI) callOrEstimateGas(args *CallArgs, height *uint64, isCall boo
 	if err != nil {
 		return nil, err
 	}
+	stateOverrides, err := overrides.toStateOverrides()
+	if err != nil {
+		return nil, err
+	}
 	if isCall {
 		isSameChain := clusterCfg.Quarkchain.IsSameFullShard(args.From.FullShardKey, args.To.FullShardKey)
 		if !isSameChain {
 			return nil, fmt.Errorf("Call cross-shard tx not supported yet\n")
 		}
-		res, err := c.b.ExecuteTransaction(tx, args.From, height)
+		res, err := c.b.ExecuteTransaction(tx, args.From, height, stateOverrides)
 		if err != nil {
 			return nil, err
 		}
 		return (hexutil.Bytes)(res), nil
 	}
-	data, err := c.b.EstimateGas(tx, args.From)
+	data, err := c.b.EstimateGas(tx, args.From, stateOverrides)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +101,10 @@ func (c *CommonAPI) GetLogs(args *rpc.FilterQuery, fullShardKey *hexutil.Uint) (
 	if err != nil {
 		return nil, err
 	}
+	return c.getLogs(args, fullShardID)
+}
+
+func (c *CommonAPI) getLogs(args *rpc.FilterQuery, fullShardID uint32) ([]map[string]interface{}, error) {
 	lastBlockHeight, err := c.b.GetLastMinorBlockByFullShardID(fullShardID)
 	if err != nil {
 		return nil, err
@@ -109,6 +120,9 @@ func (c *CommonAPI) GetLogs(args *rpc.FilterQuery, fullShardKey *hexutil.Uint) (
 	args.FullShardId = fullShardID
 
 	log, err := c.b.GetLogs(args)
+	if err != nil {
+		return nil, err
+	}
 	return encoder.LogListEncoder(log, false), nil
 }
 
@@ -387,20 +401,24 @@ func (p *PublicBlockChainAPI) GetTransactionById(txID hexutil.Bytes) (map[string
 	return encoder.TxEncoder(minorBlock, int(index))
 }
 
-func (p *PublicBlockChainAPI) Call(data CallArgs, blockNr *rpc.BlockNumber) (hexutil.Bytes, error) {
+// Call executes the given call on top of the state of blockNr, the state of the
+// accounts in overrides is replaced before the execution.
+func (p *PublicBlockChainAPI) Call(data CallArgs, blockNr *rpc.BlockNumber, overrides *StateOverrides) (hexutil.Bytes, error) {
 	if blockNr == nil {
-		return p.CommonAPI.callOrEstimateGas(&data, nil, true)
+		return p.CommonAPI.callOrEstimateGas(&data, nil, true, overrides)
 	}
 	blockNumber, err := decodeBlockNumberToUint64(p.b, blockNr)
 	if err != nil {
 		return nil, err
 	}
-	return p.CommonAPI.callOrEstimateGas(&data, blockNumber, true)
+	return p.CommonAPI.callOrEstimateGas(&data, blockNumber, true, overrides)
 
 }
 
-func (p *PublicBlockChainAPI) EstimateGas(data CallArgs) (hexutil.Uint, error) {
-	gas, err := p.CommonAPI.callOrEstimateGas(&data, nil, false)
+// EstimateGas estimates the gas needed by the given call, the state of the
+// accounts in overrides is replaced before the estimation.
+func (p *PublicBlockChainAPI) EstimateGas(data CallArgs, overrides *StateOverrides) (hexutil.Uint, error) {
+	gas, err := p.CommonAPI.callOrEstimateGas(&data, nil, false, overrides)
 	if err != nil {
 		return 0, err
 	}
@@ -428,6 +446,20 @@ func (p *PublicBlockChainAPI) GetCode(address account.Address, blockNr *rpc.Bloc
 	return p.b.GetCode(&address, blockNumber)
 }
 
+// GetProof returns the account and storage values of the address with their
+// Merkle proofs against the state root of the minor block at blockNr.
+func (p *PublicBlockChainAPI) GetProof(address account.Address, storageKeys []common.Hash, blockNr *rpc.BlockNumber) (map[string]interface{}, error) {
+	blockNumber, err := decodeBlockNumberToUint64(p.b, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := p.b.GetProof(&address, storageKeys, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.AccountProofEncoder(&address, proof), nil
+}
+
 func (p *PublicBlockChainAPI) GetTransactionsByAddress(address account.Address, start *hexutil.Bytes, limit *hexutil.Uint, transferTokenID *hexutil.Uint64) (map[string]interface{}, error) {
 	limitValue := uint32(0)
 	if limit != nil {
@@ -549,6 +581,62 @@ func (p *PublicBlockChainAPI) GasPrice(fullShardKey hexutil.Uint, tokenID *hexut
 	return hexutil.Uint64(data), err
 }
 
+// FeeHistory returns the gas prices paid in tokenID by the last blockCount
+// blocks of the shard at the given percentiles, e.g. [10, 50, 90] for slow,
+// standard and fast suggestions. The prices are also given in the default
+// chain token so that the fees of different gas tokens can be compared.
+func (p *PublicBlockChainAPI) FeeHistory(fullShardKey hexutil.Uint, tokenID *hexutil.Uint64, blockCount hexutil.Uint64, percentiles []float64) (map[string]interface{}, error) {
+	fullShardId, err := getFullShardId(&fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	tokenIDValue := qcom.TokenIDEncode(DefaultTokenID)
+	if tokenID != nil {
+		tokenIDValue = uint64(*tokenID)
+	}
+	basisPoints := make([]uint32, len(percentiles))
+	for i, percentile := range percentiles {
+		if percentile < 0 || percentile > 100 {
+			return nil, fmt.Errorf("invalid percentile %v", percentile)
+		}
+		basisPoints[i] = uint32(math.Round(percentile * 100))
+	}
+	history, err := p.b.GetFeeHistory(account.Branch{Value: fullShardId}, tokenIDValue, uint64(blockCount), basisPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	oldestBlock := hexutil.Uint64(0)
+	if len(history.Blocks) > 0 {
+		oldestBlock = hexutil.Uint64(history.Blocks[0].Height)
+	}
+	gasUsedRatio := make([]float64, 0, len(history.Blocks))
+	gasPrices := make([][]hexutil.Uint64, 0, len(history.Blocks))
+	defaultGasPrices := make([][]hexutil.Uint64, 0, len(history.Blocks))
+	for _, block := range history.Blocks {
+		ratio := float64(0)
+		if block.GasLimit > 0 {
+			ratio = float64(block.GasUsed) / float64(block.GasLimit)
+		}
+		gasUsedRatio = append(gasUsedRatio, ratio)
+		prices := make([]hexutil.Uint64, len(block.GasPrices))
+		defaultPrices := make([]hexutil.Uint64, len(block.DefaultChainTokenGasPrices))
+		for i := range block.GasPrices {
+			prices[i] = hexutil.Uint64(block.GasPrices[i])
+			defaultPrices[i] = hexutil.Uint64(block.DefaultChainTokenGasPrices[i])
+		}
+		gasPrices = append(gasPrices, prices)
+		defaultGasPrices = append(defaultGasPrices, defaultPrices)
+	}
+	return map[string]interface{}{
+		"oldestBlock":               oldestBlock,
+		"gasTokenId":                hexutil.Uint64(tokenIDValue),
+		"gasUsedRatio":              gasUsedRatio,
+		"gasPrice":                  gasPrices,
+		"defaultChainTokenGasPrice": defaultGasPrices,
+	}, nil
+}
+
 func (p *PublicBlockChainAPI) SubmitWork(fullShardKey *hexutil.Uint, headHash common.Hash, nonce hexutil.Uint64, mixHash common.Hash, signature *hexutil.Bytes) (bool, error) {
 	log.Info("ready submit work", "fullShardKey", fullShardKey, "headHash", headHash.String())
 	var fullShardId *uint32
@@ -662,6 +750,116 @@ func (p *PublicBlockChainAPI) GetTransactionConfirmedByNumberRootBlocks(txID hex
 
 }
 
+// The stages of a cross-shard transaction returned by GetCrossShardTransactionStatus.
+const (
+	XShardTxPending     = "pending"     // in the tx pool of the source shard
+	XShardTxFailed      = "failed"      // failed in the source shard, no deposit is sent
+	XShardTxUnconfirmed = "unconfirmed" // in a source minor block not yet confirmed by the root chain
+	XShardTxQueued      = "queued"      // confirmed by a root block, waiting in the destination shard
+	XShardTxApplied     = "applied"     // the deposit is applied by a destination minor block
+)
+
+// GetCrossShardTransactionStatus returns the stage of a cross-shard transaction
+// from its source shard to the deposit in its destination shard, with the root
+// block confirming the source minor block, the position of the deposit against
+// the cross-shard transaction cursor of the destination shard and the
+// destination minor block applying the deposit.
+func (p *PublicBlockChainAPI) GetCrossShardTransactionStatus(txID hexutil.Bytes) (map[string]interface{}, error) {
+	txHash, fullShardKey, err := encoder.IDDecoder(txID)
+	if err != nil {
+		return nil, err
+	}
+	fullShardID, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	branch := account.Branch{Value: fullShardID}
+	mBlock, index, receipt, err := p.b.GetTransactionReceipt(txHash, branch)
+	if err != nil || mBlock == nil || receipt == nil {
+		// the tx pool of the source shard keeps the transactions not in a block yet
+		if pBlock, _, pErr := p.b.GetTransactionByHash(txHash, branch); pErr == nil && pBlock != nil && len(pBlock.Transactions()) > 0 {
+			return p.crossShardTransactionStatus(pBlock.Transactions()[0], XShardTxPending)
+		}
+		if err == nil {
+			err = errors.New("transaction not found")
+		}
+		return nil, err
+	}
+	if len(mBlock.Transactions()) <= int(index) {
+		return nil, errors.New("index bigger than block's tx")
+	}
+	tx := mBlock.Transactions()[index]
+	fields, err := p.crossShardTransactionStatus(tx, XShardTxUnconfirmed)
+	if err != nil {
+		return nil, err
+	}
+	fields["sourceBlockId"] = encoder.IDEncoder(mBlock.Hash().Bytes(), fullShardID)
+	fields["sourceBlockHeight"] = hexutil.Uint64(mBlock.NumberU64())
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		fields["stage"] = XShardTxFailed
+		return fields, nil
+	}
+
+	rootHash := p.b.GetRootHashConfirmingMinorBlock(encoder.IDEncoder(mBlock.Hash().Bytes(), fullShardID))
+	if rootHash == (common.Hash{}) {
+		return fields, nil
+	}
+	rBlock, _, err := p.b.GetRootBlockByHash(rootHash, false)
+	if err != nil {
+		return nil, err
+	}
+	rootHeight := rBlock.NumberU64()
+	canonical, _, err := p.b.GetRootBlockByNumber(&rootHeight, false)
+	if err != nil {
+		return nil, err
+	}
+	if canonical == nil || canonical.Hash() != rootHash {
+		return fields, nil
+	}
+	fields["rootBlockHash"] = rootHash
+	fields["rootBlockHeight"] = hexutil.Uint64(rootHeight)
+	fields["rootConfirmations"] = hexutil.Uint64(p.b.CurrentBlock().NumberU64() - rootHeight + 1)
+
+	toFullShardID, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(tx.EvmTx.ToFullShardKey())
+	if err != nil {
+		return nil, err
+	}
+	status, err := p.b.GetXShardDepositStatus(txHash, account.Branch{Value: toFullShardID}, mBlock.Hash(), rootHash)
+	if err != nil {
+		return nil, err
+	}
+	fields["stage"] = XShardTxQueued
+	fields["destinationCursor"] = encoder.XShardTxCursorEncoder(&status.TipCursor)
+	if status.RootBlockReceived {
+		fields["depositCursor"] = encoder.XShardTxCursorEncoder(&status.DepositCursor)
+	}
+	if status.Applied {
+		fields["stage"] = XShardTxApplied
+		fields["destinationBlockId"] = encoder.IDEncoder(status.MinorBlockHash.Bytes(), toFullShardID)
+		fields["destinationBlockHeight"] = hexutil.Uint64(status.MinorBlockHeight)
+	}
+	return fields, nil
+}
+
+func (p *PublicBlockChainAPI) crossShardTransactionStatus(tx *types.Transaction, stage string) (map[string]interface{}, error) {
+	if tx.EvmTx == nil || !tx.EvmTx.IsCrossShard() {
+		return nil, errors.New("not a cross-shard transaction")
+	}
+	fromFullShardID, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(tx.EvmTx.FromFullShardKey())
+	if err != nil {
+		return nil, err
+	}
+	toFullShardID, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(tx.EvmTx.ToFullShardKey())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"stage":           stage,
+		"fromFullShardId": hexutil.Uint64(fromFullShardID),
+		"toFullShardId":   hexutil.Uint64(toFullShardID),
+	}, nil
+}
+
 func (p *PublicBlockChainAPI) NetVersion() hexutil.Uint {
 	return hexutil.Uint(clusterCfg.Quarkchain.NetworkID)
 }
@@ -744,8 +942,81 @@ func (p *PrivateBlockChainAPI) SetMining(flag bool) {
 	p.b.SetMining(flag)
 }
 
-//TODO ?? necessary?
-func (p *PrivateBlockChainAPI) GetJrpcCalls() { panic("not implemented") }
+// GetJrpcCalls returns the number of calls and errors and the latency histogram
+// of each method served by each JSON-RPC endpoint of the master.
+func (p *PrivateBlockChainAPI) GetJrpcCalls() []map[string]interface{} {
+	calls := rpc.Calls()
+	fields := make([]map[string]interface{}, 0, len(calls))
+	for _, c := range calls {
+		latency := make([]map[string]interface{}, 0, len(c.Histogram))
+		for i, count := range c.Histogram {
+			le := "+Inf"
+			if i < len(rpc.LatencyBuckets) {
+				le = rpc.LatencyBuckets[i].String()
+			}
+			latency = append(latency, map[string]interface{}{
+				"le":    le,
+				"count": hexutil.Uint64(count),
+			})
+		}
+		fields = append(fields, map[string]interface{}{
+			"endpoint":    c.Endpoint,
+			"method":      c.Method,
+			"calls":       hexutil.Uint64(c.Calls),
+			"errors":      hexutil.Uint64(c.Errors),
+			"totalTimeMs": hexutil.Uint64(c.TotalTime / time.Millisecond),
+			"latency":     latency,
+		})
+	}
+	return fields
+}
+
+// GetFailoverEvents returns the last slaves which stopped answering heartbeats,
+// with the standby slaves promoted then and the slave serving each shard since.
+func (p *PrivateBlockChainAPI) GetFailoverEvents() []map[string]interface{} {
+	events := p.b.GetFailoverEvents()
+	fields := make([]map[string]interface{}, 0, len(events))
+	for _, e := range events {
+		shards := make(map[string]string, len(e.ShardToSlave))
+		for fullShardId, slave := range e.ShardToSlave {
+			shards[hexutil.EncodeUint64(uint64(fullShardId))] = slave
+		}
+		fields = append(fields, map[string]interface{}{
+			"timestamp": hexutil.Uint64(e.Timestamp),
+			"slave":     e.Slave,
+			"promoted":  e.Promoted,
+			"shards":    shards,
+		})
+	}
+	return fields
+}
+
+// MigrateShard starts moving a shard from the slave from to the slave to, the
+// shard database is copied in the background and the old copy removed once the
+// slaves run it, see GetShardMigrations.
+func (p *PrivateBlockChainAPI) MigrateShard(fullShardId hexutil.Uint, from, to string) error {
+	return p.b.MigrateShard(uint32(fullShardId), from, to)
+}
+
+// GetShardMigrations returns the progress of the last migration of each shard
+// moved between slaves.
+func (p *PrivateBlockChainAPI) GetShardMigrations() []map[string]interface{} {
+	migrations := p.b.GetShardMigrations()
+	fields := make([]map[string]interface{}, 0, len(migrations))
+	for _, m := range migrations {
+		fields = append(fields, map[string]interface{}{
+			"fullShardId": hexutil.Uint(m.Branch),
+			"from":        m.From,
+			"to":          m.To,
+			"timestamp":   hexutil.Uint64(m.Timestamp),
+			"status":      m.Status,
+			"copied":      hexutil.Uint64(m.Copied),
+			"total":       hexutil.Uint64(m.Total),
+			"error":       m.Error,
+		})
+	}
+	return fields
+}
 
 func (p *PrivateBlockChainAPI) GetKadRoutingTableSize() (hexutil.Uint, error) {
 	urls, err := p.b.GetKadRoutingTable()
@@ -829,20 +1100,20 @@ func (e *EthBlockChainAPI) GetCode(address common.Address, fullShardKey *hexutil
 	return e.b.GetCode(&addr, nil)
 }
 
-func (e *EthBlockChainAPI) Call(data EthCallArgs, fullShardKey *hexutil.Uint) (hexutil.Bytes, error) {
+func (e *EthBlockChainAPI) Call(data EthCallArgs, fullShardKey *hexutil.Uint, overrides *StateOverrides) (hexutil.Bytes, error) {
 	args, err := convertEthCallData(&data)
 	if err != nil {
 		return nil, err
 	}
-	return e.CommonAPI.callOrEstimateGas(args, nil, true)
+	return e.CommonAPI.callOrEstimateGas(args, nil, true, overrides)
 }
 
-func (e *EthBlockChainAPI) EstimateGas(data EthCallArgs, fullShardKey *hexutil.Uint) (hexutil.Uint, error) {
+func (e *EthBlockChainAPI) EstimateGas(data EthCallArgs, fullShardKey *hexutil.Uint, overrides *StateOverrides) (hexutil.Uint, error) {
 	args, err := convertEthCallData(&data)
 	if err != nil {
 		return 0, err
 	}
-	gas, err := e.CommonAPI.callOrEstimateGas(args, nil, false)
+	gas, err := e.CommonAPI.callOrEstimateGas(args, nil, false, overrides)
 	if err != nil {
 		return 0, err
 	}
@@ -858,3 +1129,103 @@ func (e *EthBlockChainAPI) GetStorageAt(address common.Address, key common.Hash,
 	hash, err := e.b.GetStorageAt(&addr, key, nil)
 	return hash.Bytes(), err
 }
+
+// ChainId returns the EIP155 chain id of the shard of ETH_FULL_SHARD_KEY.
+func (e *EthBlockChainAPI) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(types.EthChainID(clusterCfg.Quarkchain.NetworkID, clusterCfg.EthFullShardKey))
+}
+
+func (e *EthBlockChainAPI) BlockNumber() (hexutil.Uint64, error) {
+	fullShardId, err := getEthFullShardId()
+	if err != nil {
+		return 0, err
+	}
+	height, err := e.b.GetLastMinorBlockByFullShardID(fullShardId)
+	return hexutil.Uint64(height), err
+}
+
+func (e *EthBlockChainAPI) GetBlockByHash(blockHash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	fullShardId, err := getEthFullShardId()
+	if err != nil {
+		return nil, err
+	}
+	minorBlock, _, err := e.b.GetMinorBlockByHash(blockHash, account.Branch{Value: fullShardId}, false)
+	if err != nil {
+		return nil, err
+	}
+	if minorBlock == nil {
+		return nil, nil
+	}
+	return encoder.EthBlockEncoder(minorBlock, fullTx)
+}
+
+func (e *EthBlockChainAPI) GetTransactionByHash(txHash common.Hash) (map[string]interface{}, error) {
+	fullShardId, err := getEthFullShardId()
+	if err != nil {
+		return nil, err
+	}
+	minorBlock, index, err := e.b.GetTransactionByHash(txHash, account.Branch{Value: fullShardId})
+	if err != nil {
+		return nil, err
+	}
+	if minorBlock == nil || len(minorBlock.Transactions()) <= int(index) {
+		return nil, nil
+	}
+	return encoder.EthTxEncoder(minorBlock, int(index))
+}
+
+// GetTransactionReceipt returns nil for pending and unknown transactions as
+// Ethereum tooling polls for the receipt until the transaction is mined.
+func (e *EthBlockChainAPI) GetTransactionReceipt(txHash common.Hash) (map[string]interface{}, error) {
+	fullShardId, err := getEthFullShardId()
+	if err != nil {
+		return nil, err
+	}
+	minorBlock, index, receipt, err := e.b.GetTransactionReceipt(txHash, account.Branch{Value: fullShardId})
+	if err != nil {
+		return nil, err
+	}
+	if encoder.IsPendingBlock(minorBlock) || receipt == nil {
+		return nil, nil
+	}
+	return encoder.EthReceiptEncoder(minorBlock, int(index), receipt)
+}
+
+// SendRawTransaction accepts an EIP155 signed Ethereum transaction and
+// returns its Ethereum hash, the transaction is sent to the shard of
+// ETH_FULL_SHARD_KEY.
+func (e *EthBlockChainAPI) SendRawTransaction(encodedTx hexutil.Bytes) (common.Hash, error) {
+	evmTx, err := types.NewEvmTransactionFromEth(encodedTx, clusterCfg.Quarkchain.NetworkID, clusterCfg.EthFullShardKey)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx := &types.Transaction{
+		EvmTx:  evmTx,
+		TxType: types.EvmTx,
+	}
+	if err := e.b.AddTransaction(tx); err != nil {
+		return common.Hash{}, err
+	}
+	return evmTx.EthHash(), nil
+}
+
+func (e *EthBlockChainAPI) GetLogs(args *rpc.FilterQuery) ([]map[string]interface{}, error) {
+	fullShardId, err := getEthFullShardId()
+	if err != nil {
+		return nil, err
+	}
+	return e.CommonAPI.getLogs(args, fullShardId)
+}
+
+// NetAPI offers the net namespace used by Ethereum tooling.
+type NetAPI struct{}
+
+func NewNetAPI() *NetAPI {
+	return &NetAPI{}
+}
+
+// Version returns the chain id of eth_chainId, Ethereum tooling expects the
+// network id to identify the same chain.
+func (n *NetAPI) Version() string {
+	return strconv.FormatUint(types.EthChainID(clusterCfg.Quarkchain.NetworkID, clusterCfg.EthFullShardKey), 10)
+}
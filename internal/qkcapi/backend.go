@@ -12,16 +12,23 @@ import (
 
 type Backend interface {
 	AddTransaction(tx *types.Transaction) error
-	ExecuteTransaction(tx *types.Transaction, address *account.Address, height *uint64) ([]byte, error)
+	ExecuteTransaction(tx *types.Transaction, address *account.Address, height *uint64, overrides []*qrpc.StateOverride) ([]byte, error)
 	GetMinorBlockByHash(blockHash common.Hash, branch account.Branch, needExtraInfo bool) (*types.MinorBlock, *qrpc.PoSWInfo, error)
 	GetMinorBlockByHeight(height *uint64, branch account.Branch, needExtraInfo bool) (*types.MinorBlock, *qrpc.PoSWInfo, error)
 	GetTransactionByHash(txHash common.Hash, branch account.Branch) (*types.MinorBlock, uint32, error)
 	GetTransactionReceipt(txHash common.Hash, branch account.Branch) (*types.MinorBlock, uint32, *types.Receipt, error)
+	TraceTransaction(txHash common.Hash, branch account.Branch, config *qrpc.TraceConfig) ([]byte, error)
+	TraceMinorBlock(blockHash common.Hash, branch account.Branch, config *qrpc.TraceConfig) ([]byte, error)
 	GetTransactionsByAddress(address *account.Address, start []byte, limit uint32, transferTokenID *uint64) ([]*qrpc.TransactionDetail, []byte, error)
 	GetAllTx(branch account.Branch, start []byte, limit uint32) ([]*qrpc.TransactionDetail, []byte, error)
 	GetLogs(args *rpc.FilterQuery) ([]*types.Log, error)
-	EstimateGas(tx *types.Transaction, address *account.Address) (uint32, error)
+	EstimateGas(tx *types.Transaction, address *account.Address, overrides []*qrpc.StateOverride) (uint32, error)
 	GetStorageAt(address *account.Address, key common.Hash, height *uint64) (common.Hash, error)
+	GetProof(address *account.Address, storageKeys []common.Hash, height *uint64) (*qrpc.AccountProof, error)
+	GetPendingTransactionHashes(branch account.Branch) ([]common.Hash, error)
+	GetXShardDepositStatus(txHash common.Hash, branch account.Branch, sourceBlockHash, rootBlockHash common.Hash) (*qrpc.XShardDepositStatus, error)
+	GetFeeHistory(branch account.Branch, tokenID, blockCount uint64, percentiles []uint32) (*qrpc.FeeHistory, error)
+	GetTxPoolContent(branch *account.Branch, sender *account.Recipient, countOnly bool) ([]*qrpc.ShardTxPool, error)
 	GetCode(address *account.Address, height *uint64) ([]byte, error)
 	GasPrice(branch account.Branch, tokenID uint64) (uint64, error)
 	GetWork(fullShardId *uint32, address *common.Address) (*consensus.MiningWork, error)
@@ -42,8 +49,13 @@ type Backend interface {
 	IsSyncing() bool
 	IsMining() bool
 	GetSlavePoolLen() int
+	GetFailoverEvents() []*qrpc.FailoverEvent
+	MigrateShard(fullShardId uint32, from, to string) error
+	GetShardMigrations() []*qrpc.ShardMigration
 	GetLastMinorBlockByFullShardID(fullShardId uint32) (uint64, error)
 	GetRootHashConfirmingMinorBlock(mBlockID []byte) common.Hash
+	// Done returns a channel which is closed when the backend stops
+	Done() <-chan struct{}
 	// p2p discovery healty nodes
 	GetKadRoutingTable() ([]string, error)
 }
@@ -65,11 +77,35 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Service:   NewPrivateBlockChainAPI(apiBackend),
 			Public:    false,
 		},
+		{
+			Namespace: "qkc",
+			Version:   "1.0",
+			Service:   NewPublicFilterAPI(apiBackend),
+			Public:    true,
+		},
 		{
 			Namespace: "eth",
 			Version:   "1.0",
 			Service:   NewEthAPI(apiBackend),
 			Public:    true,
 		},
+		{
+			Namespace: "net",
+			Version:   "1.0",
+			Service:   NewNetAPI(),
+			Public:    true,
+		},
+		{
+			Namespace: "txpool",
+			Version:   "1.0",
+			Service:   NewPrivateTxPoolAPI(apiBackend),
+			Public:    false,
+		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPrivateDebugAPI(apiBackend),
+			Public:    false,
+		},
 	}
 }
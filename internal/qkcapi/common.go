@@ -27,6 +27,10 @@ func getFullShardId(fullShardKey *hexutil.Uint) (fullShardId uint32, err error)
 	return 1, nil
 }
 
+func getEthFullShardId() (uint32, error) {
+	return clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(clusterCfg.EthFullShardKey)
+}
+
 func convertEthCallData(data *EthCallArgs) (*CallArgs, error) {
 	args := &CallArgs{
 		From:     &data.From,
@@ -0,0 +1,47 @@
+package qkcapi
+
+import (
+	"encoding/json"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/internal/encoder"
+)
+
+// PrivateDebugAPI is the collection of debug APIs exposed over the private
+// debugging endpoint.
+type PrivateDebugAPI struct {
+	b Backend
+}
+
+func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
+	return &PrivateDebugAPI{b}
+}
+
+// TraceTransaction re-executes the given transaction in the shard which owns it
+// and returns the trace of the selected tracer, the structured logs of EVM by default.
+func (p *PrivateDebugAPI) TraceTransaction(txID hexutil.Bytes, config *TraceArgs) (json.RawMessage, error) {
+	txHash, fullShardKey, err := encoder.IDDecoder(txID)
+	if err != nil {
+		return nil, err
+	}
+	fullShardId, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	return p.b.TraceTransaction(txHash, account.Branch{Value: fullShardId}, config.toTraceConfig())
+}
+
+// TraceMinorBlock re-executes all the transactions of the given minor block
+// and returns the trace of each of them.
+func (p *PrivateDebugAPI) TraceMinorBlock(blockID hexutil.Bytes, config *TraceArgs) (json.RawMessage, error) {
+	blockHash, fullShardKey, err := encoder.IDDecoder(blockID)
+	if err != nil {
+		return nil, err
+	}
+	fullShardId, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	return p.b.TraceMinorBlock(blockHash, account.Branch{Value: fullShardId}, config.toTraceConfig())
+}
@@ -0,0 +1,100 @@
+package qkcapi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+type ethBackend struct {
+	Backend
+	tx    *types.Transaction
+	mined *types.MinorBlock
+}
+
+func (b *ethBackend) AddTransaction(tx *types.Transaction) error {
+	b.tx = tx
+	return nil
+}
+
+func (b *ethBackend) GetTransactionByHash(txHash common.Hash, branch account.Branch) (*types.MinorBlock, uint32, error) {
+	if b.mined != nil {
+		return b.mined, 0, nil
+	}
+	block := types.GetEmptyMinorBlock()
+	if b.tx != nil && b.tx.EvmTx.EthHash() == txHash {
+		block.AddTx(b.tx)
+	}
+	return block, 0, nil
+}
+
+func (b *ethBackend) GetTransactionReceipt(txHash common.Hash, branch account.Branch) (*types.MinorBlock, uint32, *types.Receipt, error) {
+	if b.mined != nil {
+		return b.mined, 0, &types.Receipt{Status: 1, CumulativeGasUsed: 21000, GasUsed: 21000, TxHash: txHash}, nil
+	}
+	return types.GetEmptyMinorBlock(), 0, new(types.Receipt), nil
+}
+
+func TestEthSendRawTransaction(t *testing.T) {
+	clusterCfg = config.NewClusterConfig()
+	b := &ethBackend{}
+	api := NewEthAPI(b)
+
+	chainID := uint64(api.ChainId())
+	assert.Equal(t, types.EthChainID(clusterCfg.Quarkchain.NetworkID, clusterCfg.EthFullShardKey), chainID)
+	assert.Equal(t, new(big.Int).SetUint64(chainID).String(), NewNetAPI().Version())
+
+	key, _ := crypto.GenerateKey()
+	ethTx := ethTypes.NewTransaction(0, common.Address{1}, big.NewInt(100), 21000, big.NewInt(1), nil)
+	ethTx, err := ethTypes.SignTx(ethTx, ethTypes.NewEIP155Signer(new(big.Int).SetUint64(chainID)), key)
+	assert.NoError(t, err)
+	encoded, err := rlp.EncodeToBytes(ethTx)
+	assert.NoError(t, err)
+
+	hash, err := api.SendRawTransaction(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, ethTx.Hash(), hash)
+
+	tx, err := api.GetTransactionByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), tx["from"])
+	assert.Nil(t, tx["blockHash"])
+	v, _, _ := ethTx.RawSignatureValues()
+	assert.Equal(t, (*hexutil.Big)(v), tx["v"])
+	tx, err = api.GetTransactionByHash(common.Hash{1})
+	assert.NoError(t, err)
+	assert.Nil(t, tx)
+	receipt, err := api.GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Nil(t, receipt)
+
+	header := &types.MinorBlockHeader{Number: 3, Branch: account.Branch{Value: 1}}
+	b.mined = types.NewMinorBlockWithHeader(header, &types.MinorBlockMeta{})
+	b.mined.AddTx(b.tx)
+	tx, err = api.GetTransactionByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, b.mined.Hash(), tx["blockHash"])
+	assert.Equal(t, hash, tx["hash"])
+	receipt, err = api.GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, receipt["transactionHash"])
+	assert.Equal(t, hexutil.Uint64(3), receipt["blockNumber"])
+	assert.Equal(t, hexutil.Uint64(1), receipt["status"])
+
+	// signed for another shard
+	ethTx, err = ethTypes.SignTx(ethTx, ethTypes.NewEIP155Signer(new(big.Int).SetUint64(chainID+1)), key)
+	assert.NoError(t, err)
+	encoded, err = rlp.EncodeToBytes(ethTx)
+	assert.NoError(t, err)
+	_, err = api.SendRawTransaction(encoded)
+	assert.Equal(t, types.ErrEthTxChainID, err)
+}
@@ -0,0 +1,366 @@
+package qkcapi
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/internal/encoder"
+	"github.com/QuarkChain/goquarkchain/rpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	filterTimeout = 5 * time.Minute // consider a filter inactive if it has not been polled for within filterTimeout
+
+	// maxFilterBlockRange is the maximum number of minor blocks a single poll
+	// of a block or logs filter goes through, the rest is left for the next poll.
+	// It also bounds the block range of the criteria of GetFilterLogs.
+	maxFilterBlockRange = uint64(1024)
+
+	errFilterNotFound = errors.New("filter not found")
+	errBlockRange     = fmt.Errorf("block range of the filter exceeds %d blocks", maxFilterBlockRange)
+)
+
+type filterType byte
+
+const (
+	logsFilter filterType = iota
+	blocksFilter
+	pendingTxFilter
+)
+
+// filter is an installed filter of a shard. Block and logs filters remember the
+// last minor block whose changes were returned, so that they start again from
+// the fork point when it is reorganized out of the chain; pending transaction
+// filters remember the pending transactions seen by the last poll.
+type filter struct {
+	mu          sync.Mutex
+	typ         filterType
+	fullShardID uint32
+	deadline    *time.Timer // filter is inactive when deadline triggers
+	crit        rpc.FilterQuery
+	lastHeight  uint64
+	lastHash    common.Hash
+	pending     map[common.Hash]struct{}
+}
+
+// PublicFilterAPI offers filters which are polled by the clients that are
+// not able to keep a websocket subscription to the slaves open; the changes
+// are fetched from the slave serving the shard of the filter on each poll.
+type PublicFilterAPI struct {
+	b         Backend
+	timeout   time.Duration
+	filtersMu sync.Mutex
+	filters   map[rpc.ID]*filter
+	quit      <-chan struct{}
+}
+
+// NewPublicFilterAPI returns a new PublicFilterAPI instance.
+func NewPublicFilterAPI(b Backend) *PublicFilterAPI {
+	api := &PublicFilterAPI{
+		b:       b,
+		timeout: filterTimeout,
+		filters: make(map[rpc.ID]*filter),
+		quit:    b.Done(),
+	}
+	go api.timeoutLoop()
+	return api
+}
+
+// timeoutLoop runs every timeout and deletes filters that have not been
+// recently used, until the backend stops.
+func (api *PublicFilterAPI) timeoutLoop() {
+	ticker := time.NewTicker(api.timeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-api.quit:
+			return
+		}
+		api.filtersMu.Lock()
+		for id, f := range api.filters {
+			select {
+			case <-f.deadline.C:
+				delete(api.filters, id)
+			default:
+				continue
+			}
+		}
+		api.filtersMu.Unlock()
+	}
+}
+
+func (api *PublicFilterAPI) install(f *filter) rpc.ID {
+	f.deadline = time.NewTimer(api.timeout)
+	id := rpc.NewID()
+	api.filtersMu.Lock()
+	api.filters[id] = f
+	api.filtersMu.Unlock()
+	return id
+}
+
+// get returns the filter of the given id and resets its deadline.
+func (api *PublicFilterAPI) get(id rpc.ID) (*filter, error) {
+	api.filtersMu.Lock()
+	defer api.filtersMu.Unlock()
+	f, ok := api.filters[id]
+	if !ok {
+		return nil, errFilterNotFound
+	}
+	if !f.deadline.Stop() {
+		// timer expired but filter is not yet removed in timeout loop
+		// receive timer value and reset timer
+		<-f.deadline.C
+	}
+	f.deadline.Reset(api.timeout)
+	return f, nil
+}
+
+func (api *PublicFilterAPI) tip(fullShardID uint32) (uint64, error) {
+	return api.b.GetLastMinorBlockByFullShardID(fullShardID)
+}
+
+func (api *PublicFilterAPI) blockByHeight(fullShardID uint32, height uint64) (*types.MinorBlock, error) {
+	block, _, err := api.b.GetMinorBlockByHeight(&height, account.Branch{Value: fullShardID}, false)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("minor block %d not found", height)
+	}
+	return block, nil
+}
+
+// newChainFilter installs a block or logs filter starting after the current
+// head of the shard.
+func (api *PublicFilterAPI) newChainFilter(f *filter) (rpc.ID, error) {
+	tip, err := api.tip(f.fullShardID)
+	if err != nil {
+		return "", err
+	}
+	head, err := api.blockByHeight(f.fullShardID, tip)
+	if err != nil {
+		return "", err
+	}
+	f.lastHeight, f.lastHash = tip, head.Hash()
+	return api.install(f), nil
+}
+
+// rewind moves the last block of f back to where its chain forks from the
+// canonical chain of the shard, so that the next poll returns the blocks or
+// logs of the new canonical chain instead of skipping them after a reorg.
+func (api *PublicFilterAPI) rewind(f *filter, tip uint64) error {
+	for f.lastHeight > 0 {
+		if f.lastHeight <= tip {
+			block, err := api.blockByHeight(f.fullShardID, f.lastHeight)
+			if err != nil {
+				return err
+			}
+			if block.Hash() == f.lastHash {
+				return nil
+			}
+		}
+		block, _, err := api.b.GetMinorBlockByHash(f.lastHash, account.Branch{Value: f.fullShardID}, false)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return fmt.Errorf("minor block %x not found", f.lastHash)
+		}
+		f.lastHeight, f.lastHash = f.lastHeight-1, block.ParentHash()
+	}
+	return nil
+}
+
+// NewFilter creates a filter which returns the logs of the new minor blocks of
+// the shard matching the given criteria on each poll of GetFilterChanges.
+func (api *PublicFilterAPI) NewFilter(crit rpc.FilterQuery, fullShardKey *hexutil.Uint) (rpc.ID, error) {
+	fullShardID, err := getFullShardId(fullShardKey)
+	if err != nil {
+		return "", err
+	}
+	if crit.BlockHash != nil {
+		return "", errors.New("blockHash is not supported by filters, use getLogs instead")
+	}
+	crit.FullShardId = fullShardID
+	return api.newChainFilter(&filter{typ: logsFilter, fullShardID: fullShardID, crit: crit})
+}
+
+// NewBlockFilter creates a filter which returns the ids of the new minor blocks
+// of the shard on each poll of GetFilterChanges.
+func (api *PublicFilterAPI) NewBlockFilter(fullShardKey *hexutil.Uint) (rpc.ID, error) {
+	fullShardID, err := getFullShardId(fullShardKey)
+	if err != nil {
+		return "", err
+	}
+	return api.newChainFilter(&filter{typ: blocksFilter, fullShardID: fullShardID})
+}
+
+// NewPendingTransactionFilter creates a filter which returns the ids of the
+// transactions which became pending in the shard since the last poll.
+func (api *PublicFilterAPI) NewPendingTransactionFilter(fullShardKey *hexutil.Uint) (rpc.ID, error) {
+	fullShardID, err := getFullShardId(fullShardKey)
+	if err != nil {
+		return "", err
+	}
+	hashes, err := api.b.GetPendingTransactionHashes(account.Branch{Value: fullShardID})
+	if err != nil {
+		return "", err
+	}
+	pending := make(map[common.Hash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		pending[hash] = struct{}{}
+	}
+	return api.install(&filter{typ: pendingTxFilter, fullShardID: fullShardID, pending: pending}), nil
+}
+
+// GetFilterChanges returns the changes of the filter since the last poll,
+// a list of block ids, transaction ids or logs depending on the filter type.
+func (api *PublicFilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
+	f, err := api.get(id)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch f.typ {
+	case pendingTxFilter:
+		hashes, err := api.b.GetPendingTransactionHashes(account.Branch{Value: f.fullShardID})
+		if err != nil {
+			return nil, err
+		}
+		pending := make(map[common.Hash]struct{}, len(hashes))
+		ids := make([]hexutil.Bytes, 0)
+		for _, hash := range hashes {
+			pending[hash] = struct{}{}
+			if _, ok := f.pending[hash]; !ok {
+				ids = append(ids, encoder.IDEncoder(hash.Bytes(), f.fullShardID))
+			}
+		}
+		f.pending = pending
+		return ids, nil
+	case blocksFilter:
+		from, to, err := api.nextRange(f)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]hexutil.Bytes, 0)
+		for height := from; height <= to; height++ {
+			block, err := api.blockByHeight(f.fullShardID, height)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, encoder.IDEncoder(block.Hash().Bytes(), f.fullShardID))
+			f.lastHeight, f.lastHash = height, block.Hash()
+		}
+		return ids, nil
+	case logsFilter:
+		from, to, err := api.nextRange(f)
+		if err != nil {
+			return nil, err
+		}
+		if to < from {
+			return []map[string]interface{}{}, nil
+		}
+		// the hash is taken before the logs, a reorg in between is then
+		// found by the next poll, which returns the logs again
+		last, err := api.blockByHeight(f.fullShardID, to)
+		if err != nil {
+			return nil, err
+		}
+		logs, err := api.getLogs(f.crit, from, to)
+		if err != nil {
+			return nil, err
+		}
+		f.lastHeight, f.lastHash = to, last.Hash()
+		return logs, nil
+	}
+	return nil, errors.New("unknown filter type")
+}
+
+// nextRange returns the heights of the minor blocks to go through by the next
+// poll of f, the range is empty if to is less than from.
+func (api *PublicFilterAPI) nextRange(f *filter) (uint64, uint64, error) {
+	tip, err := api.tip(f.fullShardID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := api.rewind(f, tip); err != nil {
+		return 0, 0, err
+	}
+	to := tip
+	if f.typ == logsFilter && f.crit.ToBlock != nil && f.crit.ToBlock.Sign() >= 0 && f.crit.ToBlock.Uint64() < to {
+		to = f.crit.ToBlock.Uint64()
+	}
+	from := f.lastHeight + 1
+	if f.typ == logsFilter && f.crit.FromBlock != nil && f.crit.FromBlock.Sign() >= 0 && f.crit.FromBlock.Uint64() > from {
+		from = f.crit.FromBlock.Uint64()
+	}
+	if to >= from && to-from >= maxFilterBlockRange {
+		to = from + maxFilterBlockRange - 1
+	}
+	return from, to, nil
+}
+
+func (api *PublicFilterAPI) getLogs(crit rpc.FilterQuery, from, to uint64) ([]map[string]interface{}, error) {
+	crit.FromBlock = new(big.Int).SetUint64(from)
+	crit.ToBlock = new(big.Int).SetUint64(to)
+	logs, err := api.b.GetLogs(&crit)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.LogListEncoder(logs, false), nil
+}
+
+// GetFilterLogs returns the logs matching the criteria of the logs filter of
+// the given id, within the block range of the criteria, which must not exceed
+// maxFilterBlockRange blocks.
+func (api *PublicFilterAPI) GetFilterLogs(id rpc.ID) ([]map[string]interface{}, error) {
+	f, err := api.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if f.typ != logsFilter {
+		return nil, errFilterNotFound
+	}
+	tip, err := api.tip(f.fullShardID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := tip, tip
+	if f.crit.FromBlock != nil && f.crit.FromBlock.Sign() >= 0 {
+		from = f.crit.FromBlock.Uint64()
+	}
+	if f.crit.ToBlock != nil && f.crit.ToBlock.Sign() >= 0 && f.crit.ToBlock.Uint64() < tip {
+		to = f.crit.ToBlock.Uint64()
+	}
+	if to < from {
+		return []map[string]interface{}{}, nil
+	}
+	if to-from >= maxFilterBlockRange {
+		return nil, errBlockRange
+	}
+	return api.getLogs(f.crit, from, to)
+}
+
+// UninstallFilter removes the filter with the given filter id.
+func (api *PublicFilterAPI) UninstallFilter(id rpc.ID) bool {
+	api.filtersMu.Lock()
+	f, found := api.filters[id]
+	if found {
+		delete(api.filters, id)
+	}
+	api.filtersMu.Unlock()
+	if found {
+		f.deadline.Stop()
+	}
+	return found
+}
@@ -0,0 +1,229 @@
+package qkcapi
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	qrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/QuarkChain/goquarkchain/internal/encoder"
+	"github.com/QuarkChain/goquarkchain/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type filterBackend struct {
+	Backend
+	tip      uint64
+	pending  []common.Hash
+	logQuery *rpc.FilterQuery
+	blocks   map[uint64]*types.MinorBlock // canonical blocks, created on demand
+	byHash   map[common.Hash]*types.MinorBlock
+	done     chan struct{}
+}
+
+func (b *filterBackend) block(height uint64) *types.MinorBlock {
+	if b.blocks == nil {
+		b.blocks = make(map[uint64]*types.MinorBlock)
+		b.byHash = make(map[common.Hash]*types.MinorBlock)
+	}
+	if block, ok := b.blocks[height]; ok {
+		return block
+	}
+	header := &types.MinorBlockHeader{Number: height, Branch: account.Branch{Value: 1}}
+	if height > 0 {
+		header.ParentHash = b.block(height - 1).Hash()
+	}
+	block := types.NewMinorBlockWithHeader(header, &types.MinorBlockMeta{})
+	b.blocks[height], b.byHash[block.Hash()] = block, block
+	return block
+}
+
+func (b *filterBackend) Done() <-chan struct{} {
+	return b.done
+}
+
+func (b *filterBackend) GetLastMinorBlockByFullShardID(fullShardId uint32) (uint64, error) {
+	return b.tip, nil
+}
+
+func (b *filterBackend) GetMinorBlockByHeight(height *uint64, branch account.Branch, needExtraInfo bool) (*types.MinorBlock, *qrpc.PoSWInfo, error) {
+	return b.block(*height), nil, nil
+}
+
+func (b *filterBackend) GetMinorBlockByHash(hash common.Hash, branch account.Branch, needExtraInfo bool) (*types.MinorBlock, *qrpc.PoSWInfo, error) {
+	return b.byHash[hash], nil, nil
+}
+
+// fork replaces the canonical blocks after height from with new ones up to to,
+// keeping the replaced blocks available by hash.
+func (b *filterBackend) fork(from, to uint64, extra byte) {
+	parent := b.block(from)
+	for height := from + 1; height <= to; height++ {
+		header := &types.MinorBlockHeader{Number: height, Branch: account.Branch{Value: 1}, ParentHash: parent.Hash(), Extra: []byte{extra}}
+		parent = types.NewMinorBlockWithHeader(header, &types.MinorBlockMeta{})
+		b.blocks[height], b.byHash[parent.Hash()] = parent, parent
+	}
+	for height := to + 1; b.blocks[height] != nil; height++ {
+		delete(b.blocks, height)
+	}
+	b.tip = to
+}
+
+func (b *filterBackend) GetPendingTransactionHashes(branch account.Branch) ([]common.Hash, error) {
+	return b.pending, nil
+}
+
+func (b *filterBackend) GetLogs(args *rpc.FilterQuery) ([]*types.Log, error) {
+	b.logQuery = args
+	return []*types.Log{{BlockNumber: args.ToBlock.Uint64()}}, nil
+}
+
+func TestBlockAndPendingTxFilters(t *testing.T) {
+	b := &filterBackend{tip: 10, pending: []common.Hash{{1}}}
+	api := &PublicFilterAPI{b: b, timeout: filterTimeout, filters: make(map[rpc.ID]*filter)}
+
+	blockFilter, err := api.NewBlockFilter(nil)
+	assert.NoError(t, err)
+	changes, err := api.GetFilterChanges(blockFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changes.([]hexutil.Bytes)))
+
+	b.tip = 12
+	changes, err = api.GetFilterChanges(blockFilter)
+	assert.NoError(t, err)
+	ids := changes.([]hexutil.Bytes)
+	assert.Equal(t, 2, len(ids))
+	for i, id := range ids {
+		height := uint64(11 + i)
+		block, _, _ := b.GetMinorBlockByHeight(&height, account.Branch{Value: 1}, false)
+		assert.Equal(t, encoder.IDEncoder(block.Hash().Bytes(), 1), id)
+	}
+
+	txFilter, err := api.NewPendingTransactionFilter(nil)
+	assert.NoError(t, err)
+	b.pending = []common.Hash{{1}, {2}}
+	changes, err = api.GetFilterChanges(txFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, []hexutil.Bytes{encoder.IDEncoder(common.Hash{2}.Bytes(), 1)}, changes)
+	changes, err = api.GetFilterChanges(txFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changes.([]hexutil.Bytes)))
+
+	assert.True(t, api.UninstallFilter(txFilter))
+	assert.False(t, api.UninstallFilter(txFilter))
+	_, err = api.GetFilterChanges(txFilter)
+	assert.Equal(t, errFilterNotFound, err)
+}
+
+func TestLogsFilter(t *testing.T) {
+	b := &filterBackend{tip: 10}
+	api := &PublicFilterAPI{b: b, timeout: filterTimeout, filters: make(map[rpc.ID]*filter)}
+
+	crit := rpc.FilterQuery{}
+	crit.FromBlock = big.NewInt(5)
+	crit.ToBlock = big.NewInt(2000)
+	id, err := api.NewFilter(crit, nil)
+	assert.NoError(t, err)
+	changes, err := api.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changes.([]map[string]interface{})))
+	assert.Nil(t, b.logQuery)
+
+	b.tip = 3000
+	changes, err = api.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changes.([]map[string]interface{})))
+	assert.Equal(t, uint64(11), b.logQuery.FromBlock.Uint64())
+	assert.Equal(t, uint64(11)+maxFilterBlockRange-1, b.logQuery.ToBlock.Uint64())
+	assert.Equal(t, uint32(1), b.logQuery.FullShardId)
+
+	changes, err = api.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, 11+maxFilterBlockRange, b.logQuery.FromBlock.Uint64())
+	assert.Equal(t, uint64(2000), b.logQuery.ToBlock.Uint64())
+
+	// the range of the criteria is too large to be fetched at once
+	_, err = api.GetFilterLogs(id)
+	assert.Equal(t, errBlockRange, err)
+
+	crit.ToBlock = big.NewInt(1000)
+	id, err = api.NewFilter(crit, nil)
+	assert.NoError(t, err)
+	_, err = api.GetFilterLogs(id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), b.logQuery.FromBlock.Uint64())
+	assert.Equal(t, uint64(1000), b.logQuery.ToBlock.Uint64())
+}
+
+func TestFiltersAfterReorg(t *testing.T) {
+	b := &filterBackend{tip: 10}
+	api := &PublicFilterAPI{b: b, timeout: filterTimeout, filters: make(map[rpc.ID]*filter)}
+
+	blockFilter, err := api.NewBlockFilter(nil)
+	assert.NoError(t, err)
+	logsFilter, err := api.NewFilter(rpc.FilterQuery{}, nil)
+	assert.NoError(t, err)
+	b.tip = 12
+	_, err = api.GetFilterChanges(blockFilter)
+	assert.NoError(t, err)
+	_, err = api.GetFilterChanges(logsFilter)
+	assert.NoError(t, err)
+
+	// blocks 9 to 12 are replaced by a shorter chain, which is returned from
+	// the fork point on the next poll
+	b.fork(8, 11, 1)
+	changes, err := api.GetFilterChanges(blockFilter)
+	assert.NoError(t, err)
+	ids := changes.([]hexutil.Bytes)
+	assert.Equal(t, 3, len(ids))
+	for i, id := range ids {
+		assert.Equal(t, encoder.IDEncoder(b.blocks[uint64(9+i)].Hash().Bytes(), 1), id)
+	}
+	_, err = api.GetFilterChanges(logsFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), b.logQuery.FromBlock.Uint64())
+	assert.Equal(t, uint64(11), b.logQuery.ToBlock.Uint64())
+
+	// without a reorg, the polls go on after the last block
+	b.fork(11, 13, 2)
+	changes, err = api.GetFilterChanges(blockFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(changes.([]hexutil.Bytes)))
+	_, err = api.GetFilterChanges(logsFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), b.logQuery.FromBlock.Uint64())
+}
+
+func TestFilterTimeout(t *testing.T) {
+	timeout := filterTimeout
+	filterTimeout = 50 * time.Millisecond
+	defer func() { filterTimeout = timeout }()
+
+	b := &filterBackend{tip: 10, done: make(chan struct{})}
+	api := NewPublicFilterAPI(b)
+	polled, err := api.NewBlockFilter(nil)
+	assert.NoError(t, err)
+	idle, err := api.NewBlockFilter(nil)
+	assert.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		time.Sleep(20 * time.Millisecond)
+		_, err = api.GetFilterChanges(polled)
+		assert.NoError(t, err)
+	}
+	_, err = api.GetFilterChanges(idle)
+	assert.Equal(t, errFilterNotFound, err)
+
+	// filters are no longer removed once the backend stops
+	close(b.done)
+	time.Sleep(20 * time.Millisecond)
+	idle, err = api.NewBlockFilter(nil)
+	assert.NoError(t, err)
+	time.Sleep(120 * time.Millisecond)
+	api.filtersMu.Lock()
+	assert.Contains(t, api.filters, idle)
+	api.filtersMu.Unlock()
+}
@@ -1,10 +1,17 @@
 package qkcapi
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/QuarkChain/goquarkchain/account"
 	"github.com/QuarkChain/goquarkchain/cluster/config"
+	qrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
+	qcom "github.com/QuarkChain/goquarkchain/common"
 	"github.com/QuarkChain/goquarkchain/common/hexutil"
 	"github.com/QuarkChain/goquarkchain/core/types"
 	"github.com/QuarkChain/goquarkchain/params"
@@ -228,3 +235,113 @@ func (args *SendTxArgs) toTransaction() (*types.Transaction, error) {
 		TxType: types.EvmTx,
 	}, nil
 }
+
+// TraceArgs represents the arguments of debug_traceTransaction and debug_traceMinorBlock.
+type TraceArgs struct {
+	DisableMemory  bool   `json:"disableMemory"`
+	DisableStack   bool   `json:"disableStack"`
+	DisableStorage bool   `json:"disableStorage"`
+	Limit          uint32 `json:"limit"`
+	// Tracer is the name of the built-in tracer, structLogger or callTracer
+	Tracer *string `json:"tracer"`
+}
+
+func (args *TraceArgs) toTraceConfig() *qrpc.TraceConfig {
+	if args == nil {
+		return nil
+	}
+	config := &qrpc.TraceConfig{
+		DisableMemory:  args.DisableMemory,
+		DisableStack:   args.DisableStack,
+		DisableStorage: args.DisableStorage,
+		Limit:          args.Limit,
+	}
+	if args.Tracer != nil {
+		config.Tracer = *args.Tracer
+	}
+	return config
+}
+
+// OverrideAccount indicates the overriding fields of an account during the
+// execution of a call or a gas estimation. Balances are keyed by token name
+// (e.g. "QKC") or by hex encoded token id; State replaces the whole storage
+// of the account while StateDiff only replaces the given slots.
+type OverrideAccount struct {
+	Balances  map[string]*hexutil.Big      `json:"balances"`
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverrides is the collection of overridden accounts, keyed by recipient.
+type StateOverrides map[common.Address]OverrideAccount
+
+func (overrides *StateOverrides) toStateOverrides() ([]*qrpc.StateOverride, error) {
+	if overrides == nil {
+		return nil, nil
+	}
+	result := make([]*qrpc.StateOverride, 0, len(*overrides))
+	for addr, acc := range *overrides {
+		if acc.State != nil && acc.StateDiff != nil {
+			return nil, fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		override := &qrpc.StateOverride{Address: addr}
+		for name, balance := range acc.Balances {
+			if balance == nil {
+				return nil, fmt.Errorf("account %s has empty balance of %s", addr.Hex(), name)
+			}
+			tokenID, err := decodeTokenID(name)
+			if err != nil {
+				return nil, err
+			}
+			override.Balances = append(override.Balances, &types.TokenBalancePair{TokenID: tokenID, Balance: balance.ToInt()})
+		}
+		sort.Slice(override.Balances, func(i, j int) bool {
+			return override.Balances[i].TokenID < override.Balances[j].TokenID
+		})
+		if acc.Nonce != nil {
+			nonce := uint64(*acc.Nonce)
+			override.Nonce = &nonce
+		}
+		if acc.Code != nil {
+			override.OverrideCode = true
+			override.Code = *acc.Code
+		}
+		storage := acc.StateDiff
+		if acc.State != nil {
+			storage = acc.State
+			override.ReplaceStorage = true
+		}
+		if storage != nil {
+			for key, value := range *storage {
+				override.Storage = append(override.Storage, &qrpc.StorageEntry{Key: key, Value: value})
+			}
+			sort.Slice(override.Storage, func(i, j int) bool {
+				return bytes.Compare(override.Storage[i].Key[:], override.Storage[j].Key[:]) < 0
+			})
+		}
+		result = append(result, override)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Address[:], result[j].Address[:]) < 0
+	})
+	return result, nil
+}
+
+// decodeTokenID accepts either a hex encoded token id or a token name.
+func decodeTokenID(name string) (uint64, error) {
+	if strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X") {
+		return hexutil.DecodeUint64(name)
+	}
+	name = strings.ToUpper(name)
+	if len(name) == 0 || len(name) > 12 {
+		return 0, fmt.Errorf("invalid token name %q: must have 1 to 12 characters", name)
+	}
+	for i := 0; i < len(name); i++ {
+		if (name[i] < '0' || name[i] > '9') && (name[i] < 'A' || name[i] > 'Z') {
+			return 0, fmt.Errorf("invalid token name %q: only 0-9 and A-Z are allowed", name)
+		}
+	}
+	return qcom.TokenIDEncode(name), nil
+}
@@ -0,0 +1,24 @@
+package qkcapi
+
+import (
+	"testing"
+
+	qcom "github.com/QuarkChain/goquarkchain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeTokenID(t *testing.T) {
+	id, err := decodeTokenID("qkc")
+	assert.NoError(t, err)
+	assert.Equal(t, qcom.TokenIDEncode("QKC"), id)
+
+	id, err = decodeTokenID("0x8bb0")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x8bb0), id)
+
+	// names that TokenIDEncode cannot encode are rejected instead of panicking
+	for _, name := range []string{"", "qkc-x", "ABCDEFGHIJKLM", "qkc "} {
+		_, err := decodeTokenID(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,228 @@
+package qkcapi
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	qrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
+	qcom "github.com/QuarkChain/goquarkchain/common"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/internal/encoder"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PrivateTxPoolAPI offers the txpool namespace to look into the transaction
+// pools of the shards, the pools of all the shards are returned unless a full
+// shard key is given.
+type PrivateTxPoolAPI struct {
+	b Backend
+}
+
+func NewPrivateTxPoolAPI(b Backend) *PrivateTxPoolAPI {
+	return &PrivateTxPoolAPI{b: b}
+}
+
+func getTxPoolBranch(fullShardKey *hexutil.Uint) (*account.Branch, error) {
+	if fullShardKey == nil {
+		return nil, nil
+	}
+	fullShardId, err := clusterCfg.Quarkchain.GetFullShardIdByFullShardKey(uint32(*fullShardKey))
+	if err != nil {
+		return nil, err
+	}
+	return &account.Branch{Value: fullShardId}, nil
+}
+
+// Status returns the number of pending and queued transactions in total and
+// in each shard.
+func (t *PrivateTxPoolAPI) Status(fullShardKey *hexutil.Uint) (map[string]interface{}, error) {
+	branch, err := getTxPoolBranch(fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := t.b.GetTxPoolContent(branch, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var pending, queued uint32
+	shards := make([]map[string]interface{}, 0, len(pools))
+	for _, pool := range pools {
+		pending += pool.Pending
+		queued += pool.Queued
+		shards = append(shards, map[string]interface{}{
+			"fullShardId": hexutil.Uint(pool.Branch),
+			"pending":     hexutil.Uint(pool.Pending),
+			"queued":      hexutil.Uint(pool.Queued),
+		})
+	}
+	return map[string]interface{}{
+		"pending": hexutil.Uint(pending),
+		"queued":  hexutil.Uint(queued),
+		"shards":  shards,
+	}, nil
+}
+
+// Content returns the pending and queued transactions of each sender.
+func (t *PrivateTxPoolAPI) Content(fullShardKey *hexutil.Uint) ([]map[string]interface{}, error) {
+	return t.content(fullShardKey, nil)
+}
+
+// ContentFrom returns the pending and queued transactions of a single sender.
+func (t *PrivateTxPoolAPI) ContentFrom(sender common.Address, fullShardKey *hexutil.Uint) ([]map[string]interface{}, error) {
+	return t.content(fullShardKey, &sender)
+}
+
+func (t *PrivateTxPoolAPI) content(fullShardKey *hexutil.Uint, sender *account.Recipient) ([]map[string]interface{}, error) {
+	branch, err := getTxPoolBranch(fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := t.b.GetTxPoolContent(branch, sender, false)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	fields := make([]map[string]interface{}, 0, len(pools))
+	for _, pool := range pools {
+		accounts := make([]map[string]interface{}, 0, len(pool.Accounts))
+		for _, acc := range pool.Accounts {
+			pending, err := txPoolTxListEncoder(acc.Pending, now)
+			if err != nil {
+				return nil, err
+			}
+			queued, err := txPoolTxListEncoder(acc.Queued, now)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, map[string]interface{}{
+				"address":   acc.Address,
+				"nonce":     hexutil.Uint64(acc.Nonce),
+				"nonceGaps": nonceGaps(acc),
+				"pending":   pending,
+				"queued":    queued,
+			})
+		}
+		fields = append(fields, map[string]interface{}{
+			"fullShardId": hexutil.Uint(pool.Branch),
+			"pending":     hexutil.Uint(pool.Pending),
+			"queued":      hexutil.Uint(pool.Queued),
+			"accounts":    accounts,
+		})
+	}
+	return fields, nil
+}
+
+// Inspect returns a one line summary of each pending and queued transaction
+// grouped by sender and nonce.
+func (t *PrivateTxPoolAPI) Inspect(fullShardKey *hexutil.Uint) ([]map[string]interface{}, error) {
+	branch, err := getTxPoolBranch(fullShardKey)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := t.b.GetTxPoolContent(branch, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	fields := make([]map[string]interface{}, 0, len(pools))
+	for _, pool := range pools {
+		pending := make(map[string]map[string]string)
+		queued := make(map[string]map[string]string)
+		for _, acc := range pool.Accounts {
+			if len(acc.Pending) > 0 {
+				pending[acc.Address.Hex()] = txPoolTxListSummary(acc.Pending, now)
+			}
+			if len(acc.Queued) > 0 {
+				queued[acc.Address.Hex()] = txPoolTxListSummary(acc.Queued, now)
+			}
+		}
+		fields = append(fields, map[string]interface{}{
+			"fullShardId": hexutil.Uint(pool.Branch),
+			"pending":     pending,
+			"queued":      queued,
+		})
+	}
+	return fields, nil
+}
+
+// nonceGaps returns the ranges of nonces missing before the transactions of
+// the sender can be executed, starting from the nonce of the sender.
+func nonceGaps(acc *qrpc.TxPoolAccount) [][2]hexutil.Uint64 {
+	gaps := make([][2]hexutil.Uint64, 0)
+	next := acc.Nonce
+	for _, list := range [][]*qrpc.TxPoolTx{acc.Pending, acc.Queued} {
+		for _, ptx := range list {
+			nonce := ptx.Tx.EvmTx.Nonce()
+			if nonce > next {
+				gaps = append(gaps, [2]hexutil.Uint64{hexutil.Uint64(next), hexutil.Uint64(nonce - 1)})
+			}
+			if nonce >= next {
+				next = nonce + 1
+			}
+		}
+	}
+	return gaps
+}
+
+func txPoolTxListEncoder(txs []*qrpc.TxPoolTx, now time.Time) ([]map[string]interface{}, error) {
+	fields := make([]map[string]interface{}, 0, len(txs))
+	for _, ptx := range txs {
+		evmtx := ptx.Tx.EvmTx
+		transferTokenStr, err := qcom.TokenIdDecode(evmtx.TransferTokenID())
+		if err != nil {
+			return nil, err
+		}
+		gasTokenStr, err := qcom.TokenIdDecode(evmtx.GasTokenID())
+		if err != nil {
+			return nil, err
+		}
+		var to *common.Address
+		if evmtx.To() != nil {
+			addr := *evmtx.To()
+			to = &addr
+		}
+		fields = append(fields, map[string]interface{}{
+			"id":               encoder.IDEncoder(ptx.Tx.Hash().Bytes(), evmtx.FromFullShardKey()),
+			"hash":             ptx.Tx.Hash(),
+			"nonce":            hexutil.Uint64(evmtx.Nonce()),
+			"to":               to,
+			"toFullShardKey":   encoder.FullShardKeyEncode(evmtx.ToFullShardKey()),
+			"value":            (*hexutil.Big)(evmtx.Value()),
+			"gasPrice":         (*hexutil.Big)(evmtx.GasPrice()),
+			"gas":              hexutil.Uint64(evmtx.Gas()),
+			"transferTokenId":  hexutil.Uint64(evmtx.TransferTokenID()),
+			"gasTokenId":       hexutil.Uint64(evmtx.GasTokenID()),
+			"transferTokenStr": transferTokenStr,
+			"gasTokenStr":      gasTokenStr,
+			"seen":             hexutil.Uint64(ptx.Seen),
+			"timeInPool":       hexutil.Uint64(timeInPool(ptx, now) / time.Second),
+		})
+	}
+	return fields, nil
+}
+
+func txPoolTxListSummary(txs []*qrpc.TxPoolTx, now time.Time) map[string]string {
+	summary := make(map[string]string, len(txs))
+	for _, ptx := range txs {
+		evmtx := ptx.Tx.EvmTx
+		to := "contract creation"
+		if evmtx.To() != nil {
+			to = evmtx.To().Hex()
+		}
+		transferToken, _ := qcom.TokenIdDecode(evmtx.TransferTokenID())
+		gasToken, _ := qcom.TokenIdDecode(evmtx.GasTokenID())
+		summary[strconv.FormatUint(evmtx.Nonce(), 10)] = fmt.Sprintf("%s: %v %s + %v gas × %v %s, %v in pool",
+			to, evmtx.Value(), transferToken, evmtx.Gas(), evmtx.GasPrice(), gasToken, timeInPool(ptx, now).Round(time.Second))
+	}
+	return summary
+}
+
+func timeInPool(ptx *qrpc.TxPoolTx, now time.Time) time.Duration {
+	seen := time.Unix(int64(ptx.Seen), 0)
+	if seen.After(now) {
+		return 0
+	}
+	return now.Sub(seen)
+}
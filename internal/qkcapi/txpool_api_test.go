@@ -0,0 +1,69 @@
+package qkcapi
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/account"
+	"github.com/QuarkChain/goquarkchain/cluster/config"
+	qrpc "github.com/QuarkChain/goquarkchain/cluster/rpc"
+	"github.com/QuarkChain/goquarkchain/common/hexutil"
+	"github.com/QuarkChain/goquarkchain/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type txPoolBackend struct {
+	Backend
+	pools  []*qrpc.ShardTxPool
+	branch *account.Branch
+}
+
+func (b *txPoolBackend) GetTxPoolContent(branch *account.Branch, sender *account.Recipient, countOnly bool) ([]*qrpc.ShardTxPool, error) {
+	b.branch = branch
+	return b.pools, nil
+}
+
+func txPoolTx(nonce uint64, seen time.Time) *qrpc.TxPoolTx {
+	evmTx := types.NewEvmTransaction(nonce, account.Recipient{1}, big.NewInt(100), 21000, big.NewInt(1), 0, 0, 1, 0, nil,
+		types.EthTxTokenID, types.EthTxTokenID)
+	return &qrpc.TxPoolTx{Tx: &types.Transaction{TxType: types.EvmTx, EvmTx: evmTx}, Seen: uint64(seen.Unix())}
+}
+
+func TestTxPoolAPI(t *testing.T) {
+	clusterCfg = config.NewClusterConfig()
+	seen := time.Now().Add(-time.Minute)
+	b := &txPoolBackend{pools: []*qrpc.ShardTxPool{
+		{Branch: 1, Pending: 1, Queued: 2, Accounts: []*qrpc.TxPoolAccount{{
+			Address: account.Recipient{2},
+			Nonce:   3,
+			Pending: []*qrpc.TxPoolTx{txPoolTx(3, seen)},
+			Queued:  []*qrpc.TxPoolTx{txPoolTx(5, seen), txPoolTx(9, seen)},
+		}}},
+		{Branch: 2, Pending: 4, Queued: 0},
+	}}
+	api := NewPrivateTxPoolAPI(b)
+
+	status, err := api.Status(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, b.branch)
+	assert.Equal(t, hexutil.Uint(5), status["pending"])
+	assert.Equal(t, hexutil.Uint(2), status["queued"])
+	assert.Len(t, status["shards"], 2)
+
+	fullShardKey := hexutil.Uint(1)
+	content, err := api.Content(&fullShardKey)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Branch{Value: 3}, *b.branch)
+	accounts := content[0]["accounts"].([]map[string]interface{})
+	assert.Equal(t, [][2]hexutil.Uint64{{4, 4}, {6, 8}}, accounts[0]["nonceGaps"])
+	queued := accounts[0]["queued"].([]map[string]interface{})
+	assert.Equal(t, hexutil.Uint64(5), queued[0]["nonce"])
+	assert.True(t, queued[0]["timeInPool"].(hexutil.Uint64) >= 60)
+
+	inspect, err := api.Inspect(nil)
+	assert.NoError(t, err)
+	pending := inspect[0]["pending"].(map[string]map[string]string)
+	assert.Contains(t, pending[account.Recipient{2}.Hex()], "3")
+	assert.Empty(t, inspect[1]["pending"])
+}
@@ -210,6 +210,36 @@ func (mr *MockISlaveConnMockRecorder) GetMinorBlockHeaderListWithSkip(req interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinorBlockHeaderListWithSkip", reflect.TypeOf((*MockISlaveConn)(nil).GetMinorBlockHeaderListWithSkip), req)
 }
 
+// GetMinorStateNodeList mocks base method
+func (m *MockISlaveConn) GetMinorStateNodeList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinorStateNodeList", req)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMinorStateNodeList indicates an expected call of GetMinorStateNodeList
+func (mr *MockISlaveConnMockRecorder) GetMinorStateNodeList(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinorStateNodeList", reflect.TypeOf((*MockISlaveConn)(nil).GetMinorStateNodeList), req)
+}
+
+// GetCrossShardTxList mocks base method
+func (m *MockISlaveConn) GetCrossShardTxList(req *rpc.P2PRedirectRequest) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCrossShardTxList", req)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCrossShardTxList indicates an expected call of GetCrossShardTxList
+func (mr *MockISlaveConnMockRecorder) GetCrossShardTxList(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCrossShardTxList", reflect.TypeOf((*MockISlaveConn)(nil).GetCrossShardTxList), req)
+}
+
 // HandleNewTip mocks base method
 func (m *MockISlaveConn) HandleNewTip(request *rpc.HandleNewTipRequest) error {
 	m.ctrl.T.Helper()
@@ -253,6 +283,35 @@ func (mr *MockISlaveConnMockRecorder) AddBlockListForSync(request interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlockListForSync", reflect.TypeOf((*MockISlaveConn)(nil).AddBlockListForSync), request)
 }
 
+// SyncShardState mocks base method
+func (m *MockISlaveConn) SyncShardState(request *rpc.SyncShardStateRequest) (*rpc.SyncShardStateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncShardState", request)
+	ret0, _ := ret[0].(*rpc.SyncShardStateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncShardState indicates an expected call of SyncShardState
+func (mr *MockISlaveConnMockRecorder) SyncShardState(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncShardState", reflect.TypeOf((*MockISlaveConn)(nil).SyncShardState), request)
+}
+
+// PeerDisconnected mocks base method
+func (m *MockISlaveConn) PeerDisconnected(peerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerDisconnected", peerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PeerDisconnected indicates an expected call of PeerDisconnected
+func (mr *MockISlaveConnMockRecorder) PeerDisconnected(peerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerDisconnected", reflect.TypeOf((*MockISlaveConn)(nil).PeerDisconnected), peerID)
+}
+
 // GetSlaveID mocks base method
 func (m *MockISlaveConn) GetSlaveID() string {
 	m.ctrl.T.Helper()
@@ -326,17 +385,17 @@ func (mr *MockISlaveConnMockRecorder) SendPing() *gomock.Call {
 }
 
 // HeartBeat mocks base method
-func (m *MockISlaveConn) HeartBeat() bool {
+func (m *MockISlaveConn) HeartBeat(activeShards []uint32) bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HeartBeat")
+	ret := m.ctrl.Call(m, "HeartBeat", activeShards)
 	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
 // HeartBeat indicates an expected call of HeartBeat
-func (mr *MockISlaveConnMockRecorder) HeartBeat() *gomock.Call {
+func (mr *MockISlaveConnMockRecorder) HeartBeat(activeShards interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeartBeat", reflect.TypeOf((*MockISlaveConn)(nil).HeartBeat))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeartBeat", reflect.TypeOf((*MockISlaveConn)(nil).HeartBeat), activeShards)
 }
 
 // GetUnconfirmedHeaders mocks base method
@@ -426,18 +485,18 @@ func (mr *MockISlaveConnMockRecorder) AddTransaction(tx interface{}) *gomock.Cal
 }
 
 // ExecuteTransaction mocks base method
-func (m *MockISlaveConn) ExecuteTransaction(tx *types.Transaction, fromAddress *account.Address, height *uint64) ([]byte, error) {
+func (m *MockISlaveConn) ExecuteTransaction(tx *types.Transaction, fromAddress *account.Address, height *uint64, overrides []*rpc.StateOverride) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecuteTransaction", tx, fromAddress, height)
+	ret := m.ctrl.Call(m, "ExecuteTransaction", tx, fromAddress, height, overrides)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExecuteTransaction indicates an expected call of ExecuteTransaction
-func (mr *MockISlaveConnMockRecorder) ExecuteTransaction(tx, fromAddress, height interface{}) *gomock.Call {
+func (mr *MockISlaveConnMockRecorder) ExecuteTransaction(tx, fromAddress, height, overrides interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTransaction", reflect.TypeOf((*MockISlaveConn)(nil).ExecuteTransaction), tx, fromAddress, height)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTransaction", reflect.TypeOf((*MockISlaveConn)(nil).ExecuteTransaction), tx, fromAddress, height, overrides)
 }
 
 // GetTransactionByHash mocks base method
@@ -521,18 +580,18 @@ func (mr *MockISlaveConnMockRecorder) GetLogs(args interface{}) *gomock.Call {
 }
 
 // EstimateGas mocks base method
-func (m *MockISlaveConn) EstimateGas(tx *types.Transaction, fromAddress *account.Address) (uint32, error) {
+func (m *MockISlaveConn) EstimateGas(tx *types.Transaction, fromAddress *account.Address, overrides []*rpc.StateOverride) (uint32, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EstimateGas", tx, fromAddress)
+	ret := m.ctrl.Call(m, "EstimateGas", tx, fromAddress, overrides)
 	ret0, _ := ret[0].(uint32)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // EstimateGas indicates an expected call of EstimateGas
-func (mr *MockISlaveConnMockRecorder) EstimateGas(tx, fromAddress interface{}) *gomock.Call {
+func (mr *MockISlaveConnMockRecorder) EstimateGas(tx, fromAddress, overrides interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateGas", reflect.TypeOf((*MockISlaveConn)(nil).EstimateGas), tx, fromAddress)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateGas", reflect.TypeOf((*MockISlaveConn)(nil).EstimateGas), tx, fromAddress, overrides)
 }
 
 // GetStorageAt mocks base method
@@ -641,11 +700,12 @@ func (mr *MockISlaveConnMockRecorder) GetRootChainStakes(address, lastMinor inte
 }
 
 // CheckMinorBlocksInRoot mocks base method
-func (m *MockISlaveConn) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) error {
+func (m *MockISlaveConn) CheckMinorBlocksInRoot(rootBlock *types.RootBlock) ([]*rpc.ShardDBCheck, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CheckMinorBlocksInRoot", rootBlock)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]*rpc.ShardDBCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CheckMinorBlocksInRoot indicates an expected call of CheckMinorBlocksInRoot
@@ -653,3 +713,151 @@ func (mr *MockISlaveConnMockRecorder) CheckMinorBlocksInRoot(rootBlock interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckMinorBlocksInRoot", reflect.TypeOf((*MockISlaveConn)(nil).CheckMinorBlocksInRoot), rootBlock)
 }
+
+// TraceTransaction mocks base method
+func (m *MockISlaveConn) TraceTransaction(txHash common.Hash, branch account.Branch, config *rpc.TraceConfig) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TraceTransaction", txHash, branch, config)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TraceTransaction indicates an expected call of TraceTransaction
+func (mr *MockISlaveConnMockRecorder) TraceTransaction(txHash, branch, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TraceTransaction", reflect.TypeOf((*MockISlaveConn)(nil).TraceTransaction), txHash, branch, config)
+}
+
+// TraceMinorBlock mocks base method
+func (m *MockISlaveConn) TraceMinorBlock(blockHash common.Hash, branch account.Branch, config *rpc.TraceConfig) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TraceMinorBlock", blockHash, branch, config)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TraceMinorBlock indicates an expected call of TraceMinorBlock
+func (mr *MockISlaveConnMockRecorder) TraceMinorBlock(blockHash, branch, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TraceMinorBlock", reflect.TypeOf((*MockISlaveConn)(nil).TraceMinorBlock), blockHash, branch, config)
+}
+
+// GetProof mocks base method
+func (m *MockISlaveConn) GetProof(address *account.Address, storageKeys []common.Hash, height *uint64) (*rpc.AccountProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProof", address, storageKeys, height)
+	ret0, _ := ret[0].(*rpc.AccountProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProof indicates an expected call of GetProof
+func (mr *MockISlaveConnMockRecorder) GetProof(address, storageKeys, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProof", reflect.TypeOf((*MockISlaveConn)(nil).GetProof), address, storageKeys, height)
+}
+
+// GetPendingTransactionHashes mocks base method
+func (m *MockISlaveConn) GetPendingTransactionHashes(branch account.Branch) ([]common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingTransactionHashes", branch)
+	ret0, _ := ret[0].([]common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingTransactionHashes indicates an expected call of GetPendingTransactionHashes
+func (mr *MockISlaveConnMockRecorder) GetPendingTransactionHashes(branch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingTransactionHashes", reflect.TypeOf((*MockISlaveConn)(nil).GetPendingTransactionHashes), branch)
+}
+
+// GetXShardDepositStatus mocks base method
+func (m *MockISlaveConn) GetXShardDepositStatus(txHash common.Hash, branch account.Branch, sourceBlockHash, rootBlockHash common.Hash) (*rpc.XShardDepositStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetXShardDepositStatus", txHash, branch, sourceBlockHash, rootBlockHash)
+	ret0, _ := ret[0].(*rpc.XShardDepositStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetXShardDepositStatus indicates an expected call of GetXShardDepositStatus
+func (mr *MockISlaveConnMockRecorder) GetXShardDepositStatus(txHash, branch, sourceBlockHash, rootBlockHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetXShardDepositStatus", reflect.TypeOf((*MockISlaveConn)(nil).GetXShardDepositStatus), txHash, branch, sourceBlockHash, rootBlockHash)
+}
+
+// GetFeeHistory mocks base method
+func (m *MockISlaveConn) GetFeeHistory(branch account.Branch, tokenID, blockCount uint64, percentiles []uint32) (*rpc.FeeHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeHistory", branch, tokenID, blockCount, percentiles)
+	ret0, _ := ret[0].(*rpc.FeeHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeHistory indicates an expected call of GetFeeHistory
+func (mr *MockISlaveConnMockRecorder) GetFeeHistory(branch, tokenID, blockCount, percentiles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeHistory", reflect.TypeOf((*MockISlaveConn)(nil).GetFeeHistory), branch, tokenID, blockCount, percentiles)
+}
+
+// GetTxPoolContent mocks base method
+func (m *MockISlaveConn) GetTxPoolContent(branch account.Branch, sender *account.Recipient, countOnly bool) ([]*rpc.ShardTxPool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTxPoolContent", branch, sender, countOnly)
+	ret0, _ := ret[0].([]*rpc.ShardTxPool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTxPoolContent indicates an expected call of GetTxPoolContent
+func (mr *MockISlaveConnMockRecorder) GetTxPoolContent(branch, sender, countOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxPoolContent", reflect.TypeOf((*MockISlaveConn)(nil).GetTxPoolContent), branch, sender, countOnly)
+}
+
+// ExportShard mocks base method
+func (m *MockISlaveConn) ExportShard(req *rpc.ExportShardRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportShard", req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportShard indicates an expected call of ExportShard
+func (mr *MockISlaveConnMockRecorder) ExportShard(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportShard", reflect.TypeOf((*MockISlaveConn)(nil).ExportShard), req)
+}
+
+// ImportShard mocks base method
+func (m *MockISlaveConn) ImportShard(req *rpc.ImportShardRequest) (*rpc.ImportShardResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportShard", req)
+	ret0, _ := ret[0].(*rpc.ImportShardResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportShard indicates an expected call of ImportShard
+func (mr *MockISlaveConnMockRecorder) ImportShard(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportShard", reflect.TypeOf((*MockISlaveConn)(nil).ImportShard), req)
+}
+
+// ShardMoved mocks base method
+func (m *MockISlaveConn) ShardMoved(req *rpc.ShardMovedRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShardMoved", req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ShardMoved indicates an expected call of ShardMoved
+func (mr *MockISlaveConnMockRecorder) ShardMoved(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShardMoved", reflect.TypeOf((*MockISlaveConn)(nil).ShardMoved), req)
+}
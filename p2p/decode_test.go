@@ -139,6 +139,26 @@ func verifySerializeData(t *testing.T, decodeMsg QKCMsg, v codeC) error {
 		if err := serialize.DeserializeFromBytes(decodeMsg.Data, &cmd); err != nil {
 			t.Fatal("deserialize from Bytes err", err)
 		}
+	case GetMinorStateNodeListRequestMsg:
+		cmd := new(GetMinorStateNodeListRequest)
+		if err := serialize.DeserializeFromBytes(decodeMsg.Data, &cmd); err != nil {
+			t.Fatal("deserialize from Bytes err", err)
+		}
+	case GetMinorStateNodeListResponseMsg:
+		cmd := new(GetMinorStateNodeListResponse)
+		if err := serialize.DeserializeFromBytes(decodeMsg.Data, &cmd); err != nil {
+			t.Fatal("deserialize from Bytes err", err)
+		}
+	case GetCrossShardTxListRequestMsg:
+		cmd := new(GetCrossShardTxListRequest)
+		if err := serialize.DeserializeFromBytes(decodeMsg.Data, &cmd); err != nil {
+			t.Fatal("deserialize from Bytes err", err)
+		}
+	case GetCrossShardTxListResponseMsg:
+		cmd := new(GetCrossShardTxListResponse)
+		if err := serialize.DeserializeFromBytes(decodeMsg.Data, &cmd); err != nil {
+			t.Fatal("deserialize from Bytes err", err)
+		}
 	default:
 		t.Fatal("unexcepted decodeMsg op")
 	}
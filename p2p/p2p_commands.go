@@ -37,6 +37,10 @@ const (
 	NewRootBlockMsg
 	GetMinorBlockHeaderListWithSkipRequestMsg
 	GetMinorBlockHeaderListWithSkipResponseMsg
+	GetMinorStateNodeListRequestMsg
+	GetMinorStateNodeListResponseMsg
+	GetCrossShardTxListRequestMsg
+	GetCrossShardTxListResponseMsg
 	MaxOPNum
 )
 
@@ -63,6 +67,10 @@ var OPSerializerMap = map[P2PCommandOp]interface{}{
 	NewRootBlockMsg:                            NewRootBlockCommand{},
 	GetMinorBlockHeaderListWithSkipRequestMsg:  GetMinorBlockHeaderListWithSkipRequest{},
 	GetMinorBlockHeaderListWithSkipResponseMsg: GetMinorBlockHeaderListResponse{},
+	GetMinorStateNodeListRequestMsg:            GetMinorStateNodeListRequest{},
+	GetMinorStateNodeListResponseMsg:           GetMinorStateNodeListResponse{},
+	GetCrossShardTxListRequestMsg:              GetCrossShardTxListRequest{},
+	GetCrossShardTxListResponseMsg:             GetCrossShardTxListResponse{},
 }
 
 func (p P2PCommandOp) String() string {
@@ -241,3 +249,38 @@ func (r *GetMinorBlockHeaderListWithSkipRequest) GetHash() common.Hash {
 	}
 	return common.Hash{}
 }
+
+// GetMinorStateNodeListRequest get the state trie nodes or contract codes of
+// the given hashes, for fast sync
+type GetMinorStateNodeListRequest struct {
+	NodeHashList []common.Hash `bytesizeofslicelen:"4"`
+}
+
+// StateNode is a state trie node or a contract code
+type StateNode struct {
+	Data []byte `bytesizeofslicelen:"4"`
+}
+
+// GetMinorStateNodeListResponse get minor state node list response, the nodes
+// the peer does not have are left out
+type GetMinorStateNodeListResponse struct {
+	NodeList []*StateNode `bytesizeofslicelen:"4"`
+}
+
+// GetCrossShardTxListRequest get the cross-shard deposits the given minor
+// blocks of the neighbor shards made to the shard of the request
+type GetCrossShardTxListRequest struct {
+	MinorBlockHashList []common.Hash `bytesizeofslicelen:"4"`
+}
+
+// CrossShardTxList is the cross-shard deposits of a minor block
+type CrossShardTxList struct {
+	MinorBlockHash common.Hash
+	TxList         []*types.CrossShardTransactionDeposit `bytesizeofslicelen:"4"`
+}
+
+// GetCrossShardTxListResponse get cross shard tx list response, the minor
+// blocks the peer does not have the deposits of are left out
+type GetCrossShardTxListResponse struct {
+	CrossShardTxList []*CrossShardTxList `bytesizeofslicelen:"4"`
+}
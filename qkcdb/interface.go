@@ -21,3 +21,38 @@ const (
 	cache   int = 128
 	handles     = 256
 )
+
+// IsReadOnly returns whether the database drops the changes instead of
+// committing them, as the databases opened for the db check do.
+func IsReadOnly(db Database) bool {
+	ro, ok := db.(interface{ IsReadOnly() bool })
+	return ok && ro.IsReadOnly()
+}
+
+// readOnlyBatch is the batch of a read-only database, which drops the changes
+// instead of committing them.
+type readOnlyBatch struct {
+	size int
+}
+
+func (b *readOnlyBatch) Put(key, value []byte) error {
+	b.size += len(value)
+	return nil
+}
+
+func (b *readOnlyBatch) Delete(key []byte) error {
+	b.size++
+	return nil
+}
+
+func (b *readOnlyBatch) Write() error {
+	return nil
+}
+
+func (b *readOnlyBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *readOnlyBatch) Reset() {
+	b.size = 0
+}
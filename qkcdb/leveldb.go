@@ -26,6 +26,12 @@ func NewDatabase(file string, clean, isReadOnly bool) (*QKCDataBase, error) {
 	return &QKCDataBase{LDBDatabase: db, isReadOnly: isReadOnly}, err
 }
 
+// IsReadOnly returns whether the database drops the changes instead of
+// committing them.
+func (db *QKCDataBase) IsReadOnly() bool {
+	return db.isReadOnly
+}
+
 // Put puts the given key / value to the queue
 func (db *QKCDataBase) Put(key []byte, value []byte) error {
 	if db.isReadOnly {
@@ -33,3 +39,20 @@ func (db *QKCDataBase) Put(key []byte, value []byte) error {
 	}
 	return db.LDBDatabase.Put(key, value)
 }
+
+// Delete deletes the key from the database
+func (db *QKCDataBase) Delete(key []byte) error {
+	if db.isReadOnly {
+		return nil
+	}
+	return db.LDBDatabase.Delete(key)
+}
+
+// NewBatch returns a batch writing to the database, which drops the changes if
+// the database is read-only.
+func (db *QKCDataBase) NewBatch() Batch {
+	if db.isReadOnly {
+		return new(readOnlyBatch)
+	}
+	return db.LDBDatabase.NewBatch()
+}
@@ -73,6 +73,12 @@ func (db *QKCDataBase) Path() string {
 	return db.fn
 }
 
+// IsReadOnly returns whether the database drops the changes instead of
+// committing them.
+func (db *QKCDataBase) IsReadOnly() bool {
+	return db.isReadOnly
+}
+
 // Put puts the given key / value to the queue
 func (db *QKCDataBase) Put(key []byte, value []byte) error {
 	if db.isReadOnly {
@@ -112,6 +118,9 @@ func (db *QKCDataBase) Get(key []byte) ([]byte, error) {
 
 // Delete deletes the key from the queue and database
 func (db *QKCDataBase) Delete(key []byte) error {
+	if db.isReadOnly {
+		return nil
+	}
 	return db.db.Delete(db.wo, key)
 }
 
@@ -128,6 +137,9 @@ func (db *QKCDataBase) Close() {
 }
 
 func (db *QKCDataBase) NewBatch() Batch {
+	if db.isReadOnly {
+		return new(readOnlyBatch)
+	}
 	return &rdbBatch{db: db.db /*ro: db.ro,*/, wo: db.wo, w: gorocksdb.NewWriteBatch()}
 }
 
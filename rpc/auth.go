@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
+)
+
+// jwtIssuedAtWindow is how far the issue time of a JWT may be from now.
+const jwtIssuedAtWindow = 60 * time.Second
+
+var (
+	errMissingCredentials = errors.New("missing bearer token")
+	errInvalidCredentials = errors.New("invalid bearer token")
+)
+
+// allowList holds the namespaces and methods a caller is allowed to call.
+type allowList map[string]struct{}
+
+func newAllowList(entries []string) allowList {
+	allow := make(allowList, len(entries))
+	for _, entry := range entries {
+		allow[entry] = struct{}{}
+	}
+	return allow
+}
+
+func (a allowList) allows(namespace, method string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	if _, ok := a[namespace]; ok {
+		return true
+	}
+	_, ok := a[namespace+serviceMethodSeparator+method]
+	return ok
+}
+
+type authGrantKey struct{}
+
+// Authenticator checks the credentials of the requests made to an endpoint and
+// grants them the allow list of the credentials.
+type Authenticator struct {
+	jwtSecret []byte
+	jwtAllow  allowList
+	tokens    map[string]allowList
+	anonymous allowList // nil if anonymous callers are rejected
+}
+
+// NewAuthenticator returns nil when the config does not require any credentials.
+func NewAuthenticator(config *rpcconfig.AuthConfig) (*Authenticator, error) {
+	if config == nil || (config.JWTSecretFile == "" && len(config.Tokens) == 0) {
+		return nil, nil
+	}
+	a := &Authenticator{
+		jwtAllow: newAllowList(config.JWTAllow),
+		tokens:   make(map[string]allowList),
+	}
+	if config.JWTSecretFile != "" {
+		data, err := ioutil.ReadFile(config.JWTSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT secret: %v", err)
+		}
+		secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT secret: %v", err)
+		}
+		if len(secret) < 32 {
+			return nil, errors.New("JWT secret should be at least 32 bytes")
+		}
+		a.jwtSecret = secret
+	}
+	for _, token := range config.Tokens {
+		if token.Token == "" {
+			return nil, errors.New("empty bearer token")
+		}
+		a.tokens[token.Token] = newAllowList(token.Allow)
+	}
+	if len(config.AnonymousAllow) > 0 {
+		a.anonymous = newAllowList(config.AnonymousAllow)
+	}
+	return a, nil
+}
+
+// authenticate returns the allow list granted to the request.
+func (a *Authenticator) authenticate(r *http.Request) (allowList, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		if a.anonymous == nil {
+			return nil, errMissingCredentials
+		}
+		return a.anonymous, nil
+	}
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, errInvalidCredentials
+	}
+	token := strings.TrimSpace(header[len("Bearer "):])
+	if allow, ok := a.tokens[token]; ok {
+		return allow, nil
+	}
+	if a.jwtSecret != nil && strings.Count(token, ".") == 2 {
+		if err := verifyJWT(token, a.jwtSecret, time.Now()); err != nil {
+			return nil, err
+		}
+		return a.jwtAllow, nil
+	}
+	return nil, errInvalidCredentials
+}
+
+// verifyJWT verifies a HS256 JWT which was issued within jwtIssuedAtWindow of
+// now and has not expired.
+func verifyJWT(token string, secret []byte, now time.Time) error {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return errInvalidCredentials
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errInvalidCredentials
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errInvalidCredentials
+	}
+	var claims struct {
+		IssuedAt  *int64 `json:"iat"`
+		ExpiresAt *int64 `json:"exp"`
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return errInvalidCredentials
+	}
+	if claims.IssuedAt == nil {
+		return errors.New("missing issued-at claim")
+	}
+	if issued := time.Unix(*claims.IssuedAt, 0); issued.Before(now.Add(-jwtIssuedAtWindow)) || issued.After(now.Add(jwtIssuedAtWindow)) {
+		return errors.New("stale token")
+	}
+	if claims.ExpiresAt != nil && now.Unix() >= *claims.ExpiresAt {
+		return errors.New("expired token")
+	}
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
+)
+
+func signJWT(secret []byte, claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(header + "." + payload))
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func serveAuthenticated(srv *Server, body, authorization string) (int, string) {
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(body))
+	request.Header.Set("content-type", contentType)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	srv.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestHTTPAuthentication(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	secret := make([]byte, 32)
+	secret[0] = 1
+	secretFile := filepath.Join(dir, "jwt.hex")
+	if err := ioutil.WriteFile(secretFile, []byte(hex.EncodeToString(secret)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newTestServer("service", new(Service))
+	srv.auth, err = NewAuthenticator(&rpcconfig.AuthConfig{
+		JWTSecretFile:  secretFile,
+		Tokens:         []*rpcconfig.AuthToken{{Token: "monitor", Allow: []string{"service_rets"}}},
+		AnonymousAllow: []string{"service_noArgsRets"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	echo := `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["hello",10,{"S":"world"}]}`
+	rets := `{"jsonrpc":"2.0","id":2,"method":"service_rets","params":[]}`
+	noArgs := `{"jsonrpc":"2.0","id":3,"method":"service_noArgsRets","params":[]}`
+	now := time.Now().Unix()
+
+	tests := []struct {
+		body          string
+		authorization string
+		code          int
+		allowed       bool
+	}{
+		{noArgs, "", http.StatusOK, true},
+		{echo, "", http.StatusOK, false},
+		{rets, "Bearer monitor", http.StatusOK, true},
+		{echo, "Bearer monitor", http.StatusOK, false},
+		{echo, "Bearer unknown", http.StatusUnauthorized, false},
+		{echo, "Basic monitor", http.StatusUnauthorized, false},
+		{echo, "Bearer " + signJWT(secret, fmt.Sprintf(`{"iat":%d}`, now)), http.StatusOK, true},
+		{echo, "Bearer " + signJWT(secret, fmt.Sprintf(`{"iat":%d}`, now-120)), http.StatusUnauthorized, false},
+		{echo, "Bearer " + signJWT(secret, fmt.Sprintf(`{"iat":%d,"exp":%d}`, now, now-1)), http.StatusUnauthorized, false},
+		{echo, "Bearer " + signJWT(make([]byte, 32), fmt.Sprintf(`{"iat":%d}`, now)), http.StatusUnauthorized, false},
+		{echo, "Bearer " + signJWT(secret, `{}`), http.StatusUnauthorized, false},
+	}
+	for i, test := range tests {
+		code, body := serveAuthenticated(srv, test.body, test.authorization)
+		if code != test.code {
+			t.Fatalf("test %d: response code should be %d not %d", i, test.code, code)
+		}
+		if code != http.StatusOK {
+			continue
+		}
+		var resp jsonErrResponse
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			t.Fatal(err)
+		}
+		notAllowed := resp.Error.Code == (&methodNotAllowedError{}).ErrorCode()
+		if notAllowed == test.allowed {
+			t.Fatalf("test %d: unexpected response %s", i, body)
+		}
+	}
+}
@@ -22,8 +22,10 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules,
+// the calls it serves are accounted under name, limited by limiter and authenticated
+// by auth unless they are nil
+func StartHTTPEndpoint(endpoint string, name string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, limiter *RateLimiter, auth *Authenticator) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -31,6 +33,9 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.endpoint = name
+	handler.limiter = limiter
+	handler.auth = auth
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -51,8 +56,9 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	return listener, handler, err
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+// StartWSEndpoint starts a websocket endpoint, the calls it serves are limited by
+// limiter unless it is nil
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, limiter *RateLimiter) (net.Listener, *Server, error) {
 
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
@@ -61,6 +67,8 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.endpoint = EndpointWS
+	handler.limiter = limiter
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(MetadataApi, api.Service); err != nil {
@@ -86,6 +94,7 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, error) {
 	// Register all the APIs exposed by the services.
 	handler := NewServer()
+	handler.endpoint = EndpointIPC
 	for _, api := range apis {
 		if err := handler.RegisterName(MetadataApi, api.Service); err != nil {
 			return nil, nil, err
@@ -64,3 +64,22 @@ type shutdownError struct{}
 func (e *shutdownError) ErrorCode() int { return -32000 }
 
 func (e *shutdownError) Error() string { return "server is shutting down" }
+
+// issued when the credentials of a client do not allow the method.
+type methodNotAllowedError struct {
+	service string
+	method  string
+}
+
+func (e *methodNotAllowedError) ErrorCode() int { return -32001 }
+
+func (e *methodNotAllowedError) Error() string {
+	return fmt.Sprintf("The method %s%s%s is not allowed", e.service, serviceMethodSeparator, e.method)
+}
+
+// issued when the calls of a client are over the rate limits of the endpoint.
+type rateLimitError struct{}
+
+func (e *rateLimitError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitError) Error() string { return "rate limit exceeded" }
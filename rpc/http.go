@@ -244,8 +244,36 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if origin := r.Header.Get("Origin"); origin != "" {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
+	if srv.auth != nil {
+		allow, err := srv.auth.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		ctx = context.WithValue(ctx, authGrantKey{}, allow)
+	}
 
 	body := io.LimitReader(r.Body, maxRequestContentLength)
+	if srv.limiter != nil {
+		subject, err := srv.limiter.subject(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		msg, err := ioutil.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if reqs, batch := parseRequestMethods(msg); !srv.limiter.allow(subject, reqs) {
+			w.Header().Set("content-type", contentType)
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(rateLimitResponse(reqs, batch))
+			return
+		}
+		body = bytes.NewReader(msg)
+	}
 	codec := NewJSONCodec(&httpReadWriteNopCloser{body, w})
 	defer codec.Close()
 
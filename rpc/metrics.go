@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Endpoints the calls served by a server are accounted under.
+const (
+	EndpointHTTP     = "http"
+	EndpointPrivHTTP = "privhttp"
+	EndpointWS       = "ws"
+	EndpointIPC      = "ipc"
+	EndpointInProc   = "inproc"
+)
+
+// LatencyBuckets are the upper bounds of the latency histograms of the calls,
+// slower calls are counted in an extra bucket.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// CallStats is the accounting of the calls made to a method on an endpoint.
+type CallStats struct {
+	Endpoint  string
+	Method    string
+	Calls     uint64
+	Errors    uint64
+	TotalTime time.Duration
+	// Histogram counts the calls by LatencyBuckets, the last entry counts the
+	// calls slower than all the buckets.
+	Histogram []uint64
+}
+
+type callKey struct {
+	endpoint string
+	method   string
+}
+
+type callMeter struct {
+	stats  CallStats
+	calls  metrics.Counter
+	errors metrics.Counter
+	timer  metrics.Timer
+}
+
+var (
+	callMetersMu sync.Mutex
+	callMeters   = make(map[callKey]*callMeter)
+)
+
+// recordCall accounts a call to method on endpoint which started at start. The
+// calls are also reported to the metrics registry as rpc/<endpoint>/<method>.
+func recordCall(endpoint, method string, start time.Time, failed bool) {
+	elapsed := time.Since(start)
+	bucket := sort.Search(len(LatencyBuckets), func(i int) bool { return elapsed <= LatencyBuckets[i] })
+
+	callMetersMu.Lock()
+	defer callMetersMu.Unlock()
+	key := callKey{endpoint: endpoint, method: method}
+	meter, ok := callMeters[key]
+	if !ok {
+		prefix := "rpc/" + endpoint + "/" + method
+		meter = &callMeter{
+			stats:  CallStats{Endpoint: endpoint, Method: method, Histogram: make([]uint64, len(LatencyBuckets)+1)},
+			calls:  metrics.GetOrRegisterCounter(prefix+"/calls", nil),
+			errors: metrics.GetOrRegisterCounter(prefix+"/errors", nil),
+			timer:  metrics.GetOrRegisterTimer(prefix+"/duration", nil),
+		}
+		callMeters[key] = meter
+	}
+	meter.stats.Calls++
+	meter.stats.TotalTime += elapsed
+	meter.stats.Histogram[bucket]++
+	meter.calls.Inc(1)
+	meter.timer.Update(elapsed)
+	if failed {
+		meter.stats.Errors++
+		meter.errors.Inc(1)
+	}
+}
+
+// Calls returns the accounting of the calls served since the process started,
+// sorted by endpoint and method.
+func Calls() []*CallStats {
+	callMetersMu.Lock()
+	stats := make([]*CallStats, 0, len(callMeters))
+	for _, meter := range callMeters {
+		s := meter.stats
+		s.Histogram = append([]uint64(nil), meter.stats.Histogram...)
+		stats = append(stats, &s)
+	}
+	callMetersMu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Endpoint != stats[j].Endpoint {
+			return stats[i].Endpoint < stats[j].Endpoint
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
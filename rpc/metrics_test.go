@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"testing"
+)
+
+func TestCallAccounting(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	server.endpoint = "test"
+	client := DialInProc(server)
+	defer client.Close()
+
+	var resp Result
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&resp, "service_echo", "hello", 10, &Args{"world"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Call(&resp, "service_echo", "hello", "10"); err == nil {
+		t.Fatal("expected invalid params error")
+	}
+	if err := client.Call(&resp, "service_unknown"); err == nil {
+		t.Fatal("expected method not found error")
+	}
+
+	var stats []*CallStats
+	for _, s := range Calls() {
+		if s.Endpoint == "test" {
+			stats = append(stats, s)
+		}
+	}
+	if len(stats) != 1 {
+		t.Fatalf("accounted methods mismatch: have %d, want 1", len(stats))
+	}
+	if stats[0].Method != "service_echo" || stats[0].Calls != 3 || stats[0].Errors != 1 {
+		t.Fatalf("unexpected stats %+v", stats[0])
+	}
+	var histogram uint64
+	for _, count := range stats[0].Histogram {
+		histogram += count
+	}
+	if len(stats[0].Histogram) != len(LatencyBuckets)+1 || histogram != 3 {
+		t.Fatalf("unexpected histogram %v", stats[0].Histogram)
+	}
+}
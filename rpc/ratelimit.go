@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
+)
+
+const (
+	apiKeyHeader = "X-Api-Key"
+	apiKeyQuery  = "apikey" // for websocket clients unable to set headers
+
+	rateLimitPruneInterval = time.Minute
+)
+
+var errInvalidAPIKey = errors.New("invalid API key")
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter charges the calls made to an endpoint to token buckets kept per
+// IP and per API key.
+type RateLimiter struct {
+	ipRate      float64
+	ipBurst     float64
+	apiKeyRate  float64
+	apiKeyBurst float64
+	apiKeys     map[string]struct{}
+	methodCosts map[string]float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	pruned  time.Time
+}
+
+// NewRateLimiter returns nil when the config does not limit any call. The burst
+// defaults to the rate when it is not set.
+func NewRateLimiter(config *rpcconfig.RateLimitConfig) *RateLimiter {
+	if config == nil || (config.IPRate <= 0 && config.APIKeyRate <= 0) {
+		return nil
+	}
+	l := &RateLimiter{
+		ipRate:      config.IPRate,
+		ipBurst:     math.Max(config.IPBurst, config.IPRate),
+		apiKeyRate:  config.APIKeyRate,
+		apiKeyBurst: math.Max(config.APIKeyBurst, config.APIKeyRate),
+		apiKeys:     make(map[string]struct{}),
+		methodCosts: make(map[string]float64),
+		buckets:     make(map[string]*tokenBucket),
+		pruned:      time.Now(),
+	}
+	for _, key := range config.APIKeys {
+		l.apiKeys[key] = struct{}{}
+	}
+	for method, cost := range config.MethodCosts {
+		l.methodCosts[method] = cost
+	}
+	return l
+}
+
+// subject returns the bucket the calls of the request are charged to, the API
+// key is taken from the X-Api-Key header or the apikey query parameter. The key
+// is ignored when the calls are not limited per API key.
+func (l *RateLimiter) subject(r *http.Request) (string, error) {
+	key := r.Header.Get(apiKeyHeader)
+	if key == "" {
+		key = r.URL.Query().Get(apiKeyQuery)
+	}
+	if key != "" && l.apiKeyRate > 0 {
+		if _, ok := l.apiKeys[key]; !ok {
+			return "", errInvalidAPIKey
+		}
+		return "key:" + key, nil
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host, nil
+}
+
+func (l *RateLimiter) limits(subject string) (rate, burst float64) {
+	if strings.HasPrefix(subject, "key:") {
+		return l.apiKeyRate, l.apiKeyBurst
+	}
+	return l.ipRate, l.ipBurst
+}
+
+// allow takes the cost of the requests from the bucket of subject and reports
+// whether the bucket held enough tokens.
+func (l *RateLimiter) allow(subject string, reqs []*jsonRequest) bool {
+	rate, burst := l.limits(subject)
+	if rate <= 0 {
+		return true
+	}
+	cost := float64(0)
+	for _, req := range reqs {
+		if c, ok := l.methodCosts[req.Method]; ok {
+			cost += c
+		} else {
+			cost++
+		}
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+	bucket, ok := l.buckets[subject]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[subject] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+	if bucket.tokens < cost {
+		return false
+	}
+	bucket.tokens -= cost
+	return true
+}
+
+// prune drops the buckets refilled up to their burst, which are the same as
+// new ones.
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < rateLimitPruneInterval {
+		return
+	}
+	l.pruned = now
+	for subject, bucket := range l.buckets {
+		rate, burst := l.limits(subject)
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rate >= burst {
+			delete(l.buckets, subject)
+		}
+	}
+}
+
+// parseRequestMethods returns the requests of a single or batch message with
+// their method and id only, a message which cannot be parsed counts as a
+// single request to an unknown method.
+func parseRequestMethods(msg json.RawMessage) ([]*jsonRequest, bool) {
+	if isBatch(msg) {
+		var reqs []*jsonRequest
+		if err := json.Unmarshal(msg, &reqs); err == nil && len(reqs) > 0 {
+			return reqs, true
+		}
+		return []*jsonRequest{{}}, true
+	}
+	req := new(jsonRequest)
+	if err := json.Unmarshal(msg, req); err != nil {
+		return []*jsonRequest{{}}, false
+	}
+	return []*jsonRequest{req}, false
+}
+
+// rateLimitResponse returns the error responses to requests over the limits.
+func rateLimitResponse(reqs []*jsonRequest, batch bool) interface{} {
+	err := &rateLimitError{}
+	resps := make([]*jsonErrResponse, len(reqs))
+	for i, req := range reqs {
+		resps[i] = &jsonErrResponse{
+			Version: jsonrpcVersion,
+			Id:      req.Id,
+			Error:   jsonError{Code: err.ErrorCode(), Message: err.Error()},
+		}
+	}
+	if !batch {
+		return resps[0]
+	}
+	return resps
+}
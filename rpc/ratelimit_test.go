@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/QuarkChain/goquarkchain/rpc/rpcconfig"
+)
+
+func serveRateLimited(srv *Server, body, apiKey string) (int, json.RawMessage) {
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(body))
+	request.Header.Set("content-type", contentType)
+	if apiKey != "" {
+		request.Header.Set(apiKeyHeader, apiKey)
+	}
+	recorder := httptest.NewRecorder()
+	srv.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.Bytes()
+}
+
+func TestHTTPRateLimit(t *testing.T) {
+	srv := newTestServer("service", new(Service))
+	srv.limiter = NewRateLimiter(&rpcconfig.RateLimitConfig{
+		IPRate:      0.001,
+		IPBurst:     3,
+		APIKeyRate:  0.001,
+		APIKeyBurst: 10,
+		APIKeys:     []string{"key"},
+		MethodCosts: map[string]float64{"service_echo": 2},
+	})
+	echo := `{"jsonrpc":"2.0","id":1,"method":"service_echo","params":["hello",10,{"S":"world"}]}`
+	rets := `{"jsonrpc":"2.0","id":2,"method":"service_rets","params":[]}`
+
+	if code, _ := serveRateLimited(srv, echo, ""); code != http.StatusOK {
+		t.Fatalf("response code should be %d not %d", http.StatusOK, code)
+	}
+	// the bucket holds a single token
+	code, body := serveRateLimited(srv, echo, "")
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("response code should be %d not %d", http.StatusTooManyRequests, code)
+	}
+	var resp jsonErrResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Code != (&rateLimitError{}).ErrorCode() || resp.Id.(float64) != 1 {
+		t.Fatalf("unexpected response %s", body)
+	}
+	if code, _ := serveRateLimited(srv, rets, ""); code != http.StatusOK {
+		t.Fatalf("response code should be %d not %d", http.StatusOK, code)
+	}
+
+	// API keys have their own buckets
+	code, _ = serveRateLimited(srv, "["+echo+","+rets+","+echo+","+rets+"]", "key")
+	if code != http.StatusOK {
+		t.Fatalf("response code should be %d not %d", http.StatusOK, code)
+	}
+	code, body = serveRateLimited(srv, "["+echo+","+rets+","+echo+"]", "key")
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("response code should be %d not %d", http.StatusTooManyRequests, code)
+	}
+	var resps []jsonErrResponse
+	if err := json.Unmarshal(body, &resps); err != nil {
+		t.Fatal(err)
+	}
+	if len(resps) != 3 {
+		t.Fatalf("unexpected response %s", body)
+	}
+	if code, _ := serveRateLimited(srv, rets, "unknown"); code != http.StatusUnauthorized {
+		t.Fatalf("response code should be %d not %d", http.StatusUnauthorized, code)
+	}
+
+	// the API keys are ignored when they are not limited, the calls are
+	// charged to the IP
+	srv.limiter = NewRateLimiter(&rpcconfig.RateLimitConfig{IPRate: 0.001, IPBurst: 1})
+	if code, _ := serveRateLimited(srv, rets, "unknown"); code != http.StatusOK {
+		t.Fatalf("response code should be %d not %d", http.StatusOK, code)
+	}
+	if code, _ := serveRateLimited(srv, rets, "key"); code != http.StatusTooManyRequests {
+		t.Fatalf("response code should be %d not %d", http.StatusTooManyRequests, code)
+	}
+}
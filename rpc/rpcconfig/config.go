@@ -0,0 +1,58 @@
+// Package rpcconfig defines the configuration of the limits and of the
+// authentication of the RPC endpoints, which the cluster config holds without
+// depending on the rpc package.
+package rpcconfig
+
+// RateLimitConfig configures the token buckets limiting the calls served by the
+// public endpoints. Each IP, or each API key when a known one is given and the
+// limits per API key are enabled, has a bucket refilled with rate tokens per second up to burst tokens, and each call
+// takes the cost of its method from the bucket, 1 unless set in MethodCosts.
+type RateLimitConfig struct {
+	IPRate      float64            `json:"IP_RATE"` // 0 disables the limits per IP
+	IPBurst     float64            `json:"IP_BURST"`
+	APIKeyRate  float64            `json:"API_KEY_RATE"` // 0 disables the limits per API key
+	APIKeyBurst float64            `json:"API_KEY_BURST"`
+	APIKeys     []string           `json:"API_KEYS"`
+	MethodCosts map[string]float64 `json:"METHOD_COSTS"`
+}
+
+func NewRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		IPRate:      0,
+		IPBurst:     0,
+		APIKeyRate:  0,
+		APIKeyBurst: 0,
+		APIKeys:     []string{},
+		MethodCosts: map[string]float64{
+			"qkc_getLogs":                  10,
+			"qkc_getAllTransactions":       10,
+			"qkc_getTransactionsByAddress": 5,
+			"eth_getLogs":                  10,
+		},
+	}
+}
+
+// AuthConfig configures the authentication of the calls to an endpoint. Callers
+// present either a JWT signed with HS256 by the shared secret or one of the
+// tokens in an "Authorization: Bearer" header. Allow lists hold namespaces such
+// as "debug" or methods such as "qkc_getStats", an empty list allows every call.
+type AuthConfig struct {
+	JWTSecretFile  string       `json:"JWT_SECRET_FILE"` // file holding the hex encoded secret
+	JWTAllow       []string     `json:"JWT_ALLOW"`
+	Tokens         []*AuthToken `json:"TOKENS"`
+	AnonymousAllow []string     `json:"ANONYMOUS_ALLOW"` // calls allowed without credentials
+}
+
+type AuthToken struct {
+	Token string   `json:"TOKEN"`
+	Allow []string `json:"ALLOW"`
+}
+
+func NewAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		JWTSecretFile:  "",
+		JWTAllow:       []string{},
+		Tokens:         []*AuthToken{},
+		AnonymousAllow: []string{},
+	}
+}
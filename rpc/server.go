@@ -24,6 +24,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/deckarep/golang-set"
 	"github.com/ethereum/go-ethereum/log"
@@ -48,6 +49,7 @@ func NewServer() *Server {
 		services: make(serviceRegistry),
 		codecs:   mapset.NewSet(),
 		run:      1,
+		endpoint: EndpointInProc,
 	}
 
 	// register a default service which will provide meta information about the RPC service such as the services and
@@ -321,15 +323,35 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	return codec.CreateResponse(req.id, reply[0].Interface()), nil
 }
 
-// exec executes the given request and writes the result back using the codec.
-func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
+// call executes the given request and accounts it under the method it was made
+// to, requests to unknown methods are not accounted.
+func (s *Server) call(ctx context.Context, codec ServerCodec, req *serverRequest) (interface{}, func()) {
+	if req.callb == nil {
+		if req.err != nil {
+			return codec.CreateErrorResponse(&req.id, req.err), nil
+		}
+		return s.handle(ctx, codec, req)
+	}
+
+	start := time.Now()
+	method := formatName(req.callb.method.Name)
 	var response interface{}
 	var callback func()
-	if req.err != nil {
+	if allow, ok := ctx.Value(authGrantKey{}).(allowList); ok && !allow.allows(req.svcname, method) {
+		response = codec.CreateErrorResponse(&req.id, &methodNotAllowedError{req.svcname, method})
+	} else if req.err != nil {
 		response = codec.CreateErrorResponse(&req.id, req.err)
 	} else {
 		response, callback = s.handle(ctx, codec, req)
 	}
+	_, failed := response.(*jsonErrResponse)
+	recordCall(s.endpoint, req.svcname+serviceMethodSeparator+method, start, failed)
+	return response, callback
+}
+
+// exec executes the given request and writes the result back using the codec.
+func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
+	response, callback := s.call(ctx, codec, req)
 
 	if err := codec.Write(response); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -348,13 +370,9 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 	responses := make([]interface{}, len(requests))
 	var callbacks []func()
 	for i, req := range requests {
-		if req.err != nil {
-			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
-		} else {
-			var callback func()
-			if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
-				callbacks = append(callbacks, callback)
-			}
+		var callback func()
+		if responses[i], callback = s.call(ctx, codec, req); callback != nil {
+			callbacks = append(callbacks, callback)
 		}
 	}
 
@@ -79,6 +79,10 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	endpoint string         // endpoint the served calls are accounted under
+	limiter  *RateLimiter   // limits of the calls served over HTTP and websocket, nil if unlimited
+	auth     *Authenticator // credentials required by the calls served over HTTP, nil if none
 }
 
 // rpcRequest represents a raw incoming RPC request
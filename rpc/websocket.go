@@ -57,8 +57,18 @@ var websocketJSONCodec = websocket.Codec{
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
+	validateOrigin := wsHandshakeValidator(allowedOrigins)
 	return websocket.Server{
-		Handshake: wsHandshakeValidator(allowedOrigins),
+		Handshake: func(cfg *websocket.Config, req *http.Request) error {
+			if err := validateOrigin(cfg, req); err != nil {
+				return err
+			}
+			if srv.limiter != nil {
+				_, err := srv.limiter.subject(req)
+				return err
+			}
+			return nil
+		},
 		Handler: func(conn *websocket.Conn) {
 			// Create a custom encode/decode pair to enforce payload size and number encoding
 			conn.MaxPayloadBytes = maxRequestContentLength
@@ -69,6 +79,28 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
+			if srv.limiter != nil {
+				subject, _ := srv.limiter.subject(conn.Request())
+				decoder = func(v interface{}) error {
+					// answer the messages over the limits here and pass on the others
+					for {
+						if err := websocketJSONCodec.Receive(conn, v); err != nil {
+							return err
+						}
+						msg, ok := v.(*json.RawMessage)
+						if !ok {
+							return nil
+						}
+						reqs, batch := parseRequestMethods(*msg)
+						if srv.limiter.allow(subject, reqs) {
+							return nil
+						}
+						if err := encoder(rateLimitResponse(reqs, batch)); err != nil {
+							return err
+						}
+					}
+				}
+			}
 			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
 		},
 	}